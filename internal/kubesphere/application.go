@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ApplicationResource is the resource of applications.
+var ApplicationResource = schema.GroupVersionResource{Group: "app.k8s.io", Version: "v1beta1", Resource: "applications"}
+
+// ApplicationVersionLabel is the label of applications holding their version.
+const ApplicationVersionLabel = "app.kubernetes.io/version"
+
+// ApplicationAssemblyPhase is the phase of the assembly of the components of
+// an application.
+type ApplicationAssemblyPhase string
+
+// The assembly phases of applications.
+const (
+	ApplicationPending   ApplicationAssemblyPhase = "Pending"
+	ApplicationSucceeded ApplicationAssemblyPhase = "Succeeded"
+	ApplicationFailed    ApplicationAssemblyPhase = "Failed"
+)
+
+// Application is an application of the app.k8s.io API of the Kubernetes
+// application SIG, which KubeSphere deploys app templates as.
+type Application struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationSpec   `json:"spec,omitempty"`
+	Status ApplicationStatus `json:"status,omitempty"`
+}
+
+// ApplicationSpec is the spec of an application.
+type ApplicationSpec struct {
+	Descriptor    ApplicationDescriptor    `json:"descriptor,omitempty"`
+	AssemblyPhase ApplicationAssemblyPhase `json:"assemblyPhase,omitempty"`
+}
+
+// ApplicationDescriptor describes an application.
+type ApplicationDescriptor struct {
+	// Type is the type of the application, e.g. wordpress.
+	Type string `json:"type,omitempty"`
+}
+
+// ApplicationStatus is the status of an application.
+type ApplicationStatus struct {
+	// Components are the objects the application consists of.
+	Components []ApplicationComponent `json:"components,omitempty"`
+}
+
+// ApplicationComponent is an object an application consists of.
+type ApplicationComponent struct {
+	Group string `json:"group,omitempty"`
+	Kind  string `json:"kind,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// ApplicationList is a list of applications.
+type ApplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Application `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *Application) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status.Components != nil {
+		out.Status.Components = make([]ApplicationComponent, len(in.Status.Components))
+		copy(out.Status.Components, in.Status.Components)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ApplicationList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]Application, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*Application)
+	}
+	return &out
+}
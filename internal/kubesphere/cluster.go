@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ClusterResource is the resource of clusters.
+var ClusterResource = schema.GroupVersionResource{Group: "cluster.kubesphere.io", Version: "v1alpha1", Resource: "clusters"}
+
+// The types of the conditions of clusters.
+const (
+	ClusterReady     = "Ready"
+	ClusterFederated = "Federated"
+)
+
+// Cluster is a cluster of the cluster.kubesphere.io API, i.e. the host
+// cluster or a member cluster joined to it in KubeSphere multi-cluster mode.
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterSpec   `json:"spec,omitempty"`
+	Status ClusterStatus `json:"status,omitempty"`
+}
+
+// ClusterSpec is the spec of a cluster.
+type ClusterSpec struct {
+	Provider   string            `json:"provider,omitempty"`
+	Connection ClusterConnection `json:"connection,omitempty"`
+}
+
+// ClusterConnection is the connection of the host cluster to a cluster.
+type ClusterConnection struct {
+	// Type is the type of the connection, i.e. direct, or proxy for clusters
+	// connected through the tower agent.
+	Type string `json:"type,omitempty"`
+}
+
+// ClusterStatus is the status of a cluster.
+type ClusterStatus struct {
+	Conditions        []ClusterCondition `json:"conditions,omitempty"`
+	KubernetesVersion string             `json:"kubernetesVersion,omitempty"`
+	KubeSphereVersion string             `json:"kubeSphereVersion,omitempty"`
+	NodeCount         int                `json:"nodeCount,omitempty"`
+}
+
+// ClusterCondition is a condition of a cluster.
+type ClusterCondition struct {
+	Type               string             `json:"type"`
+	Status             v1.ConditionStatus `json:"status"`
+	LastUpdateTime     metav1.Time        `json:"lastUpdateTime,omitempty"`
+	LastTransitionTime metav1.Time        `json:"lastTransitionTime,omitempty"`
+}
+
+// ClusterList is a list of clusters.
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Cluster `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *Cluster) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]ClusterCondition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ClusterList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]Cluster, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*Cluster)
+	}
+	return &out
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// PipelineResource is the resource of pipelines.
+	PipelineResource = schema.GroupVersionResource{Group: "devops.kubesphere.io", Version: "v1alpha3", Resource: "pipelines"}
+	// PipelineRunResource is the resource of pipeline runs.
+	PipelineRunResource = schema.GroupVersionResource{Group: "devops.kubesphere.io", Version: "v1alpha3", Resource: "pipelineruns"}
+)
+
+// RunPhase is the phase of a pipeline run.
+type RunPhase string
+
+// The phases of pipeline runs.
+const (
+	RunPending   RunPhase = "Pending"
+	RunRunning   RunPhase = "Running"
+	RunSucceeded RunPhase = "Succeeded"
+	RunFailed    RunPhase = "Failed"
+	RunCancelled RunPhase = "Cancelled"
+	RunUnknown   RunPhase = "Unknown"
+)
+
+// Pipeline is a pipeline of the devops.kubesphere.io API, which is run by
+// Jenkins in the namespace of a DevOps project.
+type Pipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PipelineSpec `json:"spec,omitempty"`
+}
+
+// PipelineSpec is the spec of a pipeline.
+type PipelineSpec struct {
+	// Type is the type of the pipeline, i.e. pipeline or
+	// multi-branch-pipeline.
+	Type string `json:"type"`
+}
+
+// PipelineList is a list of pipelines.
+type PipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Pipeline `json:"items"`
+}
+
+// PipelineRun is a run of a pipeline of the devops.kubesphere.io API.
+type PipelineRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PipelineRunSpec   `json:"spec,omitempty"`
+	Status PipelineRunStatus `json:"status,omitempty"`
+}
+
+// PipelineRunSpec is the spec of a pipeline run.
+type PipelineRunSpec struct {
+	// PipelineRef refers to the pipeline run.
+	PipelineRef *v1.ObjectReference `json:"pipelineRef"`
+	// SCM is the source of the run of multi-branch pipelines.
+	SCM *SCM `json:"scm,omitempty"`
+}
+
+// SCM is the source of a pipeline run.
+type SCM struct {
+	// RefName is the name of the branch or tag run.
+	RefName string `json:"refName"`
+}
+
+// PipelineRunStatus is the status of a pipeline run.
+type PipelineRunStatus struct {
+	Phase          RunPhase     `json:"phase,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PipelineRunList is a list of pipeline runs.
+type PipelineRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PipelineRun `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *Pipeline) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PipelineList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]Pipeline, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*Pipeline)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PipelineRun) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.PipelineRef != nil {
+		out.Spec.PipelineRef = in.Spec.PipelineRef.DeepCopy()
+	}
+	if in.Spec.SCM != nil {
+		scm := *in.Spec.SCM
+		out.Spec.SCM = &scm
+	}
+	if in.Status.StartTime != nil {
+		out.Status.StartTime = in.Status.StartTime.DeepCopy()
+	}
+	if in.Status.CompletionTime != nil {
+		out.Status.CompletionTime = in.Status.CompletionTime.DeepCopy()
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PipelineRunList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]PipelineRun, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*PipelineRun)
+	}
+	return &out
+}
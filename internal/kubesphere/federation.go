@@ -0,0 +1,331 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The resources of the federated types of KubeFed KubeSphere propagates to
+// the member clusters of multi-cluster projects.
+var (
+	FederatedConfigMapResource             = federatedResource("federatedconfigmaps")
+	FederatedDeploymentResource            = federatedResource("federateddeployments")
+	FederatedIngressResource               = federatedResource("federatedingresses")
+	FederatedPersistentVolumeClaimResource = federatedResource("federatedpersistentvolumeclaims")
+	FederatedSecretResource                = federatedResource("federatedsecrets")
+	FederatedServiceResource               = federatedResource("federatedservices")
+	FederatedStatefulSetResource           = federatedResource("federatedstatefulsets")
+)
+
+func federatedResource(resource string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: "types.kubefed.io", Version: "v1beta1", Resource: resource}
+}
+
+// FederatedObject is the part of the objects of the federated types, which is
+// common to all of them. The template and overrides of the propagated object
+// are left out.
+type FederatedObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FederatedSpec   `json:"spec,omitempty"`
+	Status FederatedStatus `json:"status,omitempty"`
+}
+
+// FederatedSpec is the spec of a federated object.
+type FederatedSpec struct {
+	Placement FederatedPlacement `json:"placement,omitempty"`
+}
+
+// FederatedPlacement is the placement of a federated object.
+type FederatedPlacement struct {
+	// Clusters are the member clusters the object is propagated to, unless
+	// the clusters are selected by labels.
+	Clusters []FederatedClusterReference `json:"clusters,omitempty"`
+}
+
+// FederatedClusterReference references a member cluster.
+type FederatedClusterReference struct {
+	Name string `json:"name"`
+}
+
+// FederatedStatus is the status of a federated object.
+type FederatedStatus struct {
+	Conditions []FederatedCondition     `json:"conditions,omitempty"`
+	Clusters   []FederatedClusterStatus `json:"clusters,omitempty"`
+}
+
+// FederatedCondition is a condition of a federated object, e.g. Propagation.
+type FederatedCondition struct {
+	Type   string             `json:"type"`
+	Status v1.ConditionStatus `json:"status"`
+	Reason string             `json:"reason,omitempty"`
+}
+
+// FederatedClusterStatus is the status of the propagation of a federated
+// object to a member cluster.
+type FederatedClusterStatus struct {
+	Name string `json:"name"`
+	// Status is the reason the propagation failed, e.g. CreationFailed, or
+	// empty if the object was propagated.
+	Status string `json:"status,omitempty"`
+}
+
+// Federated returns the common part of the federated object.
+func (in *FederatedObject) Federated() *FederatedObject {
+	return in
+}
+
+// DeepCopyInto copies the federated object into out.
+func (in *FederatedObject) DeepCopyInto(out *FederatedObject) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Placement.Clusters != nil {
+		out.Spec.Placement.Clusters = make([]FederatedClusterReference, len(in.Spec.Placement.Clusters))
+		copy(out.Spec.Placement.Clusters, in.Spec.Placement.Clusters)
+	}
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]FederatedCondition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	if in.Status.Clusters != nil {
+		out.Status.Clusters = make([]FederatedClusterStatus, len(in.Status.Clusters))
+		copy(out.Status.Clusters, in.Status.Clusters)
+	}
+}
+
+// FederatedConfigMap is a federated config map.
+type FederatedConfigMap struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedConfigMapList is a list of federated config maps.
+type FederatedConfigMapList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedConfigMap `json:"items"`
+}
+
+// FederatedDeployment is a federated deployment.
+type FederatedDeployment struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedDeploymentList is a list of federated deployments.
+type FederatedDeploymentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedDeployment `json:"items"`
+}
+
+// FederatedIngress is a federated ingress.
+type FederatedIngress struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedIngressList is a list of federated ingresses.
+type FederatedIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedIngress `json:"items"`
+}
+
+// FederatedPersistentVolumeClaim is a federated persistent volume claim.
+type FederatedPersistentVolumeClaim struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedPersistentVolumeClaimList is a list of federated persistent volume
+// claims.
+type FederatedPersistentVolumeClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedPersistentVolumeClaim `json:"items"`
+}
+
+// FederatedSecret is a federated secret.
+type FederatedSecret struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedSecretList is a list of federated secrets.
+type FederatedSecretList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedSecret `json:"items"`
+}
+
+// FederatedService is a federated service.
+type FederatedService struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedServiceList is a list of federated services.
+type FederatedServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedService `json:"items"`
+}
+
+// FederatedStatefulSet is a federated stateful set.
+type FederatedStatefulSet struct {
+	FederatedObject `json:",inline"`
+}
+
+// FederatedStatefulSetList is a list of federated stateful sets.
+type FederatedStatefulSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []FederatedStatefulSet `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedConfigMap) DeepCopyObject() runtime.Object {
+	out := &FederatedConfigMap{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedConfigMapList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedConfigMap, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedDeployment) DeepCopyObject() runtime.Object {
+	out := &FederatedDeployment{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedDeploymentList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedDeployment, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedIngress) DeepCopyObject() runtime.Object {
+	out := &FederatedIngress{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedIngressList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedIngress, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedPersistentVolumeClaim) DeepCopyObject() runtime.Object {
+	out := &FederatedPersistentVolumeClaim{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedPersistentVolumeClaimList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedPersistentVolumeClaim, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedSecret) DeepCopyObject() runtime.Object {
+	out := &FederatedSecret{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedSecretList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedSecret, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedService) DeepCopyObject() runtime.Object {
+	out := &FederatedService{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedServiceList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedService, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedStatefulSet) DeepCopyObject() runtime.Object {
+	out := &FederatedStatefulSet{}
+	in.FederatedObject.DeepCopyInto(&out.FederatedObject)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *FederatedStatefulSetList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]FederatedStatefulSet, len(in.Items))
+	for i := range in.Items {
+		in.Items[i].FederatedObject.DeepCopyInto(&out.Items[i].FederatedObject)
+	}
+	return &out
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GatewayResource is the resource of gateways.
+var GatewayResource = schema.GroupVersionResource{Group: "gateway.kubesphere.io", Version: "v1alpha1", Resource: "gateways"}
+
+// Gateway is a gateway of the gateway.kubesphere.io API, i.e. an nginx
+// ingress controller KubeSphere deploys with Helm for the ingresses of a
+// project, or of the whole cluster.
+type Gateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GatewaySpec   `json:"spec,omitempty"`
+	Status GatewayStatus `json:"status,omitempty"`
+}
+
+// GatewaySpec is the spec of a gateway, which holds the values of the Helm
+// chart of the ingress controller.
+type GatewaySpec struct {
+	Controller GatewayController `json:"controller,omitempty"`
+	Deployment GatewayDeployment `json:"deployment,omitempty"`
+	Service    GatewayService    `json:"service,omitempty"`
+}
+
+// GatewayController configures the ingress controller of a gateway.
+type GatewayController struct {
+	Replicas *int32       `json:"replicas,omitempty"`
+	Scope    GatewayScope `json:"scope,omitempty"`
+}
+
+// GatewayScope limits the ingresses of a gateway to a namespace.
+type GatewayScope struct {
+	Enabled   bool   `json:"enabled,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GatewayDeployment configures the deployment of the ingress controller of a
+// gateway, and supersedes GatewayController.Replicas if set.
+type GatewayDeployment struct {
+	Replicas *int32 `json:"replicas,omitempty"`
+}
+
+// GatewayService configures the service of a gateway.
+type GatewayService struct {
+	Type v1.ServiceType `json:"type,omitempty"`
+}
+
+// GatewayStatus is the status of the Helm release of a gateway.
+type GatewayStatus struct {
+	// Conditions are the conditions of the release, e.g. Deployed or
+	// ReleaseFailed.
+	Conditions []GatewayCondition `json:"conditions,omitempty"`
+}
+
+// GatewayCondition is a condition of the Helm release of a gateway.
+type GatewayCondition struct {
+	Type   string             `json:"type"`
+	Status v1.ConditionStatus `json:"status"`
+	Reason string             `json:"reason,omitempty"`
+}
+
+// GatewayList is a list of gateways.
+type GatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Gateway `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *Gateway) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Controller.Replicas != nil {
+		r := *in.Spec.Controller.Replicas
+		out.Spec.Controller.Replicas = &r
+	}
+	if in.Spec.Deployment.Replicas != nil {
+		r := *in.Spec.Deployment.Replicas
+		out.Spec.Deployment.Replicas = &r
+	}
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]GatewayCondition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *GatewayList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]Gateway, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*Gateway)
+	}
+	return &out
+}
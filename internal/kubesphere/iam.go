@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// WorkspaceRoleBindingResource is the resource of workspace role bindings.
+	WorkspaceRoleBindingResource = schema.GroupVersionResource{Group: "iam.kubesphere.io", Version: "v1alpha2", Resource: "workspacerolebindings"}
+	// UserResource is the resource of users.
+	UserResource = schema.GroupVersionResource{Group: "iam.kubesphere.io", Version: "v1alpha2", Resource: "users"}
+)
+
+// UserState is the state of a user.
+type UserState string
+
+// The states of users.
+const (
+	// UserActive is the state of users who may log in.
+	UserActive UserState = "Active"
+	// UserDisabled is the state of users disabled by an administrator.
+	UserDisabled UserState = "Disabled"
+	// UserAuthLimitExceeded is the state of users blocked after too many
+	// failed login attempts.
+	UserAuthLimitExceeded UserState = "AuthLimitExceeded"
+)
+
+// WorkspaceRoleBinding is a workspace role binding of the iam.kubesphere.io
+// API, which grants the members of a workspace a role in it. The workspace is
+// given by its kubesphere.io/workspace label.
+type WorkspaceRoleBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Subjects []rbacv1.Subject `json:"subjects,omitempty"`
+	RoleRef  rbacv1.RoleRef   `json:"roleRef"`
+}
+
+// WorkspaceRoleBindingList is a list of workspace role bindings.
+type WorkspaceRoleBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []WorkspaceRoleBinding `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *WorkspaceRoleBinding) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Subjects != nil {
+		out.Subjects = make([]rbacv1.Subject, len(in.Subjects))
+		copy(out.Subjects, in.Subjects)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *WorkspaceRoleBindingList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]WorkspaceRoleBinding, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*WorkspaceRoleBinding)
+	}
+	return &out
+}
+
+// User is a user of the iam.kubesphere.io API.
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// UserStatus is the status of a user.
+type UserStatus struct {
+	// State is the state of the user, empty until it is first set.
+	State UserState `json:"state,omitempty"`
+	// LastLoginTime is the time the user last logged in, if ever.
+	LastLoginTime *metav1.Time `json:"lastLoginTime,omitempty"`
+}
+
+// UserList is a list of users.
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []User `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *User) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status.LastLoginTime != nil {
+		out.Status.LastLoginTime = in.Status.LastLoginTime.DeepCopy()
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *UserList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]User, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*User)
+	}
+	return &out
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubesphere provides the types of the KubeSphere custom resources
+// kube-state-metrics collects, limited to the fields metrics are generated
+// of, and the means to list and watch them.
+package kubesphere
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewListWatch returns a ListerWatcher of the objects of the given resource in
+// the given namespace, or in all namespaces if empty, which is also used for
+// cluster-scoped resources. The objects are listed and watched with the
+// dynamic client, as KubeSphere provides no clientsets compatible with the
+// vendored client-go, and converted into the objects returned by newObject
+// and the lists returned by newList.
+func NewListWatch(client dynamic.Interface, resource schema.GroupVersionResource, ns string, newObject, newList func() runtime.Object) cache.ListerWatcher {
+	var ri dynamic.ResourceInterface = client.Resource(resource)
+	if ns != metav1.NamespaceAll {
+		ri = client.Resource(resource).Namespace(ns)
+	}
+
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			u, err := ri.List(opts)
+			if err != nil {
+				return nil, err
+			}
+			list := newList()
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), list); err != nil {
+				return nil, err
+			}
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := ri.Watch(opts)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+				u, ok := e.Object.(*unstructured.Unstructured)
+				if !ok {
+					return e, true
+				}
+				// Errors are expected as *metav1.Status by the reflector.
+				var obj runtime.Object = &metav1.Status{}
+				if e.Type != watch.Error {
+					obj = newObject()
+				}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), obj); err != nil {
+					status := apierrors.NewInternalError(err).Status()
+					return watch.Event{Type: watch.Error, Object: &status}, true
+				}
+				e.Object = obj
+				return e, true
+			}), nil
+		},
+	}
+}
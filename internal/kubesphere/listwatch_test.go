@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestListWatch(t *testing.T) {
+	newWorkspace := func(name, manager string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "tenant.kubesphere.io/v1alpha1",
+			"kind":       "Workspace",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"manager": manager, "networkIsolation": true},
+		}}
+	}
+
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), newWorkspace("ws1", "admin"))
+	lw := NewListWatch(client, WorkspaceResource, metav1.NamespaceAll,
+		func() runtime.Object { return &Workspace{} },
+		func() runtime.Object { return &WorkspaceList{} },
+	)
+
+	obj, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := obj.(*WorkspaceList)
+	if !ok {
+		t.Fatalf("expected *WorkspaceList but got %T", obj)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "ws1" || list.Items[0].Spec.Manager != "admin" || !*list.Items[0].Spec.NetworkIsolation {
+		t.Fatalf("unexpected workspaces %+v", list.Items)
+	}
+
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if _, err := client.Resource(WorkspaceResource).Create(newWorkspace("ws2", "alice"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	e := <-w.ResultChan()
+	ws, ok := e.Object.(*Workspace)
+	if e.Type != watch.Added || !ok || ws.Name != "ws2" || ws.Spec.Manager != "alice" {
+		t.Fatalf("unexpected event %s of %#v", e.Type, e.Object)
+	}
+}
+
+func TestListWatchFederated(t *testing.T) {
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "types.kubefed.io/v1beta1",
+		"kind":       "FederatedDeployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "project1"},
+		"spec": map[string]interface{}{
+			"placement": map[string]interface{}{"clusters": []interface{}{map[string]interface{}{"name": "member1"}}},
+			"template":  map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}},
+		},
+		"status": map[string]interface{}{
+			"clusters": []interface{}{map[string]interface{}{"name": "member1", "status": "CreationFailed"}},
+		},
+	}})
+	lw := NewListWatch(client, FederatedDeploymentResource, "project1",
+		func() runtime.Object { return &FederatedDeployment{} },
+		func() runtime.Object { return &FederatedDeploymentList{} },
+	)
+
+	obj, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := obj.(*FederatedDeploymentList)
+	if !ok {
+		t.Fatalf("expected *FederatedDeploymentList but got %T", obj)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("unexpected federated deployments %+v", list.Items)
+	}
+	f := list.Items[0].Federated()
+	if f.Name != "web" || len(f.Spec.Placement.Clusters) != 1 || f.Spec.Placement.Clusters[0].Name != "member1" ||
+		len(f.Status.Clusters) != 1 || f.Status.Clusters[0].Status != "CreationFailed" {
+		t.Fatalf("unexpected federated deployment %+v", f)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// NotificationReceiverResource is the resource of notification receivers.
+	NotificationReceiverResource = schema.GroupVersionResource{Group: "notification.kubesphere.io", Version: "v2beta2", Resource: "receivers"}
+	// NotificationConfigResource is the resource of notification configs.
+	NotificationConfigResource = schema.GroupVersionResource{Group: "notification.kubesphere.io", Version: "v2beta2", Resource: "configs"}
+	// RuleGroupResource is the resource of alerting rule groups.
+	RuleGroupResource = schema.GroupVersionResource{Group: "alerting.kubesphere.io", Version: "v2beta1", Resource: "rulegroups"}
+)
+
+const (
+	// NotificationTypeLabel is the label of receivers and configs holding
+	// their type, i.e. global, tenant or default.
+	NotificationTypeLabel = "type"
+	// NotificationUserLabel is the label of the receivers and configs of the
+	// tenant type holding the name of their user.
+	NotificationUserLabel = "user"
+)
+
+// NotificationCondition is a condition of a notification or alerting object,
+// e.g. the result of its validation.
+type NotificationCondition struct {
+	Type   string             `json:"type"`
+	Status v1.ConditionStatus `json:"status"`
+	Reason string             `json:"reason,omitempty"`
+}
+
+// NotificationChannel is the part of the channels of receivers and configs,
+// e.g. email or slack, which is common to all of them.
+type NotificationChannel struct {
+	// Enabled is whether notifications are sent to the channel of a receiver,
+	// which defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// NotificationReceiver is a receiver of notification-manager, which sends
+// notifications to one or more channels.
+type NotificationReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the channels of the receiver by name.
+	Spec   map[string]NotificationChannel `json:"spec,omitempty"`
+	Status NotificationStatus             `json:"status,omitempty"`
+}
+
+// NotificationReceiverList is a list of notification receivers.
+type NotificationReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NotificationReceiver `json:"items"`
+}
+
+// NotificationConfig is a config of notification-manager, which configures
+// the channels of receivers, e.g. the SMTP server of email.
+type NotificationConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the channels of the config by name.
+	Spec   map[string]NotificationChannel `json:"spec,omitempty"`
+	Status NotificationStatus             `json:"status,omitempty"`
+}
+
+// NotificationConfigList is a list of notification configs.
+type NotificationConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NotificationConfig `json:"items"`
+}
+
+// NotificationStatus is the status of a notification or alerting object.
+type NotificationStatus struct {
+	Conditions []NotificationCondition `json:"conditions,omitempty"`
+}
+
+// RuleGroup is a group of the alerting rules of a namespace.
+type RuleGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RuleGroupSpec      `json:"spec,omitempty"`
+	Status NotificationStatus `json:"status,omitempty"`
+}
+
+// RuleGroupSpec is the spec of a rule group.
+type RuleGroupSpec struct {
+	Rules []Rule `json:"rules,omitempty"`
+}
+
+// Rule is an alerting rule of a rule group.
+type Rule struct {
+	Alert   string `json:"alert"`
+	Disable bool   `json:"disable,omitempty"`
+}
+
+// RuleGroupList is a list of rule groups.
+type RuleGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []RuleGroup `json:"items"`
+}
+
+func (in *NotificationStatus) deepCopyInto(out *NotificationStatus) {
+	if in.Conditions != nil {
+		out.Conditions = make([]NotificationCondition, len(in.Conditions))
+		copy(out.Conditions, in.Conditions)
+	}
+}
+
+func deepCopyChannels(in map[string]NotificationChannel) map[string]NotificationChannel {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]NotificationChannel, len(in))
+	for name, c := range in {
+		if c.Enabled != nil {
+			enabled := *c.Enabled
+			c.Enabled = &enabled
+		}
+		out[name] = c
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *NotificationReceiver) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = deepCopyChannels(in.Spec)
+	in.Status.deepCopyInto(&out.Status)
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *NotificationReceiverList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]NotificationReceiver, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*NotificationReceiver)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *NotificationConfig) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = deepCopyChannels(in.Spec)
+	in.Status.deepCopyInto(&out.Status)
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *NotificationConfigList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]NotificationConfig, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*NotificationConfig)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *RuleGroup) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Rules != nil {
+		out.Spec.Rules = make([]Rule, len(in.Spec.Rules))
+		copy(out.Spec.Rules, in.Spec.Rules)
+	}
+	in.Status.deepCopyInto(&out.Status)
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *RuleGroupList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]RuleGroup, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*RuleGroup)
+	}
+	return &out
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// S2iBuilderResource is the resource of S2I builders.
+	S2iBuilderResource = schema.GroupVersionResource{Group: "devops.kubesphere.io", Version: "v1alpha1", Resource: "s2ibuilders"}
+	// S2iRunResource is the resource of S2I runs.
+	S2iRunResource = schema.GroupVersionResource{Group: "devops.kubesphere.io", Version: "v1alpha1", Resource: "s2iruns"}
+)
+
+// S2iRunState is the state of an S2I run.
+type S2iRunState string
+
+// The states of S2I runs.
+const (
+	S2iRunRunning    S2iRunState = "Running"
+	S2iRunSuccessful S2iRunState = "Successful"
+	S2iRunFailed     S2iRunState = "Failed"
+	S2iRunUnknown    S2iRunState = "Unknown"
+)
+
+// S2iBuilder is an image builder of KubeSphere, which builds images from
+// source code or binaries with source-to-image.
+type S2iBuilder struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S2iBuilderSpec   `json:"spec,omitempty"`
+	Status S2iBuilderStatus `json:"status,omitempty"`
+}
+
+// S2iBuilderSpec is the spec of an S2I builder.
+type S2iBuilderSpec struct {
+	Config *S2iConfig `json:"config,omitempty"`
+}
+
+// S2iConfig is the configuration of the builds of an S2I builder.
+type S2iConfig struct {
+	// BuilderImage is the image the builds run in.
+	BuilderImage string `json:"builderImage,omitempty"`
+	// ImageName is the name of the built image.
+	ImageName string `json:"imageName,omitempty"`
+	// Tag is the tag of the built image.
+	Tag string `json:"tag,omitempty"`
+}
+
+// S2iBuilderStatus is the status of an S2I builder.
+type S2iBuilderStatus struct {
+	RunCount         int          `json:"runCount"`
+	LastRunState     S2iRunState  `json:"lastRunState,omitempty"`
+	LastRunStartTime *metav1.Time `json:"lastRunStartTime,omitempty"`
+}
+
+// S2iBuilderList is a list of S2I builders.
+type S2iBuilderList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []S2iBuilder `json:"items"`
+}
+
+// S2iRun is a run of an S2I builder.
+type S2iRun struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   S2iRunSpec   `json:"spec,omitempty"`
+	Status S2iRunStatus `json:"status,omitempty"`
+}
+
+// S2iRunSpec is the spec of an S2I run.
+type S2iRunSpec struct {
+	// BuilderName is the name of the S2I builder run.
+	BuilderName string `json:"builderName"`
+	// NewTag overrides the tag of the image built by the builder.
+	NewTag string `json:"newTag,omitempty"`
+}
+
+// S2iRunStatus is the status of an S2I run.
+type S2iRunStatus struct {
+	RunState       S2iRunState  `json:"runState,omitempty"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// S2iRunList is a list of S2I runs.
+type S2iRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []S2iRun `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *S2iBuilder) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Config != nil {
+		config := *in.Spec.Config
+		out.Spec.Config = &config
+	}
+	if in.Status.LastRunStartTime != nil {
+		out.Status.LastRunStartTime = in.Status.LastRunStartTime.DeepCopy()
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *S2iBuilderList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]S2iBuilder, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*S2iBuilder)
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *S2iRun) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status.StartTime != nil {
+		out.Status.StartTime = in.Status.StartTime.DeepCopy()
+	}
+	if in.Status.CompletionTime != nil {
+		out.Status.CompletionTime = in.Status.CompletionTime.DeepCopy()
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *S2iRunList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]S2iRun, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*S2iRun)
+	}
+	return &out
+}
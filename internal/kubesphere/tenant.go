@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubesphere
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// WorkspaceLabel is the label of namespaces holding the name of the workspace
+// they are assigned to.
+const WorkspaceLabel = "kubesphere.io/workspace"
+
+// WorkspaceResource is the resource of workspaces.
+var WorkspaceResource = schema.GroupVersionResource{Group: "tenant.kubesphere.io", Version: "v1alpha1", Resource: "workspaces"}
+
+// Workspace is a workspace of the tenant.kubesphere.io API, which groups the
+// namespaces of a tenant.
+type Workspace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WorkspaceSpec `json:"spec,omitempty"`
+}
+
+// WorkspaceSpec is the spec of a workspace.
+type WorkspaceSpec struct {
+	// Manager is the user managing the workspace.
+	Manager string `json:"manager,omitempty"`
+	// NetworkIsolation restricts the network traffic of the namespaces of
+	// the workspace to the workspace, if true.
+	NetworkIsolation *bool `json:"networkIsolation,omitempty"`
+}
+
+// WorkspaceList is a list of workspaces.
+type WorkspaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Workspace `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *Workspace) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.NetworkIsolation != nil {
+		v := *in.Spec.NetworkIsolation
+		out.Spec.NetworkIsolation = &v
+	}
+	return &out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *WorkspaceList) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]Workspace, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*Workspace)
+	}
+	return &out
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descApplicationLabelsName          = "kube_application_labels"
+	descApplicationLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descApplicationLabelsDefaultLabels = []string{"namespace", "application"}
+
+	applicationMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_application_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about application.",
+			GenerateFunc: wrapApplicationFunc(func(a *kubesphere.Application) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type", "version"},
+							LabelValues: []string{a.Spec.Descriptor.Type, a.Labels[kubesphere.ApplicationVersionLabel]},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_application_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapApplicationFunc(func(a *kubesphere.Application) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&a.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descApplicationLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descApplicationLabelsHelp,
+			GenerateFunc: wrapApplicationFunc(func(a *kubesphere.Application) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(a.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_application_spec_assembly_phase",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The assembly phase of the components of the application.",
+			GenerateFunc: wrapApplicationFunc(func(a *kubesphere.Application) *metric.Family {
+				phases := []kubesphere.ApplicationAssemblyPhase{
+					kubesphere.ApplicationPending,
+					kubesphere.ApplicationSucceeded,
+					kubesphere.ApplicationFailed,
+				}
+				ms := make([]*metric.Metric, len(phases))
+				for i, p := range phases {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{string(p)},
+						Value:       boolFloat64(a.Spec.AssemblyPhase == p),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_application_status_components",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of components of the application by kind.",
+			GenerateFunc: wrapApplicationFunc(func(a *kubesphere.Application) *metric.Family {
+				counts := map[schema.GroupKind]int{}
+				for _, c := range a.Status.Components {
+					counts[schema.GroupKind{Group: c.Group, Kind: c.Kind}]++
+				}
+				kinds := make([]schema.GroupKind, 0, len(counts))
+				for k := range counts {
+					kinds = append(kinds, k)
+				}
+				sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+
+				ms := make([]*metric.Metric, len(kinds))
+				for i, k := range kinds {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"group", "kind"},
+						LabelValues: []string{k.Group, k.Kind},
+						Value:       float64(counts[k]),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapApplicationFunc(f func(*kubesphere.Application) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		application, ok := unwrapObject(obj).(*kubesphere.Application)
+		if !ok {
+			return unexpectedObject((*kubesphere.Application)(nil), obj)
+		}
+
+		metricFamily := f(application)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descApplicationLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{application.Namespace, application.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createApplicationListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.ApplicationResource, ns,
+			func() runtime.Object { return &kubesphere.Application{} },
+			func() runtime.Object { return &kubesphere.ApplicationList{} },
+		)
+	}
+}
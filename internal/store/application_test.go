@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestApplicationStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_application_info [EXPERIMENTAL] Information about application.
+		# TYPE kube_application_info gauge
+		# HELP kube_application_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_application_created gauge
+		# HELP kube_application_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_application_labels gauge
+		# HELP kube_application_spec_assembly_phase [EXPERIMENTAL] The assembly phase of the components of the application.
+		# TYPE kube_application_spec_assembly_phase gauge
+		# HELP kube_application_status_components [EXPERIMENTAL] Number of components of the application by kind.
+		# TYPE kube_application_status_components gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "wordpress",
+					Namespace:         "project1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						kubesphere.ApplicationVersionLabel: "5.4",
+					},
+				},
+				Spec: kubesphere.ApplicationSpec{
+					Descriptor:    kubesphere.ApplicationDescriptor{Type: "wordpress"},
+					AssemblyPhase: kubesphere.ApplicationSucceeded,
+				},
+				Status: kubesphere.ApplicationStatus{
+					Components: []kubesphere.ApplicationComponent{
+						{Group: "apps", Kind: "Deployment", Name: "wordpress"},
+						{Group: "apps", Kind: "Deployment", Name: "mysql"},
+						{Kind: "Service", Name: "wordpress"},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_application_created{application="wordpress",namespace="project1"} 1.5e+09
+				kube_application_info{application="wordpress",namespace="project1",type="wordpress",version="5.4"} 1
+				kube_application_labels{application="wordpress",label_app_kubernetes_io_version="5.4",namespace="project1"} 1
+				kube_application_spec_assembly_phase{application="wordpress",namespace="project1",phase="Failed"} 0
+				kube_application_spec_assembly_phase{application="wordpress",namespace="project1",phase="Pending"} 0
+				kube_application_spec_assembly_phase{application="wordpress",namespace="project1",phase="Succeeded"} 1
+				kube_application_status_components{application="wordpress",group="",kind="Service",namespace="project1"} 1
+				kube_application_status_components{application="wordpress",group="apps",kind="Deployment",namespace="project1"} 2
+`,
+		},
+		{
+			Obj: &kubesphere.Application{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "empty",
+					Namespace: "project1",
+				},
+			},
+			Want: metadata + `
+				kube_application_info{application="empty",namespace="project1",type="",version=""} 1
+				kube_application_labels{application="empty",namespace="project1"} 1
+				kube_application_spec_assembly_phase{application="empty",namespace="project1",phase="Failed"} 0
+				kube_application_spec_assembly_phase{application="empty",namespace="project1",phase="Pending"} 0
+				kube_application_spec_assembly_phase{application="empty",namespace="project1",phase="Succeeded"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(applicationMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(applicationMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/tests/synthetic"
+)
+
+// BenchmarkGenerateMetrics measures the generation of the metrics of all
+// objects of a collector for synthetic clusters of different sizes.
+func BenchmarkGenerateMetrics(b *testing.B) {
+	for _, c := range synthetic.Clusters {
+		collectors := []struct {
+			name     string
+			families []metric.FamilyGenerator
+			objects  []interface{}
+		}{
+			{name: "nodes", families: nodeMetricFamilies},
+			{name: "deployments", families: deploymentMetricFamilies},
+			{name: "pods", families: podMetricFamilies(nil, false)},
+		}
+		for _, n := range c.NodeObjects() {
+			collectors[0].objects = append(collectors[0].objects, n)
+		}
+		for _, d := range c.DeploymentObjects() {
+			collectors[1].objects = append(collectors[1].objects, d)
+		}
+		for _, p := range c.PodObjects() {
+			collectors[2].objects = append(collectors[2].objects, p)
+		}
+
+		for _, collector := range collectors {
+			genFunc := metric.ComposeMetricGenFuncs(collector.families)
+			headers := metric.ExtractMetricFamilyHeaders(collector.families)
+
+			b.Run(c.Name+"/"+collector.name+"/Generate", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s := metricsstore.NewMetricsStore(headers, genFunc)
+					for _, o := range collector.objects {
+						if err := s.Add(o); err != nil {
+							b.Fatal(err)
+						}
+					}
+				}
+			})
+
+			s := metricsstore.NewMetricsStore(headers, genFunc)
+			for _, o := range collector.objects {
+				if err := s.Add(o); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.Run(c.Name+"/"+collector.name+"/Write", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					s.WriteAll(ioutil.Discard)
+				}
+			})
+		}
+	}
+}
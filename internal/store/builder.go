@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -35,37 +36,100 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	policy "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	vpaautoscaling "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1beta2"
 	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
 	"k8s.io/kube-state-metrics/pkg/listwatch"
 	"k8s.io/kube-state-metrics/pkg/metric"
 	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/pkg/options"
 	"k8s.io/kube-state-metrics/pkg/sharding"
+	"k8s.io/kube-state-metrics/pkg/tracing"
+	"k8s.io/kube-state-metrics/pkg/version"
 	"k8s.io/kube-state-metrics/pkg/watch"
 )
 
-type whiteBlackLister interface {
-	IsIncluded(string) bool
-	IsExcluded(string) bool
-}
+// Make sure the internal Builder implements the public BuilderInterface.
+// New Builder methods should be added to the public BuilderInterface.
+var _ ksmtypes.BuilderInterface = &Builder{}
 
 // Builder helps to build store. It follows the builder pattern
 // (https://en.wikipedia.org/wiki/Builder_pattern).
 type Builder struct {
-	kubeClient       clientset.Interface
-	vpaClient        vpaclientset.Interface
-	namespaces       options.NamespaceList
-	ctx              context.Context
-	enabledResources []string
-	whiteBlackList   whiteBlackLister
-	metrics          *watch.ListWatchMetrics
-	shard            int32
-	totalShards      int
+	kubeClient    clientset.Interface
+	vpaClient     vpaclientset.Interface
+	dynamicClient dynamic.Interface
+	// clusters are the member clusters the stores are populated from in
+	// multi-cluster mode, instead of kubeClient.
+	clusters          []cluster
+	namespaces        options.NamespaceList
+	ctx               context.Context
+	enabledResources  []string
+	whiteBlackList    ksmtypes.WhiteBlackLister
+	metrics           *watch.ListWatchMetrics
+	storeMetrics      *storeMetrics
+	tracer            *tracing.Tracer
+	filteredFamilies  *prometheus.GaugeVec
+	invalidMetrics    *prometheus.CounterVec
+	sanitizedMetrics  *prometheus.CounterVec
+	shard             int32
+	totalShards       int
+	listPageSize      int64
+	useAPIServerCache bool
+	node              string
+	omitHelp          bool
+	omitType          bool
+	stableOnly        bool
+	includeDeprecated bool
+	customLabelKeys   []string
+	customLabelValues []string
+	metricPrefix      string
+	familyHooks       []metric.FamilyHook
+	reasonWhitelist   []string
+	// emptyPodPhaseUnknown reports pods without a phase in the Unknown
+	// phase.
+	emptyPodPhaseUnknown bool
+	// metricFamilies overrides the metric families of the store being built,
+	// if set.
+	metricFamilies []metric.FamilyGenerator
+	// describe receives the metric families of the store being built
+	// instead of building it, if set.
+	describe       func(metricFamilies []metric.FamilyGenerator, expectedType interface{})
+	listWatchFuncs map[string]ksmtypes.ListWatchFunc
+	// listWatchFunc overrides the list watch function of the store being
+	// built, if set.
+	listWatchFunc ksmtypes.ListWatchFunc
+	// collector is the name of the collector whose store is being built.
+	collector string
+	// resource is the name of the collector of the resource the store being
+	// built is populated with, if it is not the collector itself, e.g. for
+	// external collectors.
+	resource string
+	// reflectors are the reflectors of the stores built by Build, which are
+	// started once all stores are built.
+	reflectors []*sharedReflector
+	// sharedStores are the stores added to the reflectors of the cluster
+	// being started by shareReflector, by resource.
+	sharedStores map[string][]clusterStore
+	// reflectorStore wraps the store the reflectors of the store being built
+	// populate, given the client of their cluster, if set.
+	reflectorStore func(store clusterStore, kubeClient clientset.Interface) clusterStore
+}
+
+// cluster is a member cluster of a Builder in multi-cluster mode.
+type cluster struct {
+	name          string
+	kubeClient    clientset.Interface
+	vpaClient     vpaclientset.Interface
+	dynamicClient dynamic.Interface
 }
 
 // NewBuilder returns a new builder.
@@ -74,6 +138,36 @@ func NewBuilder() *Builder { return &Builder{} }
 // WithMetrics sets the metrics property of a Builder.
 func (b *Builder) WithMetrics(r *prometheus.Registry) {
 	b.metrics = watch.NewListWatchMetrics(r)
+	b.storeMetrics = &storeMetrics{}
+	b.filteredFamilies = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_filtered_metric_families",
+			Help: "Number of metric families of a collector dropped by a filter, whose series are not generated.",
+		},
+		[]string{"filter", "resource"},
+	)
+	b.invalidMetrics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_invalid_metrics_total",
+			Help: "Total number of malformed metrics of a metric family dropped at generation time.",
+		},
+		[]string{"metric"},
+	)
+	b.sanitizedMetrics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_sanitized_metrics_total",
+			Help: "Total number of metrics of a metric family whose label values contained invalid UTF-8 or control characters, which were replaced.",
+		},
+		[]string{"metric"},
+	)
+	if r != nil {
+		r.MustRegister(b.storeMetrics, b.filteredFamilies, b.invalidMetrics, b.sanitizedMetrics, unexpectedObjects)
+	}
+}
+
+// WithTracer sets the tracer property of a Builder.
+func (b *Builder) WithTracer(t *tracing.Tracer) {
+	b.tracer = t
 }
 
 // WithEnabledResources sets the enabledResources property of a Builder.
@@ -88,11 +182,25 @@ func (b *Builder) WithEnabledResources(c []string) error {
 	copy = append(copy, c...)
 
 	sort.Strings(copy)
+	// The store of kube_namespace_object_count is built from the stores of
+	// all other collectors, hence after them.
+	for i, col := range copy {
+		if col == namespaceObjectCountsCollector {
+			copy = append(append(copy[:i], copy[i+1:]...), col)
+			break
+		}
+	}
 
 	b.enabledResources = copy
 	return nil
 }
 
+// EnabledResources returns the enabled resources of a Builder, in the order
+// their stores are returned by Build.
+func (b *Builder) EnabledResources() []string {
+	return b.enabledResources
+}
+
 // WithNamespaces sets the namespaces property of a Builder.
 func (b *Builder) WithNamespaces(n options.NamespaceList) {
 	b.namespaces = n
@@ -104,6 +212,87 @@ func (b *Builder) WithSharding(shard int32, totalShards int) {
 	b.totalShards = totalShards
 }
 
+// WithListPageSize sets the listPageSize property of a Builder. Initial lists
+// are retrieved in chunks of at most this many objects. 0 disables chunking.
+func (b *Builder) WithListPageSize(pageSize int64) {
+	b.listPageSize = pageSize
+}
+
+// WithUseAPIServerCache sets the useAPIServerCache property of a Builder. If
+// set, all lists are served from the apiserver watch cache, regardless of the
+// list page size.
+func (b *Builder) WithUseAPIServerCache(useAPIServerCache bool) {
+	b.useAPIServerCache = useAPIServerCache
+}
+
+// WithNode sets the node property of a Builder. If set, only pods scheduled to
+// the given node are collected.
+func (b *Builder) WithNode(node string) {
+	b.node = node
+}
+
+// WithFamilyHeaders configures whether the HELP and TYPE lines of metric
+// families are left out of the output of stores built by the Builder.
+func (b *Builder) WithFamilyHeaders(omitHelp, omitType bool) {
+	b.omitHelp = omitHelp
+	b.omitType = omitType
+}
+
+// WithStableMetricsOnly configures whether alpha and experimental metric
+// families are left out of the stores built by the Builder.
+func (b *Builder) WithStableMetricsOnly(stableOnly bool) {
+	b.stableOnly = stableOnly
+}
+
+// WithDeprecatedMetrics configures whether metric families deprecated in an
+// earlier minor release than the running one are kept in the stores built by
+// the Builder.
+func (b *Builder) WithDeprecatedMetrics(includeDeprecated bool) {
+	b.includeDeprecated = includeDeprecated
+}
+
+// WithCustomLabels sets labels added to all metrics of the stores built by the
+// Builder, in the order of their names.
+func (b *Builder) WithCustomLabels(labels map[string]string) {
+	b.customLabelKeys = make([]string, 0, len(labels))
+	for k := range labels {
+		b.customLabelKeys = append(b.customLabelKeys, k)
+	}
+	sort.Strings(b.customLabelKeys)
+
+	b.customLabelValues = make([]string, len(b.customLabelKeys))
+	for i, k := range b.customLabelKeys {
+		b.customLabelValues[i] = labels[k]
+	}
+}
+
+// WithMetricPrefix sets the prefix replacing kube_ in the names of the metrics
+// of the stores built by the Builder. Metric families are white- and
+// blacklisted by their original names.
+func (b *Builder) WithMetricPrefix(prefix string) {
+	b.metricPrefix = prefix
+}
+
+// WithContainerReasonWhitelist sets the waiting and terminated reasons of
+// containers reported by the pod collector. If empty, the known reasons and any
+// other current reason are reported.
+func (b *Builder) WithContainerReasonWhitelist(reasons []string) {
+	b.reasonWhitelist = reasons
+}
+
+// WithEmptyPodPhaseAsUnknown configures whether pods without a phase, e.g.
+// right after their creation, are reported in the Unknown phase by the pod
+// collector instead of being left out of kube_pod_status_phase.
+func (b *Builder) WithEmptyPodPhaseAsUnknown(emptyPhaseUnknown bool) {
+	b.emptyPodPhaseUnknown = emptyPhaseUnknown
+}
+
+// WithFamilyHooks sets hooks wrapping the generation of all metric families
+// of the stores built by the Builder, applied in the given order.
+func (b *Builder) WithFamilyHooks(hooks ...metric.FamilyHook) {
+	b.familyHooks = hooks
+}
+
 // WithContext sets the ctx property of a Builder.
 func (b *Builder) WithContext(ctx context.Context) {
 	b.ctx = ctx
@@ -119,13 +308,61 @@ func (b *Builder) WithVPAClient(c vpaclientset.Interface) {
 	b.vpaClient = c
 }
 
+// WithDynamicClient sets the dynamicClient property of a Builder so that the
+// collectors of KubeSphere custom resources can query their objects.
+func (b *Builder) WithDynamicClient(c dynamic.Interface) {
+	b.dynamicClient = c
+}
+
+// WithCluster adds a member cluster the stores built by the Builder are
+// populated from with the given clients, labeling its metrics with its name.
+// Once a member cluster is added, the stores are only populated from member
+// clusters, each by its own reflectors, so that an unreachable cluster does
+// not hold back the others.
+func (b *Builder) WithCluster(name string, kubeClient clientset.Interface, vpaClient vpaclientset.Interface, dynamicClient dynamic.Interface) {
+	b.clusters = append(b.clusters, cluster{name: name, kubeClient: kubeClient, vpaClient: vpaClient, dynamicClient: dynamicClient})
+}
+
+// vpaClientFor returns the VPA client of the cluster of the given kubeClient.
+func (b *Builder) vpaClientFor(kubeClient clientset.Interface) vpaclientset.Interface {
+	for _, c := range b.clusters {
+		if c.kubeClient == kubeClient {
+			return c.vpaClient
+		}
+	}
+	return b.vpaClient
+}
+
+// dynamicClientFor returns the dynamic client of the cluster of the given
+// kubeClient.
+func (b *Builder) dynamicClientFor(kubeClient clientset.Interface) dynamic.Interface {
+	for _, c := range b.clusters {
+		if c.kubeClient == kubeClient {
+			return c.dynamicClient
+		}
+	}
+	return b.dynamicClient
+}
+
 // WithWhiteBlackList configures the white or blacklisted metric to be exposed
 // by the store build by the Builder.
-func (b *Builder) WithWhiteBlackList(l whiteBlackLister) {
+func (b *Builder) WithWhiteBlackList(l ksmtypes.WhiteBlackLister) {
 	b.whiteBlackList = l
 }
 
-// Build initializes and registers all enabled stores.
+// WithListWatchFunc overrides the function creating the ListerWatchers of the
+// given resource, which otherwise list and watch the apiserver with the kube
+// client. The wrappers for pagination, sharding and instrumentation still
+// apply.
+func (b *Builder) WithListWatchFunc(resource string, f ksmtypes.ListWatchFunc) {
+	if b.listWatchFuncs == nil {
+		b.listWatchFuncs = map[string]ksmtypes.ListWatchFunc{}
+	}
+	b.listWatchFuncs[resource] = f
+}
+
+// Build initializes and registers all enabled stores, in the order of the
+// enabled resources.
 func (b *Builder) Build() []*metricsstore.MetricsStore {
 	if b.whiteBlackList == nil {
 		panic("whiteBlackList should not be nil")
@@ -133,65 +370,233 @@ func (b *Builder) Build() []*metricsstore.MetricsStore {
 
 	stores := []*metricsstore.MetricsStore{}
 	activeStoreNames := []string{}
+	storesByName := map[string]*metricsstore.MetricsStore{}
 
 	for _, c := range b.enabledResources {
+		if c == namespaceObjectCountsCollector {
+			b.collector = c
+			stores = append(stores, b.buildNamespaceObjectsStore(activeStoreNames, stores))
+			b.collector = ""
+			activeStoreNames = append(activeStoreNames, c)
+			continue
+		}
+
+		availableStoresMtx.RLock()
 		constructor, ok := availableStores[c]
+		availableStoresMtx.RUnlock()
 		if ok {
+			b.listWatchFunc = b.listWatchFuncs[c]
+			b.collector = c
 			store := constructor(b)
+			b.listWatchFunc = nil
+			b.collector = ""
 			activeStoreNames = append(activeStoreNames, c)
 			stores = append(stores, store)
+			storesByName[c] = store
 		}
 	}
 
 	klog.Infof("Active collectors: %s", strings.Join(activeStoreNames, ","))
 
+	b.startReflectors()
+
+	if b.storeMetrics != nil {
+		b.storeMetrics.setStores(storesByName)
+	}
+
 	return stores
 }
 
+// Validate returns an error listing every metric family of the enabled
+// resources that would be exposed with an invalid name, type or custom label
+// name, or under the name of another family, which Prometheus would refuse to
+// scrape. It only checks the static metadata of the families and does not
+// generate any metrics.
+func (b *Builder) Validate() error {
+	if b.whiteBlackList == nil {
+		panic("whiteBlackList should not be nil")
+	}
+
+	errs := []error{}
+	for _, k := range b.customLabelKeys {
+		if !metric.IsValidLabelName(k) {
+			errs = append(errs, errors.Errorf("invalid custom label name %q", k))
+		}
+	}
+
+	collectors := map[string]string{}
+	describe := b.describe
+	defer func() { b.describe = describe }()
+	for _, c := range b.enabledResources {
+		availableStoresMtx.RLock()
+		constructor, ok := availableStores[c]
+		availableStoresMtx.RUnlock()
+		if !ok {
+			continue
+		}
+
+		b.describe = func(metricFamilies []metric.FamilyGenerator, expectedType interface{}) {
+			for _, f := range b.exposedMetricFamilies(metricFamilies) {
+				if err := f.Validate(); err != nil {
+					errs = append(errs, errors.Wrapf(err, "collector %s", c))
+				}
+				if other, ok := collectors[f.Name]; ok {
+					errs = append(errs, errors.Errorf("collector %s: metric %s is also exposed by collector %s", c, f.Name, other))
+					continue
+				}
+				collectors[f.Name] = c
+			}
+		}
+		constructor(b)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// availableStoresMtx protects availableStores, which resources can be added
+// to with RegisterResource.
+var availableStoresMtx sync.RWMutex
+
 var availableStores = map[string]func(f *Builder) *metricsstore.MetricsStore{
+	"applications":                    func(b *Builder) *metricsstore.MetricsStore { return b.buildApplicationStore() },
 	"certificatesigningrequests":      func(b *Builder) *metricsstore.MetricsStore { return b.buildCsrStore() },
 	"configmaps":                      func(b *Builder) *metricsstore.MetricsStore { return b.buildConfigMapStore() },
 	"cronjobs":                        func(b *Builder) *metricsstore.MetricsStore { return b.buildCronJobStore() },
 	"daemonsets":                      func(b *Builder) *metricsstore.MetricsStore { return b.buildDaemonSetStore() },
 	"deployments":                     func(b *Builder) *metricsstore.MetricsStore { return b.buildDeploymentStore() },
 	"endpoints":                       func(b *Builder) *metricsstore.MetricsStore { return b.buildEndpointsStore() },
+	"federatedconfigmaps":             func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedConfigMapStore() },
+	"federateddeployments":            func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedDeploymentStore() },
+	"federatedingresses":              func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedIngressStore() },
+	"federatedpersistentvolumeclaims": func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedPersistentVolumeClaimStore() },
+	"federatedsecrets":                func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedSecretStore() },
+	"federatedservices":               func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedServiceStore() },
+	"federatedstatefulsets":           func(b *Builder) *metricsstore.MetricsStore { return b.buildFederatedStatefulSetStore() },
+	"gateways":                        func(b *Builder) *metricsstore.MetricsStore { return b.buildGatewayStore() },
 	"horizontalpodautoscalers":        func(b *Builder) *metricsstore.MetricsStore { return b.buildHPAStore() },
 	"ingresses":                       func(b *Builder) *metricsstore.MetricsStore { return b.buildIngressStore() },
 	"jobs":                            func(b *Builder) *metricsstore.MetricsStore { return b.buildJobStore() },
+	"kubesphereclusters":              func(b *Builder) *metricsstore.MetricsStore { return b.buildKubeSphereClusterStore() },
 	"limitranges":                     func(b *Builder) *metricsstore.MetricsStore { return b.buildLimitRangeStore() },
 	"mutatingwebhookconfigurations":   func(b *Builder) *metricsstore.MetricsStore { return b.buildMutatingWebhookConfigurationStore() },
 	"namespaces":                      func(b *Builder) *metricsstore.MetricsStore { return b.buildNamespaceStore() },
 	"networkpolicies":                 func(b *Builder) *metricsstore.MetricsStore { return b.buildNetworkPolicyStore() },
 	"nodes":                           func(b *Builder) *metricsstore.MetricsStore { return b.buildNodeStore() },
+	"notificationconfigs":             func(b *Builder) *metricsstore.MetricsStore { return b.buildNotificationConfigStore() },
+	"notificationreceivers":           func(b *Builder) *metricsstore.MetricsStore { return b.buildNotificationReceiverStore() },
 	"persistentvolumeclaims":          func(b *Builder) *metricsstore.MetricsStore { return b.buildPersistentVolumeClaimStore() },
 	"persistentvolumes":               func(b *Builder) *metricsstore.MetricsStore { return b.buildPersistentVolumeStore() },
+	"pipelineruns":                    func(b *Builder) *metricsstore.MetricsStore { return b.buildPipelineRunStore() },
+	"pipelines":                       func(b *Builder) *metricsstore.MetricsStore { return b.buildPipelineStore() },
 	"poddisruptionbudgets":            func(b *Builder) *metricsstore.MetricsStore { return b.buildPodDisruptionBudgetStore() },
 	"pods":                            func(b *Builder) *metricsstore.MetricsStore { return b.buildPodStore() },
 	"replicasets":                     func(b *Builder) *metricsstore.MetricsStore { return b.buildReplicaSetStore() },
 	"replicationcontrollers":          func(b *Builder) *metricsstore.MetricsStore { return b.buildReplicationControllerStore() },
 	"resourcequotas":                  func(b *Builder) *metricsstore.MetricsStore { return b.buildResourceQuotaStore() },
+	"rulegroups":                      func(b *Builder) *metricsstore.MetricsStore { return b.buildRuleGroupStore() },
+	"s2ibuilders":                     func(b *Builder) *metricsstore.MetricsStore { return b.buildS2iBuilderStore() },
+	"s2iruns":                         func(b *Builder) *metricsstore.MetricsStore { return b.buildS2iRunStore() },
 	"secrets":                         func(b *Builder) *metricsstore.MetricsStore { return b.buildSecretStore() },
 	"services":                        func(b *Builder) *metricsstore.MetricsStore { return b.buildServiceStore() },
 	"statefulsets":                    func(b *Builder) *metricsstore.MetricsStore { return b.buildStatefulSetStore() },
 	"storageclasses":                  func(b *Builder) *metricsstore.MetricsStore { return b.buildStorageClassStore() },
+	"users":                           func(b *Builder) *metricsstore.MetricsStore { return b.buildUserStore() },
 	"validatingwebhookconfigurations": func(b *Builder) *metricsstore.MetricsStore { return b.buildValidatingWebhookConfigurationStore() },
-	"volumeattachments":               func(b *Builder) *metricsstore.MetricsStore { return b.buildVolumeAttachmentStore() },
 	"verticalpodautoscalers":          func(b *Builder) *metricsstore.MetricsStore { return b.buildVPAStore() },
+	"volumeattachments":               func(b *Builder) *metricsstore.MetricsStore { return b.buildVolumeAttachmentStore() },
+	"workspacerolebindings":           func(b *Builder) *metricsstore.MetricsStore { return b.buildWorkspaceRoleBindingStore() },
+	"workspaces":                      func(b *Builder) *metricsstore.MetricsStore { return b.buildWorkspaceStore() },
 }
 
 func collectorExists(name string) bool {
+	if name == namespaceObjectCountsCollector {
+		return true
+	}
+
+	availableStoresMtx.RLock()
+	defer availableStoresMtx.RUnlock()
+
 	_, ok := availableStores[name]
 	return ok
 }
 
 func availableCollectors() []string {
-	c := []string{}
+	availableStoresMtx.RLock()
+	defer availableStoresMtx.RUnlock()
+
+	c := []string{namespaceObjectCountsCollector}
 	for name := range availableStores {
 		c = append(c, name)
 	}
+	sort.Strings(c)
 	return c
 }
 
+// RegisterResource registers a collector of the given name for a resource not
+// built into kube-state-metrics, e.g. a custom resource. Once enabled, its
+// store is populated by reflectors listing and watching objects of
+// expectedType with the ListerWatchers created by listWatchFunc for each
+// namespace and cluster, given the client of the cluster, and the given metric
+// families are generated for every object. It returns an error if a collector
+// of the same name exists.
+func RegisterResource(name string, expectedType runtime.Object, listWatchFunc ksmtypes.ListWatchFunc, metricFamilies []metric.FamilyGenerator) error {
+	if name == "" {
+		return errors.New("collector name must not be empty")
+	}
+	if expectedType == nil || listWatchFunc == nil {
+		return errors.Errorf("collector %s requires an expected type and a list watch function", name)
+	}
+	for i := range metricFamilies {
+		if err := metricFamilies[i].Validate(); err != nil {
+			return errors.Wrapf(err, "collector %s", name)
+		}
+	}
+
+	availableStoresMtx.Lock()
+	defer availableStoresMtx.Unlock()
+
+	if _, ok := availableStores[name]; ok {
+		return errors.Errorf("collector %s already exists", name)
+	}
+	availableStores[name] = func(b *Builder) *metricsstore.MetricsStore {
+		return b.buildStore(metricFamilies, expectedType, listWatchFunc)
+	}
+	return nil
+}
+
+// CollectorFamilies are the metric families of a collector.
+type CollectorFamilies struct {
+	Collector string
+	// ExpectedType is the type of the objects the metrics are generated for.
+	ExpectedType interface{}
+	Families     []metric.FamilyGenerator
+}
+
+// MetricFamilies returns the metric families of all available collectors,
+// including registered ones, sorted by collector name, e.g. for generating
+// documentation.
+func MetricFamilies() []CollectorFamilies {
+	collectors := []CollectorFamilies{}
+
+	for _, c := range availableCollectors() {
+		if c == namespaceObjectCountsCollector {
+			collectors = append(collectors, CollectorFamilies{Collector: c, ExpectedType: &namespaceObjects{}, Families: namespaceObjectsMetricFamilies})
+			continue
+		}
+
+		availableStoresMtx.RLock()
+		constructor := availableStores[c]
+		availableStoresMtx.RUnlock()
+
+		b := &Builder{describe: func(metricFamilies []metric.FamilyGenerator, expectedType interface{}) {
+			collectors = append(collectors, CollectorFamilies{Collector: c, ExpectedType: expectedType, Families: metricFamilies})
+		}}
+		constructor(b)
+	}
+
+	return collectors
+}
+
 func (b *Builder) buildConfigMapStore() *metricsstore.MetricsStore {
 	return b.buildStore(configMapMetricFamilies, &v1.ConfigMap{}, createConfigMapListWatch)
 }
@@ -285,7 +690,7 @@ func (b *Builder) buildStorageClassStore() *metricsstore.MetricsStore {
 }
 
 func (b *Builder) buildPodStore() *metricsstore.MetricsStore {
-	return b.buildStore(podMetricFamilies, &v1.Pod{}, createPodListWatch)
+	return b.buildStore(podMetricFamilies(b.reasonWhitelist, b.emptyPodPhaseUnknown), &v1.Pod{}, createPodListWatchFunc(b.node))
 }
 
 func (b *Builder) buildCsrStore() *metricsstore.MetricsStore {
@@ -301,7 +706,89 @@ func (b *Builder) buildVolumeAttachmentStore() *metricsstore.MetricsStore {
 }
 
 func (b *Builder) buildVPAStore() *metricsstore.MetricsStore {
-	return b.buildStore(vpaMetricFamilies, &vpaautoscaling.VerticalPodAutoscaler{}, createVPAListWatchFunc(b.vpaClient))
+	return b.buildStore(vpaMetricFamilies, &vpaautoscaling.VerticalPodAutoscaler{}, createVPAListWatchFunc(b.vpaClientFor))
+}
+
+func (b *Builder) buildWorkspaceStore() *metricsstore.MetricsStore {
+	b.reflectorStore = b.workspaceAggregatorStore
+	defer func() { b.reflectorStore = nil }()
+	return b.buildStore(workspaceMetricFamilies, &kubesphere.Workspace{}, createWorkspaceListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildWorkspaceRoleBindingStore() *metricsstore.MetricsStore {
+	return b.buildStore(workspaceRoleBindingMetricFamilies, &kubesphere.WorkspaceRoleBinding{}, createWorkspaceRoleBindingListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildUserStore() *metricsstore.MetricsStore {
+	return b.buildStore(userMetricFamilies, &kubesphere.User{}, createUserListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildPipelineStore() *metricsstore.MetricsStore {
+	return b.buildStore(pipelineMetricFamilies, &kubesphere.Pipeline{}, createPipelineListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildPipelineRunStore() *metricsstore.MetricsStore {
+	return b.buildStore(pipelineRunMetricFamilies, &kubesphere.PipelineRun{}, createPipelineRunListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildApplicationStore() *metricsstore.MetricsStore {
+	return b.buildStore(applicationMetricFamilies, &kubesphere.Application{}, createApplicationListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedConfigMapStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedConfigMapMetricFamilies, &kubesphere.FederatedConfigMap{}, createFederatedConfigMapListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedDeploymentStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedDeploymentMetricFamilies, &kubesphere.FederatedDeployment{}, createFederatedDeploymentListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedIngressStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedIngressMetricFamilies, &kubesphere.FederatedIngress{}, createFederatedIngressListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedPersistentVolumeClaimStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedPersistentVolumeClaimMetricFamilies, &kubesphere.FederatedPersistentVolumeClaim{}, createFederatedPersistentVolumeClaimListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedSecretStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedSecretMetricFamilies, &kubesphere.FederatedSecret{}, createFederatedSecretListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedServiceStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedServiceMetricFamilies, &kubesphere.FederatedService{}, createFederatedServiceListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildFederatedStatefulSetStore() *metricsstore.MetricsStore {
+	return b.buildStore(federatedStatefulSetMetricFamilies, &kubesphere.FederatedStatefulSet{}, createFederatedStatefulSetListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildGatewayStore() *metricsstore.MetricsStore {
+	return b.buildStore(gatewayMetricFamilies, &kubesphere.Gateway{}, createGatewayListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildKubeSphereClusterStore() *metricsstore.MetricsStore {
+	return b.buildStore(kubeSphereClusterMetricFamilies, &kubesphere.Cluster{}, createKubeSphereClusterListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildS2iBuilderStore() *metricsstore.MetricsStore {
+	return b.buildStore(s2iBuilderMetricFamilies, &kubesphere.S2iBuilder{}, createS2iBuilderListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildS2iRunStore() *metricsstore.MetricsStore {
+	return b.buildStore(s2iRunMetricFamilies, &kubesphere.S2iRun{}, createS2iRunListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildNotificationReceiverStore() *metricsstore.MetricsStore {
+	return b.buildStore(notificationReceiverMetricFamilies, &kubesphere.NotificationReceiver{}, createNotificationReceiverListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildNotificationConfigStore() *metricsstore.MetricsStore {
+	return b.buildStore(notificationConfigMetricFamilies, &kubesphere.NotificationConfig{}, createNotificationConfigListWatch(b.dynamicClientFor))
+}
+
+func (b *Builder) buildRuleGroupStore() *metricsstore.MetricsStore {
+	return b.buildStore(ruleGroupMetricFamilies, &kubesphere.RuleGroup{}, createRuleGroupListWatch(b.dynamicClientFor))
 }
 
 func (b *Builder) buildStore(
@@ -309,30 +796,242 @@ func (b *Builder) buildStore(
 	expectedType interface{},
 	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
 ) *metricsstore.MetricsStore {
-	filteredMetricFamilies := metric.FilterMetricFamilies(b.whiteBlackList, metricFamilies)
-	composedMetricGenFuncs := metric.ComposeMetricGenFuncs(filteredMetricFamilies)
-
-	familyHeaders := metric.ExtractMetricFamilyHeaders(filteredMetricFamilies)
-
+	if b.metricFamilies != nil {
+		metricFamilies = b.metricFamilies
+	}
+	if b.describe != nil {
+		b.describe(metricFamilies, expectedType)
+		return nil
+	}
+	familyHeaders, composedMetricGenFuncs := b.composeMetricFamilies(metricFamilies)
 	store := metricsstore.NewMetricsStore(
 		familyHeaders,
 		composedMetricGenFuncs,
 	)
+	if len(b.clusters) > 0 {
+		store.LabelClusters(options.ClusterLabel)
+	}
 	b.reflectorPerNamespace(expectedType, store, listWatchFunc)
 
 	return store
 }
 
-// reflectorPerNamespace creates a Kubernetes client-go reflector with the given
-// listWatchFunc for each given namespace and registers it with the given store.
+// composeMetricFamilies returns the headers of the exposed metric families and
+// a func generating their metrics, dropping malformed metrics and sanitizing
+// label values.
+func (b *Builder) composeMetricFamilies(metricFamilies []metric.FamilyGenerator) ([]string, func(interface{}) []metricsstore.FamilyByteSlicer) {
+	filteredMetricFamilies := b.exposedMetricFamilies(metricFamilies)
+	filteredMetricFamilies = metric.ValidateFamilies(filteredMetricFamilies, func(name string, err error) {
+		klog.V(4).Infof("Dropping malformed metric of %s: %v", name, err)
+		if b.invalidMetrics != nil {
+			b.invalidMetrics.WithLabelValues(name).Inc()
+		}
+	})
+	filteredMetricFamilies = metric.SanitizeLabelValues(filteredMetricFamilies, func(name string) {
+		klog.V(4).Infof("Replaced invalid UTF-8 or control characters in label values of %s", name)
+		if b.sanitizedMetrics != nil {
+			b.sanitizedMetrics.WithLabelValues(name).Inc()
+		}
+	})
+	return metric.ExtractMetricFamilyHeadersWithOptions(filteredMetricFamilies, b.omitHelp, b.omitType), metric.ComposeMetricGenFuncs(filteredMetricFamilies)
+}
+
+// exposedMetricFamilies returns the given metric families of a store as they
+// are exposed with the configuration of the Builder, i.e. filtered, labeled,
+// renamed and hooked.
+func (b *Builder) exposedMetricFamilies(metricFamilies []metric.FamilyGenerator) []metric.FamilyGenerator {
+	if b.stableOnly {
+		metricFamilies = b.filterMetricFamilies("stable", metricFamilies, metric.FilterStableMetricFamilies(metricFamilies))
+	}
+	if !b.includeDeprecated {
+		metricFamilies = b.filterMetricFamilies("deprecated", metricFamilies, metric.FilterDeprecatedMetricFamilies(metricFamilies, version.Release))
+	}
+	filter := "blacklist"
+	if b.whiteBlackList.IsWhiteList() {
+		filter = "whitelist"
+	}
+	metricFamilies = b.filterMetricFamilies(filter, metricFamilies, metric.FilterMetricFamilies(b.whiteBlackList, metricFamilies))
+	return b.labeledMetricFamilies(metricFamilies)
+}
+
+// filterMetricFamilies returns the given filtered metric families, recording
+// the number of families the given filter dropped from the store of the
+// collector being built.
+func (b *Builder) filterMetricFamilies(filter string, metricFamilies, filtered []metric.FamilyGenerator) []metric.FamilyGenerator {
+	if b.filteredFamilies != nil && b.collector != "" {
+		b.filteredFamilies.WithLabelValues(filter, b.collector).Set(float64(len(metricFamilies) - len(filtered)))
+	}
+	return filtered
+}
+
+// labeledMetricFamilies returns the given metric families labeled, renamed and
+// hooked as configured, without filtering them.
+func (b *Builder) labeledMetricFamilies(metricFamilies []metric.FamilyGenerator) []metric.FamilyGenerator {
+	if len(b.customLabelKeys) > 0 {
+		metricFamilies = metric.AddLabels(metricFamilies, b.customLabelKeys, b.customLabelValues)
+	}
+	if b.metricPrefix != "" && b.metricPrefix != options.DefaultMetricPrefix {
+		metricFamilies = metric.ReplacePrefix(metricFamilies, options.DefaultMetricPrefix, b.metricPrefix)
+	}
+	if len(b.familyHooks) > 0 {
+		metricFamilies = metric.ApplyHooks(metricFamilies, b.familyHooks)
+	}
+	return metricFamilies
+}
+
+// droppedBy returns the flag of the filter dropping the given metric family,
+// or an empty string if the family is exposed.
+func (b *Builder) droppedBy(g *metric.FamilyGenerator) string {
+	switch {
+	case b.stableOnly && !g.IsStable():
+		return "--stable-metrics-only"
+	case !b.includeDeprecated && g.IsHidden(version.Release):
+		return "--include-deprecated-metrics"
+	case !b.whiteBlackList.IsIncluded(g.Name) && b.whiteBlackList.IsWhiteList():
+		return "--metric-whitelist"
+	case !b.whiteBlackList.IsIncluded(g.Name):
+		return "--metric-blacklist"
+	}
+	return ""
+}
+
+// reflectorPerNamespace registers the given store to be populated by the
+// reflectors of the resource of the collector being built, which are created
+// with the given listWatchFunc for each namespace by startReflectors. The
+// stores of all collectors of a resource share its reflectors.
 func (b *Builder) reflectorPerNamespace(
 	expectedType interface{},
-	store cache.Store,
+	store *metricsstore.MetricsStore,
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
+) {
+	if b.listWatchFunc != nil {
+		listWatchFunc = b.listWatchFunc
+	}
+	resource := b.collector
+	if b.resource != "" {
+		resource = b.resource
+	}
+	s := reflectedStore{store: store, wrap: b.reflectorStore}
+	if b.resource != "" {
+		// The metrics of external collectors may take until their timeout
+		// to generate, which must not hold up the stores sharing their
+		// reflector.
+		wrap, done := s.wrap, b.ctx.Done()
+		s.wrap = func(store clusterStore, kubeClient clientset.Interface) clusterStore {
+			if wrap != nil {
+				store = wrap(store, kubeClient)
+			}
+			return newQueuedStore(store, done)
+		}
+	}
+	for _, r := range b.reflectors {
+		if resource != "" && r.resource == resource {
+			r.stores = append(r.stores, s)
+			return
+		}
+	}
+	b.reflectors = append(b.reflectors, &sharedReflector{
+		resource:      resource,
+		expectedType:  expectedType,
+		listWatchFunc: listWatchFunc,
+		stores:        []reflectedStore{s},
+	})
+}
+
+// startReflectors starts the reflectors registered by reflectorPerNamespace.
+// In multi-cluster mode, it does so for each member cluster, populating the
+// stores of the cluster.
+func (b *Builder) startReflectors() {
+	if len(b.clusters) == 0 {
+		b.startClusterReflectors("", b.kubeClient)
+	}
+	for _, c := range b.clusters {
+		b.startClusterReflectors(c.name, c.kubeClient)
+	}
+	b.reflectors = nil
+}
+
+// startClusterReflectors starts the reflectors of the given cluster with the
+// given kubeClient. The stores of all reflectors are wrapped before any is
+// started, as wrapping a store may add stores to the reflectors of other
+// resources with shareReflector.
+func (b *Builder) startClusterReflectors(cluster string, kubeClient clientset.Interface) {
+	b.sharedStores = map[string][]clusterStore{}
+	defer func() { b.sharedStores = nil }()
+
+	stores := make([]clusterStore, len(b.reflectors))
+	for i, r := range b.reflectors {
+		stores[i] = r.store(cluster, kubeClient)
+	}
+	for i, r := range b.reflectors {
+		store := stores[i]
+		if shared := b.sharedStores[r.resource]; len(shared) > 0 {
+			store = append(multiStore{store}, shared...)
+		}
+		b.startReflector(r.expectedType, store, kubeClient, r.listWatchFunc, true)
+	}
+}
+
+// shareReflector adds the given store to the stores populated by the reflector
+// of the given resource of the cluster being started. It returns false if no
+// collector of the resource is enabled.
+func (b *Builder) shareReflector(resource string, store clusterStore) bool {
+	for _, r := range b.reflectors {
+		if r.resource == resource {
+			b.sharedStores[resource] = append(b.sharedStores[resource], store)
+			return true
+		}
+	}
+	return false
+}
+
+// clusterStore is the store a reflector populates, either a MetricsStore or
+// the store of a member cluster.
+type clusterStore interface {
+	cache.Store
+	MarkActive()
+	SetDegraded(degraded bool)
+}
+
+// startReflector starts a reflector populating the given store from the given
+// kubeClient, with the given listWatchFunc for each namespace of the Builder.
+// Unless sharded, the store is populated with the objects of all shards.
+func (b *Builder) startReflector(
+	expectedType interface{},
+	store clusterStore,
+	kubeClient clientset.Interface,
 	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher,
+	sharded bool,
 ) {
-	lwf := func(ns string) cache.ListerWatcher { return listWatchFunc(b.kubeClient, ns) }
+	lwf := func(ns string) cache.ListerWatcher {
+		lw := listWatchFunc(kubeClient, ns)
+		if l, ok := lw.(*cache.ListWatch); ok {
+			// Pagination is left to the paginated listerwatcher, which
+			// lists in a single request if no page size is configured.
+			l.DisableChunking = true
+		}
+		if b.useAPIServerCache {
+			lw = listwatch.NewAPIServerCacheListerWatcher(lw)
+		}
+		return listwatch.NewPaginatedListerWatcher(lw, b.listPageSize)
+	}
 	lw := listwatch.MultiNamespaceListerWatcher(b.namespaces, nil, lwf)
-	instrumentedListWatch := watch.NewInstrumentedListerWatcher(lw, b.metrics, reflect.TypeOf(expectedType).String())
-	reflector := cache.NewReflector(sharding.NewShardedListWatch(b.shard, b.totalShards, instrumentedListWatch), expectedType, store, 0)
+	lw = listwatch.NewActivityListerWatcher(lw, store.MarkActive)
+	resource := reflect.TypeOf(expectedType).String()
+	lw = listwatch.NewBackoffListerWatcher(lw, func(err error, failures int) {
+		if err != nil {
+			b.metrics.WatchFailuresTotal.WithLabelValues(resource).Inc()
+		}
+		store.SetDegraded(failures > 0)
+	})
+	lw = watch.NewInstrumentedListerWatcher(lw, b.metrics, resource)
+	if sharded {
+		lw = sharding.NewShardedListWatch(b.shard, b.totalShards, lw)
+	}
+	if b.tracer != nil {
+		trace := &relistTrace{tracer: b.tracer, resource: resource}
+		lw, store = trace.listerWatcher(lw), trace.store(store)
+	}
+	reflector := cache.NewReflector(lw, expectedType, store, 0)
 	go reflector.Run(b.ctx.Done())
 }
@@ -0,0 +1,582 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/tracing"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+func TestMetricFamiliesValid(t *testing.T) {
+	for _, c := range MetricFamilies() {
+		for _, f := range c.Families {
+			if err := f.Validate(); err != nil {
+				t.Errorf("collector %s: %v", c.Collector, err)
+			}
+		}
+	}
+}
+
+type renameHook string
+
+func (h renameHook) Wrap(g metric.FamilyGenerator) metric.FamilyGenerator {
+	g.Name = string(h)
+	return g
+}
+
+func TestBuilderValidate(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Resources []string
+		Configure func(b *Builder)
+		WantErr   string
+	}{
+		{
+			Desc:      "default configuration",
+			Resources: []string{"nodes", "pods"},
+			Configure: func(b *Builder) {},
+		},
+		{
+			Desc:      "valid prefix and custom label",
+			Resources: []string{"pods"},
+			Configure: func(b *Builder) {
+				b.WithMetricPrefix("ksm_")
+				b.WithCustomLabels(map[string]string{"cluster": "prod"})
+			},
+		},
+		{
+			Desc:      "invalid prefix",
+			Resources: []string{"pods"},
+			Configure: func(b *Builder) { b.WithMetricPrefix("1kube_") },
+			WantErr:   `invalid metric name "1kube_pod_info"`,
+		},
+		{
+			Desc:      "invalid custom label name",
+			Resources: []string{"pods"},
+			Configure: func(b *Builder) { b.WithCustomLabels(map[string]string{"my-cluster": "prod"}) },
+			WantErr:   `invalid custom label name "my-cluster"`,
+		},
+		{
+			Desc:      "duplicate family names",
+			Resources: []string{"configmaps", "secrets"},
+			Configure: func(b *Builder) { b.WithFamilyHooks(renameHook("kube_object_info")) },
+			WantErr:   "collector configmaps: metric kube_object_info is also exposed by collector configmaps",
+		},
+	}
+
+	for _, test := range tests {
+		wbl, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wbl.Parse(); err != nil {
+			t.Fatal(err)
+		}
+
+		b := NewBuilder()
+		b.WithWhiteBlackList(wbl)
+		if err := b.WithEnabledResources(test.Resources); err != nil {
+			t.Fatal(err)
+		}
+		test.Configure(b)
+
+		err = b.Validate()
+		switch {
+		case test.WantErr == "" && err != nil:
+			t.Errorf("Test error for Desc: %s. Want no error, got: %v", test.Desc, err)
+		case test.WantErr != "" && (err == nil || !strings.Contains(err.Error(), test.WantErr)):
+			t.Errorf("Test error for Desc: %s. Want error containing %q, got: %v", test.Desc, test.WantErr, err)
+		}
+		if b.describe != nil {
+			t.Errorf("Test error for Desc: %s. describe hook was not reset", test.Desc)
+		}
+	}
+}
+
+type generateHook func(obj interface{}) *metric.Family
+
+func (h generateHook) Wrap(g metric.FamilyGenerator) metric.FamilyGenerator {
+	g.GenerateFunc = h
+	return g
+}
+
+func TestBuilderSelfCheck(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Resources []string
+		Configure func(b *Builder)
+		WantErr   string
+	}{
+		{
+			Desc:      "all collectors",
+			Resources: availableCollectors(),
+			Configure: func(b *Builder) { b.WithDeprecatedMetrics(true) },
+		},
+		{
+			Desc:      "prefix and custom label",
+			Resources: []string{"pods", "workspaces"},
+			Configure: func(b *Builder) {
+				b.WithMetricPrefix("ksm_")
+				b.WithCustomLabels(map[string]string{"cluster": "prod"})
+			},
+		},
+		{
+			Desc:      "panicking family",
+			Resources: []string{"configmaps"},
+			Configure: func(b *Builder) {
+				b.WithFamilyHooks(generateHook(func(obj interface{}) *metric.Family { panic("missing field") }))
+			},
+			WantErr: "collector configmaps: generating kube_configmap_info panicked: missing field",
+		},
+		{
+			Desc:      "malformed metric",
+			Resources: []string{"configmaps"},
+			Configure: func(b *Builder) {
+				b.WithFamilyHooks(generateHook(func(obj interface{}) *metric.Family {
+					return &metric.Family{Metrics: []*metric.Metric{{LabelKeys: []string{"config-map"}, LabelValues: []string{"a"}, Value: 1}}}
+				}))
+			},
+			WantErr: `collector configmaps: malformed metric of kube_configmap_info: invalid label name "config-map"`,
+		},
+		{
+			Desc:      "lint problem",
+			Resources: []string{"configmaps"},
+			Configure: func(b *Builder) { b.WithCustomLabels(map[string]string{"le": "1"}) },
+			WantErr:   `collector configmaps: metric kube_configmap_info: non-histogram metrics should not have "le" label`,
+		},
+	}
+
+	for _, test := range tests {
+		wbl, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := wbl.Parse(); err != nil {
+			t.Fatal(err)
+		}
+
+		b := NewBuilder()
+		b.WithWhiteBlackList(wbl)
+		if err := b.WithEnabledResources(test.Resources); err != nil {
+			t.Fatal(err)
+		}
+		test.Configure(b)
+
+		err = b.SelfCheck()
+		switch {
+		case test.WantErr == "" && err != nil:
+			t.Errorf("Test error for Desc: %s. Want no error, got: %v", test.Desc, err)
+		case test.WantErr != "" && (err == nil || !strings.Contains(err.Error(), test.WantErr)):
+			t.Errorf("Test error for Desc: %s. Want error containing %q, got: %v", test.Desc, test.WantErr, err)
+		}
+		if b.describe != nil {
+			t.Errorf("Test error for Desc: %s. describe hook was not reset", test.Desc)
+		}
+	}
+}
+
+func TestBuilderDescribeFamilies(t *testing.T) {
+	wbl, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{"kube_configmap_created": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wbl.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithWhiteBlackList(wbl)
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithStableMetricsOnly(true)
+	b.WithCustomLabels(map[string]string{"cluster": "prod"})
+	b.WithMetricPrefix("ksm_")
+
+	got := b.DescribeFamilies()
+	want := []ksmtypes.FamilyDescription{
+		{
+			Collector: "configmaps",
+			Name:      "ksm_configmap_info",
+			Help:      "Information about configmap.",
+			Type:      metric.Info,
+			Labels:    []string{"namespace", "configmap", "cluster"},
+		},
+		{
+			Collector: "configmaps",
+			Name:      "ksm_configmap_created",
+			Help:      "Unix creation timestamp",
+			Type:      metric.Gauge,
+			Labels:    []string{"namespace", "configmap", "cluster"},
+			DroppedBy: "--metric-blacklist",
+		},
+		{
+			Collector:      "configmaps",
+			Name:           "ksm_configmap_metadata_resource_version",
+			Help:           "Resource version representing a specific version of the configmap.",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Labels:         []string{"namespace", "configmap", "cluster"},
+			DroppedBy:      "--stable-metrics-only",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want families\n%+v\ngot\n%+v", want, got)
+	}
+
+	if err := b.WithEnabledResources([]string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range b.DescribeFamilies() {
+		if d.Name != "ksm_pod_labels" {
+			continue
+		}
+		if want := []string{"namespace", "pod", "label_*", "cluster"}; !reflect.DeepEqual(d.Labels, want) {
+			t.Errorf("want labels derived from objects to be given by their prefix %v, got %v", want, d.Labels)
+		}
+		return
+	}
+	t.Error("want family ksm_pod_labels to be described")
+}
+
+func TestBuilderMultiCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wl, err := whiteblacklist.New(options.MetricSet{"kube_namespace_status_phase": {}}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	newNamespace := func(uid string) *v1.Namespace {
+		return &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", UID: types.UID(uid)},
+			Status:     v1.NamespaceStatus{Phase: v1.NamespaceActive},
+		}
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	b.WithContext(ctx)
+	b.WithKubeClient(fake.NewSimpleClientset(newNamespace("default")))
+	b.WithCluster("b", fake.NewSimpleClientset(newNamespace("b")), nil, nil)
+	b.WithCluster("a", fake.NewSimpleClientset(newNamespace("a")), nil, nil)
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSharding(0, 1)
+	b.WithWhiteBlackList(wl)
+	if err := b.WithEnabledResources([]string{"namespaces"}); err != nil {
+		t.Fatal(err)
+	}
+	stores := b.Build()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !stores[0].HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for store to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	buf := &bytes.Buffer{}
+	stores[0].WriteAll(buf)
+	want := `# HELP kube_namespace_status_phase kubernetes namespace status phase.
+# TYPE kube_namespace_status_phase gauge
+kube_namespace_status_phase{namespace="default",phase="Active",cluster="a"} 1
+kube_namespace_status_phase{namespace="default",phase="Terminating",cluster="a"} 0
+kube_namespace_status_phase{namespace="default",phase="Active",cluster="b"} 1
+kube_namespace_status_phase{namespace="default",phase="Terminating",cluster="b"} 0
+`
+	if buf.String() != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, buf.String())
+	}
+}
+
+func TestBuilderMultiClusterRegisteredResource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	families := []metric.FamilyGenerator{
+		{
+			Name: "acme_widget_info",
+			Type: metric.Gauge,
+			Help: "Information about the widget.",
+			GenerateFunc: func(obj interface{}) *metric.Family {
+				cm := obj.(*v1.ConfigMap)
+				return &metric.Family{Metrics: []*metric.Metric{
+					{LabelKeys: []string{"widget"}, LabelValues: []string{cm.Name}, Value: 1},
+				}}
+			},
+		},
+	}
+	if err := RegisterResource("acme-clusterwidgets", &v1.ConfigMap{}, createConfigMapListWatch, families); err != nil {
+		t.Fatal(err)
+	}
+	newConfigMap := func(name string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)}}
+	}
+	wl, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	b.WithContext(ctx)
+	b.WithKubeClient(fake.NewSimpleClientset(newConfigMap("default")))
+	b.WithCluster("a", fake.NewSimpleClientset(newConfigMap("x")), nil, nil)
+	b.WithCluster("b", fake.NewSimpleClientset(newConfigMap("y")), nil, nil)
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSharding(0, 1)
+	b.WithWhiteBlackList(wl)
+	if err := b.WithEnabledResources([]string{"acme-clusterwidgets"}); err != nil {
+		t.Fatal(err)
+	}
+	stores := b.Build()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !stores[0].HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for store to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Each cluster is listed with its own client.
+	buf := &bytes.Buffer{}
+	stores[0].WriteAll(buf)
+	want := `# HELP acme_widget_info Information about the widget.
+# TYPE acme_widget_info gauge
+acme_widget_info{widget="x",cluster="a"} 1
+acme_widget_info{widget="y",cluster="b"} 1
+`
+	if buf.String() != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, buf.String())
+	}
+}
+
+func TestBuilderNamespaceObjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	newConfigMap := func(namespace, name string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "/" + name)}}
+	}
+	newSecret := func(namespace, name string) *v1.Secret {
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "/" + name)}}
+	}
+	kubeClient := fake.NewSimpleClientset(
+		newConfigMap("default", "a"),
+		newConfigMap("default", "b"),
+		newConfigMap("kube-system", "a"),
+		newSecret("default", "a"),
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", UID: types.UID("node")}},
+	)
+
+	tests := []struct {
+		desc       string
+		collectors []string
+		blacklist  options.MetricSet
+		want       string
+	}{
+		{
+			desc:       "exposed",
+			collectors: []string{"configmaps", "namespaceobjectcounts", "nodes", "secrets"},
+			want: `# HELP kube_namespace_object_count [EXPERIMENTAL] Number of objects of a resource in a namespace.
+# TYPE kube_namespace_object_count gauge
+kube_namespace_object_count{namespace="default",resource="configmaps"} 2
+kube_namespace_object_count{namespace="default",resource="secrets"} 1
+kube_namespace_object_count{namespace="kube-system",resource="configmaps"} 1
+`,
+		},
+		{
+			desc:       "blacklisted",
+			collectors: []string{"configmaps", "namespaceobjectcounts", "nodes", "secrets"},
+			blacklist:  options.MetricSet{"kube_namespace_object_count": {}},
+		},
+		{
+			desc:       "not enabled",
+			collectors: []string{"configmaps", "nodes", "secrets"},
+		},
+	}
+
+	for _, test := range tests {
+		l, err := whiteblacklist.New(options.MetricSet{}, test.blacklist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Parse(); err != nil {
+			t.Fatal(err)
+		}
+
+		b := NewBuilder()
+		b.WithMetrics(prometheus.NewRegistry())
+		b.WithContext(ctx)
+		b.WithKubeClient(kubeClient)
+		b.WithNamespaces(options.DefaultNamespaces)
+		b.WithSharding(0, 1)
+		b.WithWhiteBlackList(l)
+		if err := b.WithEnabledResources(test.collectors); err != nil {
+			t.Fatal(err)
+		}
+		stores := b.Build()
+
+		if len(stores) != len(test.collectors) {
+			t.Fatalf("%s: expected a store per collector, got %d stores", test.desc, len(stores))
+		}
+		if len(stores) == 3 {
+			continue
+		}
+		if resources := b.EnabledResources(); resources[3] != "namespaceobjectcounts" {
+			t.Fatalf("%s: expected namespaceobjectcounts to be the last collector, got %v", test.desc, resources)
+		}
+
+		deadline := time.Now().Add(10 * time.Second)
+		for !stores[3].HasSynced() {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: timed out waiting for store to sync", test.desc)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		buf := &bytes.Buffer{}
+		stores[3].WriteAll(buf)
+		if buf.String() != test.want {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", test.desc, test.want, buf.String())
+		}
+	}
+}
+
+func TestBuilderTracer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type span struct {
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+		Name         string `json:"name"`
+		Attributes   []struct {
+			Key   string `json:"key"`
+			Value struct {
+				StringValue string `json:"stringValue"`
+				IntValue    string `json:"intValue"`
+			} `json:"value"`
+		} `json:"attributes"`
+	}
+	spans := map[string]span{}
+	mtx := sync.Mutex{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []span `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, rs := range request.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					spans[s.Name] = s
+				}
+			}
+		}
+	}))
+	defer collector.Close()
+
+	l, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Parse(); err != nil {
+		t.Fatal(err)
+	}
+	tracer := tracing.New(collector.Client(), collector.URL, "ksm-0")
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	b.WithTracer(tracer)
+	b.WithContext(ctx)
+	b.WithKubeClient(fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "a", UID: types.UID("a")}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "b", UID: types.UID("b")}},
+	))
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSharding(0, 1)
+	b.WithWhiteBlackList(l)
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.Build()
+
+	// The relist span ends once the store has been populated.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if err := tracer.Flush(ctx); err != nil {
+			t.Fatal(err)
+		}
+		mtx.Lock()
+		_, ok := spans["relist"]
+		mtx.Unlock()
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the relist to be traced")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	relist, list, replace := spans["relist"], spans["list"], spans["replace"]
+	if relist.SpanID == "" || list.ParentSpanID != relist.SpanID || replace.ParentSpanID != relist.SpanID {
+		t.Fatalf("expected a relist span with list and replace child spans, got %+v", spans)
+	}
+	attributes := map[string]string{}
+	for _, a := range relist.Attributes {
+		attributes[a.Key] = a.Value.StringValue + a.Value.IntValue
+	}
+	if attributes["resource"] != "*v1.ConfigMap" || attributes["objects"] != "2" {
+		t.Errorf("unexpected attributes of the relist span %v", attributes)
+	}
+}
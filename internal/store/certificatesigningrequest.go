@@ -55,17 +55,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapCSRFunc(func(csr *certv1beta1.CertificateSigningRequest) *metric.Family {
-				ms := []*metric.Metric{}
-				if !csr.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(csr.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&csr.CreationTimestamp),
 				}
 			}),
 		},
@@ -100,7 +91,10 @@ var (
 
 func wrapCSRFunc(f func(*certv1beta1.CertificateSigningRequest) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		csr := obj.(*certv1beta1.CertificateSigningRequest)
+		csr, ok := unwrapObject(obj).(*certv1beta1.CertificateSigningRequest)
+		if !ok {
+			return unexpectedObject((*certv1beta1.CertificateSigningRequest)(nil), obj)
+		}
 
 		metricFamily := f(csr)
 
@@ -33,7 +33,7 @@ var (
 	configMapMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_configmap_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about configmap.",
 			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
 				return &metric.Family{
@@ -50,25 +50,16 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !c.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(c.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&c.CreationTimestamp),
 				}
 			}),
 		},
 		{
-			Name: "kube_configmap_metadata_resource_version",
-			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of the configmap.",
+			Name:           "kube_configmap_metadata_resource_version",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Resource version representing a specific version of the configmap.",
 			GenerateFunc: wrapConfigMapFunc(func(c *v1.ConfigMap) *metric.Family {
 				return &metric.Family{
 					Metrics: resourceVersionMetric(c.ObjectMeta.ResourceVersion),
@@ -91,7 +82,10 @@ func createConfigMapListWatch(kubeClient clientset.Interface, ns string) cache.L
 
 func wrapConfigMapFunc(f func(*v1.ConfigMap) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		configMap := obj.(*v1.ConfigMap)
+		configMap, ok := unwrapObject(obj).(*v1.ConfigMap)
+		if !ok {
+			return unexpectedObject((*v1.ConfigMap)(nil), obj)
+		}
 
 		metricFamily := f(configMap)
 
@@ -40,7 +40,7 @@ func TestConfigMapStore(t *testing.T) {
 			},
 			Want: `
 				# HELP kube_configmap_info Information about configmap.
-				# HELP kube_configmap_metadata_resource_version Resource version representing a specific version of the configmap.
+				# HELP kube_configmap_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of the configmap.
 				# TYPE kube_configmap_info gauge
 				# TYPE kube_configmap_metadata_resource_version gauge
 				kube_configmap_info{configmap="configmap1",namespace="ns1"} 1
@@ -59,7 +59,7 @@ func TestConfigMapStore(t *testing.T) {
 			Want: `
 				# HELP kube_configmap_created Unix creation timestamp
 				# HELP kube_configmap_info Information about configmap.
-				# HELP kube_configmap_metadata_resource_version Resource version representing a specific version of the configmap.
+				# HELP kube_configmap_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of the configmap.
 				# TYPE kube_configmap_created gauge
 				# TYPE kube_configmap_info gauge
 				# TYPE kube_configmap_metadata_resource_version gauge
@@ -56,7 +56,7 @@ var (
 		},
 		{
 			Name: "kube_cronjob_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Info about cronjob.",
 			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
 				return &metric.Family{
@@ -75,17 +75,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
-				ms := []*metric.Metric{}
-				if !j.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(j.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&j.CreationTimestamp),
 				}
 			}),
 		},
@@ -110,18 +101,8 @@ var (
 			Type: metric.Gauge,
 			Help: "LastScheduleTime keeps information of when was the last time the job was successfully scheduled.",
 			GenerateFunc: wrapCronJobFunc(func(j *batchv1beta1.CronJob) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if j.Status.LastScheduleTime != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(j.Status.LastScheduleTime.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(j.Status.LastScheduleTime),
 				}
 			}),
 		},
@@ -178,11 +159,7 @@ var (
 				if err != nil {
 					panic(err)
 				} else if !*j.Spec.Suspend {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(nextScheduledTime.Unix()),
-					})
+					ms = timestampMetrics(&metav1.Time{Time: nextScheduledTime})
 				}
 
 				return &metric.Family{
@@ -195,7 +172,10 @@ var (
 
 func wrapCronJobFunc(f func(*batchv1beta1.CronJob) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		cronJob := obj.(*batchv1beta1.CronJob)
+		cronJob, ok := unwrapObject(obj).(*batchv1beta1.CronJob)
+		if !ok {
+			return unexpectedObject((*batchv1beta1.CronJob)(nil), obj)
+		}
 
 		metricFamily := f(cronJob)
 
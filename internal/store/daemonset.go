@@ -38,18 +38,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapDaemonSetFunc(func(d *v1.DaemonSet) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !d.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(d.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&d.CreationTimestamp),
 				}
 			}),
 		},
@@ -201,7 +191,10 @@ var (
 
 func wrapDaemonSetFunc(f func(*v1.DaemonSet) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		daemonSet := obj.(*v1.DaemonSet)
+		daemonSet, ok := unwrapObject(obj).(*v1.DaemonSet)
+		if !ok {
+			return unexpectedObject((*v1.DaemonSet)(nil), obj)
+		}
 
 		metricFamily := f(daemonSet)
 
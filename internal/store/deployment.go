@@ -39,16 +39,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !d.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(d.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&d.CreationTimestamp),
 				}
 			}),
 		},
@@ -124,7 +116,7 @@ var (
 		},
 		{
 			Name: "kube_deployment_status_condition",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The current status conditions of a deployment.",
 			GenerateFunc: wrapDeploymentFunc(func(d *v1.Deployment) *metric.Family {
 				ms := make([]*metric.Metric, len(d.Status.Conditions)*len(conditionStatuses))
@@ -154,7 +146,7 @@ var (
 				return &metric.Family{
 					Metrics: []*metric.Metric{
 						{
-							Value: float64(*d.Spec.Replicas),
+							Value: float64(deploymentSpecReplicas(d)),
 						},
 					},
 				}
@@ -183,7 +175,7 @@ var (
 					return &metric.Family{}
 				}
 
-				maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(*d.Spec.Replicas), true)
+				maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(deploymentSpecReplicas(d)), true)
 				if err != nil {
 					panic(err)
 				}
@@ -206,7 +198,7 @@ var (
 					return &metric.Family{}
 				}
 
-				maxSurge, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxSurge, int(*d.Spec.Replicas), true)
+				maxSurge, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxSurge, int(deploymentSpecReplicas(d)), true)
 				if err != nil {
 					panic(err)
 				}
@@ -256,7 +248,10 @@ var (
 
 func wrapDeploymentFunc(f func(*v1.Deployment) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		deployment := obj.(*v1.Deployment)
+		deployment, ok := unwrapObject(obj).(*v1.Deployment)
+		if !ok {
+			return unexpectedObject((*v1.Deployment)(nil), obj)
+		}
 
 		metricFamily := f(deployment)
 
@@ -279,3 +274,12 @@ func createDeploymentListWatch(kubeClient clientset.Interface, ns string) cache.
 		},
 	}
 }
+
+// deploymentSpecReplicas returns the number of desired pods of d, which
+// defaults to 1 if unset.
+func deploymentSpecReplicas(d *v1.Deployment) int32 {
+	if d.Spec.Replicas == nil {
+		return 1
+	}
+	return *d.Spec.Replicas
+}
@@ -180,6 +180,34 @@ func TestDeploymentStore(t *testing.T) {
         kube_deployment_status_condition{deployment="depl2",namespace="ns2",condition="ReplicaFailure",status="unknown"} 0
 `,
 		},
+		{
+			Obj: &v1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "depl3",
+					Namespace: "ns3",
+				},
+				Spec: v1.DeploymentSpec{
+					Strategy: v1.DeploymentStrategy{
+						RollingUpdate: &v1.RollingUpdateDeployment{
+							MaxUnavailable: &depl2MaxUnavailable,
+							MaxSurge:       &depl2MaxSurge,
+						},
+					},
+				},
+			},
+			Want: `
+        # HELP kube_deployment_spec_replicas Number of desired pods for a deployment.
+        # TYPE kube_deployment_spec_replicas gauge
+        # HELP kube_deployment_spec_strategy_rollingupdate_max_surge Maximum number of replicas that can be scheduled above the desired number of replicas during a rolling update of a deployment.
+        # TYPE kube_deployment_spec_strategy_rollingupdate_max_surge gauge
+        # HELP kube_deployment_spec_strategy_rollingupdate_max_unavailable Maximum number of unavailable replicas during a rolling update of a deployment.
+        # TYPE kube_deployment_spec_strategy_rollingupdate_max_unavailable gauge
+        kube_deployment_spec_replicas{deployment="depl3",namespace="ns3"} 1
+        kube_deployment_spec_strategy_rollingupdate_max_surge{deployment="depl3",namespace="ns3"} 1
+        kube_deployment_spec_strategy_rollingupdate_max_unavailable{deployment="depl3",namespace="ns3"} 1
+`,
+			MetricNames: []string{"kube_deployment_spec_replicas", "kube_deployment_spec_strategy_rollingupdate_max_surge", "kube_deployment_spec_strategy_rollingupdate_max_unavailable"},
+		},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+// DescribeFamilies returns the metric families of the enabled resources as
+// they would be exposed with the configuration of the Builder, including the
+// ones dropped by filters. As families do not declare their labels, the
+// labels are the ones of the metrics generated for synthetic objects.
+func (b *Builder) DescribeFamilies() []ksmtypes.FamilyDescription {
+	if b.whiteBlackList == nil {
+		panic("whiteBlackList should not be nil")
+	}
+
+	descriptions := []ksmtypes.FamilyDescription{}
+	describe := b.describe
+	defer func() { b.describe = describe }()
+	for _, c := range b.enabledResources {
+		availableStoresMtx.RLock()
+		constructor, ok := availableStores[c]
+		availableStoresMtx.RUnlock()
+		if !ok {
+			continue
+		}
+
+		b.describe = func(metricFamilies []metric.FamilyGenerator, expectedType interface{}) {
+			labeled := b.labeledMetricFamilies(metricFamilies)
+			labels := familyLabels(labeled, expectedType)
+			for i, f := range labeled {
+				descriptions = append(descriptions, ksmtypes.FamilyDescription{
+					Collector:         c,
+					Name:              f.Name,
+					Help:              f.Help,
+					Type:              f.Type,
+					StabilityLevel:    f.StabilityLevel,
+					DeprecatedVersion: f.DeprecatedVersion,
+					Labels:            labels[i],
+					DroppedBy:         b.droppedBy(&metricFamilies[i]),
+				})
+			}
+		}
+		constructor(b)
+	}
+
+	return descriptions
+}
+
+// familyLabels returns the names of the labels of the metrics of each of the
+// given families generated for synthetic objects of expectedType. Label names
+// derived from random strings of the objects are given by their prefix
+// followed by an asterisk instead.
+func familyLabels(families []metric.FamilyGenerator, expectedType interface{}) [][]string {
+	labels := make([][]string, len(families))
+	objects, random, err := syntheticObjects(expectedType)
+	if err != nil {
+		return labels
+	}
+
+	for i, f := range families {
+		seen := map[string]bool{}
+		for _, obj := range objects {
+			for _, m := range generateSafely(f, obj).Metrics {
+				for _, k := range m.LabelKeys {
+					if derived := derivedLabelName(k, random); derived != "" {
+						k = derived
+					}
+					if !seen[k] {
+						seen[k] = true
+						labels[i] = append(labels[i], k)
+					}
+				}
+			}
+		}
+	}
+
+	return labels
+}
+
+// derivedLabelName returns the prefix of the given label name followed by an
+// asterisk if the name contains one of the given random strings, e.g.
+// "label_*" for "label_x7k2m9qa", or an empty string otherwise.
+func derivedLabelName(name string, random map[string]bool) string {
+	for i := 0; i+syntheticStringLength <= len(name); i++ {
+		if random[name[i:i+syntheticStringLength]] {
+			return name[:i] + "*"
+		}
+	}
+	return ""
+}
+
+// generateSafely returns the family generated by f for obj, or an empty family
+// if its generation panics.
+func generateSafely(f metric.FamilyGenerator, obj interface{}) (family *metric.Family) {
+	defer func() {
+		if r := recover(); r != nil || family == nil {
+			family = &metric.Family{}
+		}
+	}()
+	return f.GenerateFunc(obj)
+}
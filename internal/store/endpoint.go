@@ -35,7 +35,7 @@ var (
 	endpointMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_endpoint_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about endpoint.",
 			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
 				return &metric.Family{
@@ -52,17 +52,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapEndpointFunc(func(e *v1.Endpoints) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !e.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(e.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&e.CreationTimestamp),
 				}
 			}),
 		},
@@ -125,7 +116,10 @@ var (
 
 func wrapEndpointFunc(f func(*v1.Endpoints) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		endpoint := obj.(*v1.Endpoints)
+		endpoint, ok := unwrapObject(obj).(*v1.Endpoints)
+		if !ok {
+			return unexpectedObject((*v1.Endpoints)(nil), obj)
+		}
 
 		metricFamily := f(endpoint)
 
@@ -0,0 +1,402 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+const (
+	// externalCollectorTimeout is the time an external collector has to
+	// describe itself or to respond to an object.
+	externalCollectorTimeout = 10 * time.Second
+	// externalCollectorBackoff is the delay before restarting an external
+	// collector after it failed, which doubles with each consecutive failure
+	// up to externalCollectorMaxBackoff.
+	externalCollectorBackoff    = time.Second
+	externalCollectorMaxBackoff = 2 * time.Minute
+)
+
+// errExternalCollectorBackoff is returned while an external collector that
+// failed is not restarted yet.
+var errExternalCollectorBackoff = errors.New("backing off after failure")
+
+// The types below make up the protocol of external collectors, which exchange
+// a JSON document per line on their stdin and stdout. Once started, an
+// external collector writes an externalDescription to stdout. Then it reads
+// an externalRequest per object of the resource from stdin and responds with
+// an externalResponse with the metrics of the object.
+
+// externalDescription describes the metric families an external collector
+// generates for the objects of a built-in resource.
+type externalDescription struct {
+	Name     string                 `json:"name"`
+	Resource string                 `json:"resource"`
+	Families []externalFamilyHeader `json:"families"`
+}
+
+type externalFamilyHeader struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Help string `json:"help"`
+}
+
+type externalRequest struct {
+	Object interface{} `json:"object"`
+}
+
+type externalResponse struct {
+	Families []externalFamily `json:"families"`
+}
+
+type externalFamily struct {
+	Name    string           `json:"name"`
+	Metrics []externalMetric `json:"metrics"`
+}
+
+type externalMetric struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// externalCollector generates metrics by exchanging objects and metrics with
+// an external executable. The executable is restarted if it fails.
+type externalCollector struct {
+	ctx     context.Context
+	path    string
+	timeout time.Duration
+	backoff time.Duration
+
+	mtx   sync.Mutex
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan []byte
+	// done is closed once cmd is killed, so its lines are no longer read.
+	done chan struct{}
+	// failures is the number of consecutive failures, and retry the time
+	// the external collector is restarted after the last one.
+	failures int
+	retry    time.Time
+	// version is the version of the object result holds the metric families
+	// of, by name, and generated the names of the families already
+	// generated.
+	version   objectVersion
+	result    map[string]*metric.Family
+	generated map[string]bool
+}
+
+// objectVersion identifies a version of an object by its UID and resource
+// version.
+type objectVersion struct {
+	uid             types.UID
+	resourceVersion string
+}
+
+func newObjectVersion(obj interface{}) objectVersion {
+	o, err := meta.Accessor(unwrapObject(obj))
+	if err != nil {
+		return objectVersion{}
+	}
+	return objectVersion{uid: o.GetUID(), resourceVersion: o.GetResourceVersion()}
+}
+
+// RegisterExternalCollector starts the executable at the given path and
+// registers the collector it describes. The executable is stopped once ctx is
+// done. It returns the name of the collector.
+func RegisterExternalCollector(ctx context.Context, path string) (string, error) {
+	c := &externalCollector{ctx: ctx, path: path, timeout: externalCollectorTimeout, backoff: externalCollectorBackoff}
+
+	c.mtx.Lock()
+	desc, err := c.start()
+	c.mtx.Unlock()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to start external collector %s", path)
+	}
+	if err := desc.validate(); err != nil {
+		c.stop()
+		return "", errors.Wrapf(err, "invalid external collector %s", path)
+	}
+
+	availableStoresMtx.Lock()
+	defer availableStoresMtx.Unlock()
+
+	builtin, ok := availableStores[desc.Resource]
+	if !ok {
+		c.stop()
+		return "", errors.Errorf("external collector %s: unknown resource %s", desc.Name, desc.Resource)
+	}
+	if _, ok := availableStores[desc.Name]; ok {
+		c.stop()
+		return "", errors.Errorf("collector %s already exists", desc.Name)
+	}
+
+	families := c.familyGenerators(desc.Families)
+	availableStores[desc.Name] = func(b *Builder) *metricsstore.MetricsStore {
+		// The store of the built-in resource is built with the metric
+		// families of the external collector instead of its own, and is
+		// populated by the reflectors of the built-in collector if it is
+		// enabled as well.
+		listWatchFunc := b.listWatchFunc
+		b.metricFamilies, b.resource, b.listWatchFunc = families, desc.Resource, b.listWatchFuncs[desc.Resource]
+		defer func() { b.metricFamilies, b.resource, b.listWatchFunc = nil, "", listWatchFunc }()
+		return builtin(b)
+	}
+	return desc.Name, nil
+}
+
+func (d *externalDescription) validate() error {
+	if d.Name == "" || d.Resource == "" {
+		return errors.New("name and resource must be set")
+	}
+	if len(d.Families) == 0 {
+		return errors.New("no metric families described")
+	}
+	for _, f := range d.Families {
+		if err := metric.ValidateFamily(f.Name, metric.Type(f.Type)); err != nil {
+			return err
+		}
+		if metric.Type(f.Type) != metric.Gauge && metric.Type(f.Type) != metric.Counter {
+			return errors.Errorf("unsupported type %q of metric %s", f.Type, f.Name)
+		}
+	}
+	return nil
+}
+
+// familyGenerators returns the metric families described by the external
+// collector. All families of a version of an object are generated with a
+// single request, whose response is kept until the families of another
+// object or version are generated. The families of objects without a UID, or
+// whose request failed, are requested again once all of them have been
+// generated.
+func (c *externalCollector) familyGenerators(headers []externalFamilyHeader) []metric.FamilyGenerator {
+	families := make([]metric.FamilyGenerator, len(headers))
+	for i, h := range headers {
+		name := h.Name
+		families[i] = metric.FamilyGenerator{
+			Name: h.Name,
+			Type: metric.Type(h.Type),
+			Help: h.Help,
+			GenerateFunc: func(obj interface{}) *metric.Family {
+				c.mtx.Lock()
+				defer c.mtx.Unlock()
+
+				version := newObjectVersion(obj)
+				if version != c.version || (version.uid == "" || c.result == nil) && c.generated[name] {
+					c.version = version
+					c.result = c.generate(obj)
+					c.generated = map[string]bool{}
+				}
+				c.generated[name] = true
+				if f, ok := c.result[name]; ok {
+					return &metric.Family{Metrics: f.Metrics}
+				}
+				return &metric.Family{}
+			},
+		}
+	}
+	return families
+}
+
+// generate requests the metrics of the given object from the external
+// collector, by family name. Failures are logged, and the object has no
+// metrics, as have all objects until the external collector is restarted.
+func (c *externalCollector) generate(obj interface{}) map[string]*metric.Family {
+	resp, err := c.exchange(obj)
+	if err == errExternalCollectorBackoff {
+		return nil
+	}
+	if err != nil {
+		klog.Errorf("external collector %s failed: %v", c.path, err)
+		return nil
+	}
+
+	families := map[string]*metric.Family{}
+	for _, f := range resp.Families {
+		family := &metric.Family{Name: f.Name}
+		for _, m := range f.Metrics {
+			keys := make([]string, 0, len(m.Labels))
+			for k := range m.Labels {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			values := make([]string, len(keys))
+			for i, k := range keys {
+				values[i] = m.Labels[k]
+			}
+			family.Metrics = append(family.Metrics, &metric.Metric{LabelKeys: keys, LabelValues: values, Value: m.Value})
+		}
+		families[f.Name] = family
+	}
+	return families
+}
+
+// exchange sends the given object to the external collector and reads its
+// response, restarting the external collector if it is not running. After a
+// failure, the external collector is only restarted once it backed off, so a
+// wedged executable does not hold up each object for the timeout.
+func (c *externalCollector) exchange(obj interface{}) (*externalResponse, error) {
+	if c.cmd == nil && time.Now().Before(c.retry) {
+		return nil, errExternalCollectorBackoff
+	}
+
+	resp, err := c.request(obj)
+	if err != nil {
+		c.kill()
+		c.failures++
+		backoff := c.backoff
+		for i := 1; i < c.failures && backoff < externalCollectorMaxBackoff; i++ {
+			backoff *= 2
+		}
+		if backoff > externalCollectorMaxBackoff {
+			backoff = externalCollectorMaxBackoff
+		}
+		c.retry = time.Now().Add(backoff)
+		return nil, err
+	}
+	c.failures = 0
+	return resp, nil
+}
+
+// request sends the given object to the external collector and reads its
+// response, starting the external collector if it is not running.
+func (c *externalCollector) request(obj interface{}) (*externalResponse, error) {
+	if c.cmd == nil {
+		if _, err := c.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := json.Marshal(externalRequest{Object: obj})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.stdin.Write(append(req, '\n')); err != nil {
+		return nil, err
+	}
+
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	resp := &externalResponse{}
+	if err := json.Unmarshal(line, resp); err != nil {
+		return nil, errors.Wrap(err, "invalid response")
+	}
+	return resp, nil
+}
+
+// start starts the external collector and reads its description, killing it
+// if that fails. It must be called with c.mtx held.
+func (c *externalCollector) start() (*externalDescription, error) {
+	cmd := exec.CommandContext(c.ctx, c.path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	lines, done := make(chan []byte), make(chan struct{})
+	go func() {
+		defer close(lines)
+		r := bufio.NewReader(stdout)
+		for {
+			line, err := r.ReadBytes('\n')
+			if err != nil {
+				// Wait closes stdout, so it must only be called once
+				// all of it is read.
+				cmd.Wait()
+				return
+			}
+			// Lines written after the external collector is killed are
+			// discarded, as nobody reads them anymore.
+			select {
+			case lines <- line:
+			case <-done:
+			}
+		}
+	}()
+
+	c.cmd, c.stdin, c.lines, c.done = cmd, stdin, lines, done
+
+	line, err := c.readLine()
+	if err != nil {
+		c.kill()
+		return nil, err
+	}
+	desc := &externalDescription{}
+	if err := json.Unmarshal(line, desc); err != nil {
+		c.kill()
+		return nil, errors.Wrap(err, "invalid description")
+	}
+	return desc, nil
+}
+
+// readLine reads the next line written by the external collector, failing if
+// it does not write one in time.
+func (c *externalCollector) readLine() ([]byte, error) {
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case line, ok := <-c.lines:
+		if !ok {
+			return nil, errors.New("external collector exited")
+		}
+		return line, nil
+	case <-timer.C:
+		return nil, errors.Errorf("no response within %v", c.timeout)
+	}
+}
+
+// kill stops the external collector, which is restarted on the next request
+// once it backed off. It must be called with c.mtx held.
+func (c *externalCollector) kill() {
+	if c.cmd == nil {
+		return
+	}
+	c.cmd.Process.Kill()
+	c.stdin.Close()
+	close(c.done)
+	c.cmd = nil
+}
+
+func (c *externalCollector) stop() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.kill()
+}
@@ -0,0 +1,388 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+const (
+	// externalCollectorEnv makes the test binary act as the external
+	// collector described by its value, see runExternalCollector.
+	externalCollectorEnv = "KSM_TEST_EXTERNAL_COLLECTOR"
+	// externalCollectorHangEnv makes the external collector never respond.
+	externalCollectorHangEnv = "KSM_TEST_EXTERNAL_COLLECTOR_HANG"
+	// externalCollectorExitEnv makes the external collector exit after its
+	// first response.
+	externalCollectorExitEnv = "KSM_TEST_EXTERNAL_COLLECTOR_EXIT"
+)
+
+func TestMain(m *testing.M) {
+	if desc := os.Getenv(externalCollectorEnv); desc != "" {
+		runExternalCollector(desc)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runExternalCollector writes the given description and responds to config
+// maps with the number of their keys, unless it is told to hang or to exit.
+func runExternalCollector(desc string) {
+	fmt.Println(desc)
+
+	s := bufio.NewScanner(os.Stdin)
+	for s.Scan() {
+		if os.Getenv(externalCollectorHangEnv) != "" {
+			continue
+		}
+		var req struct {
+			Object v1.ConfigMap `json:"object"`
+		}
+		if err := json.Unmarshal(s.Bytes(), &req); err != nil {
+			os.Exit(1)
+		}
+		resp, _ := json.Marshal(externalResponse{Families: []externalFamily{{
+			Name: "acme_configmap_keys",
+			Metrics: []externalMetric{{
+				Labels: map[string]string{"namespace": req.Object.Namespace, "configmap": req.Object.Name},
+				Value:  float64(len(req.Object.Data)),
+			}},
+		}}})
+		fmt.Println(string(resp))
+		if os.Getenv(externalCollectorExitEnv) != "" {
+			return
+		}
+	}
+}
+
+func TestRegisterExternalCollector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	os.Setenv(externalCollectorEnv, `{"name": "acme-configmaps", "resource": "configmaps", "families": [{"name": "acme_configmap_keys", "type": "gauge", "help": "Number of keys."}, {"name": "acme_configmap_unknown", "type": "gauge", "help": "Not generated."}]}`)
+	defer os.Unsetenv(externalCollectorEnv)
+
+	name, err := RegisterExternalCollector(ctx, os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "acme-configmaps" {
+		t.Errorf("expected collector acme-configmaps but got %s", name)
+	}
+	if _, err := RegisterExternalCollector(ctx, os.Args[0]); err == nil {
+		t.Error("expected error registering the same collector twice")
+	}
+
+	wl, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	b.WithContext(ctx)
+	b.WithKubeClient(fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc"},
+		Data:       map[string]string{"a": "1", "b": "2"},
+	}))
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSharding(0, 1)
+	b.WithWhiteBlackList(wl)
+	// The external collector shares the reflector of the built-in collector.
+	var lists int32
+	b.WithListWatchFunc("configmaps", func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		lw := createConfigMapListWatch(kubeClient, ns).(*cache.ListWatch)
+		list := lw.ListFunc
+		lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+			atomic.AddInt32(&lists, 1)
+			return list(opts)
+		}
+		return lw
+	})
+	if err := b.WithEnabledResources([]string{"configmaps", name}); err != nil {
+		t.Fatal(err)
+	}
+	stores := b.Build()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !stores[0].HasSynced() || !stores[1].HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stores to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&lists); n != 1 {
+		t.Errorf("expected configmaps to be listed once but got %d lists", n)
+	}
+
+	buf := &bytes.Buffer{}
+	for i, c := range b.EnabledResources() {
+		if c == name {
+			stores[i].WriteAll(buf)
+		}
+	}
+	want := `# HELP acme_configmap_keys Number of keys.
+# TYPE acme_configmap_keys gauge
+acme_configmap_keys{configmap="cm",namespace="default"} 2
+# HELP acme_configmap_unknown Not generated.
+# TYPE acme_configmap_unknown gauge
+`
+	if buf.String() != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, buf.String())
+	}
+}
+
+func TestRegisterExternalCollectorInvalid(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		desc    string
+		wantErr string
+	}{
+		{desc: `not json`, wantErr: "invalid description"},
+		{desc: `{"name": "acme", "resource": "unknown", "families": [{"name": "acme_info", "type": "gauge"}]}`, wantErr: "unknown resource"},
+		{desc: `{"name": "acme", "resource": "pods", "families": []}`, wantErr: "no metric families"},
+		{desc: `{"name": "acme", "resource": "pods", "families": [{"name": "acme-info", "type": "gauge"}]}`, wantErr: "invalid metric name"},
+		{desc: `{"name": "acme", "resource": "pods", "families": [{"name": "acme_info", "type": "histogram"}]}`, wantErr: "unsupported type"},
+		{desc: `{"name": "acme", "resource": "pods", "families": [{"name": "acme_restarts", "type": "counter"}]}`, wantErr: "must end with _total"},
+	}
+
+	for _, test := range tests {
+		os.Setenv(externalCollectorEnv, test.desc)
+		_, err := RegisterExternalCollector(ctx, os.Args[0])
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: expected error containing %q but got %v", test.desc, test.wantErr, err)
+		}
+	}
+	os.Unsetenv(externalCollectorEnv)
+
+	if _, err := RegisterExternalCollector(ctx, "/nonexistent"); err == nil {
+		t.Error("expected error for missing executable")
+	}
+}
+
+func TestExternalCollectorCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	os.Setenv(externalCollectorEnv, `{"name": "acme-configmaps", "resource": "configmaps", "families": [{"name": "acme_configmap_keys", "type": "gauge", "help": "Number of keys."}]}`)
+	defer os.Unsetenv(externalCollectorEnv)
+
+	c := &externalCollector{ctx: ctx, path: os.Args[0], timeout: externalCollectorTimeout}
+	c.mtx.Lock()
+	desc, err := c.start()
+	c.mtx.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.stop()
+	generate := c.familyGenerators(desc.Families)[0].GenerateFunc
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc", ResourceVersion: "1"},
+		Data:       map[string]string{"a": "1"},
+	}
+	keys := func() float64 {
+		f := generate(cm)
+		if len(f.Metrics) != 1 {
+			t.Fatalf("expected one metric but got %d", len(f.Metrics))
+		}
+		return f.Metrics[0].Value
+	}
+
+	if got := keys(); got != 1 {
+		t.Errorf("expected 1 key but got %v", got)
+	}
+	// The response is kept for the same version of the object, even if it
+	// is modified in place.
+	cm.Data["b"] = "2"
+	if got := keys(); got != 1 {
+		t.Errorf("expected the cached 1 key but got %v", got)
+	}
+	cm.ResourceVersion = "2"
+	if got := keys(); got != 2 {
+		t.Errorf("expected 2 keys of the new version but got %v", got)
+	}
+}
+
+func TestExternalCollectorBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	os.Setenv(externalCollectorEnv, `{"name": "acme-configmaps", "resource": "configmaps", "families": [{"name": "acme_configmap_keys", "type": "gauge", "help": "Number of keys."}]}`)
+	os.Setenv(externalCollectorHangEnv, "1")
+	defer os.Unsetenv(externalCollectorEnv)
+	defer os.Unsetenv(externalCollectorHangEnv)
+
+	c := &externalCollector{ctx: ctx, path: os.Args[0], timeout: 100 * time.Millisecond, backoff: time.Hour}
+	c.mtx.Lock()
+	desc, err := c.start()
+	c.mtx.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.stop()
+	generate := c.familyGenerators(desc.Families)[0].GenerateFunc
+
+	newConfigMap := func(name string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)}}
+	}
+
+	start := time.Now()
+	if f := generate(newConfigMap("a")); len(f.Metrics) != 0 {
+		t.Errorf("expected no metrics from hanging collector but got %d", len(f.Metrics))
+	}
+	if d := time.Since(start); d < c.timeout {
+		t.Errorf("expected to wait for the timeout but returned after %v", d)
+	}
+
+	// Until it backed off, the collector is not restarted, so objects do not
+	// wait for the timeout again.
+	start = time.Now()
+	for _, name := range []string{"b", "c", "d"} {
+		if f := generate(newConfigMap(name)); len(f.Metrics) != 0 {
+			t.Errorf("expected no metrics while backing off but got %d", len(f.Metrics))
+		}
+	}
+	if d := time.Since(start); d >= c.timeout {
+		t.Errorf("expected no wait while backing off but took %v", d)
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.cmd != nil {
+		t.Error("expected collector not to be restarted while backing off")
+	}
+	if c.failures != 1 {
+		t.Errorf("expected 1 failure but got %d", c.failures)
+	}
+}
+
+func TestExternalCollectorHangingDoesNotStallReflector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	os.Setenv(externalCollectorEnv, `{"name": "acme-hanging-configmaps", "resource": "configmaps", "families": [{"name": "acme_configmap_keys", "type": "gauge", "help": "Number of keys."}]}`)
+	os.Setenv(externalCollectorHangEnv, "1")
+	defer os.Unsetenv(externalCollectorEnv)
+	defer os.Unsetenv(externalCollectorHangEnv)
+
+	name, err := RegisterExternalCollector(ctx, os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		availableStoresMtx.Lock()
+		delete(availableStores, name)
+		availableStoresMtx.Unlock()
+	}()
+
+	wl, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	b.WithContext(ctx)
+	b.WithKubeClient(fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", UID: "a"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", UID: "b"}},
+	))
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSharding(0, 1)
+	b.WithWhiteBlackList(wl)
+	if err := b.WithEnabledResources([]string{"configmaps", name}); err != nil {
+		t.Fatal(err)
+	}
+	stores := b.Build()
+
+	var configMaps int
+	for i, c := range b.EnabledResources() {
+		if c == "configmaps" {
+			configMaps = i
+		}
+	}
+	// The built-in store syncs well before the external collector times out
+	// on the first object.
+	deadline := time.Now().Add(externalCollectorTimeout / 2)
+	for !stores[configMaps].HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the built-in store to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestExternalCollectorExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	os.Setenv(externalCollectorEnv, `{"name": "acme-configmaps", "resource": "configmaps", "families": [{"name": "acme_configmap_keys", "type": "gauge", "help": "Number of keys."}]}`)
+	os.Setenv(externalCollectorExitEnv, "1")
+	defer os.Unsetenv(externalCollectorEnv)
+	defer os.Unsetenv(externalCollectorExitEnv)
+
+	c := &externalCollector{ctx: ctx, path: os.Args[0], timeout: externalCollectorTimeout, backoff: time.Hour}
+	c.mtx.Lock()
+	desc, err := c.start()
+	c.mtx.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.stop()
+	generate := c.familyGenerators(desc.Families)[0].GenerateFunc
+
+	// The response written right before the collector exits is not lost.
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc"},
+		Data:       map[string]string{"a": "1"},
+	}
+	if f := generate(cm); len(f.Metrics) != 1 || f.Metrics[0].Value != 1 {
+		t.Fatalf("expected the last response of the collector but got %+v", f.Metrics)
+	}
+
+	// Once it exited, the next request fails and kills it.
+	cm.UID = "def"
+	if f := generate(cm); len(f.Metrics) != 0 {
+		t.Errorf("expected no metrics of the exited collector but got %d", len(f.Metrics))
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.cmd != nil || c.failures != 1 {
+		t.Errorf("expected the exited collector to be killed after 1 failure but got %d failures", c.failures)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	federatedConfigMapMetricFamilies             = federatedMetricFamilies("federatedconfigmap", "federated config map")
+	federatedDeploymentMetricFamilies            = federatedMetricFamilies("federateddeployment", "federated deployment")
+	federatedIngressMetricFamilies               = federatedMetricFamilies("federatedingress", "federated ingress")
+	federatedPersistentVolumeClaimMetricFamilies = federatedMetricFamilies("federatedpersistentvolumeclaim", "federated persistent volume claim")
+	federatedSecretMetricFamilies                = federatedMetricFamilies("federatedsecret", "federated secret")
+	federatedServiceMetricFamilies               = federatedMetricFamilies("federatedservice", "federated service")
+	federatedStatefulSetMetricFamilies           = federatedMetricFamilies("federatedstatefulset", "federated stateful set")
+)
+
+// federatedObject is implemented by the objects of all federated types.
+type federatedObject interface {
+	Federated() *kubesphere.FederatedObject
+}
+
+// federatedMetricFamilies returns the metric families of a federated type,
+// prefixed with name, e.g. federateddeployment, which is also the label of the
+// name of the object. kind is the kind of the type used in the help texts.
+func federatedMetricFamilies(name, kind string) []metric.FamilyGenerator {
+	return []metric.FamilyGenerator{
+		{
+			Name:           "kube_" + name + "_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapFederatedFunc(name, func(f *kubesphere.FederatedObject) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&f.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           "kube_" + name + "_labels",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Kubernetes labels converted to Prometheus labels.",
+			GenerateFunc: wrapFederatedFunc(name, func(f *kubesphere.FederatedObject) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(f.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_" + name + "_placement_cluster",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Member cluster the " + kind + " is placed in.",
+			GenerateFunc: wrapFederatedFunc(name, func(f *kubesphere.FederatedObject) *metric.Family {
+				ms := make([]*metric.Metric, len(f.Spec.Placement.Clusters))
+				for i, c := range f.Spec.Placement.Clusters {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"cluster"},
+						LabelValues: []string{c.Name},
+						Value:       1,
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_" + name + "_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The current status conditions of the " + kind + ".",
+			GenerateFunc: wrapFederatedFunc(name, func(f *kubesphere.FederatedObject) *metric.Family {
+				ms := make([]*metric.Metric, len(f.Status.Conditions)*len(conditionStatuses))
+
+				for i, c := range f.Status.Conditions {
+					conditionMetrics := addConditionMetrics(c.Status)
+
+					for j, m := range conditionMetrics {
+						metric := m
+
+						metric.LabelKeys = []string{"condition", "status"}
+						metric.LabelValues = append([]string{c.Type}, metric.LabelValues...)
+						ms[i*len(conditionStatuses)+j] = metric
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_" + name + "_status_cluster_propagated",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Whether the " + kind + " is propagated to the member cluster, with the reason if not.",
+			GenerateFunc: wrapFederatedFunc(name, func(f *kubesphere.FederatedObject) *metric.Family {
+				ms := make([]*metric.Metric, len(f.Status.Clusters))
+				for i, c := range f.Status.Clusters {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"cluster", "reason"},
+						LabelValues: []string{c.Name, c.Status},
+						Value:       boolFloat64(c.Status == ""),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+}
+
+func wrapFederatedFunc(name string, f func(*kubesphere.FederatedObject) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		o, ok := unwrapObject(obj).(federatedObject)
+		if !ok {
+			return unexpectedObject((*kubesphere.FederatedObject)(nil), obj)
+		}
+		federated := o.Federated()
+
+		metricFamily := f(federated)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append([]string{"namespace", name}, m.LabelKeys...)
+			m.LabelValues = append([]string{federated.Namespace, federated.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createFederatedListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface, resource schema.GroupVersionResource, newObject, newList func() runtime.Object) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), resource, ns, newObject, newList)
+	}
+}
+
+func createFederatedConfigMapListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedConfigMapResource,
+		func() runtime.Object { return &kubesphere.FederatedConfigMap{} },
+		func() runtime.Object { return &kubesphere.FederatedConfigMapList{} },
+	)
+}
+
+func createFederatedDeploymentListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedDeploymentResource,
+		func() runtime.Object { return &kubesphere.FederatedDeployment{} },
+		func() runtime.Object { return &kubesphere.FederatedDeploymentList{} },
+	)
+}
+
+func createFederatedIngressListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedIngressResource,
+		func() runtime.Object { return &kubesphere.FederatedIngress{} },
+		func() runtime.Object { return &kubesphere.FederatedIngressList{} },
+	)
+}
+
+func createFederatedPersistentVolumeClaimListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedPersistentVolumeClaimResource,
+		func() runtime.Object { return &kubesphere.FederatedPersistentVolumeClaim{} },
+		func() runtime.Object { return &kubesphere.FederatedPersistentVolumeClaimList{} },
+	)
+}
+
+func createFederatedSecretListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedSecretResource,
+		func() runtime.Object { return &kubesphere.FederatedSecret{} },
+		func() runtime.Object { return &kubesphere.FederatedSecretList{} },
+	)
+}
+
+func createFederatedServiceListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedServiceResource,
+		func() runtime.Object { return &kubesphere.FederatedService{} },
+		func() runtime.Object { return &kubesphere.FederatedServiceList{} },
+	)
+}
+
+func createFederatedStatefulSetListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return createFederatedListWatch(dynamicClientFor, kubesphere.FederatedStatefulSetResource,
+		func() runtime.Object { return &kubesphere.FederatedStatefulSet{} },
+		func() runtime.Object { return &kubesphere.FederatedStatefulSetList{} },
+	)
+}
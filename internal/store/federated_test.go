@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestFederatedStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_federateddeployment_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_federateddeployment_created gauge
+		# HELP kube_federateddeployment_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_federateddeployment_labels gauge
+		# HELP kube_federateddeployment_placement_cluster [EXPERIMENTAL] Member cluster the federated deployment is placed in.
+		# TYPE kube_federateddeployment_placement_cluster gauge
+		# HELP kube_federateddeployment_status_condition [EXPERIMENTAL] The current status conditions of the federated deployment.
+		# TYPE kube_federateddeployment_status_condition gauge
+		# HELP kube_federateddeployment_status_cluster_propagated [EXPERIMENTAL] Whether the federated deployment is propagated to the member cluster, with the reason if not.
+		# TYPE kube_federateddeployment_status_cluster_propagated gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.FederatedDeployment{
+				FederatedObject: kubesphere.FederatedObject{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "web",
+						Namespace:         "project1",
+						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+						Labels: map[string]string{
+							"app": "web",
+						},
+					},
+					Spec: kubesphere.FederatedSpec{
+						Placement: kubesphere.FederatedPlacement{
+							Clusters: []kubesphere.FederatedClusterReference{{Name: "member1"}, {Name: "member2"}},
+						},
+					},
+					Status: kubesphere.FederatedStatus{
+						Conditions: []kubesphere.FederatedCondition{
+							{Type: "Propagation", Status: v1.ConditionFalse, Reason: "CheckClusters"},
+						},
+						Clusters: []kubesphere.FederatedClusterStatus{
+							{Name: "member1"},
+							{Name: "member2", Status: "CreationFailed"},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_federateddeployment_created{federateddeployment="web",namespace="project1"} 1.5e+09
+				kube_federateddeployment_labels{federateddeployment="web",label_app="web",namespace="project1"} 1
+				kube_federateddeployment_placement_cluster{cluster="member1",federateddeployment="web",namespace="project1"} 1
+				kube_federateddeployment_placement_cluster{cluster="member2",federateddeployment="web",namespace="project1"} 1
+				kube_federateddeployment_status_condition{condition="Propagation",federateddeployment="web",namespace="project1",status="false"} 1
+				kube_federateddeployment_status_condition{condition="Propagation",federateddeployment="web",namespace="project1",status="true"} 0
+				kube_federateddeployment_status_condition{condition="Propagation",federateddeployment="web",namespace="project1",status="unknown"} 0
+				kube_federateddeployment_status_cluster_propagated{cluster="member1",federateddeployment="web",namespace="project1",reason=""} 1
+				kube_federateddeployment_status_cluster_propagated{cluster="member2",federateddeployment="web",namespace="project1",reason="CreationFailed"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(federatedDeploymentMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(federatedDeploymentMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"regexp"
+	"testing"
+)
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// labelSeeds are label keys and values which broke or could break the
+// conversion, as tenants control the labels of their objects.
+var labelSeeds = [][4]string{
+	{"app.kubernetes.io/name", "foo", "app_kubernetes_io_name", "bar"},
+	{"", "", "_", "\x00"},
+	{"__name__", "kube_pod_info", "label___name__", "x"},
+	{"label_conflict_1", "a", "conflict", "b"},
+	{"日本語", "値", "\xff\xfe", "\xc3\x28"},
+	{"a\"b", "a\"b\\c\nd", "a=b,c", "{}"},
+}
+
+func FuzzKubeLabelsToPrometheusLabels(f *testing.F) {
+	for _, s := range labelSeeds {
+		f.Add(s[0], s[1], s[2], s[3])
+	}
+
+	f.Fuzz(func(t *testing.T, k1, v1, k2, v2 string) {
+		labels := map[string]string{k1: v1, k2: v2}
+		keys, values := kubeLabelsToPrometheusLabels(labels)
+		checkPrometheusLabels(t, labels, keys, values)
+	})
+}
+
+func FuzzAnnotationsToPrometheusLabels(f *testing.F) {
+	for _, s := range labelSeeds {
+		f.Add(s[0], s[1], s[2], s[3])
+	}
+
+	f.Fuzz(func(t *testing.T, k1, v1, k2, v2 string) {
+		annotations := map[string]string{k1: v1, k2: v2}
+		keys, values := mapToPrometheusLabels(annotations, "annotation")
+		checkPrometheusLabels(t, annotations, keys, values)
+	})
+}
+
+// checkPrometheusLabels checks that the given label names are valid and
+// unique, and that the values of the given map are kept in the order of its
+// sorted keys.
+func checkPrometheusLabels(t *testing.T, labels map[string]string, keys, values []string) {
+	t.Helper()
+
+	if len(keys) != len(labels) || len(values) != len(labels) {
+		t.Fatalf("expected %d label names and values, got %q and %q", len(labels), keys, values)
+	}
+
+	seen := map[string]struct{}{}
+	for _, k := range keys {
+		if !labelNameRegexp.MatchString(k) {
+			t.Errorf("invalid label name %q", k)
+		}
+		if _, ok := seen[k]; ok {
+			t.Errorf("duplicate label name %q in %q", k, keys)
+		}
+		seen[k] = struct{}{}
+	}
+
+	want := map[string]int{}
+	for _, v := range labels {
+		want[v]++
+	}
+	for _, v := range values {
+		want[v]--
+	}
+	for v, n := range want {
+		if n != 0 {
+			t.Errorf("label value %q not kept in %q", v, values)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descGatewayLabelsName          = "kube_gateway_labels"
+	descGatewayLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descGatewayLabelsDefaultLabels = []string{"namespace", "gateway"}
+
+	gatewayMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_gateway_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about gateway.",
+			GenerateFunc: wrapGatewayFunc(func(g *kubesphere.Gateway) *metric.Family {
+				gatewayType, project := "cluster", ""
+				if g.Spec.Controller.Scope.Enabled {
+					gatewayType, project = "project", g.Spec.Controller.Scope.Namespace
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type", "project", "service_type"},
+							LabelValues: []string{gatewayType, project, string(g.Spec.Service.Type)},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_gateway_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapGatewayFunc(func(g *kubesphere.Gateway) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&g.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descGatewayLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descGatewayLabelsHelp,
+			GenerateFunc: wrapGatewayFunc(func(g *kubesphere.Gateway) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(g.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_gateway_spec_replicas",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of desired replicas of the ingress controller of the gateway.",
+			GenerateFunc: wrapGatewayFunc(func(g *kubesphere.Gateway) *metric.Family {
+				replicas := g.Spec.Deployment.Replicas
+				if replicas == nil {
+					replicas = g.Spec.Controller.Replicas
+				}
+				if replicas == nil {
+					return &metric.Family{}
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(*replicas),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_gateway_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The current status conditions of the Helm release of the gateway.",
+			GenerateFunc: wrapGatewayFunc(func(g *kubesphere.Gateway) *metric.Family {
+				ms := make([]*metric.Metric, len(g.Status.Conditions)*len(conditionStatuses))
+
+				for i, c := range g.Status.Conditions {
+					conditionMetrics := addConditionMetrics(c.Status)
+
+					for j, m := range conditionMetrics {
+						metric := m
+
+						metric.LabelKeys = []string{"condition", "status"}
+						metric.LabelValues = append([]string{c.Type}, metric.LabelValues...)
+						ms[i*len(conditionStatuses)+j] = metric
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapGatewayFunc(f func(*kubesphere.Gateway) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		gateway, ok := unwrapObject(obj).(*kubesphere.Gateway)
+		if !ok {
+			return unexpectedObject((*kubesphere.Gateway)(nil), obj)
+		}
+
+		metricFamily := f(gateway)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descGatewayLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{gateway.Namespace, gateway.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createGatewayListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.GatewayResource, ns,
+			func() runtime.Object { return &kubesphere.Gateway{} },
+			func() runtime.Object { return &kubesphere.GatewayList{} },
+		)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestGatewayStore(t *testing.T) {
+	var one, two int32 = 1, 2
+
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_gateway_info [EXPERIMENTAL] Information about gateway.
+		# TYPE kube_gateway_info gauge
+		# HELP kube_gateway_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_gateway_created gauge
+		# HELP kube_gateway_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_gateway_labels gauge
+		# HELP kube_gateway_spec_replicas [EXPERIMENTAL] Number of desired replicas of the ingress controller of the gateway.
+		# TYPE kube_gateway_spec_replicas gauge
+		# HELP kube_gateway_status_condition [EXPERIMENTAL] The current status conditions of the Helm release of the gateway.
+		# TYPE kube_gateway_status_condition gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "kubesphere-router-project1",
+					Namespace:         "kubesphere-controls-system",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: kubesphere.GatewaySpec{
+					Controller: kubesphere.GatewayController{
+						Replicas: &one,
+						Scope:    kubesphere.GatewayScope{Enabled: true, Namespace: "project1"},
+					},
+					Deployment: kubesphere.GatewayDeployment{Replicas: &two},
+					Service:    kubesphere.GatewayService{Type: v1.ServiceTypeNodePort},
+				},
+				Status: kubesphere.GatewayStatus{
+					Conditions: []kubesphere.GatewayCondition{
+						{Type: "Deployed", Status: v1.ConditionTrue, Reason: "InstallSuccessful"},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_gateway_created{gateway="kubesphere-router-project1",namespace="kubesphere-controls-system"} 1.5e+09
+				kube_gateway_info{gateway="kubesphere-router-project1",namespace="kubesphere-controls-system",project="project1",service_type="NodePort",type="project"} 1
+				kube_gateway_labels{gateway="kubesphere-router-project1",namespace="kubesphere-controls-system"} 1
+				kube_gateway_spec_replicas{gateway="kubesphere-router-project1",namespace="kubesphere-controls-system"} 2
+				kube_gateway_status_condition{condition="Deployed",gateway="kubesphere-router-project1",namespace="kubesphere-controls-system",status="false"} 0
+				kube_gateway_status_condition{condition="Deployed",gateway="kubesphere-router-project1",namespace="kubesphere-controls-system",status="true"} 1
+				kube_gateway_status_condition{condition="Deployed",gateway="kubesphere-router-project1",namespace="kubesphere-controls-system",status="unknown"} 0
+`,
+		},
+		{
+			Obj: &kubesphere.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kubesphere-router-kubesphere-system",
+					Namespace: "kubesphere-controls-system",
+				},
+				Spec: kubesphere.GatewaySpec{
+					Controller: kubesphere.GatewayController{Replicas: &one},
+					Service:    kubesphere.GatewayService{Type: v1.ServiceTypeLoadBalancer},
+				},
+			},
+			MetricNames: []string{"kube_gateway_info", "kube_gateway_spec_replicas"},
+			Want: `
+				# HELP kube_gateway_info [EXPERIMENTAL] Information about gateway.
+				# TYPE kube_gateway_info gauge
+				# HELP kube_gateway_spec_replicas [EXPERIMENTAL] Number of desired replicas of the ingress controller of the gateway.
+				# TYPE kube_gateway_spec_replicas gauge
+				kube_gateway_info{gateway="kubesphere-router-kubesphere-system",namespace="kubesphere-controls-system",project="",service_type="LoadBalancer",type="cluster"} 1
+				kube_gateway_spec_replicas{gateway="kubesphere-router-kubesphere-system",namespace="kubesphere-controls-system"} 1
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(gatewayMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(gatewayMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -92,9 +92,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_hpa_spec_target_metric",
-			Type: metric.Gauge,
-			Help: "The metric specifications used by this autoscaler when calculating the desired replica count.",
+			Name:           "kube_hpa_spec_target_metric",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "The metric specifications used by this autoscaler when calculating the desired replica count.",
 			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
 				ms := make([]*metric.Metric, 0, len(a.Spec.Metrics))
 				for _, m := range a.Spec.Metrics {
@@ -200,7 +201,7 @@ var (
 		},
 		{
 			Name: "kube_hpa_status_condition",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The condition of this autoscaler.",
 			GenerateFunc: wrapHPAFunc(func(a *autoscaling.HorizontalPodAutoscaler) *metric.Family {
 				ms := make([]*metric.Metric, 0, len(a.Status.Conditions)*len(conditionStatuses))
@@ -226,7 +227,10 @@ var (
 
 func wrapHPAFunc(f func(*autoscaling.HorizontalPodAutoscaler) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		hpa := obj.(*autoscaling.HorizontalPodAutoscaler)
+		hpa, ok := unwrapObject(obj).(*autoscaling.HorizontalPodAutoscaler)
+		if !ok {
+			return unexpectedObject((*autoscaling.HorizontalPodAutoscaler)(nil), obj)
+		}
 
 		metricFamily := f(hpa)
 
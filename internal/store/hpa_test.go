@@ -39,7 +39,7 @@ func TestHPAStore(t *testing.T) {
 		# HELP kube_hpa_metadata_generation The generation observed by the HorizontalPodAutoscaler controller.
 		# HELP kube_hpa_spec_max_replicas Upper limit for the number of pods that can be set by the autoscaler; cannot be smaller than MinReplicas.
 		# HELP kube_hpa_spec_min_replicas Lower limit for the number of pods that can be set by the autoscaler, default 1.
-		# HELP kube_hpa_spec_target_metric The metric specifications used by this autoscaler when calculating the desired replica count.
+		# HELP kube_hpa_spec_target_metric [EXPERIMENTAL] The metric specifications used by this autoscaler when calculating the desired replica count.
 		# HELP kube_hpa_status_condition The condition of this autoscaler.
 		# HELP kube_hpa_status_current_replicas Current number of replicas of pods managed by this autoscaler.
 		# HELP kube_hpa_status_desired_replicas Desired number of replicas of pods managed by this autoscaler.
@@ -36,7 +36,7 @@ var (
 	ingressMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_ingress_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about ingress.",
 			GenerateFunc: wrapIngressFunc(func(s *v1beta1.Ingress) *metric.Family {
 				return &metric.Family{
@@ -69,23 +69,16 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !i.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(i.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&i.CreationTimestamp),
 				}
 			}),
 		},
 		{
-			Name: "kube_ingress_metadata_resource_version",
-			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of ingress.",
+			Name:           "kube_ingress_metadata_resource_version",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Resource version representing a specific version of ingress.",
 			GenerateFunc: wrapIngressFunc(func(i *v1beta1.Ingress) *metric.Family {
 				return &metric.Family{
 					Metrics: resourceVersionMetric(i.ObjectMeta.ResourceVersion),
@@ -139,7 +132,10 @@ var (
 
 func wrapIngressFunc(f func(*v1beta1.Ingress) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		ingress := obj.(*v1beta1.Ingress)
+		ingress, ok := unwrapObject(obj).(*v1beta1.Ingress)
+		if !ok {
+			return unexpectedObject((*v1beta1.Ingress)(nil), obj)
+		}
 
 		metricFamily := f(ingress)
 
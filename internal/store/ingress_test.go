@@ -36,7 +36,7 @@ func TestIngressStore(t *testing.T) {
 		# HELP kube_ingress_created Unix creation timestamp
 		# HELP kube_ingress_info Information about ingress.
 		# HELP kube_ingress_labels Kubernetes labels converted to Prometheus labels.
-		# HELP kube_ingress_metadata_resource_version Resource version representing a specific version of ingress.
+		# HELP kube_ingress_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of ingress.
 		# HELP kube_ingress_path Ingress host, paths and backend service information.
 		# HELP kube_ingress_tls Ingress TLS host and secret information.
 		# TYPE kube_ingress_created gauge
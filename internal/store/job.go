@@ -54,7 +54,7 @@ var (
 		},
 		{
 			Name: "kube_job_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about job.",
 			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
 				return &metric.Family{
@@ -71,16 +71,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !j.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(j.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&j.CreationTimestamp),
 				}
 			}),
 		},
@@ -230,17 +222,8 @@ var (
 			Type: metric.Gauge,
 			Help: "StartTime represents time when the job was acknowledged by the Job Manager.",
 			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if j.Status.StartTime != nil {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(j.Status.StartTime.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(j.Status.StartTime),
 				}
 			}),
 		},
@@ -249,16 +232,8 @@ var (
 			Type: metric.Gauge,
 			Help: "CompletionTime represents time when the job was completed.",
 			GenerateFunc: wrapJobFunc(func(j *v1batch.Job) *metric.Family {
-				ms := []*metric.Metric{}
-				if j.Status.CompletionTime != nil {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(j.Status.CompletionTime.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(j.Status.CompletionTime),
 				}
 			}),
 		},
@@ -311,7 +286,10 @@ var (
 
 func wrapJobFunc(f func(*v1batch.Job) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		job := obj.(*v1batch.Job)
+		job, ok := unwrapObject(obj).(*v1batch.Job)
+		if !ok {
+			return unexpectedObject((*v1batch.Job)(nil), obj)
+		}
 
 		metricFamily := f(job)
 
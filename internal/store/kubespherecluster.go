@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descKubeSphereClusterLabelsName = "kube_kubespherecluster_labels"
+	descKubeSphereClusterLabelsHelp = "Kubernetes labels converted to Prometheus labels."
+	// The label is not named cluster, which is the label of the member
+	// clusters of kube-state-metrics itself in multi-cluster mode.
+	descKubeSphereClusterLabelsDefaultLabels = []string{"kubespherecluster"}
+
+	kubeSphereClusterMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_kubespherecluster_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about KubeSphere cluster.",
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"provider", "connection_type", "kubernetes_version", "kubesphere_version"},
+							LabelValues: []string{c.Spec.Provider, c.Spec.Connection.Type, c.Status.KubernetesVersion, c.Status.KubeSphereVersion},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_kubespherecluster_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&c.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descKubeSphereClusterLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descKubeSphereClusterLabelsHelp,
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(c.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_kubespherecluster_joined_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the KubeSphere cluster joined the federation of the host cluster.",
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				for _, cond := range c.Status.Conditions {
+					if cond.Type == kubesphere.ClusterFederated && cond.Status == v1.ConditionTrue {
+						return &metric.Family{
+							Metrics: timestampMetrics(&cond.LastTransitionTime),
+						}
+					}
+				}
+				return &metric.Family{}
+			}),
+		},
+		{
+			Name:           "kube_kubespherecluster_status_nodes",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of nodes of the KubeSphere cluster.",
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(c.Status.NodeCount),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_kubespherecluster_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The current status conditions of the KubeSphere cluster.",
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				ms := make([]*metric.Metric, len(c.Status.Conditions)*len(conditionStatuses))
+
+				for i, cond := range c.Status.Conditions {
+					conditionMetrics := addConditionMetrics(cond.Status)
+
+					for j, m := range conditionMetrics {
+						metric := m
+
+						metric.LabelKeys = []string{"condition", "status"}
+						metric.LabelValues = append([]string{cond.Type}, metric.LabelValues...)
+						ms[i*len(conditionStatuses)+j] = metric
+					}
+				}
+
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_kubespherecluster_status_condition_last_update_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the status conditions of the KubeSphere cluster were last updated.",
+			GenerateFunc: wrapKubeSphereClusterFunc(func(c *kubesphere.Cluster) *metric.Family {
+				ms := []*metric.Metric{}
+				for _, cond := range c.Status.Conditions {
+					for _, m := range timestampMetrics(&cond.LastUpdateTime) {
+						m.LabelKeys = []string{"condition"}
+						m.LabelValues = []string{cond.Type}
+						ms = append(ms, m)
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapKubeSphereClusterFunc(f func(*kubesphere.Cluster) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		cluster, ok := unwrapObject(obj).(*kubesphere.Cluster)
+		if !ok {
+			return unexpectedObject((*kubesphere.Cluster)(nil), obj)
+		}
+
+		metricFamily := f(cluster)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descKubeSphereClusterLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{cluster.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createKubeSphereClusterListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.ClusterResource, metav1.NamespaceAll,
+			func() runtime.Object { return &kubesphere.Cluster{} },
+			func() runtime.Object { return &kubesphere.ClusterList{} },
+		)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestKubeSphereClusterStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_kubespherecluster_info [EXPERIMENTAL] Information about KubeSphere cluster.
+		# TYPE kube_kubespherecluster_info gauge
+		# HELP kube_kubespherecluster_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_kubespherecluster_created gauge
+		# HELP kube_kubespherecluster_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_kubespherecluster_labels gauge
+		# HELP kube_kubespherecluster_joined_time [EXPERIMENTAL] Unix timestamp the KubeSphere cluster joined the federation of the host cluster.
+		# TYPE kube_kubespherecluster_joined_time gauge
+		# HELP kube_kubespherecluster_status_nodes [EXPERIMENTAL] Number of nodes of the KubeSphere cluster.
+		# TYPE kube_kubespherecluster_status_nodes gauge
+		# HELP kube_kubespherecluster_status_condition [EXPERIMENTAL] The current status conditions of the KubeSphere cluster.
+		# TYPE kube_kubespherecluster_status_condition gauge
+		# HELP kube_kubespherecluster_status_condition_last_update_time [EXPERIMENTAL] Unix timestamp the status conditions of the KubeSphere cluster were last updated.
+		# TYPE kube_kubespherecluster_status_condition_last_update_time gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "member1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"cluster-role.kubesphere.io/member": "",
+					},
+				},
+				Spec: kubesphere.ClusterSpec{
+					Provider:   "QingCloud",
+					Connection: kubesphere.ClusterConnection{Type: "proxy"},
+				},
+				Status: kubesphere.ClusterStatus{
+					Conditions: []kubesphere.ClusterCondition{
+						{
+							Type:               kubesphere.ClusterFederated,
+							Status:             v1.ConditionTrue,
+							LastUpdateTime:     metav1.Time{Time: time.Unix(1500000100, 0)},
+							LastTransitionTime: metav1.Time{Time: time.Unix(1500000100, 0)},
+						},
+						{
+							Type:               kubesphere.ClusterReady,
+							Status:             v1.ConditionFalse,
+							LastUpdateTime:     metav1.Time{Time: time.Unix(1600000000, 0)},
+							LastTransitionTime: metav1.Time{Time: time.Unix(1500000200, 0)},
+						},
+					},
+					KubernetesVersion: "v1.18.6",
+					KubeSphereVersion: "v3.0.0",
+					NodeCount:         3,
+				},
+			},
+			Want: metadata + `
+				kube_kubespherecluster_created{kubespherecluster="member1"} 1.5e+09
+				kube_kubespherecluster_info{connection_type="proxy",kubernetes_version="v1.18.6",kubesphere_version="v3.0.0",kubespherecluster="member1",provider="QingCloud"} 1
+				kube_kubespherecluster_joined_time{kubespherecluster="member1"} 1.5000001e+09
+				kube_kubespherecluster_labels{kubespherecluster="member1",label_cluster_role_kubesphere_io_member=""} 1
+				kube_kubespherecluster_status_condition{condition="Federated",kubespherecluster="member1",status="false"} 0
+				kube_kubespherecluster_status_condition{condition="Federated",kubespherecluster="member1",status="true"} 1
+				kube_kubespherecluster_status_condition{condition="Federated",kubespherecluster="member1",status="unknown"} 0
+				kube_kubespherecluster_status_condition{condition="Ready",kubespherecluster="member1",status="false"} 1
+				kube_kubespherecluster_status_condition{condition="Ready",kubespherecluster="member1",status="true"} 0
+				kube_kubespherecluster_status_condition{condition="Ready",kubespherecluster="member1",status="unknown"} 0
+				kube_kubespherecluster_status_condition_last_update_time{condition="Federated",kubespherecluster="member1"} 1.5000001e+09
+				kube_kubespherecluster_status_condition_last_update_time{condition="Ready",kubespherecluster="member1"} 1.6e+09
+				kube_kubespherecluster_status_nodes{kubespherecluster="member1"} 3
+`,
+		},
+		{
+			Obj: &kubesphere.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "member2",
+				},
+				Status: kubesphere.ClusterStatus{
+					Conditions: []kubesphere.ClusterCondition{
+						{Type: kubesphere.ClusterFederated, Status: v1.ConditionFalse},
+					},
+				},
+			},
+			MetricNames: []string{"kube_kubespherecluster_joined_time", "kube_kubespherecluster_status_condition_last_update_time"},
+			Want: `
+				# HELP kube_kubespherecluster_joined_time [EXPERIMENTAL] Unix timestamp the KubeSphere cluster joined the federation of the host cluster.
+				# TYPE kube_kubespherecluster_joined_time gauge
+				# HELP kube_kubespherecluster_status_condition_last_update_time [EXPERIMENTAL] Unix timestamp the status conditions of the KubeSphere cluster were last updated.
+				# TYPE kube_kubespherecluster_status_condition_last_update_time gauge
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(kubeSphereClusterMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(kubeSphereClusterMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -90,17 +90,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapLimitRangeFunc(func(r *v1.LimitRange) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&r.CreationTimestamp),
 				}
 			}),
 		},
@@ -109,7 +100,10 @@ var (
 
 func wrapLimitRangeFunc(f func(*v1.LimitRange) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		limitRange := obj.(*v1.LimitRange)
+		limitRange, ok := unwrapObject(obj).(*v1.LimitRange)
+		if !ok {
+			return unexpectedObject((*v1.LimitRange)(nil), obj)
+		}
 
 		metricFamily := f(limitRange)
 
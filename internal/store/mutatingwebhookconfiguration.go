@@ -17,7 +17,7 @@ limitations under the License.
 package store
 
 import (
-	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	admissionregistration "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -33,9 +33,10 @@ var (
 
 	mutatingWebhookConfigurationMetricFamilies = []metric.FamilyGenerator{
 		{
-			Name: "kube_mutatingwebhookconfiguration_info",
-			Type: metric.Gauge,
-			Help: "Information about the MutatingWebhookConfiguration.",
+			Name:           "kube_mutatingwebhookconfiguration_info",
+			Type:           metric.Info,
+			Help:           "Information about the MutatingWebhookConfiguration.",
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(mwc *admissionregistration.MutatingWebhookConfiguration) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
@@ -47,26 +48,21 @@ var (
 			}),
 		},
 		{
-			Name: "kube_mutatingwebhookconfiguration_created",
-			Type: metric.Gauge,
-			Help: "Unix creation timestamp.",
+			Name:           "kube_mutatingwebhookconfiguration_created",
+			Type:           metric.Gauge,
+			Help:           "Unix creation timestamp.",
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(mwc *admissionregistration.MutatingWebhookConfiguration) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !mwc.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(mwc.CreationTimestamp.Unix()),
-					})
-				}
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&mwc.CreationTimestamp),
 				}
 			}),
 		},
 		{
-			Name: "kube_mutatingwebhookconfiguration_metadata_resource_version",
-			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of the MutatingWebhookConfiguration.",
+			Name:           "kube_mutatingwebhookconfiguration_metadata_resource_version",
+			Type:           metric.Gauge,
+			Help:           "Resource version representing a specific version of the MutatingWebhookConfiguration.",
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapMutatingWebhookConfigurationFunc(func(mwc *admissionregistration.MutatingWebhookConfiguration) *metric.Family {
 				return &metric.Family{
 					Metrics: resourceVersionMetric(mwc.ObjectMeta.ResourceVersion),
@@ -79,17 +75,20 @@ var (
 func createMutatingWebhookConfigurationListWatch(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			return kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(opts)
+			return kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().List(opts)
 		},
 		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			return kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Watch(opts)
+			return kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Watch(opts)
 		},
 	}
 }
 
 func wrapMutatingWebhookConfigurationFunc(f func(*admissionregistration.MutatingWebhookConfiguration) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		mutatingWebhookConfiguration := obj.(*admissionregistration.MutatingWebhookConfiguration)
+		mutatingWebhookConfiguration, ok := unwrapObject(obj).(*admissionregistration.MutatingWebhookConfiguration)
+		if !ok {
+			return unexpectedObject((*admissionregistration.MutatingWebhookConfiguration)(nil), obj)
+		}
 
 		metricFamily := f(mutatingWebhookConfiguration)
 
@@ -19,7 +19,7 @@ package store
 import (
 	"testing"
 
-	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	admissionregistration "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/kube-state-metrics/pkg/metric"
@@ -39,8 +39,8 @@ func TestMutatingWebhookConfigurationStore(t *testing.T) {
 				},
 			},
 			Want: `
-				# HELP kube_mutatingwebhookconfiguration_info Information about the MutatingWebhookConfiguration.
-				# HELP kube_mutatingwebhookconfiguration_metadata_resource_version Resource version representing a specific version of the MutatingWebhookConfiguration.
+				# HELP kube_mutatingwebhookconfiguration_info [EXPERIMENTAL] Information about the MutatingWebhookConfiguration.
+				# HELP kube_mutatingwebhookconfiguration_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of the MutatingWebhookConfiguration.
 				# TYPE kube_mutatingwebhookconfiguration_info gauge
 				# TYPE kube_mutatingwebhookconfiguration_metadata_resource_version gauge
 				kube_mutatingwebhookconfiguration_info{mutatingwebhookconfiguration="mutatingwebhookconfiguration1",namespace="ns1"} 1
@@ -58,9 +58,9 @@ func TestMutatingWebhookConfigurationStore(t *testing.T) {
 				},
 			},
 			Want: `
-			# HELP kube_mutatingwebhookconfiguration_created Unix creation timestamp.
-			# HELP kube_mutatingwebhookconfiguration_info Information about the MutatingWebhookConfiguration.
-			# HELP kube_mutatingwebhookconfiguration_metadata_resource_version Resource version representing a specific version of the MutatingWebhookConfiguration.
+			# HELP kube_mutatingwebhookconfiguration_created [EXPERIMENTAL] Unix creation timestamp.
+			# HELP kube_mutatingwebhookconfiguration_info [EXPERIMENTAL] Information about the MutatingWebhookConfiguration.
+			# HELP kube_mutatingwebhookconfiguration_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of the MutatingWebhookConfiguration.
 			# TYPE kube_mutatingwebhookconfiguration_created gauge
 			# TYPE kube_mutatingwebhookconfiguration_info gauge
 			# TYPE kube_mutatingwebhookconfiguration_metadata_resource_version gauge
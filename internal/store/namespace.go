@@ -38,15 +38,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
-				ms := []*metric.Metric{}
-				if !n.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(n.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&n.CreationTimestamp),
 				}
 			}),
 		},
@@ -69,7 +62,7 @@ var (
 		},
 		{
 			Name: "kube_namespace_status_phase",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "kubernetes namespace status phase.",
 			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
 				ms := []*metric.Metric{
@@ -93,9 +86,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_namespace_status_condition",
-			Type: metric.Gauge,
-			Help: "The condition of a namespace.",
+			Name:           "kube_namespace_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The condition of a namespace.",
 			GenerateFunc: wrapNamespaceFunc(func(n *v1.Namespace) *metric.Family {
 				ms := make([]*metric.Metric, len(n.Status.Conditions)*len(conditionStatuses))
 				for i, c := range n.Status.Conditions {
@@ -121,7 +115,10 @@ var (
 
 func wrapNamespaceFunc(f func(*v1.Namespace) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		namespace := obj.(*v1.Namespace)
+		namespace, ok := unwrapObject(obj).(*v1.Namespace)
+		if !ok {
+			return unexpectedObject((*v1.Namespace)(nil), obj)
+		}
 
 		metricFamily := f(namespace)
 
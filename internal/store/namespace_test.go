@@ -36,7 +36,7 @@ func TestNamespaceStore(t *testing.T) {
 		# TYPE kube_namespace_labels gauge
 		# HELP kube_namespace_status_phase kubernetes namespace status phase.
 		# TYPE kube_namespace_status_phase gauge
-		# HELP kube_namespace_status_condition The condition of a namespace.
+		# HELP kube_namespace_status_condition [EXPERIMENTAL] The condition of a namespace.
 		# TYPE kube_namespace_status_condition gauge
 	`
 
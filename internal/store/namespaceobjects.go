@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// namespaceObjectCountsCollector is the name of the collector of
+// kube_namespace_object_count. As counting the objects of all other collectors
+// on each scrape is costly, it is not enabled by default.
+const namespaceObjectCountsCollector = "namespaceobjectcounts"
+
+var (
+	descNamespaceObjectsLabelsDefaultLabels = []string{"namespace", "resource"}
+
+	// namespaceObjectsMetricFamilies are not generated by a collector, but by
+	// the Builder from the stores of all collectors.
+	namespaceObjectsMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_namespace_object_count",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of objects of a resource in a namespace.",
+			GenerateFunc: wrapNamespaceObjectsFunc(func(n *namespaceObjects) *metric.Family {
+				ms := make([]*metric.Metric, len(n.resources))
+				for i, r := range n.resources {
+					ms[i] = &metric.Metric{
+						LabelKeys:   descNamespaceObjectsLabelsDefaultLabels,
+						LabelValues: []string{n.Namespace, r.resource},
+						Value:       float64(r.objects),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+// namespaceObjects holds the number of objects of each resource in a
+// namespace of a cluster.
+type namespaceObjects struct {
+	metav1.ObjectMeta
+	resources []resourceObjects
+}
+
+// resourceObjects is the number of objects of a resource.
+type resourceObjects struct {
+	resource string
+	objects  int
+}
+
+func wrapNamespaceObjectsFunc(f func(*namespaceObjects) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		n, ok := unwrapObject(obj).(*namespaceObjects)
+		if !ok {
+			return unexpectedObject((*namespaceObjects)(nil), obj)
+		}
+
+		return f(n)
+	}
+}
+
+// buildNamespaceObjectsStore returns a store counting the objects per
+// namespace in the given stores of the given resources when it is written.
+// Objects of cluster-scoped resources are not counted.
+func (b *Builder) buildNamespaceObjectsStore(resources []string, stores []*metricsstore.MetricsStore) *metricsstore.MetricsStore {
+	familyHeaders, composedMetricGenFuncs := b.composeMetricFamilies(namespaceObjectsMetricFamilies)
+
+	store := metricsstore.NewDerivedMetricsStore(
+		familyHeaders,
+		composedMetricGenFuncs,
+		stores,
+		func() map[string][]interface{} {
+			if len(familyHeaders) == 0 {
+				return nil
+			}
+			return listNamespaceObjects(resources, stores)
+		},
+	)
+	if len(b.clusters) > 0 {
+		store.LabelClusters(options.ClusterLabel)
+	}
+	return store
+}
+
+// listNamespaceObjects returns the number of objects of each of the given
+// resources per namespace by cluster, given the stores of the resources.
+func listNamespaceObjects(resources []string, stores []*metricsstore.MetricsStore) map[string][]interface{} {
+	type namespaceKey struct {
+		cluster   string
+		namespace string
+	}
+
+	namespaces := map[namespaceKey]*namespaceObjects{}
+	lists := map[string][]interface{}{}
+	for i, s := range stores {
+		for _, c := range s.NamespaceCounts() {
+			key := namespaceKey{cluster: c.Cluster, namespace: c.Namespace}
+			n, ok := namespaces[key]
+			if !ok {
+				n = &namespaceObjects{ObjectMeta: metav1.ObjectMeta{
+					Namespace: c.Namespace,
+					UID:       types.UID(c.Cluster + "/" + c.Namespace),
+				}}
+				namespaces[key] = n
+				lists[c.Cluster] = append(lists[c.Cluster], n)
+			}
+			n.resources = append(n.resources, resourceObjects{resource: resources[i], objects: c.Objects})
+		}
+	}
+	return lists
+}
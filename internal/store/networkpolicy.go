@@ -32,25 +32,21 @@ var (
 
 	networkpolicyMetricFamilies = []metric.FamilyGenerator{
 		{
-			Name: "kube_networkpolicy_created",
-			Type: metric.Gauge,
-			Help: "Unix creation timestamp of network policy",
+			Name:           "kube_networkpolicy_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp of network policy",
 			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
 				return &metric.Family{
-					Metrics: []*metric.Metric{
-						{
-							LabelKeys:   []string{},
-							LabelValues: []string{},
-							Value:       float64(n.CreationTimestamp.Unix()),
-						},
-					},
+					Metrics: timestampMetrics(&n.CreationTimestamp),
 				}
 			}),
 		},
 		{
-			Name: "kube_networkpolicy_labels",
-			Type: metric.Gauge,
-			Help: "Kubernetes labels converted to Prometheus labels",
+			Name:           "kube_networkpolicy_labels",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Kubernetes labels converted to Prometheus labels",
 			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
 				labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels)
 				return &metric.Family{
@@ -65,9 +61,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_networkpolicy_spec_ingress_rules",
-			Type: metric.Gauge,
-			Help: "Number of ingress rules on the networkpolicy",
+			Name:           "kube_networkpolicy_spec_ingress_rules",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of ingress rules on the networkpolicy",
 			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
@@ -81,9 +78,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_networkpolicy_spec_egress_rules",
-			Type: metric.Gauge,
-			Help: "Number of egress rules on the networkpolicy",
+			Name:           "kube_networkpolicy_spec_egress_rules",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of egress rules on the networkpolicy",
 			GenerateFunc: wrapNetworkPolicyFunc(func(n *networkingv1.NetworkPolicy) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
@@ -101,7 +99,10 @@ var (
 
 func wrapNetworkPolicyFunc(f func(*networkingv1.NetworkPolicy) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		networkPolicy := obj.(*networkingv1.NetworkPolicy)
+		networkPolicy, ok := unwrapObject(obj).(*networkingv1.NetworkPolicy)
+		if !ok {
+			return unexpectedObject((*networkingv1.NetworkPolicy)(nil), obj)
+		}
 
 		metricFamily := f(networkPolicy)
 
@@ -30,7 +30,7 @@ func TestNetworkPolicyStore(t *testing.T) {
 	metav1StartTime := metav1.Unix(int64(startTime), 0)
 
 	const metadata = `
-		# HELP kube_verticalpodautoscaler_labels Kubernetes labels converted to Prometheus labels.
+		# HELP kube_verticalpodautoscaler_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_verticalpodautoscaler_labels gauge
 		`
 	cases := []generateMetricsTestCase{
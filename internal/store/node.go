@@ -19,7 +19,6 @@ package store
 import (
 	"strings"
 
-	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
 
 	v1 "k8s.io/api/core/v1"
@@ -38,7 +37,7 @@ var (
 	nodeMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_node_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about a cluster node.",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				return &metric.Family{
@@ -73,17 +72,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !n.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(n.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&n.CreationTimestamp),
 				}
 			}),
 		},
@@ -105,9 +95,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_role",
-			Type: metric.Gauge,
-			Help: "The role of a cluster node.",
+			Name:           "kube_node_role",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "The role of a cluster node.",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				const prefix = "node-role.kubernetes.io/"
 				ms := []*metric.Metric{}
@@ -168,7 +159,7 @@ var (
 		// conditions in future.
 		{
 			Name: "kube_node_status_condition",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The condition of a cluster node.",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := make([]*metric.Metric, len(n.Status.Conditions)*len(conditionStatuses))
@@ -194,7 +185,7 @@ var (
 		},
 		{
 			Name: "kube_node_status_phase",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The phase the node is currently in.",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				p := n.Status.Phase
@@ -239,63 +230,11 @@ var (
 
 				capacity := n.Status.Capacity
 				for resourceName, val := range capacity {
-					switch resourceName {
-					case v1.ResourceCPU:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitCore),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
+					if value, unit, ok := resourceQuantityValue(resourceName, val); ok {
 						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitByte),
-							},
-							Value: float64(val.MilliValue()) / 1000,
+							LabelValues: []string{sanitizeLabelName(string(resourceName)), string(unit)},
+							Value:       value,
 						})
-					case v1.ResourcePods:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitInteger),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitInteger),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
 					}
 				}
 
@@ -309,9 +248,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_status_capacity_pods",
-			Type: metric.Gauge,
-			Help: "The total pod resources of the node.",
+			Name:              "kube_node_status_capacity_pods",
+			Type:              metric.Gauge,
+			Help:              "The total pod resources of the node.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -329,9 +269,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_status_capacity_cpu_cores",
-			Type: metric.Gauge,
-			Help: "The total CPU resources of the node.",
+			Name:              "kube_node_status_capacity_cpu_cores",
+			Type:              metric.Gauge,
+			Help:              "The total CPU resources of the node.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -348,9 +289,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_status_capacity_memory_bytes",
-			Type: metric.Gauge,
-			Help: "The total memory resources of the node.",
+			Name:              "kube_node_status_capacity_memory_bytes",
+			Type:              metric.Gauge,
+			Help:              "The total memory resources of the node.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -376,63 +318,11 @@ var (
 				allocatable := n.Status.Allocatable
 
 				for resourceName, val := range allocatable {
-					switch resourceName {
-					case v1.ResourceCPU:
+					if value, unit, ok := resourceQuantityValue(resourceName, val); ok {
 						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitCore),
-							},
-							Value: float64(val.MilliValue()) / 1000,
+							LabelValues: []string{sanitizeLabelName(string(resourceName)), string(unit)},
+							Value:       value,
 						})
-					case v1.ResourceStorage:
-						fallthrough
-					case v1.ResourceEphemeralStorage:
-						fallthrough
-					case v1.ResourceMemory:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitByte),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					case v1.ResourcePods:
-						ms = append(ms, &metric.Metric{
-							LabelValues: []string{
-								sanitizeLabelName(string(resourceName)),
-								string(constant.UnitInteger),
-							},
-							Value: float64(val.MilliValue()) / 1000,
-						})
-					default:
-						if isHugePageResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isAttachableVolumeResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitByte),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
-						if isExtendedResourceName(resourceName) {
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{
-									sanitizeLabelName(string(resourceName)),
-									string(constant.UnitInteger),
-								},
-								Value: float64(val.MilliValue()) / 1000,
-							})
-						}
 					}
 				}
 
@@ -446,9 +336,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_status_allocatable_pods",
-			Type: metric.Gauge,
-			Help: "The pod resources of a node that are available for scheduling.",
+			Name:              "kube_node_status_allocatable_pods",
+			Type:              metric.Gauge,
+			Help:              "The pod resources of a node that are available for scheduling.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -465,9 +356,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_status_allocatable_cpu_cores",
-			Type: metric.Gauge,
-			Help: "The CPU resources of a node that are available for scheduling.",
+			Name:              "kube_node_status_allocatable_cpu_cores",
+			Type:              metric.Gauge,
+			Help:              "The CPU resources of a node that are available for scheduling.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -484,9 +376,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_node_status_allocatable_memory_bytes",
-			Type: metric.Gauge,
-			Help: "The memory resources of a node that are available for scheduling.",
+			Name:              "kube_node_status_allocatable_memory_bytes",
+			Type:              metric.Gauge,
+			Help:              "The memory resources of a node that are available for scheduling.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapNodeFunc(func(n *v1.Node) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -508,7 +401,10 @@ var (
 
 func wrapNodeFunc(f func(*v1.Node) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		node := obj.(*v1.Node)
+		node, ok := unwrapObject(obj).(*v1.Node)
+		if !ok {
+			return unexpectedObject((*v1.Node)(nil), obj)
+		}
 
 		metricFamily := f(node)
 
@@ -121,16 +121,16 @@ func TestNodeStore(t *testing.T) {
 		# HELP kube_node_created Unix creation timestamp
 		# HELP kube_node_info Information about a cluster node.
 		# HELP kube_node_labels Kubernetes labels converted to Prometheus labels.
-		# HELP kube_node_role The role of a cluster node.
+		# HELP kube_node_role [EXPERIMENTAL] The role of a cluster node.
 		# HELP kube_node_spec_unschedulable Whether a node can schedule new pods.
 		# HELP kube_node_status_allocatable The allocatable for different resources of a node that are available for scheduling.
-		# HELP kube_node_status_allocatable_cpu_cores The CPU resources of a node that are available for scheduling.
-		# HELP kube_node_status_allocatable_memory_bytes The memory resources of a node that are available for scheduling.
-		# HELP kube_node_status_allocatable_pods The pod resources of a node that are available for scheduling.
+		# HELP kube_node_status_allocatable_cpu_cores (Deprecated since 1.4.0) The CPU resources of a node that are available for scheduling.
+		# HELP kube_node_status_allocatable_memory_bytes (Deprecated since 1.4.0) The memory resources of a node that are available for scheduling.
+		# HELP kube_node_status_allocatable_pods (Deprecated since 1.4.0) The pod resources of a node that are available for scheduling.
 		# HELP kube_node_status_capacity The capacity for different resources of a node.
-		# HELP kube_node_status_capacity_cpu_cores The total CPU resources of the node.
-		# HELP kube_node_status_capacity_memory_bytes The total memory resources of the node.
-		# HELP kube_node_status_capacity_pods The total pod resources of the node.
+		# HELP kube_node_status_capacity_cpu_cores (Deprecated since 1.4.0) The total CPU resources of the node.
+		# HELP kube_node_status_capacity_memory_bytes (Deprecated since 1.4.0) The total memory resources of the node.
+		# HELP kube_node_status_capacity_pods (Deprecated since 1.4.0) The total pod resources of the node.
 		# TYPE kube_node_created gauge
 		# TYPE kube_node_info gauge
 		# TYPE kube_node_labels gauge
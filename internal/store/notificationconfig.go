@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descNotificationConfigLabelsName          = "kube_notificationconfig_labels"
+	descNotificationConfigLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descNotificationConfigLabelsDefaultLabels = []string{"notificationconfig"}
+
+	notificationConfigMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_notificationconfig_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about notification config.",
+			GenerateFunc: wrapNotificationConfigFunc(func(c *kubesphere.NotificationConfig) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type", "user"},
+							LabelValues: []string{c.Labels[kubesphere.NotificationTypeLabel], c.Labels[kubesphere.NotificationUserLabel]},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_notificationconfig_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapNotificationConfigFunc(func(c *kubesphere.NotificationConfig) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&c.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descNotificationConfigLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descNotificationConfigLabelsHelp,
+			GenerateFunc: wrapNotificationConfigFunc(func(c *kubesphere.NotificationConfig) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(c.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_notificationconfig_channel",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Channel configured by the notification config.",
+			GenerateFunc: wrapNotificationConfigFunc(func(c *kubesphere.NotificationConfig) *metric.Family {
+				channels := notificationChannels(c.Spec)
+				ms := make([]*metric.Metric, len(channels))
+				for i, name := range channels {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"channel"},
+						LabelValues: []string{name},
+						Value:       1,
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_notificationconfig_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The current status conditions of the notification config.",
+			GenerateFunc: wrapNotificationConfigFunc(func(c *kubesphere.NotificationConfig) *metric.Family {
+				return &metric.Family{
+					Metrics: notificationConditionMetrics(c.Status.Conditions),
+				}
+			}),
+		},
+	}
+)
+
+func wrapNotificationConfigFunc(f func(*kubesphere.NotificationConfig) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		config, ok := unwrapObject(obj).(*kubesphere.NotificationConfig)
+		if !ok {
+			return unexpectedObject((*kubesphere.NotificationConfig)(nil), obj)
+		}
+
+		metricFamily := f(config)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descNotificationConfigLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{config.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createNotificationConfigListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.NotificationConfigResource, metav1.NamespaceAll,
+			func() runtime.Object { return &kubesphere.NotificationConfig{} },
+			func() runtime.Object { return &kubesphere.NotificationConfigList{} },
+		)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestNotificationConfigStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_notificationconfig_info [EXPERIMENTAL] Information about notification config.
+		# TYPE kube_notificationconfig_info gauge
+		# HELP kube_notificationconfig_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_notificationconfig_created gauge
+		# HELP kube_notificationconfig_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_notificationconfig_labels gauge
+		# HELP kube_notificationconfig_channel [EXPERIMENTAL] Channel configured by the notification config.
+		# TYPE kube_notificationconfig_channel gauge
+		# HELP kube_notificationconfig_status_condition [EXPERIMENTAL] The current status conditions of the notification config.
+		# TYPE kube_notificationconfig_status_condition gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.NotificationConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default-email-config",
+					Labels: map[string]string{
+						kubesphere.NotificationTypeLabel: "default",
+					},
+				},
+				Spec: map[string]kubesphere.NotificationChannel{
+					"email": {},
+				},
+			},
+			Want: metadata + `
+				kube_notificationconfig_channel{channel="email",notificationconfig="default-email-config"} 1
+				kube_notificationconfig_info{notificationconfig="default-email-config",type="default",user=""} 1
+				kube_notificationconfig_labels{label_type="default",notificationconfig="default-email-config"} 1
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(notificationConfigMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(notificationConfigMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
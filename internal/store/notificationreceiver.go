@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descNotificationReceiverLabelsName          = "kube_notificationreceiver_labels"
+	descNotificationReceiverLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descNotificationReceiverLabelsDefaultLabels = []string{"notificationreceiver"}
+
+	notificationReceiverMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_notificationreceiver_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about notification receiver.",
+			GenerateFunc: wrapNotificationReceiverFunc(func(r *kubesphere.NotificationReceiver) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type", "user"},
+							LabelValues: []string{r.Labels[kubesphere.NotificationTypeLabel], r.Labels[kubesphere.NotificationUserLabel]},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_notificationreceiver_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapNotificationReceiverFunc(func(r *kubesphere.NotificationReceiver) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&r.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descNotificationReceiverLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descNotificationReceiverLabelsHelp,
+			GenerateFunc: wrapNotificationReceiverFunc(func(r *kubesphere.NotificationReceiver) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(r.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_notificationreceiver_channel_enabled",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Whether notifications are sent to the channel of the notification receiver.",
+			GenerateFunc: wrapNotificationReceiverFunc(func(r *kubesphere.NotificationReceiver) *metric.Family {
+				channels := notificationChannels(r.Spec)
+				ms := make([]*metric.Metric, len(channels))
+				for i, name := range channels {
+					enabled := r.Spec[name].Enabled
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"channel"},
+						LabelValues: []string{name},
+						Value:       boolFloat64(enabled == nil || *enabled),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_notificationreceiver_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The current status conditions of the notification receiver.",
+			GenerateFunc: wrapNotificationReceiverFunc(func(r *kubesphere.NotificationReceiver) *metric.Family {
+				return &metric.Family{
+					Metrics: notificationConditionMetrics(r.Status.Conditions),
+				}
+			}),
+		},
+	}
+)
+
+func wrapNotificationReceiverFunc(f func(*kubesphere.NotificationReceiver) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		receiver, ok := unwrapObject(obj).(*kubesphere.NotificationReceiver)
+		if !ok {
+			return unexpectedObject((*kubesphere.NotificationReceiver)(nil), obj)
+		}
+
+		metricFamily := f(receiver)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descNotificationReceiverLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{receiver.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createNotificationReceiverListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.NotificationReceiverResource, metav1.NamespaceAll,
+			func() runtime.Object { return &kubesphere.NotificationReceiver{} },
+			func() runtime.Object { return &kubesphere.NotificationReceiverList{} },
+		)
+	}
+}
+
+// notificationChannels returns the names of the given channels of a receiver
+// or config in order.
+func notificationChannels(channels map[string]kubesphere.NotificationChannel) []string {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// notificationConditionMetrics returns the metrics of the given conditions of
+// a notification or alerting object.
+func notificationConditionMetrics(conditions []kubesphere.NotificationCondition) []*metric.Metric {
+	ms := make([]*metric.Metric, len(conditions)*len(conditionStatuses))
+
+	for i, c := range conditions {
+		conditionMetrics := addConditionMetrics(c.Status)
+
+		for j, m := range conditionMetrics {
+			metric := m
+
+			metric.LabelKeys = []string{"condition", "status"}
+			metric.LabelValues = append([]string{c.Type}, metric.LabelValues...)
+			ms[i*len(conditionStatuses)+j] = metric
+		}
+	}
+
+	return ms
+}
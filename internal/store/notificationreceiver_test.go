@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestNotificationReceiverStore(t *testing.T) {
+	disabled := false
+
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_notificationreceiver_info [EXPERIMENTAL] Information about notification receiver.
+		# TYPE kube_notificationreceiver_info gauge
+		# HELP kube_notificationreceiver_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_notificationreceiver_created gauge
+		# HELP kube_notificationreceiver_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_notificationreceiver_labels gauge
+		# HELP kube_notificationreceiver_channel_enabled [EXPERIMENTAL] Whether notifications are sent to the channel of the notification receiver.
+		# TYPE kube_notificationreceiver_channel_enabled gauge
+		# HELP kube_notificationreceiver_status_condition [EXPERIMENTAL] The current status conditions of the notification receiver.
+		# TYPE kube_notificationreceiver_status_condition gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.NotificationReceiver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "alice-receivers",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						kubesphere.NotificationTypeLabel: "tenant",
+						kubesphere.NotificationUserLabel: "alice",
+					},
+				},
+				Spec: map[string]kubesphere.NotificationChannel{
+					"slack": {},
+					"email": {Enabled: &disabled},
+				},
+				Status: kubesphere.NotificationStatus{
+					Conditions: []kubesphere.NotificationCondition{
+						{Type: "Valid", Status: v1.ConditionFalse, Reason: "ConfigNotFound"},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_notificationreceiver_channel_enabled{channel="email",notificationreceiver="alice-receivers"} 0
+				kube_notificationreceiver_channel_enabled{channel="slack",notificationreceiver="alice-receivers"} 1
+				kube_notificationreceiver_created{notificationreceiver="alice-receivers"} 1.5e+09
+				kube_notificationreceiver_info{notificationreceiver="alice-receivers",type="tenant",user="alice"} 1
+				kube_notificationreceiver_labels{label_type="tenant",label_user="alice",notificationreceiver="alice-receivers"} 1
+				kube_notificationreceiver_status_condition{condition="Valid",notificationreceiver="alice-receivers",status="false"} 1
+				kube_notificationreceiver_status_condition{condition="Valid",notificationreceiver="alice-receivers",status="true"} 0
+				kube_notificationreceiver_status_condition{condition="Valid",notificationreceiver="alice-receivers",status="unknown"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(notificationReceiverMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(notificationReceiverMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
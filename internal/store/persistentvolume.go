@@ -52,7 +52,7 @@ var (
 		},
 		{
 			Name: "kube_persistentvolume_status_phase",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The phase indicates if a volume is available, bound to a claim, or released by a claim.",
 			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
 				phase := p.Status.Phase
@@ -98,7 +98,7 @@ var (
 		},
 		{
 			Name: "kube_persistentvolume_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about persistentvolume.",
 			GenerateFunc: wrapPersistentVolumeFunc(func(p *v1.PersistentVolume) *metric.Family {
 				return &metric.Family{
@@ -132,7 +132,10 @@ var (
 
 func wrapPersistentVolumeFunc(f func(*v1.PersistentVolume) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		persistentVolume := obj.(*v1.PersistentVolume)
+		persistentVolume, ok := unwrapObject(obj).(*v1.PersistentVolume)
+		if !ok {
+			return unexpectedObject((*v1.PersistentVolume)(nil), obj)
+		}
 
 		metricFamily := f(persistentVolume)
 
@@ -52,7 +52,7 @@ var (
 		},
 		{
 			Name: "kube_persistentvolumeclaim_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about persistent volume claim.",
 			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
 				storageClassName := getPersistentVolumeClaimClass(p)
@@ -70,7 +70,7 @@ var (
 		},
 		{
 			Name: "kube_persistentvolumeclaim_status_phase",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The phase the persistent volume claim is currently in.",
 			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
 				phase := p.Status.Phase
@@ -145,9 +145,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_persistentvolumeclaim_status_condition",
-			Help: "Information about status of different conditions of persistent volume claim.",
-			Type: metric.Gauge,
+			Name:           "kube_persistentvolumeclaim_status_condition",
+			Help:           "Information about status of different conditions of persistent volume claim.",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapPersistentVolumeClaimFunc(func(p *v1.PersistentVolumeClaim) *metric.Family {
 				ms := make([]*metric.Metric, len(p.Status.Conditions)*len(conditionStatuses))
 
@@ -174,7 +175,10 @@ var (
 
 func wrapPersistentVolumeClaimFunc(f func(*v1.PersistentVolumeClaim) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		persistentVolumeClaim := obj.(*v1.PersistentVolumeClaim)
+		persistentVolumeClaim, ok := unwrapObject(obj).(*v1.PersistentVolumeClaim)
+		if !ok {
+			return unexpectedObject((*v1.PersistentVolumeClaim)(nil), obj)
+		}
 
 		metricFamily := f(persistentVolumeClaim)
 
@@ -66,7 +66,7 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 				# HELP kube_persistentvolumeclaim_labels Kubernetes labels converted to Prometheus labels.
 				# HELP kube_persistentvolumeclaim_resource_requests_storage_bytes The capacity of storage requested by the persistent volume claim.
 				# HELP kube_persistentvolumeclaim_status_phase The phase the persistent volume claim is currently in.
-				# HELP kube_persistentvolumeclaim_status_condition Information about status of different conditions of persistent volume claim.
+				# HELP kube_persistentvolumeclaim_status_condition [EXPERIMENTAL] Information about status of different conditions of persistent volume claim.
 				# TYPE kube_persistentvolumeclaim_access_mode gauge
 				# TYPE kube_persistentvolumeclaim_info gauge
 				# TYPE kube_persistentvolumeclaim_labels gauge
@@ -115,7 +115,7 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 				# HELP kube_persistentvolumeclaim_labels Kubernetes labels converted to Prometheus labels.
 				# HELP kube_persistentvolumeclaim_resource_requests_storage_bytes The capacity of storage requested by the persistent volume claim.
 				# HELP kube_persistentvolumeclaim_status_phase The phase the persistent volume claim is currently in.
-				# HELP kube_persistentvolumeclaim_status_condition Information about status of different conditions of persistent volume claim.
+				# HELP kube_persistentvolumeclaim_status_condition [EXPERIMENTAL] Information about status of different conditions of persistent volume claim.
 				# TYPE kube_persistentvolumeclaim_access_mode gauge
 				# TYPE kube_persistentvolumeclaim_info gauge
 				# TYPE kube_persistentvolumeclaim_labels gauge
@@ -156,7 +156,7 @@ func TestPersistentVolumeClaimStore(t *testing.T) {
 				# HELP kube_persistentvolumeclaim_labels Kubernetes labels converted to Prometheus labels.
 				# HELP kube_persistentvolumeclaim_resource_requests_storage_bytes The capacity of storage requested by the persistent volume claim.
 				# HELP kube_persistentvolumeclaim_status_phase The phase the persistent volume claim is currently in.
-				# HELP kube_persistentvolumeclaim_status_condition Information about status of different conditions of persistent volume claim.
+				# HELP kube_persistentvolumeclaim_status_condition [EXPERIMENTAL] Information about status of different conditions of persistent volume claim.
 				# TYPE kube_persistentvolumeclaim_access_mode gauge
 				# TYPE kube_persistentvolumeclaim_info gauge
 				# TYPE kube_persistentvolumeclaim_labels gauge
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descPipelineLabelsName          = "kube_pipeline_labels"
+	descPipelineLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descPipelineLabelsDefaultLabels = []string{"namespace", "pipeline"}
+
+	pipelineMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_pipeline_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about pipeline.",
+			GenerateFunc: wrapPipelineFunc(func(p *kubesphere.Pipeline) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"type"},
+							LabelValues: []string{p.Spec.Type},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_pipeline_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapPipelineFunc(func(p *kubesphere.Pipeline) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&p.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descPipelineLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descPipelineLabelsHelp,
+			GenerateFunc: wrapPipelineFunc(func(p *kubesphere.Pipeline) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+	}
+)
+
+func wrapPipelineFunc(f func(*kubesphere.Pipeline) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		pipeline, ok := unwrapObject(obj).(*kubesphere.Pipeline)
+		if !ok {
+			return unexpectedObject((*kubesphere.Pipeline)(nil), obj)
+		}
+
+		metricFamily := f(pipeline)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descPipelineLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{pipeline.Namespace, pipeline.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createPipelineListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.PipelineResource, ns,
+			func() runtime.Object { return &kubesphere.Pipeline{} },
+			func() runtime.Object { return &kubesphere.PipelineList{} },
+		)
+	}
+}
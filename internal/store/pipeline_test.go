@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestPipelineStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_pipeline_info [EXPERIMENTAL] Information about pipeline.
+		# TYPE kube_pipeline_info gauge
+		# HELP kube_pipeline_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_pipeline_created gauge
+		# HELP kube_pipeline_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_pipeline_labels gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "build",
+					Namespace:         "project1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"app": "web",
+					},
+				},
+				Spec: kubesphere.PipelineSpec{
+					Type: "multi-branch-pipeline",
+				},
+			},
+			Want: metadata + `
+				kube_pipeline_created{namespace="project1",pipeline="build"} 1.5e+09
+				kube_pipeline_info{namespace="project1",pipeline="build",type="multi-branch-pipeline"} 1
+				kube_pipeline_labels{label_app="web",namespace="project1",pipeline="build"} 1
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(pipelineMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(pipelineMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
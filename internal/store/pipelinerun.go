@@ -0,0 +1,183 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descPipelineRunLabelsName          = "kube_pipelinerun_labels"
+	descPipelineRunLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descPipelineRunLabelsDefaultLabels = []string{"namespace", "pipelinerun"}
+
+	pipelineRunMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_pipelinerun_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about pipeline run.",
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				pipeline, ref := "", ""
+				if r.Spec.PipelineRef != nil {
+					pipeline = r.Spec.PipelineRef.Name
+				}
+				if r.Spec.SCM != nil {
+					ref = r.Spec.SCM.RefName
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"pipeline", "scm_ref"},
+							LabelValues: []string{pipeline, ref},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_pipelinerun_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&r.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descPipelineRunLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descPipelineRunLabelsHelp,
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(r.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_pipelinerun_status_phase",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The phase of the pipeline run.",
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				phases := []kubesphere.RunPhase{
+					kubesphere.RunPending,
+					kubesphere.RunRunning,
+					kubesphere.RunSucceeded,
+					kubesphere.RunFailed,
+					kubesphere.RunCancelled,
+					kubesphere.RunUnknown,
+				}
+				ms := make([]*metric.Metric, len(phases))
+				for i, p := range phases {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"phase"},
+						LabelValues: []string{string(p)},
+						Value:       boolFloat64(r.Status.Phase == p),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_pipelinerun_status_start_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the pipeline run was started.",
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(r.Status.StartTime),
+				}
+			}),
+		},
+		{
+			Name:           "kube_pipelinerun_status_completion_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the pipeline run was completed.",
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(r.Status.CompletionTime),
+				}
+			}),
+		},
+		{
+			Name:           "kube_pipelinerun_duration_seconds",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Duration of the completed pipeline run in seconds.",
+			GenerateFunc: wrapPipelineRunFunc(func(r *kubesphere.PipelineRun) *metric.Family {
+				ms := []*metric.Metric{}
+				start, completion := r.Status.StartTime, r.Status.CompletionTime
+				if start != nil && !start.IsZero() && completion != nil && !completion.IsZero() {
+					ms = append(ms, &metric.Metric{
+						Value: completion.Sub(start.Time).Seconds(),
+					})
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapPipelineRunFunc(f func(*kubesphere.PipelineRun) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		run, ok := unwrapObject(obj).(*kubesphere.PipelineRun)
+		if !ok {
+			return unexpectedObject((*kubesphere.PipelineRun)(nil), obj)
+		}
+
+		metricFamily := f(run)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descPipelineRunLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{run.Namespace, run.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createPipelineRunListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.PipelineRunResource, ns,
+			func() runtime.Object { return &kubesphere.PipelineRun{} },
+			func() runtime.Object { return &kubesphere.PipelineRunList{} },
+		)
+	}
+}
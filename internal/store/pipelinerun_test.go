@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestPipelineRunStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_pipelinerun_info [EXPERIMENTAL] Information about pipeline run.
+		# TYPE kube_pipelinerun_info gauge
+		# HELP kube_pipelinerun_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_pipelinerun_created gauge
+		# HELP kube_pipelinerun_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_pipelinerun_labels gauge
+		# HELP kube_pipelinerun_status_phase [EXPERIMENTAL] The phase of the pipeline run.
+		# TYPE kube_pipelinerun_status_phase gauge
+		# HELP kube_pipelinerun_status_start_time [EXPERIMENTAL] Unix timestamp the pipeline run was started.
+		# TYPE kube_pipelinerun_status_start_time gauge
+		# HELP kube_pipelinerun_status_completion_time [EXPERIMENTAL] Unix timestamp the pipeline run was completed.
+		# TYPE kube_pipelinerun_status_completion_time gauge
+		# HELP kube_pipelinerun_duration_seconds [EXPERIMENTAL] Duration of the completed pipeline run in seconds.
+		# TYPE kube_pipelinerun_duration_seconds gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "build-abc12",
+					Namespace:         "project1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: kubesphere.PipelineRunSpec{
+					PipelineRef: &v1.ObjectReference{Name: "build"},
+					SCM:         &kubesphere.SCM{RefName: "main"},
+				},
+				Status: kubesphere.PipelineRunStatus{
+					Phase:          kubesphere.RunFailed,
+					StartTime:      &metav1.Time{Time: time.Unix(1500000010, 0)},
+					CompletionTime: &metav1.Time{Time: time.Unix(1500000100, 0)},
+				},
+			},
+			Want: metadata + `
+				kube_pipelinerun_created{namespace="project1",pipelinerun="build-abc12"} 1.5e+09
+				kube_pipelinerun_duration_seconds{namespace="project1",pipelinerun="build-abc12"} 90
+				kube_pipelinerun_info{namespace="project1",pipeline="build",pipelinerun="build-abc12",scm_ref="main"} 1
+				kube_pipelinerun_labels{namespace="project1",pipelinerun="build-abc12"} 1
+				kube_pipelinerun_status_completion_time{namespace="project1",pipelinerun="build-abc12"} 1.5000001e+09
+				kube_pipelinerun_status_phase{namespace="project1",phase="Cancelled",pipelinerun="build-abc12"} 0
+				kube_pipelinerun_status_phase{namespace="project1",phase="Failed",pipelinerun="build-abc12"} 1
+				kube_pipelinerun_status_phase{namespace="project1",phase="Pending",pipelinerun="build-abc12"} 0
+				kube_pipelinerun_status_phase{namespace="project1",phase="Running",pipelinerun="build-abc12"} 0
+				kube_pipelinerun_status_phase{namespace="project1",phase="Succeeded",pipelinerun="build-abc12"} 0
+				kube_pipelinerun_status_phase{namespace="project1",phase="Unknown",pipelinerun="build-abc12"} 0
+				kube_pipelinerun_status_start_time{namespace="project1",pipelinerun="build-abc12"} 1.50000001e+09
+`,
+		},
+		{
+			// Runs in progress have no completion time and duration.
+			Obj: &kubesphere.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "build-def34",
+					Namespace: "project1",
+				},
+				Spec: kubesphere.PipelineRunSpec{
+					PipelineRef: &v1.ObjectReference{Name: "build"},
+				},
+				Status: kubesphere.PipelineRunStatus{
+					Phase:     kubesphere.RunRunning,
+					StartTime: &metav1.Time{Time: time.Unix(1500000010, 0)},
+				},
+			},
+			MetricNames: []string{"kube_pipelinerun_info", "kube_pipelinerun_status_completion_time", "kube_pipelinerun_duration_seconds"},
+			Want: `
+				# HELP kube_pipelinerun_info [EXPERIMENTAL] Information about pipeline run.
+				# TYPE kube_pipelinerun_info gauge
+				# HELP kube_pipelinerun_status_completion_time [EXPERIMENTAL] Unix timestamp the pipeline run was completed.
+				# TYPE kube_pipelinerun_status_completion_time gauge
+				# HELP kube_pipelinerun_duration_seconds [EXPERIMENTAL] Duration of the completed pipeline run in seconds.
+				# TYPE kube_pipelinerun_duration_seconds gauge
+				kube_pipelinerun_info{namespace="project1",pipeline="build",pipelinerun="build-def34",scm_ref=""} 1
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(pipelineRunMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(pipelineRunMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
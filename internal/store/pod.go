@@ -19,11 +19,11 @@ package store
 import (
 	"strconv"
 
-	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
@@ -36,11 +36,25 @@ var (
 	descPodLabelsDefaultLabels = []string{"namespace", "pod"}
 	containerWaitingReasons    = []string{"ContainerCreating", "CrashLoopBackOff", "CreateContainerConfigError", "ErrImagePull", "ImagePullBackOff", "CreateContainerError", "InvalidImageName"}
 	containerTerminatedReasons = []string{"OOMKilled", "Completed", "Error", "ContainerCannotRun", "DeadlineExceeded", "Evicted"}
+)
+
+// podMetricFamilies returns the metric families of pods. The waiting and
+// terminated reasons of containers are reported for the known reasons and any
+// other current reason, or only for the given reasons if the whitelist is not
+// empty, e.g. to bound the number of series. Pods without a phase, e.g. right
+// after their creation, are reported in the Unknown phase if emptyPhaseUnknown
+// is set.
+func podMetricFamilies(reasonWhitelist []string, emptyPhaseUnknown bool) []metric.FamilyGenerator {
+	waitingReasons, terminatedReasons := containerWaitingReasons, containerTerminatedReasons
+	if len(reasonWhitelist) > 0 {
+		waitingReasons, terminatedReasons = reasonWhitelist, reasonWhitelist
+	}
+	includeOtherReasons := len(reasonWhitelist) == 0
 
-	podMetricFamilies = []metric.FamilyGenerator{
+	return []metric.FamilyGenerator{
 		{
 			Name: "kube_pod_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				createdBy := metav1.GetControllerOf(p)
@@ -72,18 +86,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Start time in unix timestamp for a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if p.Status.StartTime != nil {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64((p.Status.StartTime).Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(p.Status.StartTime),
 				}
 			}),
 		},
@@ -94,23 +98,15 @@ var (
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
-				var lastFinishTime float64
+				var lastFinishTime *metav1.Time
 				for _, cs := range p.Status.ContainerStatuses {
 					if cs.State.Terminated != nil {
-						if lastFinishTime == 0 || lastFinishTime < float64(cs.State.Terminated.FinishedAt.Unix()) {
-							lastFinishTime = float64(cs.State.Terminated.FinishedAt.Unix())
+						if lastFinishTime == nil || lastFinishTime.Before(&cs.State.Terminated.FinishedAt) {
+							lastFinishTime = &cs.State.Terminated.FinishedAt
 						}
 					}
 				}
-
-				if lastFinishTime > 0 {
-					ms = append(ms, &metric.Metric{
-
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       lastFinishTime,
-					})
-				}
+				ms = append(ms, timestampMetrics(lastFinishTime)...)
 
 				return &metric.Family{
 					Metrics: ms,
@@ -181,18 +177,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !p.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						LabelKeys:   []string{},
-						LabelValues: []string{},
-						Value:       float64(p.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&p.CreationTimestamp),
 				}
 			}),
 		},
@@ -223,11 +209,7 @@ var (
 					switch c.Type {
 					case v1.PodScheduled:
 						if c.Status == v1.ConditionTrue {
-							ms = append(ms, &metric.Metric{
-								LabelKeys:   []string{},
-								LabelValues: []string{},
-								Value:       float64(c.LastTransitionTime.Unix()),
-							})
+							ms = append(ms, timestampMetrics(&c.LastTransitionTime)...)
 						}
 					}
 				}
@@ -264,14 +246,17 @@ var (
 		},
 		{
 			Name: "kube_pod_status_phase",
-			Type: metric.Gauge,
+			Type: metric.StateSet,
 			Help: "The pods current phase.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				phase := p.Status.Phase
 				if phase == "" {
-					return &metric.Family{
-						Metrics: []*metric.Metric{},
+					if !emptyPhaseUnknown {
+						return &metric.Family{
+							Metrics: []*metric.Metric{},
+						}
 					}
+					phase = v1.PodUnknown
 				}
 
 				phases := []struct {
@@ -283,8 +268,8 @@ var (
 					{phase == v1.PodFailed, string(v1.PodFailed)},
 					// This logic is directly copied from: https://github.com/kubernetes/kubernetes/blob/d39bfa0d138368bbe72b0eaf434501dcb4ec9908/pkg/printers/internalversion/printers.go#L597-L601
 					// For more info, please go to: https://github.com/kubernetes/kube-state-metrics/issues/410
-					{phase == v1.PodRunning && !(p.DeletionTimestamp != nil && p.Status.Reason == nodeUnreachablePodReason), string(v1.PodRunning)},
-					{phase == v1.PodUnknown || (p.DeletionTimestamp != nil && p.Status.Reason == nodeUnreachablePodReason), string(v1.PodUnknown)},
+					{phase == v1.PodRunning && !podOnUnreachableNode(p), string(v1.PodRunning)},
+					{phase == v1.PodUnknown || podOnUnreachableNode(p), string(v1.PodUnknown)},
 				}
 
 				ms := make([]*metric.Metric, len(phases))
@@ -313,7 +298,15 @@ var (
 				for _, c := range p.Status.Conditions {
 					switch c.Type {
 					case v1.PodReady:
-						conditionMetrics := addConditionMetrics(c.Status)
+						status := c.Status
+						// The kubelet of an unreachable node can no longer
+						// update the condition, so report it as unknown to
+						// match kube_pod_status_phase. The PodScheduled
+						// condition stays valid as the binding is unchanged.
+						if podOnUnreachableNode(p) {
+							status = v1.ConditionUnknown
+						}
+						conditionMetrics := addConditionMetrics(status)
 
 						for _, m := range conditionMetrics {
 							metric := m
@@ -355,7 +348,7 @@ var (
 		},
 		{
 			Name: "kube_pod_container_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about a container in a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := make([]*metric.Metric, len(p.Status.ContainerStatuses))
@@ -376,7 +369,7 @@ var (
 		},
 		{
 			Name: "kube_pod_init_container_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about an init container in a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses))
@@ -440,20 +433,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the reason the container is currently in waiting state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.ContainerStatuses)*len(containerWaitingReasons))
-
-				for i, cs := range p.Status.ContainerStatuses {
-					for j, reason := range containerWaitingReasons {
-						ms[i*len(containerWaitingReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(waitingReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerReasonMetrics(p.Status.ContainerStatuses, waitingReasons, includeOtherReasons, waitingReason),
 				}
 			}),
 		},
@@ -462,20 +443,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the reason the init container is currently in waiting state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses)*len(containerWaitingReasons))
-
-				for i, cs := range p.Status.InitContainerStatuses {
-					for j, reason := range containerWaitingReasons {
-						ms[i*len(containerWaitingReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(waitingReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerReasonMetrics(p.Status.InitContainerStatuses, waitingReasons, includeOtherReasons, waitingReason),
 				}
 			}),
 		},
@@ -564,20 +533,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the reason the container is currently in terminated state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.ContainerStatuses)*len(containerTerminatedReasons))
-
-				for i, cs := range p.Status.ContainerStatuses {
-					for j, reason := range containerTerminatedReasons {
-						ms[i*len(containerTerminatedReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(terminationReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerReasonMetrics(p.Status.ContainerStatuses, terminatedReasons, includeOtherReasons, terminationReason),
 				}
 			}),
 		},
@@ -586,20 +543,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the reason the init container is currently in terminated state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses)*len(containerTerminatedReasons))
-
-				for i, cs := range p.Status.InitContainerStatuses {
-					for j, reason := range containerTerminatedReasons {
-						ms[i*len(containerTerminatedReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(terminationReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerReasonMetrics(p.Status.InitContainerStatuses, terminatedReasons, includeOtherReasons, terminationReason),
 				}
 			}),
 		},
@@ -608,20 +553,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the last reason the container was in terminated state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.ContainerStatuses)*len(containerTerminatedReasons))
-
-				for i, cs := range p.Status.ContainerStatuses {
-					for j, reason := range containerTerminatedReasons {
-						ms[i*len(containerTerminatedReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(lastTerminationReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerReasonMetrics(p.Status.ContainerStatuses, terminatedReasons, includeOtherReasons, lastTerminationReason),
 				}
 			}),
 		},
@@ -630,20 +563,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Describes the last reason the init container was in terminated state.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
-				ms := make([]*metric.Metric, len(p.Status.InitContainerStatuses)*len(containerTerminatedReasons))
-
-				for i, cs := range p.Status.InitContainerStatuses {
-					for j, reason := range containerTerminatedReasons {
-						ms[i*len(containerTerminatedReasons)+j] = &metric.Metric{
-							LabelKeys:   []string{"container", "reason"},
-							LabelValues: []string{cs.Name, reason},
-							Value:       boolFloat64(lastTerminationReason(cs, reason)),
-						}
-					}
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: containerReasonMetrics(p.Status.InitContainerStatuses, terminatedReasons, includeOtherReasons, lastTerminationReason),
 				}
 			}),
 		},
@@ -738,40 +659,11 @@ var (
 					req := c.Resources.Requests
 
 					for resourceName, val := range req {
-						switch resourceName {
-						case v1.ResourceCPU:
+						if value, unit, ok := resourceQuantityValue(resourceName, val); ok {
 							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-								Value:       float64(val.MilliValue()) / 1000,
+								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(unit)},
+								Value:       value,
 							})
-						case v1.ResourceStorage:
-							fallthrough
-						case v1.ResourceEphemeralStorage:
-							fallthrough
-						case v1.ResourceMemory:
-							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
-							})
-						default:
-							if isHugePageResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isAttachableVolumeResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isExtendedResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-									Value:       float64(val.Value()),
-								})
-							}
 						}
 					}
 				}
@@ -796,40 +688,11 @@ var (
 					lim := c.Resources.Limits
 
 					for resourceName, val := range lim {
-						switch resourceName {
-						case v1.ResourceCPU:
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.MilliValue()) / 1000,
-								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							})
-						case v1.ResourceStorage:
-							fallthrough
-						case v1.ResourceEphemeralStorage:
-							fallthrough
-						case v1.ResourceMemory:
+						if value, unit, ok := resourceQuantityValue(resourceName, val); ok {
 							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
+								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(unit)},
+								Value:       value,
 							})
-						default:
-							if isHugePageResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isAttachableVolumeResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								})
-							}
-							if isExtendedResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-								})
-							}
 						}
 					}
 				}
@@ -854,40 +717,11 @@ var (
 					lim := c.Resources.Limits
 
 					for resourceName, val := range lim {
-						switch resourceName {
-						case v1.ResourceCPU:
-							ms = append(ms, &metric.Metric{
-								Value:       float64(val.MilliValue()) / 1000,
-								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-							})
-						case v1.ResourceStorage:
-							fallthrough
-						case v1.ResourceEphemeralStorage:
-							fallthrough
-						case v1.ResourceMemory:
+						if value, unit, ok := resourceQuantityValue(resourceName, val); ok {
 							ms = append(ms, &metric.Metric{
-								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								Value:       float64(val.Value()),
+								LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(unit)},
+								Value:       value,
 							})
-						default:
-							if isHugePageResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-									Value:       float64(val.Value()),
-								})
-							}
-							if isAttachableVolumeResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-								})
-							}
-							if isExtendedResourceName(resourceName) {
-								ms = append(ms, &metric.Metric{
-									Value:       float64(val.Value()),
-									LabelValues: []string{c.Name, p.Spec.NodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger)},
-								})
-							}
 						}
 					}
 				}
@@ -902,9 +736,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_pod_container_resource_requests_cpu_cores",
-			Type: metric.Gauge,
-			Help: "The number of requested cpu cores by a container.",
+			Name:              "kube_pod_container_resource_requests_cpu_cores",
+			Type:              metric.Gauge,
+			Help:              "The number of requested cpu cores by a container.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -925,9 +760,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_pod_container_resource_requests_memory_bytes",
-			Type: metric.Gauge,
-			Help: "The number of requested memory bytes by a container.",
+			Name:              "kube_pod_container_resource_requests_memory_bytes",
+			Type:              metric.Gauge,
+			Help:              "The number of requested memory bytes by a container.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -948,9 +784,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_pod_container_resource_limits_cpu_cores",
-			Type: metric.Gauge,
-			Help: "The limit on cpu cores to be used by a container.",
+			Name:              "kube_pod_container_resource_limits_cpu_cores",
+			Type:              metric.Gauge,
+			Help:              "The limit on cpu cores to be used by a container.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -971,9 +808,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_pod_container_resource_limits_memory_bytes",
-			Type: metric.Gauge,
-			Help: "The limit on memory to be used by a container in bytes.",
+			Name:              "kube_pod_container_resource_limits_memory_bytes",
+			Type:              metric.Gauge,
+			Help:              "The limit on memory to be used by a container in bytes.",
+			DeprecatedVersion: "1.4.0",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -996,7 +834,7 @@ var (
 		},
 		{
 			Name: "kube_pod_spec_volumes_persistentvolumeclaims_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about persistentvolumeclaim volumes in a pod.",
 			GenerateFunc: wrapPodFunc(func(p *v1.Pod) *metric.Family {
 				ms := []*metric.Metric{}
@@ -1039,11 +877,20 @@ var (
 			}),
 		},
 	}
-)
+}
+
+// podOnUnreachableNode reports whether the pod is being deleted because its
+// node became unreachable, in which case its last reported status is stale.
+func podOnUnreachableNode(p *v1.Pod) bool {
+	return p.DeletionTimestamp != nil && p.Status.Reason == nodeUnreachablePodReason
+}
 
 func wrapPodFunc(f func(*v1.Pod) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		pod := obj.(*v1.Pod)
+		pod, ok := unwrapObject(obj).(*v1.Pod)
+		if !ok {
+			return unexpectedObject((*v1.Pod)(nil), obj)
+		}
 
 		metricFamily := f(pod)
 
@@ -1056,34 +903,76 @@ func wrapPodFunc(f func(*v1.Pod) *metric.Family) func(interface{}) *metric.Famil
 	}
 }
 
-func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
-	return &cache.ListWatch{
-		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			return kubeClient.CoreV1().Pods(ns).List(opts)
-		},
-		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			return kubeClient.CoreV1().Pods(ns).Watch(opts)
-		},
+// createPodListWatchFunc returns a func creating pod ListWatches. If nodeName
+// is not empty, only pods scheduled to the given node are listed and watched.
+func createPodListWatchFunc(nodeName string) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	fieldSelector := ""
+	if nodeName != "" {
+		fieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.FieldSelector = fieldSelector
+				return kubeClient.CoreV1().Pods(ns).List(opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.FieldSelector = fieldSelector
+				return kubeClient.CoreV1().Pods(ns).Watch(opts)
+			},
+		}
 	}
 }
 
-func waitingReason(cs v1.ContainerStatus, reason string) bool {
+// containerReasonMetrics returns a metric per container and reason, whose
+// value is 1 if the given function returns the reason for the container and 0
+// otherwise. If includeOther is true, the current reason of a container is
+// reported as well if it is not among the given reasons, e.g. new reasons of
+// the kubelet.
+func containerReasonMetrics(statuses []v1.ContainerStatus, reasons []string, includeOther bool, reasonOf func(v1.ContainerStatus) string) []*metric.Metric {
+	ms := make([]*metric.Metric, 0, len(statuses)*len(reasons))
+
+	for _, cs := range statuses {
+		current := reasonOf(cs)
+		known := false
+		for _, reason := range reasons {
+			known = known || reason == current
+			ms = append(ms, &metric.Metric{
+				LabelKeys:   []string{"container", "reason"},
+				LabelValues: []string{cs.Name, reason},
+				Value:       boolFloat64(reason == current),
+			})
+		}
+		if includeOther && !known && current != "" {
+			ms = append(ms, &metric.Metric{
+				LabelKeys:   []string{"container", "reason"},
+				LabelValues: []string{cs.Name, current},
+				Value:       1,
+			})
+		}
+	}
+
+	return ms
+}
+
+func waitingReason(cs v1.ContainerStatus) string {
 	if cs.State.Waiting == nil {
-		return false
+		return ""
 	}
-	return cs.State.Waiting.Reason == reason
+	return cs.State.Waiting.Reason
 }
 
-func terminationReason(cs v1.ContainerStatus, reason string) bool {
+func terminationReason(cs v1.ContainerStatus) string {
 	if cs.State.Terminated == nil {
-		return false
+		return ""
 	}
-	return cs.State.Terminated.Reason == reason
+	return cs.State.Terminated.Reason
 }
 
-func lastTerminationReason(cs v1.ContainerStatus, reason string) bool {
+func lastTerminationReason(cs v1.ContainerStatus) string {
 	if cs.LastTerminationState.Terminated == nil {
-		return false
+		return ""
 	}
-	return cs.LastTerminationState.Terminated.Reason == reason
+	return cs.LastTerminationState.Terminated.Reason
 }
@@ -23,6 +23,10 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 
 	"k8s.io/kube-state-metrics/pkg/metric"
 )
@@ -1064,6 +1068,53 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 `,
 			MetricNames: []string{"kube_pod_status_phase"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pod5",
+					Namespace:         "ns5",
+					DeletionTimestamp: &metav1.Time{},
+				},
+				Status: v1.PodStatus{
+					Phase:  v1.PodRunning,
+					Reason: nodeUnreachablePodReason,
+					Conditions: []v1.PodCondition{
+						{
+							Type:   v1.PodReady,
+							Status: v1.ConditionTrue,
+						},
+						{
+							Type:               v1.PodScheduled,
+							Status:             v1.ConditionTrue,
+							LastTransitionTime: metav1.Time{Time: time.Unix(1501666018, 0)},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_status_phase The pods current phase.
+				# HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
+				# HELP kube_pod_status_scheduled Describes the status of the scheduling process for the pod.
+				# HELP kube_pod_status_scheduled_time Unix timestamp when pod moved into scheduled status
+				# TYPE kube_pod_status_phase gauge
+				# TYPE kube_pod_status_ready gauge
+				# TYPE kube_pod_status_scheduled gauge
+				# TYPE kube_pod_status_scheduled_time gauge
+				kube_pod_status_phase{namespace="ns5",phase="Failed",pod="pod5"} 0
+				kube_pod_status_phase{namespace="ns5",phase="Pending",pod="pod5"} 0
+				kube_pod_status_phase{namespace="ns5",phase="Running",pod="pod5"} 0
+				kube_pod_status_phase{namespace="ns5",phase="Succeeded",pod="pod5"} 0
+				kube_pod_status_phase{namespace="ns5",phase="Unknown",pod="pod5"} 1
+				kube_pod_status_ready{condition="false",namespace="ns5",pod="pod5"} 0
+				kube_pod_status_ready{condition="true",namespace="ns5",pod="pod5"} 0
+				kube_pod_status_ready{condition="unknown",namespace="ns5",pod="pod5"} 1
+				kube_pod_status_scheduled_time{namespace="ns5",pod="pod5"} 1.501666018e+09
+				kube_pod_status_scheduled{condition="false",namespace="ns5",pod="pod5"} 0
+				kube_pod_status_scheduled{condition="true",namespace="ns5",pod="pod5"} 1
+				kube_pod_status_scheduled{condition="unknown",namespace="ns5",pod="pod5"} 0
+`,
+			MetricNames: []string{"kube_pod_status_phase", "kube_pod_status_ready", "kube_pod_status_scheduled"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1142,6 +1193,39 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			`,
 			MetricNames: []string{"kube_pod_status_scheduled", "kube_pod_status_scheduled_time"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns2",
+				},
+				Status: v1.PodStatus{
+					Conditions: []v1.PodCondition{
+						{
+							Type:   v1.PodScheduled,
+							Status: v1.ConditionTrue,
+						},
+					},
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name: "container1",
+							State: v1.ContainerState{
+								Terminated: &v1.ContainerStateTerminated{},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_completion_time Completion time in unix timestamp for a pod.
+				# HELP kube_pod_start_time Start time in unix timestamp for a pod.
+				# HELP kube_pod_status_scheduled_time Unix timestamp when pod moved into scheduled status
+				# TYPE kube_pod_completion_time gauge
+				# TYPE kube_pod_start_time gauge
+				# TYPE kube_pod_status_scheduled_time gauge
+			`,
+			MetricNames: []string{"kube_pod_completion_time", "kube_pod_start_time", "kube_pod_status_scheduled_time"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1259,11 +1343,11 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			},
 			Want: `
 				# HELP kube_pod_container_resource_limits The number of requested limit resource by a container.
-				# HELP kube_pod_container_resource_limits_cpu_cores The limit on cpu cores to be used by a container.
-				# HELP kube_pod_container_resource_limits_memory_bytes The limit on memory to be used by a container in bytes.
+				# HELP kube_pod_container_resource_limits_cpu_cores (Deprecated since 1.4.0) The limit on cpu cores to be used by a container.
+				# HELP kube_pod_container_resource_limits_memory_bytes (Deprecated since 1.4.0) The limit on memory to be used by a container in bytes.
 				# HELP kube_pod_container_resource_requests The number of requested request resource by a container.
-				# HELP kube_pod_container_resource_requests_cpu_cores The number of requested cpu cores by a container.
-				# HELP kube_pod_container_resource_requests_memory_bytes The number of requested memory bytes by a container.
+				# HELP kube_pod_container_resource_requests_cpu_cores (Deprecated since 1.4.0) The number of requested cpu cores by a container.
+				# HELP kube_pod_container_resource_requests_memory_bytes (Deprecated since 1.4.0) The number of requested memory bytes by a container.
 				# HELP kube_pod_init_container_resource_limits The number of requested limit resource by the init container.
 				# HELP kube_pod_init_container_status_last_terminated_reason Describes the last reason the init container was in terminated state.
 				# TYPE kube_pod_container_resource_limits gauge
@@ -1373,11 +1457,11 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 			},
 			Want: `
 				# HELP kube_pod_container_resource_limits The number of requested limit resource by a container.
-				# HELP kube_pod_container_resource_limits_cpu_cores The limit on cpu cores to be used by a container.
-				# HELP kube_pod_container_resource_limits_memory_bytes The limit on memory to be used by a container in bytes.
+				# HELP kube_pod_container_resource_limits_cpu_cores (Deprecated since 1.4.0) The limit on cpu cores to be used by a container.
+				# HELP kube_pod_container_resource_limits_memory_bytes (Deprecated since 1.4.0) The limit on memory to be used by a container in bytes.
 				# HELP kube_pod_container_resource_requests The number of requested request resource by a container.
-				# HELP kube_pod_container_resource_requests_cpu_cores The number of requested cpu cores by a container.
-				# HELP kube_pod_container_resource_requests_memory_bytes The number of requested memory bytes by a container.
+				# HELP kube_pod_container_resource_requests_cpu_cores (Deprecated since 1.4.0) The number of requested cpu cores by a container.
+				# HELP kube_pod_container_resource_requests_memory_bytes (Deprecated since 1.4.0) The number of requested memory bytes by a container.
 				# HELP kube_pod_init_container_resource_limits The number of requested limit resource by the init container.
 				# TYPE kube_pod_container_resource_limits gauge
 				# TYPE kube_pod_container_resource_limits_cpu_cores gauge
@@ -1448,6 +1532,27 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_labels",
 			},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+					Labels: map[string]string{
+						"namespace":  "other-ns",
+						"pod":        "other-pod",
+						"deployment": "web",
+					},
+				},
+			},
+			Want: `
+				# HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
+				# TYPE kube_pod_labels gauge
+				kube_pod_labels{label_deployment="web",label_namespace="other-ns",label_pod="other-pod",namespace="ns1",pod="pod1"} 1
+		`,
+			MetricNames: []string{
+				"kube_pod_labels",
+			},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1507,18 +1612,145 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 	}
 
 	for i, c := range cases {
-		c.Func = metric.ComposeMetricGenFuncs(podMetricFamilies)
-		c.Headers = metric.ExtractMetricFamilyHeaders(podMetricFamilies)
+		c.Func = metric.ComposeMetricGenFuncs(podMetricFamilies(nil, false))
+		c.Headers = metric.ExtractMetricFamilyHeaders(podMetricFamilies(nil, false))
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
 
+func TestPodStoreContainerReasons(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{
+					Name: "container1",
+					State: v1.ContainerState{
+						Waiting: &v1.ContainerStateWaiting{Reason: "RunContainerError"},
+					},
+					LastTerminationState: v1.ContainerState{
+						Terminated: &v1.ContainerStateTerminated{Reason: "StartError"},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		whitelist []string
+		want      string
+	}{
+		{
+			want: `
+				# HELP kube_pod_container_status_last_terminated_reason Describes the last reason the container was in terminated state.
+				# HELP kube_pod_container_status_waiting_reason Describes the reason the container is currently in waiting state.
+				# TYPE kube_pod_container_status_last_terminated_reason gauge
+				# TYPE kube_pod_container_status_waiting_reason gauge
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="Completed"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="ContainerCannotRun"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="DeadlineExceeded"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="Error"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="Evicted"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="OOMKilled"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="StartError"} 1
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="ContainerCreating"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="CrashLoopBackOff"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="CreateContainerConfigError"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="CreateContainerError"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="ErrImagePull"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="ImagePullBackOff"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="InvalidImageName"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="RunContainerError"} 1
+`,
+		},
+		{
+			whitelist: []string{"CrashLoopBackOff", "OOMKilled", "StartError"},
+			want: `
+				# HELP kube_pod_container_status_last_terminated_reason Describes the last reason the container was in terminated state.
+				# HELP kube_pod_container_status_waiting_reason Describes the reason the container is currently in waiting state.
+				# TYPE kube_pod_container_status_last_terminated_reason gauge
+				# TYPE kube_pod_container_status_waiting_reason gauge
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="CrashLoopBackOff"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="OOMKilled"} 0
+				kube_pod_container_status_last_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="StartError"} 1
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="CrashLoopBackOff"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="OOMKilled"} 0
+				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="StartError"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		families := podMetricFamilies(c.whitelist, false)
+		testCase := generateMetricsTestCase{
+			Obj:         pod,
+			Want:        c.want,
+			MetricNames: []string{"kube_pod_container_status_waiting_reason", "kube_pod_container_status_last_terminated_reason"},
+			Func:        metric.ComposeMetricGenFuncs(families),
+			Headers:     metric.ExtractMetricFamilyHeaders(families),
+		}
+		if err := testCase.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
+func TestPodStoreEmptyPhase(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+		},
+	}
+
+	cases := []struct {
+		emptyPhaseUnknown bool
+		want              string
+	}{
+		{
+			want: `
+				# HELP kube_pod_status_phase The pods current phase.
+				# TYPE kube_pod_status_phase gauge
+`,
+		},
+		{
+			emptyPhaseUnknown: true,
+			want: `
+				# HELP kube_pod_status_phase The pods current phase.
+				# TYPE kube_pod_status_phase gauge
+				kube_pod_status_phase{namespace="ns1",phase="Failed",pod="pod1"} 0
+				kube_pod_status_phase{namespace="ns1",phase="Pending",pod="pod1"} 0
+				kube_pod_status_phase{namespace="ns1",phase="Running",pod="pod1"} 0
+				kube_pod_status_phase{namespace="ns1",phase="Succeeded",pod="pod1"} 0
+				kube_pod_status_phase{namespace="ns1",phase="Unknown",pod="pod1"} 1
+`,
+		},
+	}
+
+	for i, c := range cases {
+		families := podMetricFamilies(nil, c.emptyPhaseUnknown)
+		testCase := generateMetricsTestCase{
+			Obj:         pod,
+			Want:        c.want,
+			MetricNames: []string{"kube_pod_status_phase"},
+			Func:        metric.ComposeMetricGenFuncs(families),
+			Headers:     metric.ExtractMetricFamilyHeaders(families),
+		}
+		if err := testCase.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
 func BenchmarkPodStore(b *testing.B) {
 	b.ReportAllocs()
 
-	f := metric.ComposeMetricGenFuncs(podMetricFamilies)
+	f := metric.ComposeMetricGenFuncs(podMetricFamilies(nil, false))
 
 	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1587,3 +1819,40 @@ func BenchmarkPodStore(b *testing.B) {
 		}
 	}
 }
+
+func TestPodListWatchFieldSelector(t *testing.T) {
+	tests := []struct {
+		nodeName              string
+		expectedFieldSelector string
+	}{
+		{nodeName: "", expectedFieldSelector: ""},
+		{nodeName: "node1", expectedFieldSelector: "spec.nodeName=node1"},
+	}
+
+	for _, test := range tests {
+		client := fake.NewSimpleClientset()
+		var fieldSelectors []string
+		client.PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			fieldSelectors = append(fieldSelectors, action.(clienttesting.ListAction).GetListRestrictions().Fields.String())
+			return true, &v1.PodList{}, nil
+		})
+		client.PrependWatchReactor("pods", func(action clienttesting.Action) (bool, watch.Interface, error) {
+			fieldSelectors = append(fieldSelectors, action.(clienttesting.WatchAction).GetWatchRestrictions().Fields.String())
+			return true, watch.NewFake(), nil
+		})
+
+		lw := createPodListWatchFunc(test.nodeName)(client, metav1.NamespaceAll)
+		if _, err := lw.List(metav1.ListOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := lw.Watch(metav1.ListOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, fs := range fieldSelectors {
+			if fs != test.expectedFieldSelector {
+				t.Errorf("expected field selector %q for node %q but got %q", test.expectedFieldSelector, test.nodeName, fs)
+			}
+		}
+	}
+}
@@ -36,16 +36,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapPodDisruptionBudgetFunc(func(p *v1beta1.PodDisruptionBudget) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !p.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(p.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&p.CreationTimestamp),
 				}
 			}),
 		},
@@ -124,7 +116,10 @@ var (
 
 func wrapPodDisruptionBudgetFunc(f func(*v1beta1.PodDisruptionBudget) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		podDisruptionBudget := obj.(*v1beta1.PodDisruptionBudget)
+		podDisruptionBudget, ok := unwrapObject(obj).(*v1beta1.PodDisruptionBudget)
+		if !ok {
+			return unexpectedObject((*v1beta1.PodDisruptionBudget)(nil), obj)
+		}
 
 		metricFamily := f(podDisruptionBudget)
 
@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// sharedReflector describes the reflectors of a resource, which populate the
+// stores of all enabled collectors of the resource, e.g. of a built-in
+// collector and of the external collectors generating metrics of its objects.
+type sharedReflector struct {
+	// resource is the name of the collector of the resource.
+	resource      string
+	expectedType  interface{}
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher
+	stores        []reflectedStore
+}
+
+// reflectedStore is a store populated by a sharedReflector, with the func
+// wrapping the store its reflectors populate, if any.
+type reflectedStore struct {
+	store *metricsstore.MetricsStore
+	wrap  func(store clusterStore, kubeClient clientset.Interface) clusterStore
+}
+
+// store returns the store a reflector of r populates from the given cluster
+// with the given kubeClient, populating all stores of r. cluster is empty
+// unless in multi-cluster mode.
+func (r *sharedReflector) store(cluster string, kubeClient clientset.Interface) clusterStore {
+	stores := make(multiStore, len(r.stores))
+	for i, s := range r.stores {
+		var store clusterStore = s.store
+		if cluster != "" {
+			store = s.store.ClusterStore(cluster)
+		}
+		if s.wrap != nil {
+			store = s.wrap(store, kubeClient)
+		}
+		stores[i] = store
+	}
+	if len(stores) == 1 {
+		return stores[0]
+	}
+	return stores
+}
+
+// multiStore implements clusterStore, populating all of the given stores.
+// Lookups are served by the first store.
+type multiStore []clusterStore
+
+func (m multiStore) each(f func(s clusterStore) error) error {
+	errs := []error{}
+	for _, s := range m {
+		if err := f(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Add adds obj to all stores.
+func (m multiStore) Add(obj interface{}) error {
+	return m.each(func(s clusterStore) error { return s.Add(obj) })
+}
+
+// Update updates obj in all stores.
+func (m multiStore) Update(obj interface{}) error {
+	return m.each(func(s clusterStore) error { return s.Update(obj) })
+}
+
+// Delete deletes obj from all stores.
+func (m multiStore) Delete(obj interface{}) error {
+	return m.each(func(s clusterStore) error { return s.Delete(obj) })
+}
+
+// List implements the List method of the store interface.
+func (m multiStore) List() []interface{} {
+	return m[0].List()
+}
+
+// ListKeys implements the ListKeys method of the store interface.
+func (m multiStore) ListKeys() []string {
+	return m[0].ListKeys()
+}
+
+// Get implements the Get method of the store interface.
+func (m multiStore) Get(obj interface{}) (interface{}, bool, error) {
+	return m[0].Get(obj)
+}
+
+// GetByKey implements the GetByKey method of the store interface.
+func (m multiStore) GetByKey(key string) (interface{}, bool, error) {
+	return m[0].GetByKey(key)
+}
+
+// Replace replaces the contents of all stores with the given list.
+func (m multiStore) Replace(list []interface{}, resourceVersion string) error {
+	return m.each(func(s clusterStore) error { return s.Replace(list, resourceVersion) })
+}
+
+// Resync resyncs all stores.
+func (m multiStore) Resync() error {
+	return m.each(func(s clusterStore) error { return s.Resync() })
+}
+
+// MarkActive marks all stores active.
+func (m multiStore) MarkActive() {
+	for _, s := range m {
+		s.MarkActive()
+	}
+}
+
+// SetDegraded records whether the reflector populating all stores is
+// degraded.
+func (m multiStore) SetDegraded(degraded bool) {
+	for _, s := range m {
+		s.SetDegraded(degraded)
+	}
+}
+
+// queuedStore implements clusterStore, applying the changes to the given store
+// on its own goroutine, so that a store whose metrics are slow to generate,
+// e.g. of an external collector, does not hold up the reflector populating the
+// other stores of the resource. Lookups are served by the given store.
+type queuedStore struct {
+	clusterStore
+
+	mtx sync.Mutex
+	// changes are the changes not applied yet, in order. wake is signaled
+	// once changes are queued.
+	changes []func(s clusterStore) error
+	wake    chan struct{}
+}
+
+// newQueuedStore returns a queuedStore applying the changes to the given
+// store until done is closed.
+func newQueuedStore(store clusterStore, done <-chan struct{}) *queuedStore {
+	q := &queuedStore{clusterStore: store, wake: make(chan struct{}, 1)}
+	go q.run(done)
+	return q
+}
+
+func (q *queuedStore) run(done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-q.wake:
+		}
+
+		q.mtx.Lock()
+		changes := q.changes
+		q.changes = nil
+		q.mtx.Unlock()
+
+		for _, change := range changes {
+			if err := change(q.clusterStore); err != nil {
+				klog.Errorf("failed to apply queued change: %v", err)
+			}
+		}
+	}
+}
+
+// queue queues the given change. A replacement drops all changes queued
+// before, as it supersedes them.
+func (q *queuedStore) queue(change func(s clusterStore) error, replace bool) {
+	q.mtx.Lock()
+	if replace {
+		q.changes = nil
+	}
+	q.changes = append(q.changes, change)
+	q.mtx.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Add queues adding obj to the store.
+func (q *queuedStore) Add(obj interface{}) error {
+	q.queue(func(s clusterStore) error { return s.Add(obj) }, false)
+	return nil
+}
+
+// Update queues updating obj in the store.
+func (q *queuedStore) Update(obj interface{}) error {
+	q.queue(func(s clusterStore) error { return s.Update(obj) }, false)
+	return nil
+}
+
+// Delete queues deleting obj from the store.
+func (q *queuedStore) Delete(obj interface{}) error {
+	q.queue(func(s clusterStore) error { return s.Delete(obj) }, false)
+	return nil
+}
+
+// Replace queues replacing the contents of the store with the given list.
+func (q *queuedStore) Replace(list []interface{}, resourceVersion string) error {
+	q.queue(func(s clusterStore) error { return s.Replace(list, resourceVersion) }, true)
+	return nil
+}
+
+// Resync queues resyncing the store.
+func (q *queuedStore) Resync() error {
+	q.queue(func(s clusterStore) error { return s.Resync() }, false)
+	return nil
+}
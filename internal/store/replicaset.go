@@ -40,17 +40,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapReplicaSetFunc(func(r *v1.ReplicaSet) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&r.CreationTimestamp),
 				}
 			}),
 		},
@@ -207,7 +198,10 @@ var (
 
 func wrapReplicaSetFunc(f func(*v1.ReplicaSet) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		replicaSet := obj.(*v1.ReplicaSet)
+		replicaSet, ok := unwrapObject(obj).(*v1.ReplicaSet)
+		if !ok {
+			return unexpectedObject((*v1.ReplicaSet)(nil), obj)
+		}
 
 		metricFamily := f(replicaSet)
 
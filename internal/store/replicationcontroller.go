@@ -36,16 +36,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapReplicationControllerFunc(func(r *v1.ReplicationController) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&r.CreationTimestamp),
 				}
 			}),
 		},
@@ -156,7 +148,10 @@ var (
 
 func wrapReplicationControllerFunc(f func(*v1.ReplicationController) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		replicationController := obj.(*v1.ReplicationController)
+		replicationController, ok := unwrapObject(obj).(*v1.ReplicationController)
+		if !ok {
+			return unexpectedObject((*v1.ReplicationController)(nil), obj)
+		}
 
 		metricFamily := f(replicationController)
 
@@ -36,17 +36,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapResourceQuotaFunc(func(r *v1.ResourceQuota) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !r.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-
-						Value: float64(r.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&r.CreationTimestamp),
 				}
 			}),
 		},
@@ -84,7 +75,10 @@ var (
 
 func wrapResourceQuotaFunc(f func(*v1.ResourceQuota) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		resourceQuota := obj.(*v1.ResourceQuota)
+		resourceQuota, ok := unwrapObject(obj).(*v1.ResourceQuota)
+		if !ok {
+			return unexpectedObject((*v1.ResourceQuota)(nil), obj)
+		}
 
 		metricFamily := f(resourceQuota)
 
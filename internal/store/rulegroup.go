@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descRuleGroupLabelsName          = "kube_rulegroup_labels"
+	descRuleGroupLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descRuleGroupLabelsDefaultLabels = []string{"namespace", "rulegroup"}
+
+	ruleGroupMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_rulegroup_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapRuleGroupFunc(func(g *kubesphere.RuleGroup) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&g.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descRuleGroupLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descRuleGroupLabelsHelp,
+			GenerateFunc: wrapRuleGroupFunc(func(g *kubesphere.RuleGroup) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(g.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_rulegroup_rules",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of alerting rules of the rule group by state.",
+			GenerateFunc: wrapRuleGroupFunc(func(g *kubesphere.RuleGroup) *metric.Family {
+				disabled := 0
+				for _, r := range g.Spec.Rules {
+					if r.Disable {
+						disabled++
+					}
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"state"},
+							LabelValues: []string{"enabled"},
+							Value:       float64(len(g.Spec.Rules) - disabled),
+						},
+						{
+							LabelKeys:   []string{"state"},
+							LabelValues: []string{"disabled"},
+							Value:       float64(disabled),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_rulegroup_status_condition",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The current status conditions of the rule group.",
+			GenerateFunc: wrapRuleGroupFunc(func(g *kubesphere.RuleGroup) *metric.Family {
+				return &metric.Family{
+					Metrics: notificationConditionMetrics(g.Status.Conditions),
+				}
+			}),
+		},
+	}
+)
+
+func wrapRuleGroupFunc(f func(*kubesphere.RuleGroup) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		group, ok := unwrapObject(obj).(*kubesphere.RuleGroup)
+		if !ok {
+			return unexpectedObject((*kubesphere.RuleGroup)(nil), obj)
+		}
+
+		metricFamily := f(group)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descRuleGroupLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{group.Namespace, group.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createRuleGroupListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.RuleGroupResource, ns,
+			func() runtime.Object { return &kubesphere.RuleGroup{} },
+			func() runtime.Object { return &kubesphere.RuleGroupList{} },
+		)
+	}
+}
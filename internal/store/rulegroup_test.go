@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestRuleGroupStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_rulegroup_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_rulegroup_created gauge
+		# HELP kube_rulegroup_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_rulegroup_labels gauge
+		# HELP kube_rulegroup_rules [EXPERIMENTAL] Number of alerting rules of the rule group by state.
+		# TYPE kube_rulegroup_rules gauge
+		# HELP kube_rulegroup_status_condition [EXPERIMENTAL] The current status conditions of the rule group.
+		# TYPE kube_rulegroup_status_condition gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.RuleGroup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "web-alerts",
+					Namespace:         "project1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: kubesphere.RuleGroupSpec{
+					Rules: []kubesphere.Rule{
+						{Alert: "HighErrorRate"},
+						{Alert: "HighLatency"},
+						{Alert: "PodRestarts", Disable: true},
+					},
+				},
+				Status: kubesphere.NotificationStatus{
+					Conditions: []kubesphere.NotificationCondition{
+						{Type: "Valid", Status: v1.ConditionTrue},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_rulegroup_created{namespace="project1",rulegroup="web-alerts"} 1.5e+09
+				kube_rulegroup_labels{namespace="project1",rulegroup="web-alerts"} 1
+				kube_rulegroup_rules{namespace="project1",rulegroup="web-alerts",state="disabled"} 1
+				kube_rulegroup_rules{namespace="project1",rulegroup="web-alerts",state="enabled"} 2
+				kube_rulegroup_status_condition{condition="Valid",namespace="project1",rulegroup="web-alerts",status="false"} 0
+				kube_rulegroup_status_condition{condition="Valid",namespace="project1",rulegroup="web-alerts",status="true"} 1
+				kube_rulegroup_status_condition{condition="Valid",namespace="project1",rulegroup="web-alerts",status="unknown"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(ruleGroupMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(ruleGroupMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
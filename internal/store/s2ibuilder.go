@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descS2iBuilderLabelsName          = "kube_s2ibuilder_labels"
+	descS2iBuilderLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descS2iBuilderLabelsDefaultLabels = []string{"namespace", "s2ibuilder"}
+
+	s2iBuilderMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_s2ibuilder_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about S2I builder.",
+			GenerateFunc: wrapS2iBuilderFunc(func(b *kubesphere.S2iBuilder) *metric.Family {
+				config := kubesphere.S2iConfig{}
+				if b.Spec.Config != nil {
+					config = *b.Spec.Config
+				}
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"builder_image", "image_name", "tag"},
+							LabelValues: []string{config.BuilderImage, config.ImageName, config.Tag},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2ibuilder_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapS2iBuilderFunc(func(b *kubesphere.S2iBuilder) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&b.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descS2iBuilderLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descS2iBuilderLabelsHelp,
+			GenerateFunc: wrapS2iBuilderFunc(func(b *kubesphere.S2iBuilder) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(b.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2ibuilder_status_runs",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of runs of the S2I builder.",
+			GenerateFunc: wrapS2iBuilderFunc(func(b *kubesphere.S2iBuilder) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(b.Status.RunCount),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2ibuilder_status_last_run_state",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The state of the last run of the S2I builder.",
+			GenerateFunc: wrapS2iBuilderFunc(func(b *kubesphere.S2iBuilder) *metric.Family {
+				if b.Status.LastRunState == "" {
+					return &metric.Family{}
+				}
+				return &metric.Family{
+					Metrics: s2iRunStateMetrics(b.Status.LastRunState),
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2ibuilder_status_last_run_start_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the last run of the S2I builder was started.",
+			GenerateFunc: wrapS2iBuilderFunc(func(b *kubesphere.S2iBuilder) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(b.Status.LastRunStartTime),
+				}
+			}),
+		},
+	}
+)
+
+func wrapS2iBuilderFunc(f func(*kubesphere.S2iBuilder) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		builder, ok := unwrapObject(obj).(*kubesphere.S2iBuilder)
+		if !ok {
+			return unexpectedObject((*kubesphere.S2iBuilder)(nil), obj)
+		}
+
+		metricFamily := f(builder)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descS2iBuilderLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{builder.Namespace, builder.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createS2iBuilderListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.S2iBuilderResource, ns,
+			func() runtime.Object { return &kubesphere.S2iBuilder{} },
+			func() runtime.Object { return &kubesphere.S2iBuilderList{} },
+		)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestS2iBuilderStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_s2ibuilder_info [EXPERIMENTAL] Information about S2I builder.
+		# TYPE kube_s2ibuilder_info gauge
+		# HELP kube_s2ibuilder_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_s2ibuilder_created gauge
+		# HELP kube_s2ibuilder_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_s2ibuilder_labels gauge
+		# HELP kube_s2ibuilder_status_runs [EXPERIMENTAL] Number of runs of the S2I builder.
+		# TYPE kube_s2ibuilder_status_runs gauge
+		# HELP kube_s2ibuilder_status_last_run_state [EXPERIMENTAL] The state of the last run of the S2I builder.
+		# TYPE kube_s2ibuilder_status_last_run_state gauge
+		# HELP kube_s2ibuilder_status_last_run_start_time [EXPERIMENTAL] Unix timestamp the last run of the S2I builder was started.
+		# TYPE kube_s2ibuilder_status_last_run_start_time gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.S2iBuilder{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "web-s2i",
+					Namespace:         "project1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: kubesphere.S2iBuilderSpec{
+					Config: &kubesphere.S2iConfig{
+						BuilderImage: "kubesphere/java-8-centos7:v2.1.0",
+						ImageName:    "harbor.example.com/project1/web",
+						Tag:          "latest",
+					},
+				},
+				Status: kubesphere.S2iBuilderStatus{
+					RunCount:         2,
+					LastRunState:     kubesphere.S2iRunFailed,
+					LastRunStartTime: &metav1.Time{Time: time.Unix(1500000100, 0)},
+				},
+			},
+			Want: metadata + `
+				kube_s2ibuilder_created{namespace="project1",s2ibuilder="web-s2i"} 1.5e+09
+				kube_s2ibuilder_info{builder_image="kubesphere/java-8-centos7:v2.1.0",image_name="harbor.example.com/project1/web",namespace="project1",s2ibuilder="web-s2i",tag="latest"} 1
+				kube_s2ibuilder_labels{namespace="project1",s2ibuilder="web-s2i"} 1
+				kube_s2ibuilder_status_last_run_start_time{namespace="project1",s2ibuilder="web-s2i"} 1.5000001e+09
+				kube_s2ibuilder_status_last_run_state{namespace="project1",s2ibuilder="web-s2i",state="Failed"} 1
+				kube_s2ibuilder_status_last_run_state{namespace="project1",s2ibuilder="web-s2i",state="Running"} 0
+				kube_s2ibuilder_status_last_run_state{namespace="project1",s2ibuilder="web-s2i",state="Successful"} 0
+				kube_s2ibuilder_status_last_run_state{namespace="project1",s2ibuilder="web-s2i",state="Unknown"} 0
+				kube_s2ibuilder_status_runs{namespace="project1",s2ibuilder="web-s2i"} 2
+`,
+		},
+		{
+			Obj: &kubesphere.S2iBuilder{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "new",
+					Namespace: "project1",
+				},
+			},
+			Want: metadata + `
+				kube_s2ibuilder_info{builder_image="",image_name="",namespace="project1",s2ibuilder="new",tag=""} 1
+				kube_s2ibuilder_labels{namespace="project1",s2ibuilder="new"} 1
+				kube_s2ibuilder_status_runs{namespace="project1",s2ibuilder="new"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(s2iBuilderMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(s2iBuilderMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
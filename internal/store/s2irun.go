@@ -0,0 +1,180 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descS2iRunLabelsName          = "kube_s2irun_labels"
+	descS2iRunLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descS2iRunLabelsDefaultLabels = []string{"namespace", "s2irun"}
+
+	s2iRunMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_s2irun_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about S2I run.",
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"s2ibuilder", "tag"},
+							LabelValues: []string{r.Spec.BuilderName, r.Spec.NewTag},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2irun_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&r.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descS2iRunLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descS2iRunLabelsHelp,
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(r.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2irun_status_run_state",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The state of the S2I run.",
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				return &metric.Family{
+					Metrics: s2iRunStateMetrics(r.Status.RunState),
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2irun_status_start_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the S2I run was started.",
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(r.Status.StartTime),
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2irun_status_completion_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp the S2I run was completed.",
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(r.Status.CompletionTime),
+				}
+			}),
+		},
+		{
+			Name:           "kube_s2irun_duration_seconds",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Duration of the completed S2I run in seconds.",
+			GenerateFunc: wrapS2iRunFunc(func(r *kubesphere.S2iRun) *metric.Family {
+				ms := []*metric.Metric{}
+				start, completion := r.Status.StartTime, r.Status.CompletionTime
+				if start != nil && !start.IsZero() && completion != nil && !completion.IsZero() {
+					ms = append(ms, &metric.Metric{
+						Value: completion.Sub(start.Time).Seconds(),
+					})
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+// s2iRunStateMetrics returns a metric for each state of S2I runs, which is 1
+// for the given state.
+func s2iRunStateMetrics(state kubesphere.S2iRunState) []*metric.Metric {
+	states := []kubesphere.S2iRunState{
+		kubesphere.S2iRunRunning,
+		kubesphere.S2iRunSuccessful,
+		kubesphere.S2iRunFailed,
+		kubesphere.S2iRunUnknown,
+	}
+	ms := make([]*metric.Metric, len(states))
+	for i, s := range states {
+		ms[i] = &metric.Metric{
+			LabelKeys:   []string{"state"},
+			LabelValues: []string{string(s)},
+			Value:       boolFloat64(state == s),
+		}
+	}
+	return ms
+}
+
+func wrapS2iRunFunc(f func(*kubesphere.S2iRun) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		run, ok := unwrapObject(obj).(*kubesphere.S2iRun)
+		if !ok {
+			return unexpectedObject((*kubesphere.S2iRun)(nil), obj)
+		}
+
+		metricFamily := f(run)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descS2iRunLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{run.Namespace, run.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createS2iRunListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.S2iRunResource, ns,
+			func() runtime.Object { return &kubesphere.S2iRun{} },
+			func() runtime.Object { return &kubesphere.S2iRunList{} },
+		)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestS2iRunStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_s2irun_info [EXPERIMENTAL] Information about S2I run.
+		# TYPE kube_s2irun_info gauge
+		# HELP kube_s2irun_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_s2irun_created gauge
+		# HELP kube_s2irun_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_s2irun_labels gauge
+		# HELP kube_s2irun_status_run_state [EXPERIMENTAL] The state of the S2I run.
+		# TYPE kube_s2irun_status_run_state gauge
+		# HELP kube_s2irun_status_start_time [EXPERIMENTAL] Unix timestamp the S2I run was started.
+		# TYPE kube_s2irun_status_start_time gauge
+		# HELP kube_s2irun_status_completion_time [EXPERIMENTAL] Unix timestamp the S2I run was completed.
+		# TYPE kube_s2irun_status_completion_time gauge
+		# HELP kube_s2irun_duration_seconds [EXPERIMENTAL] Duration of the completed S2I run in seconds.
+		# TYPE kube_s2irun_duration_seconds gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.S2iRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "web-s2i-abc12",
+					Namespace:         "project1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: kubesphere.S2iRunSpec{
+					BuilderName: "web-s2i",
+					NewTag:      "v1.0.1",
+				},
+				Status: kubesphere.S2iRunStatus{
+					RunState:       kubesphere.S2iRunSuccessful,
+					StartTime:      &metav1.Time{Time: time.Unix(1500000000, 0)},
+					CompletionTime: &metav1.Time{Time: time.Unix(1500000090, 0)},
+				},
+			},
+			Want: metadata + `
+				kube_s2irun_created{namespace="project1",s2irun="web-s2i-abc12"} 1.5e+09
+				kube_s2irun_duration_seconds{namespace="project1",s2irun="web-s2i-abc12"} 90
+				kube_s2irun_info{namespace="project1",s2ibuilder="web-s2i",s2irun="web-s2i-abc12",tag="v1.0.1"} 1
+				kube_s2irun_labels{namespace="project1",s2irun="web-s2i-abc12"} 1
+				kube_s2irun_status_completion_time{namespace="project1",s2irun="web-s2i-abc12"} 1.50000009e+09
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-abc12",state="Failed"} 0
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-abc12",state="Running"} 0
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-abc12",state="Successful"} 1
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-abc12",state="Unknown"} 0
+				kube_s2irun_status_start_time{namespace="project1",s2irun="web-s2i-abc12"} 1.5e+09
+`,
+		},
+		{
+			Obj: &kubesphere.S2iRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "web-s2i-def34",
+					Namespace: "project1",
+				},
+				Spec: kubesphere.S2iRunSpec{
+					BuilderName: "web-s2i",
+				},
+				Status: kubesphere.S2iRunStatus{
+					RunState:  kubesphere.S2iRunRunning,
+					StartTime: &metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+			},
+			MetricNames: []string{"kube_s2irun_status_run_state", "kube_s2irun_duration_seconds"},
+			Want: `
+				# HELP kube_s2irun_status_run_state [EXPERIMENTAL] The state of the S2I run.
+				# TYPE kube_s2irun_status_run_state gauge
+				# HELP kube_s2irun_duration_seconds [EXPERIMENTAL] Duration of the completed S2I run in seconds.
+				# TYPE kube_s2irun_duration_seconds gauge
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-def34",state="Failed"} 0
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-def34",state="Running"} 1
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-def34",state="Successful"} 0
+				kube_s2irun_status_run_state{namespace="project1",s2irun="web-s2i-def34",state="Unknown"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(s2iRunMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(s2iRunMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -35,7 +35,7 @@ var (
 	secretMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_secret_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about secret.",
 			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
 				return &metric.Family{
@@ -86,23 +86,16 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&s.CreationTimestamp),
 				}
 			}),
 		},
 		{
-			Name: "kube_secret_metadata_resource_version",
-			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of secret.",
+			Name:           "kube_secret_metadata_resource_version",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Resource version representing a specific version of secret.",
 			GenerateFunc: wrapSecretFunc(func(s *v1.Secret) *metric.Family {
 				return &metric.Family{
 					Metrics: resourceVersionMetric(s.ObjectMeta.ResourceVersion),
@@ -114,7 +107,10 @@ var (
 
 func wrapSecretFunc(f func(*v1.Secret) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		secret := obj.(*v1.Secret)
+		secret, ok := unwrapObject(obj).(*v1.Secret)
+		if !ok {
+			return unexpectedObject((*v1.Secret)(nil), obj)
+		}
 
 		metricFamily := f(secret)
 
@@ -42,7 +42,7 @@ func TestSecretStore(t *testing.T) {
 				# HELP kube_secret_created Unix creation timestamp
 				# HELP kube_secret_info Information about secret.
 				# HELP kube_secret_labels Kubernetes labels converted to Prometheus labels.
-				# HELP kube_secret_metadata_resource_version Resource version representing a specific version of secret.
+				# HELP kube_secret_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of secret.
 				# HELP kube_secret_type Type about secret.
 				# TYPE kube_secret_created gauge
 				# TYPE kube_secret_info gauge
@@ -70,7 +70,7 @@ func TestSecretStore(t *testing.T) {
 				# HELP kube_secret_created Unix creation timestamp
 				# HELP kube_secret_info Information about secret.
 				# HELP kube_secret_labels Kubernetes labels converted to Prometheus labels.
-				# HELP kube_secret_metadata_resource_version Resource version representing a specific version of secret.
+				# HELP kube_secret_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of secret.
 				# HELP kube_secret_type Type about secret.
 				# TYPE kube_secret_created gauge
 				# TYPE kube_secret_info gauge
@@ -99,7 +99,7 @@ func TestSecretStore(t *testing.T) {
 				# HELP kube_secret_created Unix creation timestamp
 				# HELP kube_secret_info Information about secret.
 				# HELP kube_secret_labels Kubernetes labels converted to Prometheus labels.
-				# HELP kube_secret_metadata_resource_version Resource version representing a specific version of secret.
+				# HELP kube_secret_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of secret.
 				# HELP kube_secret_type Type about secret.
 				# TYPE kube_secret_created gauge
 				# TYPE kube_secret_info gauge
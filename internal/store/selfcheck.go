@@ -0,0 +1,318 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	fuzz "github.com/google/gofuzz"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/util/promlint"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// selfCheckSeeds are the seeds of the synthetic objects generated by
+// SelfCheck, which are fixed for its results to be reproducible.
+var selfCheckSeeds = []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+// SelfCheck generates the metrics of the enabled resources as they would be
+// exposed with the configuration of the Builder for synthetic objects and
+// returns an error listing every violation of the Prometheus exposition
+// format and its conventions. Violations are metric families whose
+// generation panics, malformed metrics, which would be dropped at runtime, and
+// problems reported by promlint.
+func (b *Builder) SelfCheck() error {
+	if b.whiteBlackList == nil {
+		panic("whiteBlackList should not be nil")
+	}
+
+	errs := []error{}
+	describe := b.describe
+	defer func() { b.describe = describe }()
+	for _, c := range b.enabledResources {
+		availableStoresMtx.RLock()
+		constructor, ok := availableStores[c]
+		availableStoresMtx.RUnlock()
+		if !ok {
+			continue
+		}
+
+		b.describe = func(metricFamilies []metric.FamilyGenerator, expectedType interface{}) {
+			for _, err := range b.selfCheck(metricFamilies, expectedType) {
+				errs = append(errs, errors.Wrapf(err, "collector %s", c))
+			}
+		}
+		constructor(b)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// selfCheck returns the violations of the given metric families of a store
+// for objects of expectedType.
+func (b *Builder) selfCheck(metricFamilies []metric.FamilyGenerator, expectedType interface{}) []error {
+	errs := []error{}
+	reported := map[string]bool{}
+	report := func(err error) {
+		if !reported[err.Error()] {
+			reported[err.Error()] = true
+			errs = append(errs, err)
+		}
+	}
+
+	families := b.exposedMetricFamilies(metricFamilies)
+	families = metric.ValidateFamilies(families, func(name string, err error) {
+		report(errors.Wrapf(err, "malformed metric of %s", name))
+	})
+	for i := range families {
+		name, generate := families[i].Name, families[i].GenerateFunc
+		families[i].GenerateFunc = func(obj interface{}) (family *metric.Family) {
+			defer func() {
+				if r := recover(); r != nil {
+					report(errors.Errorf("generating %s panicked: %v", name, r))
+					family = &metric.Family{}
+				}
+			}()
+			return generate(obj)
+		}
+	}
+
+	// The metrics are rendered as by the store exposing them, though always with
+	// HELP and TYPE lines, as promlint requires them.
+	store := metricsstore.NewMetricsStore(
+		metric.ExtractMetricFamilyHeaders(families),
+		metric.ComposeMetricGenFuncs(families),
+	)
+	objects, _, err := syntheticObjects(expectedType)
+	if err != nil {
+		return []error{err}
+	}
+	for _, obj := range objects {
+		if err := store.Add(obj); err != nil {
+			return []error{err}
+		}
+	}
+
+	var buf bytes.Buffer
+	store.WriteAll(&buf)
+	problems, err := promlint.New(&buf).Lint()
+	if err != nil {
+		return append(errs, errors.Wrap(err, "failed to parse metrics"))
+	}
+	for _, p := range problems {
+		report(errors.Errorf("metric %s: %s", p.Metric, p.Text))
+	}
+
+	return errs
+}
+
+// syntheticObjects returns objects of expectedType with all fields set to
+// random values, which are valid as far as the collectors rely on the
+// validation and defaulting of the apiserver, and the random strings set.
+func syntheticObjects(expectedType interface{}) ([]interface{}, map[string]bool, error) {
+	t := reflect.TypeOf(expectedType)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, nil, errors.Errorf("unsupported expected type %T", expectedType)
+	}
+
+	objects := []interface{}{}
+	g := &stringGenerator{r: rand.New(rand.NewSource(0)), random: map[string]bool{}}
+	for i, seed := range selfCheckSeeds {
+		obj := reflect.New(t.Elem()).Interface()
+		fuzz.NewWithSeed(seed).NilChance(0).NumElements(1, 3).Funcs(syntheticFuzzFuncs...).Fuzz(obj)
+		g.setStrings(reflect.ValueOf(obj), "")
+		if j, ok := obj.(*batchv1beta1.CronJob); ok {
+			j.Spec.Schedule = fmt.Sprintf("%d * * * *", seed)
+		}
+
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The objects are stored by their UID, which has to be unique.
+		o.SetUID(types.UID(fmt.Sprintf("synthetic-%d", i)))
+		if wrap, ok := syntheticWrappers[t]; ok {
+			obj = wrap(obj)
+		}
+		objects = append(objects, obj)
+	}
+
+	return objects, g.random, nil
+}
+
+// syntheticFuzzFuncs fill fields of types which the fuzzer cannot fill, as
+// they hold unexported fields or interfaces, or whose valid values are
+// restricted by the apiserver.
+var syntheticFuzzFuncs = []interface{}{
+	func(i *int32, c fuzz.Continue) {
+		*i = c.Int31n(1000)
+	},
+	func(i *int64, c fuzz.Continue) {
+		*i = c.Int63n(1000000)
+	},
+	func(t *metav1.Time, c fuzz.Continue) {
+		*t = metav1.Unix(1500000000+c.Int63n(100000000), 0)
+	},
+	func(t *metav1.MicroTime, c fuzz.Continue) {
+		*t = metav1.NewMicroTime(time.Unix(1500000000+c.Int63n(100000000), 0))
+	},
+	func(q *resource.Quantity, c fuzz.Continue) {
+		*q = *resource.NewMilliQuantity(c.Int63n(1000000), resource.DecimalSI)
+	},
+	func(v *intstr.IntOrString, c fuzz.Continue) {
+		if c.RandBool() {
+			*v = intstr.FromInt(c.Intn(100))
+		} else {
+			*v = intstr.FromString(fmt.Sprintf("%d%%", c.Intn(100)))
+		}
+	},
+	func(e *runtime.RawExtension, c fuzz.Continue) {
+		*e = runtime.RawExtension{}
+	},
+}
+
+// syntheticChars are the characters of random strings of synthetic objects.
+// Unicode and invalid label values are covered by the fuzz tests instead.
+const syntheticChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// syntheticStringLength is the length of random strings of synthetic objects.
+const syntheticStringLength = 8
+
+// syntheticEnums are common values of enumerations of the Kubernetes and
+// KubeSphere APIs by the suffix of the name of their type or field, which
+// strings of synthetic objects are set to every other time, as many metrics
+// are only generated for known values.
+var syntheticEnums = []struct {
+	suffix string
+	values []string
+}{
+	{"JobConditionType", []string{"Complete", "Failed"}},
+	{"PodConditionType", []string{"Ready", "PodScheduled", "ContainersReady", "Initialized"}},
+	{"NodeConditionType", []string{"Ready", "MemoryPressure", "DiskPressure", "PIDPressure", "NetworkUnavailable"}},
+	{"DeploymentConditionType", []string{"Available", "Progressing", "ReplicaFailure"}},
+	{"HorizontalPodAutoscalerConditionType", []string{"AbleToScale", "ScalingActive", "ScalingLimited"}},
+	{"RequestConditionType", []string{"Approved", "Denied"}},
+	{"MetricSourceType", []string{"Object", "Pods", "Resource", "External"}},
+	{"ConditionStatus", []string{"True", "False", "Unknown"}},
+	{"Status", []string{"True", "False", "Unknown"}},
+	{"ServiceType", []string{"ClusterIP", "NodePort", "LoadBalancer", "ExternalName"}},
+	{"Type", []string{"Ready", "Available", "Federated", "Failed"}},
+	{"Kind", []string{"User", "Group", "ServiceAccount", "Deployment", "ReplicaSet", "StatefulSet", "Node"}},
+	{"ResourceVersion", []string{"1", "42", "123456"}},
+	{"Phase", []string{"Pending", "Running", "Succeeded", "Failed", "Unknown", "Cancelled", "Available", "Bound", "Released", "Lost", "Active", "Terminating"}},
+	{"State", []string{"Active", "Disabled", "AuthLimitExceeded", "Running", "Successful", "Failed", "Unknown"}},
+	{"Reason", []string{"ContainerCreating", "CrashLoopBackOff", "ErrImagePull", "ImagePullBackOff", "CreateContainerConfigError", "InvalidImageName", "OOMKilled", "Completed", "Error", "ContainerCannotRun", "DeadlineExceeded", "Evicted", "NodeLost", "UnexpectedAdmissionError", "Unschedulable"}},
+	{"ResourceName", []string{"cpu", "memory", "storage", "ephemeral-storage", "pods", "nvidia.com/gpu", "hugepages-2Mi", "attachable-volumes-aws-ebs"}},
+	{"RestartPolicy", []string{"Always", "OnFailure", "Never"}},
+	{"Policy", []string{"Allow", "Forbid", "Replace"}},
+}
+
+// stringGenerator sets the strings of synthetic objects.
+type stringGenerator struct {
+	r *rand.Rand
+	// random are the random strings set.
+	random map[string]bool
+}
+
+// setStrings sets the strings of v, including the ones of named string types,
+// which the fuzzer cannot be given a function for, to either a random string
+// of syntheticChars or a value of the syntheticEnums of the name of their type
+// or the given name of their field.
+func (g *stringGenerator) setStrings(v reflect.Value, field string) {
+	if v.Type() == reflect.TypeOf(intstr.IntOrString{}) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(g.generate(v.Type().Name(), field))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			g.setStrings(v.Elem(), field)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			g.setStrings(v.Field(i), v.Type().Field(i).Name)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			g.setStrings(v.Index(i), field)
+		}
+	case reflect.Map:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := reflect.New(v.Type().Key()).Elem()
+			key.Set(iter.Key())
+			g.setStrings(key, "")
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			g.setStrings(value, "")
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	}
+}
+
+func (g *stringGenerator) generate(typeName, field string) string {
+	if g.r.Intn(2) == 0 {
+		for _, name := range []string{typeName, field} {
+			for _, e := range syntheticEnums {
+				if strings.HasSuffix(name, e.suffix) {
+					return e.values[g.r.Intn(len(e.values))]
+				}
+			}
+		}
+	}
+
+	b := make([]byte, syntheticStringLength)
+	for i := range b {
+		b[i] = syntheticChars[g.r.Intn(len(syntheticChars))]
+	}
+	g.random[string(b)] = true
+	return string(b)
+}
+
+// syntheticWrappers wrap the synthetic objects of the expected types of stores
+// which are passed objects of another type.
+var syntheticWrappers = map[reflect.Type]func(obj interface{}) interface{}{
+	reflect.TypeOf(&kubesphere.Workspace{}): func(obj interface{}) interface{} {
+		usage := workspaceUsage{namespaces: 2, pods: 10, deployments: 3, storage: 1 << 30}
+		return &workspace{Workspace: obj.(*kubesphere.Workspace), usage: usage}
+	},
+}
@@ -35,7 +35,7 @@ var (
 	serviceMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_service_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about service.",
 			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
 				m := metric.Metric{
@@ -51,15 +51,9 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapSvcFunc(func(s *v1.Service) *metric.Family {
-				if !s.CreationTimestamp.IsZero() {
-					m := metric.Metric{
-						LabelKeys:   nil,
-						LabelValues: nil,
-						Value:       float64(s.CreationTimestamp.Unix()),
-					}
-					return &metric.Family{Metrics: []*metric.Metric{&m}}
+				return &metric.Family{
+					Metrics: timestampMetrics(&s.CreationTimestamp),
 				}
-				return &metric.Family{Metrics: []*metric.Metric{}}
 			}),
 		},
 		{
@@ -148,7 +142,10 @@ var (
 
 func wrapSvcFunc(f func(*v1.Service) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		svc := obj.(*v1.Service)
+		svc, ok := unwrapObject(obj).(*v1.Service)
+		if !ok {
+			return unexpectedObject((*v1.Service)(nil), obj)
+		}
 
 		metricFamily := f(svc)
 
@@ -38,16 +38,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapStatefulSetFunc(func(s *v1.StatefulSet) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
-				}
-
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&s.CreationTimestamp),
 				}
 			}),
 		},
@@ -207,7 +199,10 @@ var (
 
 func wrapStatefulSetFunc(f func(*v1.StatefulSet) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		statefulSet := obj.(*v1.StatefulSet)
+		statefulSet, ok := unwrapObject(obj).(*v1.StatefulSet)
+		if !ok {
+			return unexpectedObject((*v1.StatefulSet)(nil), obj)
+		}
 
 		metricFamily := f(statefulSet)
 
@@ -35,7 +35,7 @@ var (
 	storageClassMetricFamilies = []metric.FamilyGenerator{
 		{
 			Name: "kube_storageclass_info",
-			Type: metric.Gauge,
+			Type: metric.Info,
 			Help: "Information about storageclass.",
 			GenerateFunc: wrapStorageClassFunc(func(s *storagev1.StorageClass) *metric.Family {
 
@@ -61,14 +61,8 @@ var (
 			Type: metric.Gauge,
 			Help: "Unix creation timestamp",
 			GenerateFunc: wrapStorageClassFunc(func(s *storagev1.StorageClass) *metric.Family {
-				ms := []*metric.Metric{}
-				if !s.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(s.CreationTimestamp.Unix()),
-					})
-				}
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&s.CreationTimestamp),
 				}
 			}),
 		},
@@ -94,7 +88,10 @@ var (
 
 func wrapStorageClassFunc(f func(*storagev1.StorageClass) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		storageClass := obj.(*storagev1.StorageClass)
+		storageClass, ok := unwrapObject(obj).(*storagev1.StorageClass)
+		if !ok {
+			return unexpectedObject((*storagev1.StorageClass)(nil), obj)
+		}
 
 		metricFamily := f(storageClass)
 
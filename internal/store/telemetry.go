@@ -0,0 +1,85 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+var descStoreObjects = prometheus.NewDesc(
+	"kube_state_metrics_store_objects",
+	"Number of objects the store of a collector currently holds.",
+	[]string{"resource"}, nil,
+)
+
+var descStoreLastSync = prometheus.NewDesc(
+	"kube_state_metrics_store_last_sync_timestamp_seconds",
+	"Unix timestamp the store of a collector was last populated by a successful list.",
+	[]string{"resource"}, nil,
+)
+
+var descStoreLastActivity = prometheus.NewDesc(
+	"kube_state_metrics_store_last_activity_timestamp_seconds",
+	"Unix timestamp of the last successful list, watch or watch event of the store of a collector.",
+	[]string{"resource"}, nil,
+)
+
+// storeMetrics implements prometheus.Collector, exposing telemetry about the
+// stores last built by a Builder.
+type storeMetrics struct {
+	mtx    sync.RWMutex
+	stores map[string]*metricsstore.MetricsStore
+}
+
+// setStores replaces the stores to expose telemetry about, indexed by the
+// name of their collector.
+func (m *storeMetrics) setStores(stores map[string]*metricsstore.MetricsStore) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.stores = stores
+}
+
+// Describe implements the prometheus.Collector interface.
+func (m *storeMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descStoreObjects
+	ch <- descStoreLastSync
+	ch <- descStoreLastActivity
+}
+
+// Collect implements the prometheus.Collector interface.
+func (m *storeMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	for name, s := range m.stores {
+		ch <- prometheus.MustNewConstMetric(descStoreObjects, prometheus.GaugeValue, float64(s.Len()), name)
+		ch <- prometheus.MustNewConstMetric(descStoreLastActivity, prometheus.GaugeValue, timestampSeconds(s.LastActive()), name)
+		if s.HasSynced() {
+			ch <- prometheus.MustNewConstMetric(descStoreLastSync, prometheus.GaugeValue, timestampSeconds(s.LastSynced()), name)
+		}
+	}
+}
+
+func timestampSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+func TestStoreMetrics(t *testing.T) {
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer { return nil }
+	pods := metricsstore.NewMetricsStore(nil, genFunc)
+	for _, uid := range []string{"a", "b"} {
+		if err := pods.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nodes := metricsstore.NewMetricsStore(nil, genFunc)
+	if err := nodes.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &storeMetrics{}
+	r := prometheus.NewRegistry()
+	r.MustRegister(m)
+	m.setStores(map[string]*metricsstore.MetricsStore{"pods": pods, "nodes": nodes})
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]map[string]float64{}
+	for _, f := range families {
+		got[f.GetName()] = map[string]float64{}
+		for _, m := range f.GetMetric() {
+			got[f.GetName()][m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+		}
+	}
+
+	objects := got["kube_state_metrics_store_objects"]
+	if len(objects) != 2 || objects["pods"] != 2 || objects["nodes"] != 0 {
+		t.Errorf("unexpected kube_state_metrics_store_objects %v", objects)
+	}
+	// Only the nodes store has been populated by a list.
+	lastSync := got["kube_state_metrics_store_last_sync_timestamp_seconds"]
+	if len(lastSync) != 1 || lastSync["nodes"] != float64(nodes.LastSynced().UnixNano())/1e9 {
+		t.Errorf("unexpected kube_state_metrics_store_last_sync_timestamp_seconds %v", lastSync)
+	}
+	lastActivity := got["kube_state_metrics_store_last_activity_timestamp_seconds"]
+	if len(lastActivity) != 2 || lastActivity["pods"] != float64(pods.LastActive().UnixNano())/1e9 {
+		t.Errorf("unexpected kube_state_metrics_store_last_activity_timestamp_seconds %v", lastActivity)
+	}
+}
+
+func TestFilteredMetricFamilies(t *testing.T) {
+	wl, err := whiteblacklist.New(map[string]struct{}{"kube_configmap_info": {}}, map[string]struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := prometheus.NewRegistry()
+	b := NewBuilder()
+	b.WithMetrics(r)
+	b.WithContext(ctx)
+	b.WithKubeClient(fake.NewSimpleClientset())
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithSharding(0, 1)
+	b.WithWhiteBlackList(wl)
+	b.WithStableMetricsOnly(true)
+	b.WithDeprecatedMetrics(true)
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.Build()
+
+	stable := 0
+	for _, f := range configMapMetricFamilies {
+		if f.IsStable() {
+			stable++
+		}
+	}
+	want := map[string]float64{
+		"stable":    float64(len(configMapMetricFamilies) - stable),
+		"whitelist": float64(stable - 1),
+	}
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "kube_state_metrics_filtered_metric_families" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["resource"] == "configmaps" {
+				got[labels["filter"]] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected filtered metric families of configmaps %v, got %v", want, got)
+	}
+}
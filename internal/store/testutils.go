@@ -19,14 +19,10 @@ package store
 // TODO: Does this file need to be renamed to not be compiled in production?
 
 import (
-	"fmt"
-	"regexp"
-	"sort"
-	"strings"
-
 	"github.com/pkg/errors"
 
 	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type generateMetricsTestCase struct {
@@ -38,121 +34,11 @@ type generateMetricsTestCase struct {
 }
 
 func (testCase *generateMetricsTestCase) run() error {
-	metricFamilies := testCase.Func(testCase.Obj)
-	metricFamilyStrings := []string{}
-	for _, f := range metricFamilies {
-		metricFamilyStrings = append(metricFamilyStrings, string(f.ByteSlice()))
-	}
-	metric := strings.Split(strings.Join(metricFamilyStrings, ""), "\n")
-	filteredMetrics := filterMetricNames(metric, testCase.MetricNames)
-	filteredHeaders := filterMetricNames(testCase.Headers, testCase.MetricNames)
-	headers := strings.Join(filteredHeaders, "\n")
-	metrics := strings.Join(filteredMetrics, "\n")
-	out := headers + "\n" + metrics
+	out := testutils.FormatMetrics(testCase.Func(testCase.Obj), testCase.Headers, testCase.MetricNames)
 
-	if err := compareOutput(testCase.Want, out); err != nil {
+	if err := testutils.CompareOutput(testCase.Want, out); err != nil {
 		return errors.Wrap(err, "expected wanted output to equal output")
 	}
 
 	return nil
 }
-
-func compareOutput(expected, actual string) error {
-	entities := []string{expected, actual}
-	// Align wanted and actual
-	for i := 0; i < len(entities); i++ {
-		for _, f := range []func(string) string{removeUnusedWhitespace, sortLabels, sortByLine} {
-			entities[i] = f(entities[i])
-		}
-	}
-
-	if entities[0] != entities[1] {
-		return errors.Errorf("\nEXPECTED:\n--------------\n%v\nACTUAL:\n--------------\n%v", entities[0], entities[1])
-	}
-
-	return nil
-}
-
-// sortLabels sorts the order of labels in each line of the given metric. The
-// Prometheus exposition format does not force ordering of labels. Hence a test
-// should not fail due to different metric orders.
-func sortLabels(s string) string {
-	sorted := []string{}
-
-	for _, line := range strings.Split(s, "\n") {
-		// skipping if its headers
-		if strings.HasPrefix(line, "# ") {
-			sorted = append(sorted, line)
-			continue
-		}
-		split := strings.Split(line, "{")
-		if len(split) != 2 {
-			panic(fmt.Sprintf("failed to sort labels in \"%v\"", line))
-		}
-		name := split[0]
-
-		split = strings.Split(split[1], "}")
-		value := split[1]
-
-		labels := strings.Split(split[0], ",")
-		sort.Strings(labels)
-
-		sorted = append(sorted, fmt.Sprintf("%v{%v}%v", name, strings.Join(labels, ","), value))
-	}
-
-	return strings.Join(sorted, "\n")
-}
-
-func sortByLine(s string) string {
-	split := strings.Split(s, "\n")
-	sort.Strings(split)
-	return strings.Join(split, "\n")
-}
-
-// filterMetricNames removes those metrics and headers that
-// are not part of the names.
-func filterMetricNames(ms []string, names []string) []string {
-	// In case the test case is based on all returned metric, MetricNames does
-	// not need to me defined.
-	if names == nil {
-		return ms
-	}
-	filtered := []string{}
-
-	regexps := []*regexp.Regexp{}
-	for _, n := range names {
-		regexps = append(regexps, regexp.MustCompile(fmt.Sprintf(".*%v.*$", n)))
-	}
-
-	for _, m := range ms {
-		drop := true
-		for _, r := range regexps {
-			if r.MatchString(m) {
-				drop = false
-				break
-			}
-		}
-		if !drop {
-			filtered = append(filtered, m)
-		}
-	}
-	return filtered
-}
-
-func removeUnusedWhitespace(s string) string {
-	var (
-		trimmedLine  string
-		trimmedLines []string
-		lines        = strings.Split(s, "\n")
-	)
-
-	for _, l := range lines {
-		trimmedLine = strings.TrimSpace(l)
-
-		if len(trimmedLine) > 0 {
-			trimmedLines = append(trimmedLines, trimmedLine)
-		}
-	}
-
-	return strings.Join(trimmedLines, "\n")
-}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/tracing"
+)
+
+// relistTrace traces the relists of a reflector, each as a relist span with
+// a child span for the list, or each page of it, and one for replacing the
+// contents of the store with its result, which generates the metrics of all
+// listed objects.
+type relistTrace struct {
+	tracer   *tracing.Tracer
+	resource string
+
+	mtx sync.Mutex
+	// ctx holds span, the relist span in progress, if any.
+	ctx  context.Context
+	span *tracing.Span
+}
+
+// listerWatcher returns lw, tracing its lists.
+func (t *relistTrace) listerWatcher(lw cache.ListerWatcher) cache.ListerWatcher {
+	return &tracedListerWatcher{ListerWatcher: lw, trace: t}
+}
+
+// store returns store, tracing the replacement of its contents.
+func (t *relistTrace) store(store clusterStore) clusterStore {
+	return &tracedStore{clusterStore: store, trace: t}
+}
+
+// start returns the context of the relist span in progress, starting a new
+// one if there is none.
+func (t *relistTrace) start() context.Context {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.span == nil {
+		t.ctx, t.span = t.tracer.Start(context.Background(), "relist", tracing.String("resource", t.resource))
+	}
+	return t.ctx
+}
+
+// end ends the relist span in progress, if any, as failed with err, unless
+// err is nil.
+func (t *relistTrace) end(err error, attributes ...tracing.Attribute) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.span.SetAttributes(attributes...)
+	t.span.RecordError(err)
+	t.span.End()
+	t.ctx, t.span = nil, nil
+}
+
+type tracedListerWatcher struct {
+	cache.ListerWatcher
+	trace *relistTrace
+}
+
+// List lists the objects as part of the relist span in progress. A failed
+// list ends the relist span, as the reflector starts over.
+func (lw *tracedListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	_, span := lw.trace.tracer.Start(lw.trace.start(), "list")
+	res, err := lw.ListerWatcher.List(options)
+	span.RecordError(err)
+	if err == nil {
+		span.SetAttributes(tracing.Int("objects", meta.LenList(res)))
+	}
+	span.End()
+
+	if err != nil {
+		lw.trace.end(err)
+	}
+	return res, err
+}
+
+type tracedStore struct {
+	clusterStore
+	trace *relistTrace
+}
+
+// Replace replaces the contents of the store, ending the relist span in
+// progress.
+func (s *tracedStore) Replace(list []interface{}, resourceVersion string) error {
+	_, span := s.trace.tracer.Start(s.trace.start(), "replace", tracing.Int("objects", len(list)))
+	err := s.clusterStore.Replace(list, resourceVersion)
+	span.RecordError(err)
+	span.End()
+
+	s.trace.end(err, tracing.Int("objects", len(list)))
+	return err
+}
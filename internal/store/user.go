@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descUserLabelsName          = "kube_user_labels"
+	descUserLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descUserLabelsDefaultLabels = []string{"user"}
+
+	userMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_user_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapUserFunc(func(u *kubesphere.User) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&u.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descUserLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descUserLabelsHelp,
+			GenerateFunc: wrapUserFunc(func(u *kubesphere.User) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(u.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_user_status_state",
+			Type:           metric.StateSet,
+			StabilityLevel: metric.Experimental,
+			Help:           "The state of the user.",
+			GenerateFunc: wrapUserFunc(func(u *kubesphere.User) *metric.Family {
+				states := []kubesphere.UserState{
+					kubesphere.UserActive,
+					kubesphere.UserDisabled,
+					kubesphere.UserAuthLimitExceeded,
+				}
+				ms := make([]*metric.Metric, len(states))
+				for i, s := range states {
+					ms[i] = &metric.Metric{
+						LabelKeys:   []string{"state"},
+						LabelValues: []string{string(s)},
+						Value:       boolFloat64(u.Status.State == s),
+					}
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+		{
+			Name:           "kube_user_status_last_login_time",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix timestamp of the last login of the user.",
+			GenerateFunc: wrapUserFunc(func(u *kubesphere.User) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(u.Status.LastLoginTime),
+				}
+			}),
+		},
+	}
+)
+
+func wrapUserFunc(f func(*kubesphere.User) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		user, ok := unwrapObject(obj).(*kubesphere.User)
+		if !ok {
+			return unexpectedObject((*kubesphere.User)(nil), obj)
+		}
+
+		metricFamily := f(user)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descUserLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{user.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createUserListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.UserResource, metav1.NamespaceAll,
+			func() runtime.Object { return &kubesphere.User{} },
+			func() runtime.Object { return &kubesphere.UserList{} },
+		)
+	}
+}
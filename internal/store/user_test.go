@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestUserStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_user_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_user_created gauge
+		# HELP kube_user_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_user_labels gauge
+		# HELP kube_user_status_state [EXPERIMENTAL] The state of the user.
+		# TYPE kube_user_status_state gauge
+		# HELP kube_user_status_last_login_time [EXPERIMENTAL] Unix timestamp of the last login of the user.
+		# TYPE kube_user_status_last_login_time gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "alice",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"iam.kubesphere.io/identity-provider": "ldap",
+					},
+				},
+				Status: kubesphere.UserStatus{
+					State:         kubesphere.UserActive,
+					LastLoginTime: &metav1.Time{Time: time.Unix(1600000000, 0)},
+				},
+			},
+			Want: metadata + `
+				kube_user_created{user="alice"} 1.5e+09
+				kube_user_labels{label_iam_kubesphere_io_identity_provider="ldap",user="alice"} 1
+				kube_user_status_last_login_time{user="alice"} 1.6e+09
+				kube_user_status_state{state="Active",user="alice"} 1
+				kube_user_status_state{state="AuthLimitExceeded",user="alice"} 0
+				kube_user_status_state{state="Disabled",user="alice"} 0
+`,
+		},
+		{
+			// Users who never logged in and whose state is not set yet.
+			Obj: &kubesphere.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "bob",
+				},
+			},
+			Want: metadata + `
+				kube_user_labels{user="bob"} 1
+				kube_user_status_state{state="Active",user="bob"} 0
+				kube_user_status_state{state="AuthLimitExceeded",user="bob"} 0
+				kube_user_status_state{state="Disabled",user="bob"} 0
+`,
+		},
+		{
+			Obj: &kubesphere.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mallory",
+				},
+				Status: kubesphere.UserStatus{
+					State: kubesphere.UserAuthLimitExceeded,
+				},
+			},
+			MetricNames: []string{"kube_user_status_state"},
+			Want: `
+				# HELP kube_user_status_state [EXPERIMENTAL] The state of the user.
+				# TYPE kube_user_status_state gauge
+				kube_user_status_state{state="Active",user="mallory"} 0
+				kube_user_status_state{state="AuthLimitExceeded",user="mallory"} 1
+				kube_user_status_state{state="Disabled",user="mallory"} 0
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(userMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(userMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
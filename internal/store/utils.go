@@ -18,23 +18,72 @@ package store
 
 import (
 	"fmt"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
 
 	v1 "k8s.io/api/core/v1"
 
+	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
 )
 
 var (
-	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
-	conditionStatuses  = []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}
+	conditionStatuses = []v1.ConditionStatus{v1.ConditionTrue, v1.ConditionFalse, v1.ConditionUnknown}
+
+	unexpectedObjects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_unexpected_objects_total",
+			Help: "Total number of metric families of a resource not generated because of an object of unexpected type.",
+		},
+		[]string{"resource"},
+	)
 )
 
+// unwrapObject returns the last known state of obj if it is a tombstone of a
+// deleted object, otherwise obj itself.
+func unwrapObject(obj interface{}) interface{} {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
+
+// unexpectedObject logs and counts obj passed to the wrap function of a
+// collector expecting objects of the type of expected, and returns an empty
+// family instead of panicking.
+func unexpectedObject(expected, obj interface{}) *metric.Family {
+	resource := fmt.Sprintf("%T", expected)
+	klog.Errorf("Unexpected object of type %T in store of %s", obj, resource)
+	unexpectedObjects.WithLabelValues(resource).Inc()
+	return &metric.Family{
+		Metrics: []*metric.Metric{},
+	}
+}
+
+// timestampMetrics returns a metric with t as Unix timestamp, or none if t is
+// unset. Unset times would otherwise be exposed as 0, or as a large negative
+// number for the zero time.Time.
+func timestampMetrics(t *metav1.Time) []*metric.Metric {
+	if t.IsZero() {
+		return []*metric.Metric{}
+	}
+	return []*metric.Metric{
+		{
+			Value: float64(t.Unix()),
+		},
+	}
+}
+
 func resourceVersionMetric(rv string) []*metric.Metric {
 	v, err := strconv.ParseFloat(rv, 64)
 	if err != nil {
@@ -76,6 +125,10 @@ func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string)
 	return mapToPrometheusLabels(labels, "label")
 }
 
+// mapToPrometheusLabels returns the sanitized names of the given labels with
+// the given prefix, sorted by their keys, and their values. The names are
+// always prefixed, so that they never shadow the default labels identifying
+// an object, e.g. namespace or pod.
 func mapToPrometheusLabels(labels map[string]string, prefix string) ([]string, []string) {
 	labelKeys := make([]string, 0, len(labels))
 	for k := range labels {
@@ -83,16 +136,142 @@ func mapToPrometheusLabels(labels map[string]string, prefix string) ([]string, [
 	}
 	sort.Strings(labelKeys)
 
-	labelValues := make([]string, 0, len(labels))
+	labelValues := make([]string, len(labelKeys))
 	for i, k := range labelKeys {
-		labelKeys[i] = prefix + "_" + sanitizeLabelName(k)
-		labelValues = append(labelValues, labels[k])
+		labelValues[i] = labels[k]
+		labelKeys[i] = labelNames.get(prefix, k)
 	}
+	resolveLabelNameConflicts(labelKeys)
 	return labelKeys, labelValues
 }
 
+// resolveLabelNameConflicts renames all label names that occur more than once,
+// e.g. because app.kubernetes.io/name and app_kubernetes_io_name are sanitized
+// to the same name, by appending metric.LabelConflictSuffix and a number counting
+// from 1. Given label names sorted by their original keys, the resulting names
+// are deterministic.
+func resolveLabelNameConflicts(names []string) {
+	conflicts := false
+	for i := 0; i < len(names) && !conflicts; i++ {
+		for j := i + 1; j < len(names); j++ {
+			if names[i] == names[j] {
+				conflicts = true
+				break
+			}
+		}
+	}
+	if !conflicts {
+		return
+	}
+
+	counts := make(map[string]int, len(names))
+	for _, name := range names {
+		counts[name]++
+	}
+	numbers := map[string]int{}
+	for i, name := range names {
+		if counts[name] < 2 {
+			continue
+		}
+		for {
+			numbers[name]++
+			renamed := name + metric.LabelConflictSuffix + strconv.Itoa(numbers[name])
+			// The renamed label must not collide with any other label.
+			if _, ok := counts[renamed]; !ok {
+				counts[renamed] = 1
+				names[i] = renamed
+				break
+			}
+		}
+	}
+}
+
+// maxCachedLabelNames bounds the number of label names cached per prefix, as
+// label keys are user controlled.
+const maxCachedLabelNames = 10000
+
+// labelNames caches the Prometheus label names of Kubernetes label,
+// annotation, ... keys. The same keys are converted over and over again for
+// every object and every update of an object.
+var labelNames = &labelNameCache{names: map[string]map[string]string{}}
+
+type labelNameCache struct {
+	mtx sync.RWMutex
+	// names maps prefixes to keys to prefixed, sanitized label names.
+	names map[string]map[string]string
+}
+
+// get returns the sanitized label name of key, prefixed with prefix.
+func (c *labelNameCache) get(prefix, key string) string {
+	c.mtx.RLock()
+	name, ok := c.names[prefix][key]
+	c.mtx.RUnlock()
+	if ok {
+		return name
+	}
+
+	name = prefix + "_" + sanitizeLabelName(key)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	names, ok := c.names[prefix]
+	if !ok {
+		names = map[string]string{}
+		c.names[prefix] = names
+	}
+	if len(names) < maxCachedLabelNames {
+		names[key] = name
+	}
+	return name
+}
+
+// sanitizeLabelName replaces all characters not allowed in Prometheus label
+// names with underscores. It does not allocate if s is a valid label name
+// already.
 func sanitizeLabelName(s string) string {
-	return invalidLabelCharRE.ReplaceAllString(s, "_")
+	valid := true
+	for i := 0; i < len(s); i++ {
+		if !isLabelNameChar(s[i]) {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r < utf8.RuneSelf && isLabelNameChar(byte(r)) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func isLabelNameChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_'
+}
+
+// resourceQuantityValue converts a quantity of the named resource to its base
+// unit: cpu in cores, memory, storage, hugepages and attachable volumes in
+// bytes, and pods and extended resources as plain integers. Milli-values are
+// only kept for cpu, everything else is rounded up to a whole number as the
+// API server does. ok is false for resources without a known unit.
+func resourceQuantityValue(name v1.ResourceName, q resource.Quantity) (value float64, unit constant.ResourceUnit, ok bool) {
+	switch {
+	case name == v1.ResourceCPU:
+		return float64(q.MilliValue()) / 1000, constant.UnitCore, true
+	case name == v1.ResourceMemory, name == v1.ResourceStorage, name == v1.ResourceEphemeralStorage,
+		isHugePageResourceName(name), isAttachableVolumeResourceName(name):
+		return float64(q.Value()), constant.UnitByte, true
+	case name == v1.ResourcePods, isExtendedResourceName(name):
+		return float64(q.Value()), constant.UnitInteger, true
+	}
+	return 0, "", false
 }
 
 func isHugePageResourceName(name v1.ResourceName) bool {
@@ -18,9 +18,20 @@ package store
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
 	"testing"
+	"time"
 
+	dto "github.com/prometheus/client_model/go"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/constant"
+	"k8s.io/kube-state-metrics/pkg/metric"
 )
 
 func TestIsHugePageSizeFromResourceName(t *testing.T) {
@@ -126,6 +137,98 @@ func TestIsExtendedResourceName(t *testing.T) {
 	}
 }
 
+func TestResourceQuantityValue(t *testing.T) {
+	testCases := []struct {
+		resourceName v1.ResourceName
+		quantity     string
+		expectVal    float64
+		expectUnit   constant.ResourceUnit
+		expectOK     bool
+	}{
+		{v1.ResourceCPU, "500m", 0.5, constant.UnitCore, true},
+		{v1.ResourceCPU, "2", 2, constant.UnitCore, true},
+		{v1.ResourceCPU, "1.25", 1.25, constant.UnitCore, true},
+		{v1.ResourceMemory, "1536Mi", 1610612736, constant.UnitByte, true},
+		{v1.ResourceMemory, "1.5Gi", 1610612736, constant.UnitByte, true},
+		{v1.ResourceMemory, "2G", 2000000000, constant.UnitByte, true},
+		{v1.ResourceMemory, "1500m", 2, constant.UnitByte, true},
+		{v1.ResourceEphemeralStorage, "10Pi", 11258999068426240, constant.UnitByte, true},
+		{v1.ResourceStorage, "1Ti", 1099511627776, constant.UnitByte, true},
+		{"hugepages-2Mi", "4Mi", 4194304, constant.UnitByte, true},
+		{"attachable-volumes-aws-ebs", "39", 39, constant.UnitByte, true},
+		{v1.ResourcePods, "110", 110, constant.UnitInteger, true},
+		{"nvidia.com/gpu", "4", 4, constant.UnitInteger, true},
+		{"requests.cpu", "1", 0, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s=%s", tc.resourceName, tc.quantity), func(t *testing.T) {
+			v, unit, ok := resourceQuantityValue(tc.resourceName, resource.MustParse(tc.quantity))
+			if v != tc.expectVal || unit != tc.expectUnit || ok != tc.expectOK {
+				t.Errorf("Got (%v, %q, %v) but expected (%v, %q, %v)", v, unit, ok, tc.expectVal, tc.expectUnit, tc.expectOK)
+			}
+		})
+	}
+}
+
+func TestTimestampMetrics(t *testing.T) {
+	testCases := []struct {
+		time       *metav1.Time
+		expectVals []float64
+	}{
+		{nil, []float64{}},
+		{&metav1.Time{}, []float64{}},
+		{&metav1.Time{Time: time.Unix(0, 0)}, []float64{0}},
+		{&metav1.Time{Time: time.Unix(1501569018, 0)}, []float64{1501569018}},
+	}
+
+	for _, tc := range testCases {
+		ms := timestampMetrics(tc.time)
+		vals := make([]float64, len(ms))
+		for i, m := range ms {
+			vals[i] = m.Value
+		}
+		if !reflect.DeepEqual(vals, tc.expectVals) {
+			t.Errorf("Got %v for %v but expected %v", vals, tc.time, tc.expectVals)
+		}
+	}
+}
+
+func TestWrapUnexpectedObject(t *testing.T) {
+	generate := wrapPodFunc(func(p *v1.Pod) *metric.Family {
+		return &metric.Family{
+			Metrics: []*metric.Metric{{Value: 1}},
+		}
+	})
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1"}}
+
+	if f := generate(pod); len(f.Metrics) != 1 {
+		t.Errorf("Expected 1 metric for pod but got %d", len(f.Metrics))
+	}
+	if f := generate(cache.DeletedFinalStateUnknown{Key: "ns1/pod1", Obj: pod}); len(f.Metrics) != 1 {
+		t.Errorf("Expected 1 metric for tombstone of pod but got %d", len(f.Metrics))
+	}
+
+	counter := unexpectedObjects.WithLabelValues("*v1.Pod")
+	before := &dto.Metric{}
+	if err := counter.Write(before); err != nil {
+		t.Fatal(err)
+	}
+	if f := generate(&v1.Node{}); len(f.Metrics) != 0 {
+		t.Errorf("Expected no metrics for node but got %d", len(f.Metrics))
+	}
+	if f := generate(cache.DeletedFinalStateUnknown{Key: "ns1/pod1"}); len(f.Metrics) != 0 {
+		t.Errorf("Expected no metrics for empty tombstone but got %d", len(f.Metrics))
+	}
+	after := &dto.Metric{}
+	if err := counter.Write(after); err != nil {
+		t.Fatal(err)
+	}
+	if got := after.GetCounter().GetValue() - before.GetCounter().GetValue(); got != 2 {
+		t.Errorf("Expected 2 unexpected objects to be counted but got %v", got)
+	}
+}
+
 func TestKubeLabelsToPrometheusLabels(t *testing.T) {
 	testCases := []struct {
 		kubeLabels   map[string]string
@@ -176,6 +279,42 @@ func TestKubeLabelsToPrometheusLabels(t *testing.T) {
 			expectKeys:   []string{"label_an", "label_order", "label_test"},
 			expectValues: []string{"", "", ""},
 		},
+		{
+			kubeLabels: map[string]string{
+				"namespace":  "identity",
+				"pod":        "identity",
+				"deployment": "identity",
+			},
+			expectKeys:   []string{"label_deployment", "label_namespace", "label_pod"},
+			expectValues: []string{"identity", "identity", "identity"},
+		},
+		{
+			kubeLabels: map[string]string{
+				"app.kubernetes.io/name": "dots_and_slashes",
+				"ünïcödé":                "unicode",
+			},
+			expectKeys:   []string{"label_app_kubernetes_io_name", "label__n_c_d_"},
+			expectValues: []string{"dots_and_slashes", "unicode"},
+		},
+		{
+			kubeLabels: map[string]string{
+				"app.kubernetes.io/name": "dots",
+				"app_kubernetes_io_name": "underscores",
+				"app-kubernetes-io-name": "dashes",
+				"tier":                   "web",
+			},
+			expectKeys:   []string{"label_app_kubernetes_io_name_conflict1", "label_app_kubernetes_io_name_conflict2", "label_app_kubernetes_io_name_conflict3", "label_tier"},
+			expectValues: []string{"dashes", "dots", "underscores", "web"},
+		},
+		{
+			kubeLabels: map[string]string{
+				"app.name":           "dots",
+				"app_name":           "underscores",
+				"app_name_conflict1": "taken",
+			},
+			expectKeys:   []string{"label_app_name_conflict2", "label_app_name_conflict3", "label_app_name_conflict1"},
+			expectValues: []string{"dots", "underscores", "taken"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -198,3 +337,67 @@ func TestKubeLabelsToPrometheusLabels(t *testing.T) {
 	}
 
 }
+
+func TestSanitizeLabelName(t *testing.T) {
+	// sanitizeLabelName must behave exactly like the regular expression it
+	// replaced.
+	invalidLabelCharRE := regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	for _, name := range []string{
+		"",
+		"app",
+		"app_kubernetes_io_name",
+		"app.kubernetes.io/name",
+		"$app",
+		"0_app",
+		"ünïcödé",
+		"日本語",
+		"invalid\xffutf8",
+	} {
+		expected := invalidLabelCharRE.ReplaceAllString(name, "_")
+		if got := sanitizeLabelName(name); got != expected {
+			t.Errorf("sanitizeLabelName(%q): expected %q but got %q", name, expected, got)
+		}
+	}
+}
+
+func TestLabelNameCache(t *testing.T) {
+	c := &labelNameCache{names: map[string]map[string]string{}}
+
+	for i := 0; i < 2; i++ {
+		if got := c.get("label", "app.kubernetes.io/name"); got != "label_app_kubernetes_io_name" {
+			t.Errorf("expected label_app_kubernetes_io_name but got %q", got)
+		}
+		if got := c.get("annotation", "app.kubernetes.io/name"); got != "annotation_app_kubernetes_io_name" {
+			t.Errorf("expected annotation_app_kubernetes_io_name but got %q", got)
+		}
+	}
+
+	for i := 0; i < maxCachedLabelNames+10; i++ {
+		c.get("label", strconv.Itoa(i))
+	}
+	if len(c.names["label"]) != maxCachedLabelNames {
+		t.Errorf("expected cache to be bounded to %d names but got %d", maxCachedLabelNames, len(c.names["label"]))
+	}
+	if got := c.get("label", "uncached-key"); got != "label_uncached_key" {
+		t.Errorf("expected label_uncached_key but got %q", got)
+	}
+}
+
+func BenchmarkKubeLabelsToPrometheusLabels(b *testing.B) {
+	labels := map[string]string{
+		"app":                          "kube-state-metrics",
+		"app.kubernetes.io/name":       "kube-state-metrics",
+		"app.kubernetes.io/version":    "v1.9.0",
+		"app.kubernetes.io/component":  "exporter",
+		"app.kubernetes.io/part-of":    "monitoring",
+		"app.kubernetes.io/managed-by": "helm",
+		"pod-template-hash":            "5d8f7c9b4",
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		kubeLabelsToPrometheusLabels(labels)
+	}
+}
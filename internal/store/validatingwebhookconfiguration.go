@@ -33,9 +33,10 @@ var (
 
 	validatingWebhookConfigurationMetricFamilies = []metric.FamilyGenerator{
 		{
-			Name: "kube_validatingwebhookconfiguration_info",
-			Type: metric.Gauge,
-			Help: "Information about the ValidatingWebhookConfiguration.",
+			Name:           "kube_validatingwebhookconfiguration_info",
+			Type:           metric.Info,
+			Help:           "Information about the ValidatingWebhookConfiguration.",
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(vwc *admissionregistration.ValidatingWebhookConfiguration) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
@@ -47,26 +48,21 @@ var (
 			}),
 		},
 		{
-			Name: "kube_validatingwebhookconfiguration_created",
-			Type: metric.Gauge,
-			Help: "Unix creation timestamp.",
+			Name:           "kube_validatingwebhookconfiguration_created",
+			Type:           metric.Gauge,
+			Help:           "Unix creation timestamp.",
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(vwc *admissionregistration.ValidatingWebhookConfiguration) *metric.Family {
-				ms := []*metric.Metric{}
-
-				if !vwc.CreationTimestamp.IsZero() {
-					ms = append(ms, &metric.Metric{
-						Value: float64(vwc.CreationTimestamp.Unix()),
-					})
-				}
 				return &metric.Family{
-					Metrics: ms,
+					Metrics: timestampMetrics(&vwc.CreationTimestamp),
 				}
 			}),
 		},
 		{
-			Name: "kube_validatingwebhookconfiguration_metadata_resource_version",
-			Type: metric.Gauge,
-			Help: "Resource version representing a specific version of the ValidatingWebhookConfiguration.",
+			Name:           "kube_validatingwebhookconfiguration_metadata_resource_version",
+			Type:           metric.Gauge,
+			Help:           "Resource version representing a specific version of the ValidatingWebhookConfiguration.",
+			StabilityLevel: metric.Experimental,
 			GenerateFunc: wrapValidatingWebhookConfigurationFunc(func(vwc *admissionregistration.ValidatingWebhookConfiguration) *metric.Family {
 				return &metric.Family{
 					Metrics: resourceVersionMetric(vwc.ObjectMeta.ResourceVersion),
@@ -89,7 +85,10 @@ func createValidatingWebhookConfigurationListWatch(kubeClient clientset.Interfac
 
 func wrapValidatingWebhookConfigurationFunc(f func(*admissionregistration.ValidatingWebhookConfiguration) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		mutatingWebhookConfiguration := obj.(*admissionregistration.ValidatingWebhookConfiguration)
+		mutatingWebhookConfiguration, ok := unwrapObject(obj).(*admissionregistration.ValidatingWebhookConfiguration)
+		if !ok {
+			return unexpectedObject((*admissionregistration.ValidatingWebhookConfiguration)(nil), obj)
+		}
 
 		metricFamily := f(mutatingWebhookConfiguration)
 
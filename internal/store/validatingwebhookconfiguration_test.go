@@ -39,8 +39,8 @@ func TestValidatingWebhookConfigurationStore(t *testing.T) {
 				},
 			},
 			Want: `
-				# HELP kube_validatingwebhookconfiguration_info Information about the ValidatingWebhookConfiguration.
-				# HELP kube_validatingwebhookconfiguration_metadata_resource_version Resource version representing a specific version of the ValidatingWebhookConfiguration.
+				# HELP kube_validatingwebhookconfiguration_info [EXPERIMENTAL] Information about the ValidatingWebhookConfiguration.
+				# HELP kube_validatingwebhookconfiguration_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of the ValidatingWebhookConfiguration.
 				# TYPE kube_validatingwebhookconfiguration_info gauge
 				# TYPE kube_validatingwebhookconfiguration_metadata_resource_version gauge
 				kube_validatingwebhookconfiguration_info{validatingwebhookconfiguration="validatingwebhookconfiguration1",namespace="ns1"} 1
@@ -58,9 +58,9 @@ func TestValidatingWebhookConfigurationStore(t *testing.T) {
 				},
 			},
 			Want: `
-			# HELP kube_validatingwebhookconfiguration_created Unix creation timestamp.
-			# HELP kube_validatingwebhookconfiguration_info Information about the ValidatingWebhookConfiguration.
-			# HELP kube_validatingwebhookconfiguration_metadata_resource_version Resource version representing a specific version of the ValidatingWebhookConfiguration.
+			# HELP kube_validatingwebhookconfiguration_created [EXPERIMENTAL] Unix creation timestamp.
+			# HELP kube_validatingwebhookconfiguration_info [EXPERIMENTAL] Information about the ValidatingWebhookConfiguration.
+			# HELP kube_validatingwebhookconfiguration_metadata_resource_version [EXPERIMENTAL] Resource version representing a specific version of the ValidatingWebhookConfiguration.
 			# TYPE kube_validatingwebhookconfiguration_created gauge
 			# TYPE kube_validatingwebhookconfiguration_info gauge
 			# TYPE kube_validatingwebhookconfiguration_metadata_resource_version gauge
@@ -26,7 +26,6 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
-	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/metric"
 )
 
@@ -37,9 +36,10 @@ var (
 
 	vpaMetricFamilies = []metric.FamilyGenerator{
 		{
-			Name: descVerticalPodAutoscalerLabelsName,
-			Type: metric.Gauge,
-			Help: descVerticalPodAutoscalerLabelsHelp,
+			Name:           descVerticalPodAutoscalerLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descVerticalPodAutoscalerLabelsHelp,
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				labelKeys, labelValues := kubeLabelsToPrometheusLabels(a.Labels)
 				return &metric.Family{
@@ -54,9 +54,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_spec_updatepolicy_updatemode",
-			Type: metric.Gauge,
-			Help: "Update mode of the VerticalPodAutoscaler.",
+			Name:           "kube_verticalpodautoscaler_spec_updatepolicy_updatemode",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Update mode of the VerticalPodAutoscaler.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 
@@ -91,9 +92,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_minallowed",
-			Type: metric.Gauge,
-			Help: "Minimum resources the VerticalPodAutoscaler can set for containers matching the name.",
+			Name:           "kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_minallowed",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Minimum resources the VerticalPodAutoscaler can set for containers matching the name.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 				if a.Spec.ResourcePolicy == nil || a.Spec.ResourcePolicy.ContainerPolicies == nil {
@@ -112,9 +114,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_maxallowed",
-			Type: metric.Gauge,
-			Help: "Maximum resources the VerticalPodAutoscaler can set for containers matching the name.",
+			Name:           "kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_maxallowed",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Maximum resources the VerticalPodAutoscaler can set for containers matching the name.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 				if a.Spec.ResourcePolicy == nil || a.Spec.ResourcePolicy.ContainerPolicies == nil {
@@ -132,9 +135,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound",
-			Type: metric.Gauge,
-			Help: "Minimum resources the container can use before the VerticalPodAutoscaler updater evicts it.",
+			Name:           "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Minimum resources the container can use before the VerticalPodAutoscaler updater evicts it.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 				if a.Status.Recommendation == nil || a.Status.Recommendation.ContainerRecommendations == nil {
@@ -152,9 +156,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound",
-			Type: metric.Gauge,
-			Help: "Maximum resources the container can use before the VerticalPodAutoscaler updater evicts it.",
+			Name:           "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Maximum resources the container can use before the VerticalPodAutoscaler updater evicts it.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 				if a.Status.Recommendation == nil || a.Status.Recommendation.ContainerRecommendations == nil {
@@ -172,9 +177,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target",
-			Type: metric.Gauge,
-			Help: "Target resources the VerticalPodAutoscaler recommends for the container.",
+			Name:           "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Target resources the VerticalPodAutoscaler recommends for the container.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 				if a.Status.Recommendation == nil || a.Status.Recommendation.ContainerRecommendations == nil {
@@ -191,9 +197,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget",
-			Type: metric.Gauge,
-			Help: "Target resources the VerticalPodAutoscaler recommends for the container ignoring bounds.",
+			Name:           "kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Target resources the VerticalPodAutoscaler recommends for the container ignoring bounds.",
 			GenerateFunc: wrapVPAFunc(func(a *autoscaling.VerticalPodAutoscaler) *metric.Family {
 				ms := []*metric.Metric{}
 				if a.Status.Recommendation == nil || a.Status.Recommendation.ContainerRecommendations == nil {
@@ -215,20 +222,10 @@ var (
 func vpaResourcesToMetrics(containerName string, resources v1.ResourceList) []*metric.Metric {
 	ms := []*metric.Metric{}
 	for resourceName, val := range resources {
-		switch resourceName {
-		case v1.ResourceCPU:
+		if value, unit, ok := resourceQuantityValue(resourceName, val); ok {
 			ms = append(ms, &metric.Metric{
-				LabelValues: []string{containerName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore)},
-				Value:       float64(val.MilliValue()) / 1000,
-			})
-		case v1.ResourceStorage:
-			fallthrough
-		case v1.ResourceEphemeralStorage:
-			fallthrough
-		case v1.ResourceMemory:
-			ms = append(ms, &metric.Metric{
-				LabelValues: []string{containerName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte)},
-				Value:       float64(val.Value()),
+				LabelValues: []string{containerName, sanitizeLabelName(string(resourceName)), string(unit)},
+				Value:       value,
 			})
 		}
 	}
@@ -240,7 +237,10 @@ func vpaResourcesToMetrics(containerName string, resources v1.ResourceList) []*m
 
 func wrapVPAFunc(f func(*autoscaling.VerticalPodAutoscaler) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		vpa := obj.(*autoscaling.VerticalPodAutoscaler)
+		vpa, ok := unwrapObject(obj).(*autoscaling.VerticalPodAutoscaler)
+		if !ok {
+			return unexpectedObject((*autoscaling.VerticalPodAutoscaler)(nil), obj)
+		}
 
 		metricFamily := f(vpa)
 		targetRef := vpa.Spec.TargetRef
@@ -254,8 +254,9 @@ func wrapVPAFunc(f func(*autoscaling.VerticalPodAutoscaler) *metric.Family) func
 	}
 }
 
-func createVPAListWatchFunc(vpaClient vpaclientset.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+func createVPAListWatchFunc(vpaClientFor func(kubeClient clientset.Interface) vpaclientset.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
 	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		vpaClient := vpaClientFor(kubeClient)
 		return &cache.ListWatch{
 			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
 				return vpaClient.AutoscalingV1beta2().VerticalPodAutoscalers(ns).List(opts)
@@ -30,14 +30,14 @@ import (
 
 func TestVPAStore(t *testing.T) {
 	const metadata = `
-		# HELP kube_verticalpodautoscaler_labels Kubernetes labels converted to Prometheus labels.
-        # HELP kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_maxallowed Maximum resources the VerticalPodAutoscaler can set for containers matching the name.
-        # HELP kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_minallowed Minimum resources the VerticalPodAutoscaler can set for containers matching the name.
-        # HELP kube_verticalpodautoscaler_spec_updatepolicy_updatemode Update mode of the VerticalPodAutoscaler.
-        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound Minimum resources the container can use before the VerticalPodAutoscaler updater evicts it.
-        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target Target resources the VerticalPodAutoscaler recommends for the container.
-        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget Target resources the VerticalPodAutoscaler recommends for the container ignoring bounds.
-        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound Maximum resources the container can use before the VerticalPodAutoscaler updater evicts it.
+		# HELP kube_verticalpodautoscaler_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+        # HELP kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_maxallowed [EXPERIMENTAL] Maximum resources the VerticalPodAutoscaler can set for containers matching the name.
+        # HELP kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_minallowed [EXPERIMENTAL] Minimum resources the VerticalPodAutoscaler can set for containers matching the name.
+        # HELP kube_verticalpodautoscaler_spec_updatepolicy_updatemode [EXPERIMENTAL] Update mode of the VerticalPodAutoscaler.
+        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound [EXPERIMENTAL] Minimum resources the container can use before the VerticalPodAutoscaler updater evicts it.
+        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target [EXPERIMENTAL] Target resources the VerticalPodAutoscaler recommends for the container.
+        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget [EXPERIMENTAL] Target resources the VerticalPodAutoscaler recommends for the container ignoring bounds.
+        # HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound [EXPERIMENTAL] Maximum resources the container can use before the VerticalPodAutoscaler updater evicts it.
         # TYPE kube_verticalpodautoscaler_labels gauge
         # TYPE kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_maxallowed gauge
         # TYPE kube_verticalpodautoscaler_spec_resourcepolicy_container_policies_minallowed gauge
@@ -34,9 +34,10 @@ var (
 
 	volumeAttachmentMetricFamilies = []metric.FamilyGenerator{
 		{
-			Name: descVolumeAttachmentLabelsName,
-			Type: metric.Gauge,
-			Help: descVolumeAttachmentLabelsHelp,
+			Name:           descVolumeAttachmentLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descVolumeAttachmentLabelsHelp,
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
 				labelKeys, labelValues := kubeLabelsToPrometheusLabels(va.Labels)
 				return &metric.Family{
@@ -51,9 +52,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_volumeattachment_info",
-			Type: metric.Gauge,
-			Help: "Information about volumeattachment.",
+			Name:           "kube_volumeattachment_info",
+			Type:           metric.Info,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about volumeattachment.",
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
@@ -67,25 +69,21 @@ var (
 			}),
 		},
 		{
-			Name: "kube_volumeattachment_created",
-			Type: metric.Gauge,
-			Help: "Unix creation timestamp",
+			Name:           "kube_volumeattachment_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
-				if !va.CreationTimestamp.IsZero() {
-					m := metric.Metric{
-						LabelKeys:   nil,
-						LabelValues: nil,
-						Value:       float64(va.CreationTimestamp.Unix()),
-					}
-					return &metric.Family{Metrics: []*metric.Metric{&m}}
+				return &metric.Family{
+					Metrics: timestampMetrics(&va.CreationTimestamp),
 				}
-				return &metric.Family{Metrics: []*metric.Metric{}}
 			}),
 		},
 		{
-			Name: "kube_volumeattachment_spec_source_persistentvolume",
-			Type: metric.Gauge,
-			Help: "PersistentVolume source reference.",
+			Name:           "kube_volumeattachment_spec_source_persistentvolume",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "PersistentVolume source reference.",
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
 				if va.Spec.Source.PersistentVolumeName != nil {
 					return &metric.Family{
@@ -102,9 +100,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_volumeattachment_status_attached",
-			Type: metric.Gauge,
-			Help: "Information about volumeattachment.",
+			Name:           "kube_volumeattachment_status_attached",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about volumeattachment.",
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
 				return &metric.Family{
 					Metrics: []*metric.Metric{
@@ -118,9 +117,10 @@ var (
 			}),
 		},
 		{
-			Name: "kube_volumeattachment_status_attachment_metadata",
-			Type: metric.Gauge,
-			Help: "volumeattachment metadata.",
+			Name:           "kube_volumeattachment_status_attachment_metadata",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "volumeattachment metadata.",
 			GenerateFunc: wrapVolumeAttachmentFunc(func(va *storagev1.VolumeAttachment) *metric.Family {
 				labelKeys, labelValues := mapToPrometheusLabels(va.Status.AttachmentMetadata, "metadata")
 				return &metric.Family{
@@ -139,7 +139,10 @@ var (
 
 func wrapVolumeAttachmentFunc(f func(*storagev1.VolumeAttachment) *metric.Family) func(interface{}) *metric.Family {
 	return func(obj interface{}) *metric.Family {
-		va := obj.(*storagev1.VolumeAttachment)
+		va, ok := unwrapObject(obj).(*storagev1.VolumeAttachment)
+		if !ok {
+			return unexpectedObject((*storagev1.VolumeAttachment)(nil), obj)
+		}
 
 		metricFamily := f(va)
 
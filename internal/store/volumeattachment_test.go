@@ -27,12 +27,12 @@ import (
 
 func TestVolumeAttachmentStore(t *testing.T) {
 	const metadata = `
-		# HELP kube_volumeattachment_created Unix creation timestamp
-        # HELP kube_volumeattachment_info Information about volumeattachment.
-        # HELP kube_volumeattachment_labels Kubernetes labels converted to Prometheus labels.
-        # HELP kube_volumeattachment_spec_source_persistentvolume PersistentVolume source reference.
-        # HELP kube_volumeattachment_status_attached Information about volumeattachment.
-        # HELP kube_volumeattachment_status_attachment_metadata volumeattachment metadata.
+		# HELP kube_volumeattachment_created [EXPERIMENTAL] Unix creation timestamp
+        # HELP kube_volumeattachment_info [EXPERIMENTAL] Information about volumeattachment.
+        # HELP kube_volumeattachment_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+        # HELP kube_volumeattachment_spec_source_persistentvolume [EXPERIMENTAL] PersistentVolume source reference.
+        # HELP kube_volumeattachment_status_attached [EXPERIMENTAL] Information about volumeattachment.
+        # HELP kube_volumeattachment_status_attachment_metadata [EXPERIMENTAL] volumeattachment metadata.
         # TYPE kube_volumeattachment_created gauge
         # TYPE kube_volumeattachment_info gauge
         # TYPE kube_volumeattachment_labels gauge
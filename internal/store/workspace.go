@@ -0,0 +1,646 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"strconv"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descWorkspaceLabelsName          = "kube_workspace_labels"
+	descWorkspaceLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descWorkspaceLabelsDefaultLabels = []string{"workspace"}
+
+	descWorkspacePodsName        = "kube_workspace_pods"
+	descWorkspaceDeploymentsName = "kube_workspace_deployments"
+	descWorkspaceStorageName     = "kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes"
+
+	workspaceMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_workspace_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about workspace.",
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				networkIsolation := w.Spec.NetworkIsolation != nil && *w.Spec.NetworkIsolation
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"manager", "network_isolation"},
+							LabelValues: []string{w.Spec.Manager, strconv.FormatBool(networkIsolation)},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_workspace_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&w.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descWorkspaceLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descWorkspaceLabelsHelp,
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(w.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_workspace_namespaces",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of namespaces assigned to the workspace.",
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(w.usage.namespaces),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           descWorkspacePodsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of pods which are not terminated in the namespaces of the workspace.",
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(w.usage.pods),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           descWorkspaceDeploymentsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Number of deployments in the namespaces of the workspace.",
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(w.usage.deployments),
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           descWorkspaceStorageName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Storage requested by the persistent volume claims in the namespaces of the workspace.",
+			GenerateFunc: wrapWorkspaceFunc(func(w *workspace) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							Value: float64(w.usage.storage),
+						},
+					},
+				}
+			}),
+		},
+	}
+)
+
+// workspace is a workspace along with the usage of resources in its
+// namespaces, which the metrics of workspaces are generated for.
+type workspace struct {
+	*kubesphere.Workspace
+	usage workspaceUsage
+}
+
+// workspaceUsage is the usage of resources in the namespaces of a workspace,
+// or in a single namespace.
+type workspaceUsage struct {
+	namespaces  int
+	pods        int
+	deployments int
+	// storage is the storage requested by persistent volume claims in bytes.
+	storage int64
+}
+
+func (u workspaceUsage) add(o workspaceUsage) workspaceUsage {
+	return workspaceUsage{
+		namespaces:  u.namespaces + o.namespaces,
+		pods:        u.pods + o.pods,
+		deployments: u.deployments + o.deployments,
+		storage:     u.storage + o.storage,
+	}
+}
+
+func (u workspaceUsage) sub(o workspaceUsage) workspaceUsage {
+	return workspaceUsage{
+		namespaces:  u.namespaces - o.namespaces,
+		pods:        u.pods - o.pods,
+		deployments: u.deployments - o.deployments,
+		storage:     u.storage - o.storage,
+	}
+}
+
+func wrapWorkspaceFunc(f func(*workspace) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		w, ok := unwrapObject(obj).(*workspace)
+		if !ok {
+			return unexpectedObject((*workspace)(nil), obj)
+		}
+
+		metricFamily := f(w)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descWorkspaceLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{w.Name}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createWorkspaceListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.WorkspaceResource, metav1.NamespaceAll,
+			func() runtime.Object { return &kubesphere.Workspace{} },
+			func() runtime.Object { return &kubesphere.WorkspaceList{} },
+		)
+	}
+}
+
+// workspaceResources are the resources whose usage is aggregated per
+// workspace, along with the family of the usage and the name of their
+// collector. The objects of a resource are only watched if its family is
+// exposed.
+var workspaceResources = []struct {
+	family        string
+	resource      string
+	expectedType  runtime.Object
+	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher
+	usage         func(obj interface{}) workspaceUsage
+}{
+	{
+		family:        descWorkspacePodsName,
+		resource:      "pods",
+		expectedType:  &v1.Pod{},
+		listWatchFunc: createPodListWatchFunc(""),
+		usage: func(obj interface{}) workspaceUsage {
+			p, ok := obj.(*v1.Pod)
+			if !ok || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+				return workspaceUsage{}
+			}
+			return workspaceUsage{pods: 1}
+		},
+	},
+	{
+		family:        descWorkspaceDeploymentsName,
+		resource:      "deployments",
+		expectedType:  &appsv1.Deployment{},
+		listWatchFunc: createDeploymentListWatch,
+		usage: func(obj interface{}) workspaceUsage {
+			if _, ok := obj.(*appsv1.Deployment); !ok {
+				return workspaceUsage{}
+			}
+			return workspaceUsage{deployments: 1}
+		},
+	},
+	{
+		family:        descWorkspaceStorageName,
+		resource:      "persistentvolumeclaims",
+		expectedType:  &v1.PersistentVolumeClaim{},
+		listWatchFunc: createPersistentVolumeClaimListWatch,
+		usage: func(obj interface{}) workspaceUsage {
+			pvc, ok := obj.(*v1.PersistentVolumeClaim)
+			if !ok {
+				return workspaceUsage{}
+			}
+			storage := pvc.Spec.Resources.Requests[v1.ResourceStorage]
+			return workspaceUsage{storage: storage.Value()}
+		},
+	},
+}
+
+// workspaceAggregatorStore wraps the store of the workspaces of the cluster of
+// the given kubeClient, aggregating the usage of resources in the namespaces
+// assigned to them with an informer of the namespaces of the cluster, and
+// reflectors of the objects of the resources whose families are exposed. The
+// reflector of the collector of a resource is shared if it is enabled and
+// watches all objects of the resource, i.e. neither sharded nor restricted to
+// the pods of a node.
+func (b *Builder) workspaceAggregatorStore(store clusterStore, kubeClient clientset.Interface) clusterStore {
+	w := newWorkspaceAggregator(store)
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.LabelSelector = kubesphere.WorkspaceLabel
+			return kubeClient.CoreV1().Namespaces().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.LabelSelector = kubesphere.WorkspaceLabel
+			return kubeClient.CoreV1().Namespaces().Watch(opts)
+		},
+	}
+	_, controller := cache.NewInformer(lw, &v1.Namespace{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    w.setNamespace,
+		UpdateFunc: func(_, obj interface{}) { w.setNamespace(obj) },
+		DeleteFunc: w.deleteNamespace,
+	})
+	go controller.Run(b.ctx.Done())
+
+	for _, r := range workspaceResources {
+		if b.stableOnly || !b.whiteBlackList.IsIncluded(r.family) {
+			continue
+		}
+		usage := newNamespaceUsage(w, r.usage, r.resource)
+		shared := b.totalShards <= 1 && (r.resource != "pods" || b.node == "")
+		if shared && b.shareReflector(r.resource, usage) {
+			continue
+		}
+		b.startReflector(r.expectedType, usage, kubeClient, r.listWatchFunc, false)
+	}
+	return w
+}
+
+// workspaceAggregator aggregates the usage of resources in the namespaces
+// assigned to each workspace by their kubesphere.io/workspace label. It passes
+// the workspaces given to it on to its store along with their usage, and
+// updates them in the store whenever their usage changes.
+type workspaceAggregator struct {
+	clusterStore
+
+	mutex sync.Mutex
+	// workspaces holds the last known state of the workspaces by name.
+	workspaces map[string]*kubesphere.Workspace
+	// namespaces holds the workspaces of the assigned namespaces by name.
+	namespaces map[string]string
+	// usage holds the usage of resources in the namespaces by name, whether
+	// they are assigned to a workspace or not.
+	usage map[string]workspaceUsage
+	// totals holds the usage of the workspaces by name.
+	totals map[string]workspaceUsage
+	// degraded holds whether the reflectors of the workspaces, by the empty
+	// resource, and of the objects whose usage is aggregated, by resource,
+	// are degraded.
+	degraded map[string]bool
+}
+
+func newWorkspaceAggregator(store clusterStore) *workspaceAggregator {
+	return &workspaceAggregator{
+		clusterStore: store,
+		workspaces:   map[string]*kubesphere.Workspace{},
+		namespaces:   map[string]string{},
+		usage:        map[string]workspaceUsage{},
+		totals:       map[string]workspaceUsage{},
+		degraded:     map[string]bool{},
+	}
+}
+
+// SetDegraded records whether the reflector of the workspaces is degraded.
+func (w *workspaceAggregator) SetDegraded(degraded bool) {
+	w.setDegraded("", degraded)
+}
+
+// setDegraded records whether the reflector of the given resource is
+// degraded. The store is degraded while any of the reflectors is, as the
+// usage of workspaces is stale while the one of their objects is.
+func (w *workspaceAggregator) setDegraded(resource string, degraded bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.degraded[resource] = degraded
+	for _, d := range w.degraded {
+		if d {
+			w.clusterStore.SetDegraded(true)
+			return
+		}
+	}
+	w.clusterStore.SetDegraded(false)
+}
+
+// Add adds the given workspace to the store.
+func (w *workspaceAggregator) Add(obj interface{}) error {
+	ws, ok := obj.(*kubesphere.Workspace)
+	if !ok {
+		return w.clusterStore.Add(obj)
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.workspaces[ws.Name] = ws
+	return w.clusterStore.Add(&workspace{Workspace: ws, usage: w.totals[ws.Name]})
+}
+
+// Update updates the given workspace in the store.
+func (w *workspaceAggregator) Update(obj interface{}) error {
+	return w.Add(obj)
+}
+
+// Delete deletes the given workspace from the store.
+func (w *workspaceAggregator) Delete(obj interface{}) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if ws, ok := unwrapObject(obj).(*kubesphere.Workspace); ok {
+		delete(w.workspaces, ws.Name)
+	} else if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		delete(w.workspaces, tombstone.Key)
+	}
+	return w.clusterStore.Delete(obj)
+}
+
+// Replace replaces the workspaces in the store with the given ones.
+func (w *workspaceAggregator) Replace(list []interface{}, resourceVersion string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.workspaces = make(map[string]*kubesphere.Workspace, len(list))
+	objs := make([]interface{}, len(list))
+	for i, obj := range list {
+		objs[i] = obj
+		if ws, ok := obj.(*kubesphere.Workspace); ok {
+			w.workspaces[ws.Name] = ws
+			objs[i] = &workspace{Workspace: ws, usage: w.totals[ws.Name]}
+		}
+	}
+	return w.clusterStore.Replace(objs, resourceVersion)
+}
+
+// setNamespace records the workspace the given namespace is assigned to.
+func (w *workspaceAggregator) setNamespace(obj interface{}) {
+	ns, ok := obj.(*v1.Namespace)
+	if !ok {
+		return
+	}
+	w.assign(ns.Name, ns.Labels[kubesphere.WorkspaceLabel])
+}
+
+// deleteNamespace records the deletion of the given namespace.
+func (w *workspaceAggregator) deleteNamespace(obj interface{}) {
+	if ns, ok := unwrapObject(obj).(*v1.Namespace); ok {
+		w.assign(ns.Name, "")
+	} else if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		w.assign(tombstone.Key, "")
+	}
+}
+
+// assign assigns the given namespace to the given workspace, or to none if
+// empty, moving the usage of the namespace between the workspaces.
+func (w *workspaceAggregator) assign(namespace, workspaceName string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	previous := w.namespaces[namespace]
+	if previous == workspaceName {
+		return
+	}
+	if workspaceName == "" {
+		delete(w.namespaces, namespace)
+	} else {
+		w.namespaces[namespace] = workspaceName
+	}
+
+	moved := w.usage[namespace].add(workspaceUsage{namespaces: 1})
+	w.addTotal(previous, workspaceUsage{}.sub(moved))
+	w.addTotal(workspaceName, moved)
+	w.update(previous)
+	w.update(workspaceName)
+}
+
+// addUsage adds the given changes of the usage of namespaces by name, and
+// updates the workspaces whose usage changes.
+func (w *workspaceAggregator) addUsage(changes map[string]workspaceUsage) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	changed := map[string]bool{}
+	for namespace, change := range changes {
+		if change == (workspaceUsage{}) {
+			continue
+		}
+		if u := w.usage[namespace].add(change); u == (workspaceUsage{}) {
+			delete(w.usage, namespace)
+		} else {
+			w.usage[namespace] = u
+		}
+		if name := w.namespaces[namespace]; name != "" {
+			w.addTotal(name, change)
+			changed[name] = true
+		}
+	}
+	for name := range changed {
+		w.update(name)
+	}
+}
+
+// addTotal adds the given change to the usage of the given workspace, if any.
+func (w *workspaceAggregator) addTotal(name string, change workspaceUsage) {
+	if name == "" {
+		return
+	}
+	if t := w.totals[name].add(change); t == (workspaceUsage{}) {
+		delete(w.totals, name)
+	} else {
+		w.totals[name] = t
+	}
+}
+
+// update updates the given workspace in the store with its usage, if the
+// workspace is known.
+func (w *workspaceAggregator) update(name string) {
+	ws, ok := w.workspaces[name]
+	if !ok {
+		return
+	}
+	if err := w.clusterStore.Update(&workspace{Workspace: ws, usage: w.totals[name]}); err != nil {
+		klog.Errorf("Failed to update usage of workspace %s: %v", name, err)
+	}
+}
+
+// namespaceUsage is the store of a reflector of the objects of a resource,
+// which only keeps the usage of the objects and adds its changes to the
+// workspaceAggregator.
+type namespaceUsage struct {
+	aggregator *workspaceAggregator
+	usageFunc  func(obj interface{}) workspaceUsage
+	resource   string
+
+	mutex sync.Mutex
+	// objects holds the namespace and usage of the objects by key.
+	objects map[string]objectUsage
+}
+
+type objectUsage struct {
+	namespace string
+	usage     workspaceUsage
+}
+
+func newNamespaceUsage(aggregator *workspaceAggregator, usageFunc func(obj interface{}) workspaceUsage, resource string) *namespaceUsage {
+	return &namespaceUsage{
+		aggregator: aggregator,
+		usageFunc:  usageFunc,
+		resource:   resource,
+		objects:    map[string]objectUsage{},
+	}
+}
+
+// Add records the usage of the given object.
+func (s *namespaceUsage) Add(obj interface{}) error {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	changes := map[string]workspaceUsage{}
+	previous := s.objects[key]
+	changes[previous.namespace] = workspaceUsage{}.sub(previous.usage)
+	current := objectUsage{namespace: o.GetNamespace(), usage: s.usageFunc(obj)}
+	changes[current.namespace] = changes[current.namespace].add(current.usage)
+	s.objects[key] = current
+	s.aggregator.addUsage(changes)
+	return nil
+}
+
+// Update records the usage of the given object.
+func (s *namespaceUsage) Update(obj interface{}) error {
+	return s.Add(obj)
+}
+
+// Delete removes the usage of the given object.
+func (s *namespaceUsage) Delete(obj interface{}) error {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, ok := s.objects[key]
+	if !ok {
+		return nil
+	}
+	delete(s.objects, key)
+	s.aggregator.addUsage(map[string]workspaceUsage{previous.namespace: workspaceUsage{}.sub(previous.usage)})
+	return nil
+}
+
+// Replace replaces the usage of all objects with the usage of the given ones.
+func (s *namespaceUsage) Replace(list []interface{}, _ string) error {
+	objects := make(map[string]objectUsage, len(list))
+	for _, obj := range list {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			return err
+		}
+		objects[key] = objectUsage{namespace: o.GetNamespace(), usage: s.usageFunc(obj)}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	changes := map[string]workspaceUsage{}
+	for _, previous := range s.objects {
+		changes[previous.namespace] = changes[previous.namespace].sub(previous.usage)
+	}
+	for _, current := range objects {
+		changes[current.namespace] = changes[current.namespace].add(current.usage)
+	}
+	s.objects = objects
+	s.aggregator.addUsage(changes)
+	return nil
+}
+
+// List implements the List method of the store interface.
+func (s *namespaceUsage) List() []interface{} {
+	return nil
+}
+
+// ListKeys implements the ListKeys method of the store interface.
+func (s *namespaceUsage) ListKeys() []string {
+	return nil
+}
+
+// Get implements the Get method of the store interface.
+func (s *namespaceUsage) Get(obj interface{}) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// GetByKey implements the GetByKey method of the store interface.
+func (s *namespaceUsage) GetByKey(key string) (item interface{}, exists bool, err error) {
+	return nil, false, nil
+}
+
+// Resync implements the Resync method of the store interface.
+func (s *namespaceUsage) Resync() error {
+	return nil
+}
+
+// MarkActive has no effect, as the activity of the store of the workspaces
+// only reflects the reflector of the workspaces.
+func (s *namespaceUsage) MarkActive() {}
+
+// SetDegraded records whether the reflector of the objects is degraded with
+// the workspaceAggregator.
+func (s *namespaceUsage) SetDegraded(degraded bool) {
+	s.aggregator.setDegraded(s.resource, degraded)
+}
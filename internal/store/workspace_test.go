@@ -0,0 +1,291 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+func TestWorkspaceStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_workspace_info [EXPERIMENTAL] Information about workspace.
+		# TYPE kube_workspace_info gauge
+		# HELP kube_workspace_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_workspace_created gauge
+		# HELP kube_workspace_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_workspace_labels gauge
+		# HELP kube_workspace_namespaces [EXPERIMENTAL] Number of namespaces assigned to the workspace.
+		# TYPE kube_workspace_namespaces gauge
+		# HELP kube_workspace_pods [EXPERIMENTAL] Number of pods which are not terminated in the namespaces of the workspace.
+		# TYPE kube_workspace_pods gauge
+		# HELP kube_workspace_deployments [EXPERIMENTAL] Number of deployments in the namespaces of the workspace.
+		# TYPE kube_workspace_deployments gauge
+		# HELP kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes [EXPERIMENTAL] Storage requested by the persistent volume claims in the namespaces of the workspace.
+		# TYPE kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes gauge
+	`
+	networkIsolation := true
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &workspace{
+				Workspace: &kubesphere.Workspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "ws1",
+					},
+					Spec: kubesphere.WorkspaceSpec{
+						Manager: "admin",
+					},
+				},
+			},
+			Want: metadata + `
+				kube_workspace_info{manager="admin",network_isolation="false",workspace="ws1"} 1
+				kube_workspace_labels{workspace="ws1"} 1
+				kube_workspace_namespaces{workspace="ws1"} 0
+				kube_workspace_pods{workspace="ws1"} 0
+				kube_workspace_deployments{workspace="ws1"} 0
+				kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes{workspace="ws1"} 0
+`,
+		},
+		{
+			Obj: &workspace{
+				Workspace: &kubesphere.Workspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "ws2",
+						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+						Labels: map[string]string{
+							"kubesphere.io/creator": "alice",
+						},
+					},
+					Spec: kubesphere.WorkspaceSpec{
+						Manager:          "alice",
+						NetworkIsolation: &networkIsolation,
+					},
+				},
+				usage: workspaceUsage{namespaces: 3, pods: 12, deployments: 4, storage: 10737418240},
+			},
+			Want: metadata + `
+				kube_workspace_created{workspace="ws2"} 1.5e+09
+				kube_workspace_info{manager="alice",network_isolation="true",workspace="ws2"} 1
+				kube_workspace_labels{label_kubesphere_io_creator="alice",workspace="ws2"} 1
+				kube_workspace_namespaces{workspace="ws2"} 3
+				kube_workspace_pods{workspace="ws2"} 12
+				kube_workspace_deployments{workspace="ws2"} 4
+				kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes{workspace="ws2"} 1.073741824e+10
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(workspaceMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(workspaceMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
+
+func TestWorkspaceAggregator(t *testing.T) {
+	families := []metric.FamilyGenerator{}
+	for _, f := range workspaceMetricFamilies {
+		switch f.Name {
+		case "kube_workspace_namespaces", descWorkspacePodsName, descWorkspaceStorageName:
+			families = append(families, f)
+		}
+	}
+	ms := metricsstore.NewMetricsStore(metric.ExtractMetricFamilyHeaders(families), metric.ComposeMetricGenFuncs(families))
+	w := newWorkspaceAggregator(ms)
+	pods := newNamespaceUsage(w, workspaceResources[0].usage, workspaceResources[0].resource)
+	pvcs := newNamespaceUsage(w, workspaceResources[2].usage, workspaceResources[2].resource)
+
+	newWorkspace := func(name string) *kubesphere.Workspace {
+		return &kubesphere.Workspace{ObjectMeta: metav1.ObjectMeta{Name: name, UID: "uid-" + types.UID(name)}}
+	}
+	newNamespace := func(name, workspace string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{kubesphere.WorkspaceLabel: workspace}}}
+	}
+	newPod := func(namespace, name string, phase v1.PodPhase) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Status: v1.PodStatus{Phase: phase}}
+	}
+	newPVC := func(namespace, name, storage string) *v1.PersistentVolumeClaim {
+		return &v1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1.PersistentVolumeClaimSpec{
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse(storage)}},
+			},
+		}
+	}
+
+	// Namespaces and their objects may be known before their workspaces.
+	w.setNamespace(newNamespace("ns1", "ws1"))
+	if err := pods.Replace([]interface{}{newPod("ns1", "p1", v1.PodRunning), newPod("ns2", "p2", v1.PodPending), newPod("ns3", "p3", v1.PodRunning)}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Replace([]interface{}{newWorkspace("ws1"), newWorkspace("ws2")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	w.setNamespace(newNamespace("ns2", "ws1"))
+	w.setNamespace(newNamespace("ns3", "ws2"))
+	// Moving a namespace moves its usage to the other workspace.
+	w.setNamespace(newNamespace("ns3", "ws1"))
+	w.setNamespace(newNamespace("ns4", "ws2"))
+	w.deleteNamespace(cache.DeletedFinalStateUnknown{Key: "ns4"})
+	// Namespaces of unknown workspaces are counted once they are known.
+	w.setNamespace(newNamespace("ns5", "ws3"))
+	if err := w.Add(newWorkspace("ws3")); err != nil {
+		t.Fatal(err)
+	}
+	// Terminated pods are not counted.
+	if err := pods.Update(newPod("ns1", "p1", v1.PodSucceeded)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pods.Add(newPod("ns5", "p5", v1.PodRunning)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pods.Delete(cache.DeletedFinalStateUnknown{Key: "ns2/p2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pvcs.Add(newPVC("ns1", "data", "10Gi")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pvcs.Add(newPVC("ns3", "data", "5Gi")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pvcs.Update(newPVC("ns3", "data", "20Gi")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := strings.Builder{}
+	ms.WriteAll(&buf)
+	want := `# HELP kube_workspace_namespaces [EXPERIMENTAL] Number of namespaces assigned to the workspace.
+# TYPE kube_workspace_namespaces gauge
+kube_workspace_namespaces{workspace="ws1"} 3
+kube_workspace_namespaces{workspace="ws2"} 0
+kube_workspace_namespaces{workspace="ws3"} 1
+# HELP kube_workspace_pods [EXPERIMENTAL] Number of pods which are not terminated in the namespaces of the workspace.
+# TYPE kube_workspace_pods gauge
+kube_workspace_pods{workspace="ws1"} 1
+kube_workspace_pods{workspace="ws2"} 0
+kube_workspace_pods{workspace="ws3"} 1
+# HELP kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes [EXPERIMENTAL] Storage requested by the persistent volume claims in the namespaces of the workspace.
+# TYPE kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes gauge
+kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes{workspace="ws1"} 3.221225472e+10
+kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes{workspace="ws2"} 0
+kube_workspace_persistentvolumeclaim_resource_requests_storage_bytes{workspace="ws3"} 0
+`
+	if buf.String() != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, buf.String())
+	}
+}
+
+func TestBuilderWorkspaceUsageReflectors(t *testing.T) {
+	tests := []struct {
+		desc      string
+		resources []string
+	}{
+		{desc: "shared with the pods collector", resources: []string{"pods", "workspaces"}},
+		{desc: "own reflector", resources: []string{"workspaces"}},
+	}
+
+	for _, test := range tests {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		kubeClient := fake.NewSimpleClientset(
+			&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", UID: "ns1", Labels: map[string]string{kubesphere.WorkspaceLabel: "ws1"}}},
+			&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "p1", UID: "p1"}, Status: v1.PodStatus{Phase: v1.PodRunning}},
+		)
+		dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "tenant.kubesphere.io/v1alpha1",
+			"kind":       "Workspace",
+			"metadata":   map[string]interface{}{"name": "ws1", "uid": "ws1"},
+		}})
+		l, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := NewBuilder()
+		b.WithMetrics(prometheus.NewRegistry())
+		b.WithContext(ctx)
+		b.WithKubeClient(kubeClient)
+		b.WithDynamicClient(dynamicClient)
+		b.WithNamespaces(options.DefaultNamespaces)
+		b.WithSharding(0, 1)
+		b.WithWhiteBlackList(l)
+		if err := b.WithEnabledResources(test.resources); err != nil {
+			t.Fatal(err)
+		}
+		stores := b.Build()
+		workspaces := stores[len(stores)-1]
+
+		want := `kube_workspace_pods{workspace="ws1"} 1`
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			buf := &strings.Builder{}
+			workspaces.WriteAll(buf)
+			if strings.Contains(buf.String(), want) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: timed out waiting for %s in\n%s", test.desc, want, buf.String())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// Pods are listed by a single reflector, which is instrumented.
+		lists := 0
+		for _, a := range kubeClient.Actions() {
+			if a.GetVerb() == "list" && a.GetResource().Resource == "pods" {
+				lists++
+			}
+		}
+		if lists != 1 {
+			t.Errorf("%s: expected pods to be listed once but got %d lists", test.desc, lists)
+		}
+		m := &dto.Metric{}
+		if err := b.metrics.ListTotal.WithLabelValues("success", "*v1.Pod").Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetCounter().GetValue(); got != 1 {
+			t.Errorf("%s: expected 1 instrumented list of pods but got %v", test.desc, got)
+		}
+
+		cancel()
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+var (
+	descWorkspaceRoleBindingLabelsName          = "kube_workspacerolebinding_labels"
+	descWorkspaceRoleBindingLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descWorkspaceRoleBindingLabelsDefaultLabels = []string{"workspacerolebinding", "workspace"}
+
+	workspaceRoleBindingMetricFamilies = []metric.FamilyGenerator{
+		{
+			Name:           "kube_workspacerolebinding_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about workspace role binding.",
+			GenerateFunc: wrapWorkspaceRoleBindingFunc(func(b *kubesphere.WorkspaceRoleBinding) *metric.Family {
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"role"},
+							LabelValues: []string{b.RoleRef.Name},
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_workspacerolebinding_created",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Unix creation timestamp",
+			GenerateFunc: wrapWorkspaceRoleBindingFunc(func(b *kubesphere.WorkspaceRoleBinding) *metric.Family {
+				return &metric.Family{
+					Metrics: timestampMetrics(&b.CreationTimestamp),
+				}
+			}),
+		},
+		{
+			Name:           descWorkspaceRoleBindingLabelsName,
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           descWorkspaceRoleBindingLabelsHelp,
+			GenerateFunc: wrapWorkspaceRoleBindingFunc(func(b *kubesphere.WorkspaceRoleBinding) *metric.Family {
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(b.Labels)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   labelKeys,
+							LabelValues: labelValues,
+							Value:       1,
+						},
+					},
+				}
+			}),
+		},
+		{
+			Name:           "kube_workspace_member_info",
+			Type:           metric.Gauge,
+			StabilityLevel: metric.Experimental,
+			Help:           "Information about the users granted a role in a workspace by a workspace role binding.",
+			GenerateFunc: wrapWorkspaceRoleBindingFunc(func(b *kubesphere.WorkspaceRoleBinding) *metric.Family {
+				ms := []*metric.Metric{}
+				for _, s := range b.Subjects {
+					if s.Kind != rbacv1.UserKind {
+						continue
+					}
+					ms = append(ms, &metric.Metric{
+						LabelKeys:   []string{"user", "role"},
+						LabelValues: []string{s.Name, b.RoleRef.Name},
+						Value:       1,
+					})
+				}
+				return &metric.Family{
+					Metrics: ms,
+				}
+			}),
+		},
+	}
+)
+
+func wrapWorkspaceRoleBindingFunc(f func(*kubesphere.WorkspaceRoleBinding) *metric.Family) func(interface{}) *metric.Family {
+	return func(obj interface{}) *metric.Family {
+		binding, ok := unwrapObject(obj).(*kubesphere.WorkspaceRoleBinding)
+		if !ok {
+			return unexpectedObject((*kubesphere.WorkspaceRoleBinding)(nil), obj)
+		}
+
+		metricFamily := f(binding)
+
+		for _, m := range metricFamily.Metrics {
+			m.LabelKeys = append(descWorkspaceRoleBindingLabelsDefaultLabels, m.LabelKeys...)
+			m.LabelValues = append([]string{binding.Name, binding.Labels[kubesphere.WorkspaceLabel]}, m.LabelValues...)
+		}
+
+		return metricFamily
+	}
+}
+
+func createWorkspaceRoleBindingListWatch(dynamicClientFor func(kubeClient clientset.Interface) dynamic.Interface) func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+	return func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return kubesphere.NewListWatch(dynamicClientFor(kubeClient), kubesphere.WorkspaceRoleBindingResource, metav1.NamespaceAll,
+			func() runtime.Object { return &kubesphere.WorkspaceRoleBinding{} },
+			func() runtime.Object { return &kubesphere.WorkspaceRoleBindingList{} },
+		)
+	}
+}
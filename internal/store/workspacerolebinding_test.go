@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/internal/kubesphere"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestWorkspaceRoleBindingStore(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_workspacerolebinding_info [EXPERIMENTAL] Information about workspace role binding.
+		# TYPE kube_workspacerolebinding_info gauge
+		# HELP kube_workspacerolebinding_created [EXPERIMENTAL] Unix creation timestamp
+		# TYPE kube_workspacerolebinding_created gauge
+		# HELP kube_workspacerolebinding_labels [EXPERIMENTAL] Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_workspacerolebinding_labels gauge
+		# HELP kube_workspace_member_info [EXPERIMENTAL] Information about the users granted a role in a workspace by a workspace role binding.
+		# TYPE kube_workspace_member_info gauge
+	`
+
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &kubesphere.WorkspaceRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "ws1-admin",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						kubesphere.WorkspaceLabel: "ws1",
+					},
+				},
+				Subjects: []rbacv1.Subject{
+					{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+					{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "bob"},
+					{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "admins"},
+				},
+				RoleRef: rbacv1.RoleRef{APIGroup: "iam.kubesphere.io", Kind: "WorkspaceRole", Name: "ws1-admin"},
+			},
+			Want: metadata + `
+				kube_workspacerolebinding_created{workspace="ws1",workspacerolebinding="ws1-admin"} 1.5e+09
+				kube_workspacerolebinding_info{role="ws1-admin",workspace="ws1",workspacerolebinding="ws1-admin"} 1
+				kube_workspacerolebinding_labels{label_kubesphere_io_workspace="ws1",workspace="ws1",workspacerolebinding="ws1-admin"} 1
+				kube_workspace_member_info{role="ws1-admin",user="alice",workspace="ws1",workspacerolebinding="ws1-admin"} 1
+				kube_workspace_member_info{role="ws1-admin",user="bob",workspace="ws1",workspacerolebinding="ws1-admin"} 1
+`,
+		},
+		{
+			Obj: &kubesphere.WorkspaceRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "unlabeled",
+				},
+				RoleRef: rbacv1.RoleRef{APIGroup: "iam.kubesphere.io", Kind: "WorkspaceRole", Name: "viewer"},
+			},
+			Want: metadata + `
+				kube_workspacerolebinding_info{role="viewer",workspace="",workspacerolebinding="unlabeled"} 1
+				kube_workspacerolebinding_labels{workspace="",workspacerolebinding="unlabeled"} 1
+`,
+		},
+	}
+
+	for i, c := range cases {
+		c.Func = metric.ComposeMetricGenFuncs(workspaceRoleBindingMetricFamilies)
+		c.Headers = metric.ExtractMetricFamilyHeaders(workspaceRoleBindingMetricFamilies)
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -161,8 +161,8 @@ local k = import 'ksonnet/ksonnet.beta.4/k.libsonnet';
       container.mixin.livenessProbe.httpGet.withPort(8080) +
       container.mixin.livenessProbe.withInitialDelaySeconds(5) +
       container.mixin.livenessProbe.withTimeoutSeconds(5) +
-      container.mixin.readinessProbe.httpGet.withPath('/') +
-      container.mixin.readinessProbe.httpGet.withPort(8081) +
+      container.mixin.readinessProbe.httpGet.withPath('/readyz') +
+      container.mixin.readinessProbe.httpGet.withPort(8080) +
       container.mixin.readinessProbe.withInitialDelaySeconds(5) +
       container.mixin.readinessProbe.withTimeoutSeconds(5);
 
@@ -19,33 +19,62 @@ package main
 import (
 	"context"
 	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"net/url"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/http/httpproxy"
+	"k8s.io/apimachinery/pkg/api/meta"
 	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/transport"
 	"k8s.io/klog"
 
-	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/auth"
+	"k8s.io/kube-state-metrics/pkg/builder"
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+	"k8s.io/kube-state-metrics/pkg/election"
+	"k8s.io/kube-state-metrics/pkg/metric"
 	"k8s.io/kube-state-metrics/pkg/metricshandler"
+	"k8s.io/kube-state-metrics/pkg/offline"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/tracing"
+	"k8s.io/kube-state-metrics/pkg/util/cgroups"
+	"k8s.io/kube-state-metrics/pkg/util/jsonlog"
 	"k8s.io/kube-state-metrics/pkg/util/proc"
+	"k8s.io/kube-state-metrics/pkg/util/tlsconfig"
 	"k8s.io/kube-state-metrics/pkg/version"
 	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
 )
 
 const (
-	metricsPath = "/metrics"
-	healthzPath = "/healthz"
+	metricsPath  = "/metrics"
+	healthzPath  = "/healthz"
+	readyzPath   = "/readyz"
+	livezPath    = "/livez"
+	snapshotPath = "/snapshot"
+	pprofPath    = "/debug/pprof/"
 )
 
 // promLogger implements promhttp.Logger
@@ -62,13 +91,27 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Stop the informers and shut down gracefully on SIGTERM, e.g. during
+	// rolling restarts, so in-flight scrapes are not truncated.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		sig := <-signals
+		klog.Infof("Received %s, shutting down", sig)
+		cancel()
+	}()
+
 	err := opts.Parse()
 	if err != nil {
 		klog.Fatalf("Error: %s", err)
 	}
 
+	if opts.LogFormat == "json" {
+		jsonlog.Enable(os.Stderr)
+	}
+
 	if opts.Version {
-		fmt.Printf("%#v\n", version.GetVersion())
+		fmt.Print(version.GetVersion().Print())
 		os.Exit(0)
 	}
 
@@ -76,7 +119,10 @@ func main() {
 		opts.Usage()
 		os.Exit(0)
 	}
-	storeBuilder := store.NewBuilder()
+	cgroups.SetGOMAXPROCS(cgroups.Root, opts.GOMAXPROCS)
+	cgroups.SetMemoryLimit(cgroups.Root, opts.GOMEMLIMIT, opts.GOMEMLIMITRatio)
+
+	storeBuilder := builder.NewBuilder()
 
 	ksmMetricsRegistry := prometheus.NewRegistry()
 	storeBuilder.WithMetrics(ksmMetricsRegistry)
@@ -90,6 +136,15 @@ func main() {
 		collectors = opts.Collectors.AsSlice()
 	}
 
+	for _, path := range opts.ExternalCollectors {
+		name, err := builder.RegisterExternalCollector(ctx, path)
+		if err != nil {
+			klog.Fatalf("Failed to register external collector: %v", err)
+		}
+		klog.Infof("Using external collector %s (%s)", name, path)
+		collectors = append(collectors, name)
+	}
+
 	if err := storeBuilder.WithEnabledResources(collectors); err != nil {
 		klog.Fatalf("Failed to set up collectors: %v", err)
 	}
@@ -140,69 +195,316 @@ func main() {
 
 	storeBuilder.WithWhiteBlackList(whiteBlackList)
 
-	proc.StartReaper()
+	storeBuilder.WithSharding(opts.Shard, opts.TotalShards)
+	storeBuilder.WithListPageSize(opts.ListPageSize)
+	storeBuilder.WithUseAPIServerCache(opts.UseAPIServerCache)
+	storeBuilder.WithFamilyHeaders(opts.OmitMetricHelp, opts.OmitMetricType)
+	storeBuilder.WithStableMetricsOnly(opts.StableMetricsOnly)
+	storeBuilder.WithDeprecatedMetrics(opts.IncludeDeprecated)
+	customLabels := options.LabelSet{}
+	for name, value := range opts.CustomLabels {
+		customLabels[name] = value
+	}
+	clusterName := opts.ClusterName
+	if opts.ClusterNameFromKubeconfig {
+		clusterName, err = kubeconfigClusterName(opts.Kubeconfig, opts.Context)
+		if err != nil {
+			klog.Fatalf("Failed to determine cluster name: %v", err)
+		}
+	}
+	if clusterName != "" {
+		klog.Infof("Labeling metrics with cluster name %s", clusterName)
+		customLabels[options.ClusterLabel] = clusterName
+	}
+	storeBuilder.WithCustomLabels(customLabels)
+	storeBuilder.WithMetricPrefix(opts.MetricPrefix)
+	storeBuilder.WithContainerReasonWhitelist(opts.ContainerReasonWhitelist)
+	storeBuilder.WithEmptyPodPhaseAsUnknown(opts.EmptyPodPhaseAsUnknown)
+	if opts.Node != "" {
+		klog.Infof("Collecting pods scheduled to node %s", opts.Node)
+		storeBuilder.WithNode(opts.Node)
+	}
 
-	kubeClient, vpaClient, err := createKubeClient(opts.Apiserver, opts.Kubeconfig)
+	// Fail fast on metric families Prometheus would refuse to scrape, e.g.
+	// due to an invalid prefix or custom label name.
+	if err := storeBuilder.Validate(); err != nil {
+		klog.Fatalf("Invalid metric families: %v", err)
+	}
+	if opts.HelpMetrics {
+		if err := writeFamilyDescriptions(os.Stdout, storeBuilder.DescribeFamilies()); err != nil {
+			klog.Fatalf("Failed to print metric families: %v", err)
+		}
+		klog.Flush()
+		os.Exit(0)
+	}
+	if opts.Validate {
+		if err := storeBuilder.SelfCheck(); err != nil {
+			klog.Fatalf("Invalid metrics: %v", err)
+		}
+		klog.Info("Metric families are valid")
+		klog.Flush()
+		os.Exit(0)
+	}
+
+	if opts.Offline() {
+		if err := printOfflineMetrics(ctx, storeBuilder, opts); err != nil {
+			klog.Fatalf("Failed to generate metrics from manifests: %v", err)
+		}
+		klog.Flush()
+		os.Exit(0)
+	}
+
+	// The reaper would reap the external collectors as well, which are
+	// waited for and killed by their collector.
+	if len(opts.ExternalCollectors) == 0 {
+		proc.StartReaper()
+	}
+
+	kubeClient, vpaClient, dynamicClient, err := createKubeClient(opts.Apiserver, opts.Kubeconfig, opts.Context, opts.ProxyURL, opts.KubeAPIQPS, opts.KubeAPIBurst)
 	if err != nil {
 		klog.Fatalf("Failed to create client: %v", err)
 	}
 	storeBuilder.WithKubeClient(kubeClient)
 	storeBuilder.WithVPAClient(vpaClient)
-	storeBuilder.WithSharding(opts.Shard, opts.TotalShards)
+	storeBuilder.WithDynamicClient(dynamicClient)
+	for _, m := range opts.MemberClusters {
+		apiserver, kubeconfig, kubecontext := "", m.Kubeconfig, m.Context
+		if kubeconfig == "" {
+			apiserver, kubeconfig = opts.Apiserver, opts.Kubeconfig
+			if kubecontext == "" {
+				kubecontext = opts.Context
+			}
+		}
+		memberKubeClient, memberVPAClient, memberDynamicClient, err := newKubeClient(apiserver, kubeconfig, kubecontext, opts.ProxyURL, opts.KubeAPIQPS, opts.KubeAPIBurst)
+		if err != nil {
+			klog.Fatalf("Failed to create client of member cluster %s: %v", m.Name, err)
+		}
+		// Unreachable member clusters must not keep the others from being
+		// collected, their stores are reported as degraded instead.
+		if _, err := memberKubeClient.Discovery().ServerVersion(); err != nil {
+			klog.Warningf("Failed to communicate with member cluster %s: %v", m.Name, err)
+		}
+		klog.Infof("Collecting objects of member cluster %s", m.Name)
+		storeBuilder.WithCluster(m.Name, memberKubeClient, memberVPAClient, memberDynamicClient)
+	}
 
 	ksmMetricsRegistry.MustRegister(
 		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
 		prometheus.NewGoCollector(),
+		version.NewCollector(),
 	)
-	go telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort)
+	go telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort, opts.EnableProfiling)
 
-	serveMetrics(ctx, kubeClient, storeBuilder, opts, opts.Host, opts.Port, opts.EnableGZIPEncoding)
+	serveMetrics(ctx, kubeClient, storeBuilder, ksmMetricsRegistry, opts, opts.Host, opts.Port, opts.EnableGZIPEncoding)
+	klog.Infof("Shut down gracefully")
+	klog.Flush()
 }
 
-func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface, vpaclientset.Interface, error) {
-	config, err := clientcmd.BuildConfigFromFlags(apiserver, kubeconfig)
-	if err != nil {
-		return nil, nil, err
+// writeFamilyDescriptions writes a table of the given metric families to w.
+func writeFamilyDescriptions(w io.Writer, descriptions []ksmtypes.FamilyDescription) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLLECTOR\tNAME\tTYPE\tSTABILITY\tLABELS\tEXPOSED\tHELP")
+	for _, d := range descriptions {
+		stability := string(d.StabilityLevel)
+		if stability == "" {
+			stability = string(metric.Stable)
+		}
+		if d.DeprecatedVersion != "" {
+			stability += ", deprecated since " + d.DeprecatedVersion
+		}
+		exposed := "yes"
+		if d.DroppedBy != "" {
+			exposed = "no, dropped by " + d.DroppedBy
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Collector, d.Name, d.Type, stability, strings.Join(d.Labels, ","), exposed, d.Help)
 	}
+	return tw.Flush()
+}
 
-	config.UserAgent = version.GetVersion().String()
-	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
-	config.ContentType = "application/vnd.kubernetes.protobuf"
+// offlineSyncTimeout bounds the time waited for the stores to be populated with
+// the objects read from manifests.
+const offlineSyncTimeout = 30 * time.Second
+
+// printOfflineMetrics prints the metrics of the objects read from the manifests
+// given by --from-file and --from-dir to stdout, without connecting to an
+// apiserver.
+func printOfflineMetrics(ctx context.Context, storeBuilder ksmtypes.BuilderInterface, opts *options.Options) error {
+	objects := offline.NewObjects()
+	if err := objects.ReadPaths(append(opts.FromFiles, opts.FromDirs...)...); err != nil {
+		return err
+	}
+	klog.Infof("Read %d objects from manifests", objects.Len())
 
-	kubeClient, err := clientset.NewForConfig(config)
+	kubeClient, vpaClient, dynamicClient, err := objects.Clients()
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
+	storeBuilder.WithKubeClient(kubeClient)
+	storeBuilder.WithVPAClient(vpaClient)
+	storeBuilder.WithDynamicClient(dynamicClient)
+	storeBuilder.WithContext(ctx)
+	storeBuilder.WithFamilyHooks(offline.RecoverHook(func(name string, obj interface{}, recovered interface{}) {
+		if o, err := meta.Accessor(obj); err == nil {
+			klog.Warningf("Failed to generate %s of %s/%s, possibly due to fields set by the apiserver missing from its manifest: %v", name, o.GetNamespace(), o.GetName(), recovered)
+			return
+		}
+		klog.Warningf("Failed to generate %s: %v", name, recovered)
+	}))
 
-	vpaClient, err := vpaclientset.NewForConfig(config)
+	return offline.WriteMetrics(ctx, storeBuilder.Build(), os.Stdout, offlineSyncTimeout)
+}
+
+// proxyWrapper returns a transport.WrapperFunc sending requests through the
+// given proxy, except for requests to hosts excluded by the NO_PROXY
+// environment variable. Without it, client-go sends requests through the
+// proxies given by the HTTPS_PROXY and HTTP_PROXY environment variables.
+func proxyWrapper(proxy *url.URL) transport.WrapperFunc {
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxy.String(),
+		HTTPSProxy: proxy.String(),
+		NoProxy:    httpproxy.FromEnvironment().NoProxy,
+	}).ProxyFunc()
+
+	return func(rt http.RoundTripper) http.RoundTripper {
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			klog.Warningf("Not using proxy %s, as the transport is of unexpected type %T", proxy.Host, rt)
+			return rt
+		}
+		// Transports are shared between clients, hence they must not be
+		// modified.
+		t = t.Clone()
+		t.Proxy = func(r *http.Request) (*url.URL, error) {
+			return proxyFunc(r.URL)
+		}
+		return t
+	}
+}
+
+func createKubeClient(apiserver string, kubeconfig string, kubecontext string, proxyURL string, qps float32, burst int) (clientset.Interface, vpaclientset.Interface, dynamic.Interface, error) {
+	kubeClient, vpaClient, dynamicClient, err := newKubeClient(apiserver, kubeconfig, kubecontext, proxyURL, qps, burst)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
+
 	// Informers don't seem to do a good job logging error messages when it
 	// can't reach the server, making debugging hard. This makes it easier to
 	// figure out if apiserver is configured incorrectly.
 	klog.Infof("Testing communication with server")
 	v, err := kubeClient.Discovery().ServerVersion()
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "error while trying to communicate with apiserver")
+		return nil, nil, nil, errors.Wrap(err, "error while trying to communicate with apiserver")
 	}
 	klog.Infof("Running with Kubernetes cluster version: v%s.%s. git version: %s. git tree state: %s. commit: %s. platform: %s",
 		v.Major, v.Minor, v.GitVersion, v.GitTreeState, v.GitCommit, v.Platform)
 	klog.Infof("Communication with server successful")
 
-	return kubeClient, vpaClient, nil
+	return kubeClient, vpaClient, dynamicClient, nil
+}
+
+// newKubeClient creates the clients of the given apiserver without testing
+// the communication with it.
+func newKubeClient(apiserver string, kubeconfig string, kubecontext string, proxyURL string, qps float32, burst int) (clientset.Interface, vpaclientset.Interface, dynamic.Interface, error) {
+	config, err := buildConfig(apiserver, kubeconfig, kubecontext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if proxyURL != "" {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// The proxy must wrap the transport before any authentication
+		// wrappers, which hide the underlying *http.Transport.
+		config.WrapTransport = transport.Wrappers(proxyWrapper(proxy), config.WrapTransport)
+	}
+
+	config.UserAgent = version.GetVersion().String()
+	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
+	config.ContentType = "application/vnd.kubernetes.protobuf"
+	config.QPS = qps
+	config.Burst = burst
+
+	kubeClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	vpaClient, err := vpaclientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return kubeClient, vpaClient, dynamicClient, nil
+}
+
+// buildConfig builds the client config from the given apiserver URL and
+// kubeconfig file, using the given kubeconfig context if set.
+func buildConfig(apiserver string, kubeconfig string, kubecontext string) (*rest.Config, error) {
+	if kubecontext == "" {
+		return clientcmd.BuildConfigFromFlags(apiserver, kubeconfig)
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules = &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}
+	}
+	overrides := &clientcmd.ConfigOverrides{
+		ClusterInfo:    clientcmdapi.Cluster{Server: apiserver},
+		CurrentContext: kubecontext,
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
 }
 
-func telemetryServer(registry prometheus.Gatherer, host string, port int) {
-	// Address to listen on for web interface and telemetry
-	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
+// kubeconfigClusterName returns the name of the cluster of the given kubeconfig
+// context, or of the current context if none is given.
+func kubeconfigClusterName(kubeconfig string, kubecontext string) (string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules = &clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig}
+	}
+	config, err := loadingRules.Load()
+	if err != nil {
+		return "", err
+	}
+
+	if kubecontext == "" {
+		kubecontext = config.CurrentContext
+	}
+	kubeContext, ok := config.Contexts[kubecontext]
+	if !ok || kubeContext.Cluster == "" {
+		return "", errors.Errorf("no cluster found for kubeconfig context %q", kubecontext)
+	}
+	return kubeContext.Cluster, nil
+}
 
-	klog.Infof("Starting kube-state-metrics self metrics server: %s", listenAddress)
+func telemetryServer(registry prometheus.Gatherer, hosts string, port int, enableProfiling bool) {
+	// Addresses to listen on for web interface and telemetry
+	listenAddresses := joinHostsPort(hosts, port)
+
+	klog.Infof("Starting kube-state-metrics self metrics server: %s", strings.Join(listenAddresses, ", "))
 
 	mux := http.NewServeMux()
 
 	// Add metricsPath
 	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: promLogger{}}))
+	// Add pprofPath
+	index := ""
+	if enableProfiling {
+		klog.Infof("Serving profiles under %s", pprofPath)
+		mux.Handle(pprofPath, http.HandlerFunc(pprof.Index))
+		mux.Handle(pprofPath+"cmdline", http.HandlerFunc(pprof.Cmdline))
+		mux.Handle(pprofPath+"profile", http.HandlerFunc(pprof.Profile))
+		mux.Handle(pprofPath+"symbol", http.HandlerFunc(pprof.Symbol))
+		mux.Handle(pprofPath+"trace", http.HandlerFunc(pprof.Trace))
+		index = `
+             <li><a href='` + pprofPath + `'>pprof</a></li>`
+	}
 	// Add index
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
@@ -210,28 +512,36 @@ func telemetryServer(registry prometheus.Gatherer, host string, port int) {
              <body>
              <h1>Kube-State-Metrics Metrics</h1>
 			 <ul>
-             <li><a href='` + metricsPath + `'>metrics</a></li>
+             <li><a href='` + metricsPath + `'>metrics</a></li>` + index + `
 			 </ul>
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+
+	listeners, err := listen(listenAddresses)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := &http.Server{Handler: mux}
+	var serves []func() error
+	for _, l := range listeners {
+		l := l
+		serves = append(serves, func() error { return server.Serve(l) })
+	}
+	log.Fatal(serveAll(serves...))
 }
 
-func serveMetrics(ctx context.Context, kubeClient clientset.Interface, storeBuilder *store.Builder, opts *options.Options, host string, port int, enableGZIPEncoding bool) {
-	// Address to listen on for web interface and telemetry
-	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
+// otlpTracesTimeout bounds the time an export of traces to the OTLP endpoint
+// may take.
+const otlpTracesTimeout = 10 * time.Second
 
-	klog.Infof("Starting metrics server: %s", listenAddress)
+func serveMetrics(ctx context.Context, kubeClient clientset.Interface, storeBuilder ksmtypes.BuilderInterface, registry prometheus.Registerer, opts *options.Options, hosts string, port int, enableGZIPEncoding bool) {
+	// Addresses to listen on for web interface and telemetry
+	listenAddresses := joinHostsPort(hosts, port)
 
-	mux := http.NewServeMux()
+	klog.Infof("Starting metrics server: %s", strings.Join(listenAddresses, ", "))
 
-	// TODO: This doesn't belong into serveMetrics
-	mux.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
-	mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
-	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	mux := http.NewServeMux()
 
 	m := metricshandler.New(
 		opts,
@@ -239,26 +549,355 @@ func serveMetrics(ctx context.Context, kubeClient clientset.Interface, storeBuil
 		storeBuilder,
 		enableGZIPEncoding,
 	)
+	m.WithMetrics(registry)
+	if opts.OTLPTracesEndpoint != "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			klog.Fatalf("Failed to determine OTLP service instance: %v", err)
+		}
+		klog.Infof("Exporting traces to %s", opts.OTLPTracesEndpoint)
+		tracer := tracing.New(&http.Client{Timeout: otlpTracesTimeout}, opts.OTLPTracesEndpoint, hostname)
+		storeBuilder.WithTracer(tracer)
+		m.WithTracer(tracer)
+		go tracer.Run(ctx)
+	}
 	go m.Run(ctx)
-	mux.Handle(metricsPath, m)
+	if opts.LeaderElect {
+		hostname, err := os.Hostname()
+		if err != nil {
+			klog.Fatalf("Failed to determine leader election identity: %v", err)
+		}
+		m.SetStandby(true)
+		go func() {
+			err := election.Run(ctx, kubeClient, election.Config{
+				Namespace:     opts.LeaderElectNamespace,
+				Name:          opts.LeaderElectLeaseName,
+				Identity:      hostname,
+				LeaseDuration: opts.LeaderElectLeaseDuration,
+				RenewDeadline: opts.LeaderElectRenewDeadline,
+				RetryPeriod:   opts.LeaderElectRetryPeriod,
+			}, func() { m.SetStandby(false) }, func() { m.SetStandby(true) })
+			if err != nil && err != context.Canceled {
+				klog.Fatalf("Failed to run leader election: %v", err)
+			}
+		}()
+	}
+	if opts.PushgatewayURL != "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			klog.Fatalf("Failed to determine Pushgateway instance: %v", err)
+		}
+		klog.Infof("Pushing metrics to %s every %s", opts.PushgatewayURL, opts.PushgatewayInterval)
+		go m.Push(ctx, &http.Client{Timeout: opts.PushgatewayInterval}, opts.PushgatewayURL, opts.PushgatewayJob, hostname, opts.PushgatewayInterval)
+	}
+	if opts.OTLPEndpoint != "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			klog.Fatalf("Failed to determine OTLP service instance: %v", err)
+		}
+		klog.Infof("Exporting metrics to %s every %s", opts.OTLPEndpoint, opts.OTLPInterval)
+		go m.ExportOTLP(ctx, &http.Client{Timeout: opts.OTLPInterval}, opts.OTLPEndpoint, hostname, opts.OTLPInterval)
+	}
+	if opts.BridgeProtocol != "" {
+		families, err := whiteblacklist.New(opts.BridgeMetricFamilies, nil)
+		if err != nil {
+			klog.Fatal(err)
+		}
+		if err := families.Parse(); err != nil {
+			klog.Fatalf("error initializing the bridge metric families: %v", err)
+		}
+		klog.Infof("Sending metrics to %s endpoint %s every %s", opts.BridgeProtocol, opts.BridgeAddress, opts.BridgeInterval)
+		go m.Bridge(ctx, opts.BridgeProtocol, opts.BridgeAddress, opts.BridgePrefix, families, opts.BridgeInterval)
+	}
+	// Authentication, if configured, applies to all endpoints exposing
+	// metrics.
+	var protect []func(http.Handler) http.Handler
+	if opts.EnableDelegatedAuth {
+		resource, subresource := opts.AuthResource, ""
+		if i := strings.Index(resource, "/"); i >= 0 {
+			resource, subresource = resource[:i], resource[i+1:]
+		}
+		authorizer := auth.NewAuthorizer(kubeClient, auth.Resource{
+			Namespace:   opts.AuthResourceNamespace,
+			Group:       opts.AuthResourceGroup,
+			Resource:    resource,
+			Subresource: subresource,
+			Name:        opts.AuthResourceName,
+			Verb:        opts.AuthVerb,
+		})
+		klog.Infof("Authenticating and authorizing scrapes against the Kubernetes apiserver")
+		protect = append(protect, authorizer.Handler)
+	}
+	if opts.BasicAuthUsername != "" {
+		password, err := ioutil.ReadFile(opts.BasicAuthPasswordFile)
+		if err != nil {
+			klog.Fatalf("Failed to read basic auth password: %v", err)
+		}
+		klog.Infof("Authenticating scrapes with HTTP basic auth")
+		protect = append(protect, auth.NewBasicAuth(opts.BasicAuthUsername, strings.TrimRight(string(password), "\r\n")).Handler)
+	}
+	if opts.BasicAuthHtpasswdFile != "" {
+		basicAuth, err := auth.LoadHtpasswd(opts.BasicAuthHtpasswdFile)
+		if err != nil {
+			klog.Fatalf("Failed to load htpasswd file: %v", err)
+		}
+		klog.Infof("Authenticating scrapes with HTTP basic auth")
+		protect = append(protect, basicAuth.Handler)
+	}
+	var handler http.Handler = m
+	var snapshotHandler http.Handler = http.HandlerFunc(m.ServeSnapshot)
+	for _, p := range protect {
+		handler = p(handler)
+		snapshotHandler = p(snapshotHandler)
+	}
+	if err := handleMetrics(mux, opts.MetricsPaths, instrumentHandler(registry, handler)); err != nil {
+		klog.Fatal(err)
+	}
+	// Add snapshotPath, serving the current metrics as JSON.
+	mux.Handle(snapshotPath, snapshotHandler)
 
 	// Add healthzPath
 	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(http.StatusText(http.StatusOK)))
 	})
+	// Add readyzPath, which only reports ready once all collectors have
+	// synced, so partial data is not served right after startup.
+	mux.HandleFunc(readyzPath, func(w http.ResponseWriter, r *http.Request) {
+		if ctx.Err() != nil {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		if !m.HasSynced() {
+			http.Error(w, "collectors not synced", http.StatusServiceUnavailable)
+			return
+		}
+		// Degraded collectors, whose lists or watches fail, keep serving
+		// their last known state, hence they are reported without failing.
+		degraded := m.Degraded()
+		names := make([]string, 0, len(degraded))
+		for name := range degraded {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		w.WriteHeader(http.StatusOK)
+		for _, name := range names {
+			if degraded[name] {
+				fmt.Fprintf(w, "[-]%s degraded\n", name)
+			} else {
+				fmt.Fprintf(w, "[+]%s ok\n", name)
+			}
+		}
+		w.Write([]byte(http.StatusText(http.StatusOK)))
+	})
+	// Add livezPath, which fails if any collector's watch has been stale for
+	// too long, so a wedged reflector results in a restart.
+	mux.HandleFunc(livezPath, func(w http.ResponseWriter, r *http.Request) {
+		if opts.WatchStalenessThreshold > 0 && m.IsStale(opts.WatchStalenessThreshold) {
+			http.Error(w, "collectors stale", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(http.StatusText(http.StatusOK)))
+	})
 	// Add index
+	metricsLinks := ""
+	for _, p := range opts.MetricsPaths {
+		metricsLinks += `
+             <li><a href='` + html.EscapeString(p) + `'>metrics</a></li>`
+	}
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
              <head><title>Kube Metrics Server</title></head>
              <body>
              <h1>Kube Metrics</h1>
-			 <ul>
-             <li><a href='` + metricsPath + `'>metrics</a></li>
+			 <ul>` + metricsLinks + `
              <li><a href='` + healthzPath + `'>healthz</a></li>
+             <li><a href='` + readyzPath + `'>readyz</a></li>
+             <li><a href='` + livezPath + `'>livez</a></li>
+             <li><a href='` + snapshotPath + `'>snapshot</a></li>
 			 </ul>
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+
+	// Timeouts keep slow or stalled scrapers from holding connections, and
+	// thereby file descriptors, indefinitely.
+	server := &http.Server{
+		Handler:           mux,
+		ReadTimeout:       opts.ServerReadTimeout,
+		ReadHeaderTimeout: opts.ServerReadHeaderTimeout,
+		WriteTimeout:      opts.ServerWriteTimeout,
+		IdleTimeout:       opts.ServerIdleTimeout,
+		MaxHeaderBytes:    opts.ServerMaxHeaderBytes,
+	}
+
+	// Once ctx is done, i.e. the informers have been stopped, stop accepting
+	// new connections and give in-flight scrapes time to finish.
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+
+		klog.Infof("Shutting down metrics server, draining in-flight scrapes for up to %s", opts.ShutdownDrainTimeout)
+		drainCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownDrainTimeout)
+		defer cancel()
+		if err := server.Shutdown(drainCtx); err != nil {
+			klog.Errorf("Failed to drain in-flight scrapes: %v", err)
+		}
+	}()
+
+	listeners, err := listen(listenAddresses)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.TLSCertFile != "" {
+		server.TLSConfig, err = tlsconfig.NewServerConfig(opts.TLSCertFile, opts.TLSKeyFile, opts.TLSClientCAFile)
+		if err != nil {
+			klog.Fatalf("Failed to configure TLS: %v", err)
+		}
+		klog.Infof("Serving metrics over TLS")
+	}
+
+	// Serving without TLS may initialize server.TLSConfig, hence it must not
+	// be consulted once serving has started.
+	var serves []func() error
+	for _, l := range listeners {
+		l := l
+		if opts.TLSCertFile != "" {
+			serves = append(serves, func() error { return server.ServeTLS(l, "", "") })
+		} else {
+			serves = append(serves, func() error { return server.Serve(l) })
+		}
+	}
+
+	// The Unix socket is only reachable from within the pod, hence it is
+	// served without TLS.
+	if opts.ListenSocket != "" {
+		socket, err := listenSocket(opts.ListenSocket)
+		if err != nil {
+			klog.Fatalf("Failed to listen on Unix socket: %v", err)
+		}
+		klog.Infof("Serving metrics on Unix socket %s", opts.ListenSocket)
+		serves = append(serves, func() error { return server.Serve(socket) })
+	}
+
+	if err := serveAll(serves...); err != nil {
+		log.Fatal(err)
+	}
+	<-drained
+}
+
+// joinHostsPort returns the addresses to listen on for the comma-separated
+// list of hosts and the port, e.g. 10.0.0.1:8080 and [fd00::1]:8080.
+func joinHostsPort(hosts string, port int) []string {
+	var addresses []string
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(host), "["), "]")
+		addresses = append(addresses, net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+	return addresses
+}
+
+// listen listens on all the given TCP addresses.
+func listen(addresses []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, address := range addresses {
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// serveAll runs all serve functions concurrently until they return. It
+// returns the first error other than http.ErrServerClosed right away, and nil
+// once all of them returned http.ErrServerClosed.
+func serveAll(serves ...func() error) error {
+	errs := make(chan error, len(serves))
+	for _, serve := range serves {
+		go func(serve func() error) { errs <- serve() }(serve)
+	}
+	for range serves {
+		if err := <-errs; err != http.ErrServerClosed {
+			return err
+		}
+	}
+	return nil
+}
+
+// listenSocket listens on the Unix socket at path, replacing a socket left
+// behind by a previous process. The socket is removed once the listener is
+// closed.
+func listenSocket(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// handleMetrics registers handler at each of the given paths, which must not
+// be the path of another endpoint of the metrics server.
+func handleMetrics(mux *http.ServeMux, paths []string, handler http.Handler) error {
+	for _, p := range paths {
+		switch p {
+		case healthzPath, readyzPath, livezPath, snapshotPath:
+			return fmt.Errorf("metrics path %s is already the path of another endpoint", p)
+		}
+		mux.Handle(p, handler)
+	}
+	return nil
+}
+
+// instrumentHandler instruments the given handler of the metrics endpoint
+// with request metrics, which are exposed by the telemetry server.
+func instrumentHandler(registry prometheus.Registerer, handler http.Handler) http.Handler {
+	requests := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_http_requests_total",
+			Help: "Number of requests to the metrics endpoint by status code and method.",
+		},
+		[]string{"code", "method"},
+	)
+	duration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kube_state_metrics_http_request_duration_seconds",
+			Help:    "Duration of requests to the metrics endpoint by status code and method.",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		},
+		[]string{"code", "method"},
+	)
+	inFlight := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_http_requests_in_flight",
+			Help: "Number of requests to the metrics endpoint currently being served.",
+		},
+	)
+	size := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kube_state_metrics_http_response_size_bytes",
+			Help:    "Size of responses of the metrics endpoint by status code and method.",
+			Buckets: prometheus.ExponentialBuckets(1024, 4, 10),
+		},
+		[]string{"code", "method"},
+	)
+	registry.MustRegister(requests, duration, inFlight, size)
+
+	return promhttp.InstrumentHandlerInFlight(inFlight,
+		promhttp.InstrumentHandlerDuration(duration,
+			promhttp.InstrumentHandlerCounter(requests,
+				promhttp.InstrumentHandlerResponseSize(size, handler),
+			),
+		),
+	)
 }
@@ -19,8 +19,15 @@ package main
 import (
 	"bytes"
 	"context"
+	"io"
 	"io/ioutil"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,16 +35,21 @@ import (
 	"time"
 
 	"k8s.io/kube-state-metrics/internal/store"
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+	"k8s.io/kube-state-metrics/pkg/metric"
 	"k8s.io/kube-state-metrics/pkg/metricshandler"
 	"k8s.io/kube-state-metrics/pkg/options"
 	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+	"k8s.io/kube-state-metrics/tests/synthetic"
 
 	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 func BenchmarkKubeStateMetrics(b *testing.B) {
@@ -108,6 +120,68 @@ func BenchmarkKubeStateMetrics(b *testing.B) {
 	})
 }
 
+// BenchmarkSyntheticClusters measures the time until the metrics of all
+// objects of synthetic clusters of different sizes are generated, as well as
+// the latency of subsequent scrapes.
+func BenchmarkSyntheticClusters(b *testing.B) {
+	l, err := whiteblacklist.New(map[string]struct{}{}, map[string]struct{}{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range synthetic.Clusters {
+		kubeClient := c.NewClientset()
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+		var handler *metricshandler.MetricsHandler
+		// The reflectors of the last handler are kept running for the scrape
+		// benchmark.
+		stop := func() {}
+
+		b.Run(c.Name+"/GenerateMetrics", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				stop()
+				var ctx context.Context
+				ctx, stop = context.WithCancel(context.Background())
+
+				builder := store.NewBuilder()
+				builder.WithMetrics(prometheus.NewRegistry())
+				builder.WithEnabledResources([]string{"deployments", "nodes", "pods"})
+				builder.WithKubeClient(kubeClient)
+				builder.WithNamespaces(options.DefaultNamespaces)
+				builder.WithWhiteBlackList(l)
+
+				handler = metricshandler.New(&options.Options{}, kubeClient, builder, false)
+				handler.ConfigureSharding(ctx, 0, 1)
+
+				// Wait until the metrics of all pods have been generated.
+				for {
+					w := httptest.NewRecorder()
+					handler.ServeHTTP(w, req)
+					if strings.Count(w.Body.String(), "\nkube_pod_info{") == c.Pods() {
+						break
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+		})
+
+		b.Run(c.Name+"/Scrape", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+				if w.Code != 200 {
+					b.Fatalf("expected 200 status code but got %v", w.Code)
+				}
+				b.SetBytes(int64(w.Body.Len()))
+			}
+		})
+
+		stop()
+	}
+}
+
 // TestFullScrapeCycle is a simple smoke test covering the entire cycle from
 // cache filling to scraping.
 func TestFullScrapeCycle(t *testing.T) {
@@ -293,19 +367,19 @@ kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod
 kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod1_con1",node="node1",resource="storage",unit="byte"} 4e+08
 kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod1_con2",node="node1",resource="memory",unit="byte"} 2e+08
 kube_pod_container_resource_limits{namespace="default",pod="pod0",container="pod1_con2",node="node1",resource="cpu",unit="core"} 0.3
-# HELP kube_pod_container_resource_requests_cpu_cores The number of requested cpu cores by a container.
+# HELP kube_pod_container_resource_requests_cpu_cores (Deprecated since 1.4.0) The number of requested cpu cores by a container.
 # TYPE kube_pod_container_resource_requests_cpu_cores gauge
 kube_pod_container_resource_requests_cpu_cores{namespace="default",pod="pod0",container="pod1_con1",node="node1"} 0.2
 kube_pod_container_resource_requests_cpu_cores{namespace="default",pod="pod0",container="pod1_con2",node="node1"} 0.3
-# HELP kube_pod_container_resource_requests_memory_bytes The number of requested memory bytes by a container.
+# HELP kube_pod_container_resource_requests_memory_bytes (Deprecated since 1.4.0) The number of requested memory bytes by a container.
 # TYPE kube_pod_container_resource_requests_memory_bytes gauge
 kube_pod_container_resource_requests_memory_bytes{namespace="default",pod="pod0",container="pod1_con1",node="node1"} 1e+08
 kube_pod_container_resource_requests_memory_bytes{namespace="default",pod="pod0",container="pod1_con2",node="node1"} 2e+08
-# HELP kube_pod_container_resource_limits_cpu_cores The limit on cpu cores to be used by a container.
+# HELP kube_pod_container_resource_limits_cpu_cores (Deprecated since 1.4.0) The limit on cpu cores to be used by a container.
 # TYPE kube_pod_container_resource_limits_cpu_cores gauge
 kube_pod_container_resource_limits_cpu_cores{namespace="default",pod="pod0",container="pod1_con1",node="node1"} 0.2
 kube_pod_container_resource_limits_cpu_cores{namespace="default",pod="pod0",container="pod1_con2",node="node1"} 0.3
-# HELP kube_pod_container_resource_limits_memory_bytes The limit on memory to be used by a container in bytes.
+# HELP kube_pod_container_resource_limits_memory_bytes (Deprecated since 1.4.0) The limit on memory to be used by a container in bytes.
 # TYPE kube_pod_container_resource_limits_memory_bytes gauge
 kube_pod_container_resource_limits_memory_bytes{namespace="default",pod="pod0",container="pod1_con1",node="node1"} 1e+08
 kube_pod_container_resource_limits_memory_bytes{namespace="default",pod="pod0",container="pod1_con2",node="node1"} 2e+08
@@ -496,6 +570,173 @@ func TestShardingEquivalenceScrapeCycle(t *testing.T) {
 	}
 }
 
+func TestInstrumentHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := instrumentHandler(reg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "kube_state_metrics_http_requests_total" {
+			continue
+		}
+		m := f.GetMetric()[0]
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["code"] != "503" || labels["method"] != "get" || m.GetCounter().GetValue() != 2 {
+			t.Errorf("unexpected request metric: %v", m)
+		}
+		return
+	}
+	t.Error("expected kube_state_metrics_http_requests_total to be registered")
+}
+
+func TestHandleMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	err := handleMetrics(mux, []string{"/metrics", "/kapis/monitoring/metrics"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "metrics")
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, path := range []string{"/metrics", "/kapis/monitoring/metrics"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != "metrics" {
+			t.Errorf("%s: expected metrics to be served, got %d %q", path, w.Code, w.Body.String())
+		}
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/kapis/monitoring", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected other paths not to be served, got %d", w.Code)
+	}
+
+	if err := handleMetrics(http.NewServeMux(), []string{"/metrics", healthzPath}, http.NotFoundHandler()); err == nil {
+		t.Error("expected error for the path of another endpoint")
+	}
+}
+
+func TestBuildConfigContext(t *testing.T) {
+	kubeconfig, err := ioutil.TempFile("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kubeconfig.Name())
+
+	_, err = kubeconfig.WriteString(`apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: https://local.example.com
+- name: remote
+  cluster:
+    server: https://remote.example.com
+contexts:
+- name: local
+  context:
+    cluster: local
+- name: remote
+  context:
+    cluster: remote
+current-context: local
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig.Close()
+
+	tests := []struct {
+		desc       string
+		apiserver  string
+		context    string
+		wantServer string
+		wantErr    bool
+	}{
+		{desc: "current context", wantServer: "https://local.example.com"},
+		{desc: "selected context", context: "remote", wantServer: "https://remote.example.com"},
+		{desc: "apiserver overrides context", apiserver: "https://other.example.com", context: "remote", wantServer: "https://other.example.com"},
+		{desc: "unknown context", context: "unknown", wantErr: true},
+	}
+
+	for _, test := range tests {
+		config, err := buildConfig(test.apiserver, kubeconfig.Name(), test.context)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("%s: expected error to be %v but got %v", test.desc, test.wantErr, err)
+		}
+		if test.wantErr {
+			continue
+		}
+		if config.Host != test.wantServer {
+			t.Errorf("%s: expected server %s but got %s", test.desc, test.wantServer, config.Host)
+		}
+	}
+}
+
+func TestKubeconfigClusterName(t *testing.T) {
+	kubeconfig, err := ioutil.TempFile("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(kubeconfig.Name())
+
+	_, err = kubeconfig.WriteString(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod-eu
+  cluster:
+    server: https://eu.example.com
+- name: prod-us
+  cluster:
+    server: https://us.example.com
+contexts:
+- name: admin@eu
+  context:
+    cluster: prod-eu
+- name: admin@us
+  context:
+    cluster: prod-us
+current-context: admin@eu
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig.Close()
+
+	tests := []struct {
+		desc        string
+		context     string
+		wantCluster string
+		wantErr     bool
+	}{
+		{desc: "current context", wantCluster: "prod-eu"},
+		{desc: "selected context", context: "admin@us", wantCluster: "prod-us"},
+		{desc: "unknown context", context: "unknown", wantErr: true},
+	}
+
+	for _, test := range tests {
+		cluster, err := kubeconfigClusterName(kubeconfig.Name(), test.context)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("%s: expected error to be %v but got %v", test.desc, test.wantErr, err)
+		}
+		if cluster != test.wantCluster {
+			t.Errorf("%s: expected cluster %q but got %q", test.desc, test.wantCluster, cluster)
+		}
+	}
+}
+
 func injectFixtures(client *fake.Clientset, multiplier int) error {
 	creators := []func(*fake.Clientset, int) error{
 		configMap,
@@ -626,3 +867,169 @@ func pod(client *fake.Clientset, index int) error {
 	_, err := client.CoreV1().Pods(metav1.NamespaceDefault).Create(&pod)
 	return err
 }
+
+func TestProxyWrapper(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"17"}`))
+	}))
+	defer proxy.Close()
+
+	os.Setenv("NO_PROXY", "internal.example.com")
+	defer os.Unsetenv("NO_PROXY")
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrapper := proxyWrapper(proxyURL)
+
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: "http://apiserver.example.com", WrapTransport: wrapper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kubeClient.Discovery().ServerVersion(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(proxied) != 1 || !strings.HasPrefix(proxied[0], "http://apiserver.example.com/version") {
+		t.Errorf("expected the version request to be sent through the proxy but got %v", proxied)
+	}
+
+	rt := wrapper(http.DefaultTransport).(*http.Transport)
+	if rt == http.DefaultTransport {
+		t.Error("expected the shared transport not to be modified")
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://internal.example.com/version", nil)
+	if u, err := rt.Proxy(req); err != nil || u != nil {
+		t.Errorf("expected hosts excluded by NO_PROXY not to be proxied, got %v, %v", u, err)
+	}
+}
+
+func TestListenSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Leave a socket behind, like a killed process would.
+	path := filepath.Join(dir, "metrics.sock")
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l, err := listenSocket(path)
+	if err != nil {
+		t.Fatalf("expected stale socket to be replaced, got %v", err)
+	}
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("metrics"))
+	}))
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/metrics")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "metrics" {
+		t.Errorf("expected body metrics but got %q", body)
+	}
+
+	l.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected socket to be removed on close, got %v", err)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenSocket(file); err == nil {
+		t.Error("expected error for existing file which is not a socket")
+	}
+}
+
+func TestWriteFamilyDescriptions(t *testing.T) {
+	var b bytes.Buffer
+	err := writeFamilyDescriptions(&b, []ksmtypes.FamilyDescription{
+		{Collector: "pods", Name: "kube_pod_info", Help: "Information about pod.", Type: metric.Info, Labels: []string{"namespace", "pod"}},
+		{Collector: "pods", Name: "kube_pod_labels", Help: "Kubernetes labels converted to Prometheus labels.", Type: metric.Gauge, Labels: []string{"namespace", "pod", "label_*"}, DroppedBy: "--metric-blacklist"},
+		{Collector: "nodes", Name: "kube_node_role", Help: "The role of a cluster node.", Type: metric.Gauge, StabilityLevel: metric.Experimental, DeprecatedVersion: "1.4.0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `COLLECTOR  NAME             TYPE   STABILITY                             LABELS                 EXPOSED                            HELP
+pods       kube_pod_info    info   STABLE                                namespace,pod          yes                                Information about pod.
+pods       kube_pod_labels  gauge  STABLE                                namespace,pod,label_*  no, dropped by --metric-blacklist  Kubernetes labels converted to Prometheus labels.
+nodes      kube_node_role   gauge  EXPERIMENTAL, deprecated since 1.4.0                         yes                                The role of a cluster node.
+`
+	if got := b.String(); got != want {
+		t.Errorf("expected\n%s\nbut got\n%s", want, got)
+	}
+}
+
+func TestJoinHostsPort(t *testing.T) {
+	tests := []struct {
+		hosts string
+		want  []string
+	}{
+		{hosts: "", want: []string{":8080"}},
+		{hosts: "0.0.0.0", want: []string{"0.0.0.0:8080"}},
+		{hosts: "10.0.0.1, fd00::1", want: []string{"10.0.0.1:8080", "[fd00::1]:8080"}},
+		{hosts: "[::1],localhost", want: []string{"[::1]:8080", "localhost:8080"}},
+	}
+
+	for _, test := range tests {
+		if got := joinHostsPort(test.hosts, 8080); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: expected %v but got %v", test.hosts, test.want, got)
+		}
+	}
+}
+
+func TestServeMultipleAddresses(t *testing.T) {
+	listeners, err := listen([]string{"127.0.0.1:0", "[::1]:0"})
+	if err != nil {
+		t.Skipf("IPv4 and IPv6 loopback addresses not available: %v", err)
+	}
+
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("metrics"))
+	})}
+	var serves []func() error
+	for _, l := range listeners {
+		l := l
+		serves = append(serves, func() error { return server.Serve(l) })
+	}
+	done := make(chan error)
+	go func() { done <- serveAll(serves...) }()
+
+	for _, l := range listeners {
+		resp, err := http.Get("http://" + l.Addr().String())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "metrics" {
+			t.Errorf("%s: expected body metrics but got %q", l.Addr(), body)
+		}
+	}
+
+	server.Shutdown(context.Background())
+	if err := <-done; err != nil {
+		t.Errorf("expected no error once the server is shut down but got %v", err)
+	}
+}
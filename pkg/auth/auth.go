@@ -0,0 +1,203 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package auth authenticates and authorizes requests to the metrics endpoint,
+// either with HTTP basic auth or by delegating to the Kubernetes apiserver,
+// using TokenReviews and SubjectAccessReviews.
+package auth
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+const (
+	// cacheTTL is the duration for which decisions are cached per token, so
+	// every scrape does not cause two requests to the apiserver.
+	cacheTTL = time.Minute
+	// maxCacheSize bounds the number of cached decisions.
+	maxCacheSize = 1024
+)
+
+// Resource describes the resource requests are authorized against. If
+// Resource is empty, requests are authorized against their non-resource URL
+// path instead, e.g. /metrics.
+type Resource struct {
+	Namespace   string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	Verb        string
+}
+
+// Authorizer authenticates requests by the bearer token they present and
+// authorizes the authenticated users against a Resource.
+type Authorizer struct {
+	client   clientset.Interface
+	resource Resource
+
+	mtx   sync.Mutex
+	cache map[[sha256.Size]byte]decision
+}
+
+type decision struct {
+	status  int
+	expires time.Time
+}
+
+// NewAuthorizer returns a new Authorizer reviewing tokens and access with the
+// given client.
+func NewAuthorizer(client clientset.Interface, resource Resource) *Authorizer {
+	if resource.Verb == "" {
+		resource.Verb = "get"
+	}
+
+	return &Authorizer{
+		client:   client,
+		resource: resource,
+		cache:    map[[sha256.Size]byte]decision{},
+	}
+}
+
+// Handler returns a http.Handler only passing authenticated and authorized
+// requests on to next. Other requests are answered with 401 Unauthorized or
+// 403 Forbidden respectively.
+func (a *Authorizer) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := a.authorize(r)
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize returns the HTTP status code resulting from authenticating and
+// authorizing r.
+func (a *Authorizer) authorize(r *http.Request) int {
+	token := bearerToken(r)
+	if token == "" {
+		return http.StatusUnauthorized
+	}
+
+	key := sha256.Sum256([]byte(r.URL.Path + "\x00" + token))
+
+	a.mtx.Lock()
+	d, ok := a.cache[key]
+	a.mtx.Unlock()
+	if ok && time.Now().Before(d.expires) {
+		return d.status
+	}
+
+	status, cacheable := a.review(r, token)
+	if cacheable {
+		a.mtx.Lock()
+		a.store(key, decision{status: status, expires: time.Now().Add(cacheTTL)})
+		a.mtx.Unlock()
+	}
+
+	return status
+}
+
+// review reviews token and the access of its user. Decisions are not
+// cacheable if the apiserver could not be reached.
+func (a *Authorizer) review(r *http.Request, token string) (int, bool) {
+	tr, err := a.client.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		klog.Errorf("failed to review token: %v", err)
+		return http.StatusInternalServerError, false
+	}
+	if !tr.Status.Authenticated {
+		return http.StatusUnauthorized, true
+	}
+
+	user := tr.Status.User
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  map[string]authorizationv1.ExtraValue{},
+		},
+	}
+	for k, v := range user.Extra {
+		sar.Spec.Extra[k] = authorizationv1.ExtraValue(v)
+	}
+	if a.resource.Resource != "" {
+		sar.Spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   a.resource.Namespace,
+			Verb:        a.resource.Verb,
+			Group:       a.resource.Group,
+			Resource:    a.resource.Resource,
+			Subresource: a.resource.Subresource,
+			Name:        a.resource.Name,
+		}
+	} else {
+		sar.Spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: r.URL.Path,
+			Verb: a.resource.Verb,
+		}
+	}
+
+	sar, err = a.client.AuthorizationV1().SubjectAccessReviews().Create(sar)
+	if err != nil {
+		klog.Errorf("failed to review access of user %s: %v", user.Username, err)
+		return http.StatusInternalServerError, false
+	}
+	if !sar.Status.Allowed {
+		klog.V(4).Infof("denied access of user %s: %s", user.Username, sar.Status.Reason)
+		return http.StatusForbidden, true
+	}
+
+	return http.StatusOK, true
+}
+
+// store caches d under key. It must be called with a.mtx held.
+func (a *Authorizer) store(key [sha256.Size]byte, d decision) {
+	if len(a.cache) >= maxCacheSize {
+		now := time.Now()
+		for k, cached := range a.cache {
+			if now.After(cached.expires) {
+				delete(a.cache, k)
+			}
+		}
+		if len(a.cache) >= maxCacheSize {
+			return
+		}
+	}
+	a.cache[key] = d
+}
+
+func bearerToken(r *http.Request) string {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
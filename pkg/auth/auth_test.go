@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// reviewingClientset returns a fake clientset authenticating the token
+// "valid" as user "prometheus" and allowing access to the given user only.
+func reviewingClientset(allowedUser string, reviews *[]*authorizationv1.SubjectAccessReview) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		tr := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if tr.Spec.Token == "broken" {
+			return true, &authenticationv1.TokenReview{}, errors.New("apiserver unavailable")
+		}
+		if tr.Spec.Token == "valid" {
+			tr.Status.Authenticated = true
+			tr.Status.User = authenticationv1.UserInfo{Username: "prometheus", Groups: []string{"monitoring"}}
+		}
+		return true, tr, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		*reviews = append(*reviews, sar.DeepCopy())
+		sar.Status.Allowed = sar.Spec.User == allowedUser
+		return true, sar, nil
+	})
+	return client
+}
+
+func TestAuthorizer(t *testing.T) {
+	tests := []struct {
+		desc        string
+		header      string
+		allowedUser string
+		want        int
+	}{
+		{
+			desc:        "missing token",
+			allowedUser: "prometheus",
+			want:        http.StatusUnauthorized,
+		},
+		{
+			desc:        "basic auth",
+			header:      "Basic dXNlcjpwYXNz",
+			allowedUser: "prometheus",
+			want:        http.StatusUnauthorized,
+		},
+		{
+			desc:        "unauthenticated token",
+			header:      "Bearer invalid",
+			allowedUser: "prometheus",
+			want:        http.StatusUnauthorized,
+		},
+		{
+			desc:        "unauthorized user",
+			header:      "Bearer valid",
+			allowedUser: "someone-else",
+			want:        http.StatusForbidden,
+		},
+		{
+			desc:        "authorized user",
+			header:      "Bearer valid",
+			allowedUser: "prometheus",
+			want:        http.StatusOK,
+		},
+		{
+			desc:        "failing review",
+			header:      "Bearer broken",
+			allowedUser: "prometheus",
+			want:        http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		var reviews []*authorizationv1.SubjectAccessReview
+		a := NewAuthorizer(reviewingClientset(test.allowedUser, &reviews), Resource{})
+		h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if test.header != "" {
+			req.Header.Set("Authorization", test.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != test.want {
+			t.Errorf("%s: expected status %d but got %d", test.desc, test.want, w.Code)
+		}
+	}
+}
+
+func TestAuthorizerAttributes(t *testing.T) {
+	var reviews []*authorizationv1.SubjectAccessReview
+	client := reviewingClientset("prometheus", &reviews)
+
+	a := NewAuthorizer(client, Resource{})
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer valid")
+	if status := a.authorize(req); status != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+	}
+	attrs := reviews[0].Spec.NonResourceAttributes
+	if attrs == nil || attrs.Path != "/metrics" || attrs.Verb != "get" {
+		t.Errorf("unexpected non-resource attributes: %+v", attrs)
+	}
+	if reviews[0].Spec.User != "prometheus" || len(reviews[0].Spec.Groups) != 1 {
+		t.Errorf("unexpected user info: %+v", reviews[0].Spec)
+	}
+
+	reviews = nil
+	a = NewAuthorizer(client, Resource{Namespace: "kube-system", Resource: "services", Subresource: "proxy", Name: "kube-state-metrics"})
+	if status := a.authorize(req); status != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+	}
+	want := authorizationv1.ResourceAttributes{Namespace: "kube-system", Verb: "get", Resource: "services", Subresource: "proxy", Name: "kube-state-metrics"}
+	if reviews[0].Spec.ResourceAttributes == nil || *reviews[0].Spec.ResourceAttributes != want {
+		t.Errorf("expected resource attributes %+v but got %+v", want, reviews[0].Spec.ResourceAttributes)
+	}
+}
+
+func TestAuthorizerCachesDecisions(t *testing.T) {
+	var reviews []*authorizationv1.SubjectAccessReview
+	a := NewAuthorizer(reviewingClientset("prometheus", &reviews), Resource{})
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		if status := a.authorize(req); status != http.StatusOK {
+			t.Fatalf("expected status %d but got %d", http.StatusOK, status)
+		}
+	}
+
+	if len(reviews) != 1 {
+		t.Errorf("expected 1 access review but got %d", len(reviews))
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuth authenticates requests with HTTP basic auth.
+type BasicAuth struct {
+	// users maps user names to functions verifying their password.
+	users map[string]func(password string) bool
+
+	mtx sync.Mutex
+	// verified caches successfully verified credentials, as verifying
+	// bcrypt hashes is deliberately slow.
+	verified map[[sha256.Size]byte]time.Time
+}
+
+// NewBasicAuth returns a new BasicAuth accepting the given user and password.
+func NewBasicAuth(username, password string) *BasicAuth {
+	hash := sha256.Sum256([]byte(password))
+	return newBasicAuth(map[string]func(string) bool{
+		username: func(p string) bool {
+			h := sha256.Sum256([]byte(p))
+			return subtle.ConstantTimeCompare(h[:], hash[:]) == 1
+		},
+	})
+}
+
+// LoadHtpasswd returns a new BasicAuth accepting the users of the given
+// htpasswd file. Passwords must be hashed with bcrypt (htpasswd -B) or SHA-1
+// (htpasswd -s).
+func LoadHtpasswd(path string) (*BasicAuth, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	users := map[string]func(string) bool{}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%s:%d: expected user:hash", path, n)
+		}
+		user, hash := parts[0], []byte(parts[1])
+
+		switch {
+		case strings.HasPrefix(parts[1], "$2a$"), strings.HasPrefix(parts[1], "$2b$"), strings.HasPrefix(parts[1], "$2y$"):
+			users[user] = func(p string) bool {
+				return bcrypt.CompareHashAndPassword(hash, []byte(p)) == nil
+			}
+		case strings.HasPrefix(parts[1], "{SHA}"):
+			users[user] = func(p string) bool {
+				h := sha1.Sum([]byte(p))
+				want := "{SHA}" + base64.StdEncoding.EncodeToString(h[:])
+				return subtle.ConstantTimeCompare([]byte(want), hash) == 1
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: unsupported hash of user %s, only bcrypt and SHA-1 are supported", path, n, user)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%s: no users", path)
+	}
+
+	return newBasicAuth(users), nil
+}
+
+func newBasicAuth(users map[string]func(string) bool) *BasicAuth {
+	return &BasicAuth{
+		users:    users,
+		verified: map[[sha256.Size]byte]time.Time{},
+	}
+}
+
+// Handler returns a http.Handler only passing requests with valid credentials
+// on to next. Other requests are answered with 401 Unauthorized.
+func (b *BasicAuth) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || !b.verify(username, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="kube-state-metrics", charset="UTF-8"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (b *BasicAuth) verify(username, password string) bool {
+	key := sha256.Sum256([]byte(username + "\x00" + password))
+
+	b.mtx.Lock()
+	expires, ok := b.verified[key]
+	b.mtx.Unlock()
+	if ok && time.Now().Before(expires) {
+		return true
+	}
+
+	verify, ok := b.users[username]
+	if !ok || !verify(password) {
+		return false
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if len(b.verified) >= maxCacheSize {
+		now := time.Now()
+		for k, expires := range b.verified {
+			if now.After(expires) {
+				delete(b.verified, k)
+			}
+		}
+		if len(b.verified) >= maxCacheSize {
+			return true
+		}
+	}
+	b.verified[key] = time.Now().Add(cacheTTL)
+
+	return true
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBasicAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "htpasswd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	htpasswd := filepath.Join(dir, "htpasswd")
+	content := "# scrapers\nprometheus:" + string(hash) + "\n\ngrafana:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=\n"
+	if err := ioutil.WriteFile(htpasswd, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile, err := LoadHtpasswd(htpasswd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		desc       string
+		auth       *BasicAuth
+		username   string
+		password   string
+		noAuth     bool
+		wantStatus int
+	}{
+		{"password", NewBasicAuth("prometheus", "secret"), "prometheus", "secret", false, http.StatusOK},
+		{"wrong password", NewBasicAuth("prometheus", "secret"), "prometheus", "wrong", false, http.StatusUnauthorized},
+		{"wrong user", NewBasicAuth("prometheus", "secret"), "grafana", "secret", false, http.StatusUnauthorized},
+		{"no credentials", NewBasicAuth("prometheus", "secret"), "", "", true, http.StatusUnauthorized},
+		{"htpasswd bcrypt", fromFile, "prometheus", "secret", false, http.StatusOK},
+		{"htpasswd bcrypt wrong password", fromFile, "prometheus", "wrong", false, http.StatusUnauthorized},
+		{"htpasswd sha", fromFile, "grafana", "secret", false, http.StatusOK},
+		{"htpasswd unknown user", fromFile, "admin", "secret", false, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		handler := test.auth.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		// Repeat requests to cover cached verifications.
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if !test.noAuth {
+				req.SetBasicAuth(test.username, test.password)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != test.wantStatus {
+				t.Errorf("%s: expected status %d but got %d", test.desc, test.wantStatus, rec.Code)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("%s: expected WWW-Authenticate header", test.desc)
+			}
+		}
+	}
+}
+
+func TestLoadHtpasswdErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "htpasswd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]string{
+		"empty":   "# no users\n",
+		"no hash": "prometheus\n",
+		"md5":     "prometheus:$apr1$xyz$abc\n",
+		"crypt":   "prometheus:rl0uWNzH9u0gE\n",
+	}
+
+	for desc, content := range tests {
+		path := filepath.Join(dir, "htpasswd")
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadHtpasswd(path); err == nil {
+			t.Errorf("%s: expected error", desc)
+		}
+	}
+
+	if _, err := LoadHtpasswd(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
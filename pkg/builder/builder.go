@@ -0,0 +1,267 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder builds the stores of the metrics of Kubernetes objects, for
+// embedding kube-state-metrics into other Go programs.
+//
+// The stores are populated by reflectors listing and watching the enabled
+// resources, and can be served with a metricshandler.MetricsHandler, which
+// builds them according to the sharding configured in the options:
+//
+//	b := builder.NewBuilder()
+//	b.WithMetrics(registry)
+//	if err := b.WithEnabledResources([]string{"deployments", "pods"}); err != nil {
+//		return err
+//	}
+//	b.WithNamespaces(options.DefaultNamespaces)
+//	b.WithWhiteBlackList(whiteBlackList)
+//	b.WithKubeClient(kubeClient)
+//
+//	opts := options.NewOptions()
+//	opts.TotalShards = 1
+//	m := metricshandler.New(opts, kubeClient, b, false)
+//	go m.Run(ctx)
+//	http.Handle("/metrics", m)
+package builder
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/tracing"
+)
+
+// Make sure the public Builder implements the public BuilderInterface.
+// New internal Builder methods should be added to the public BuilderInterface.
+var _ ksmtypes.BuilderInterface = &Builder{}
+
+// Builder helps to build store. It follows the builder pattern
+// (https://en.wikipedia.org/wiki/Builder_pattern).
+type Builder struct {
+	internal ksmtypes.BuilderInterface
+}
+
+// RegisterResource registers a collector of the given name for a resource not
+// built into kube-state-metrics, e.g. a custom resource, so that it can be
+// enabled with WithEnabledResources. Objects of expectedType are listed and
+// watched with the ListerWatchers created by listWatchFunc for each configured
+// namespace, given the kube client of the Builder or, in multi-cluster mode,
+// of each member cluster, and the given metric families are generated for
+// each of them.
+//
+// Resources should be registered before any Builder is built, e.g. in an init
+// function. It returns an error if a collector of the same name exists.
+func RegisterResource(name string, expectedType runtime.Object, listWatchFunc ksmtypes.ListWatchFunc, metricFamilies []metric.FamilyGenerator) error {
+	return store.RegisterResource(name, expectedType, listWatchFunc, metricFamilies)
+}
+
+// RegisterExternalCollector starts the external collector executable at the
+// given path and registers the collector it describes, returning its name.
+// The executable is stopped once ctx is done.
+func RegisterExternalCollector(ctx context.Context, path string) (string, error) {
+	return store.RegisterExternalCollector(ctx, path)
+}
+
+// NewBuilder returns a new builder.
+func NewBuilder() *Builder {
+	return &Builder{internal: store.NewBuilder()}
+}
+
+// WithMetrics sets the metrics property of a Builder. The metrics about
+// listing and watching resources and about the stores are registered with r.
+func (b *Builder) WithMetrics(r *prometheus.Registry) {
+	b.internal.WithMetrics(r)
+}
+
+// WithTracer sets the tracer property of a Builder. The relists of the
+// reflectors populating the stores are traced with t.
+func (b *Builder) WithTracer(t *tracing.Tracer) {
+	b.internal.WithTracer(t)
+}
+
+// WithEnabledResources sets the enabledResources property of a Builder. It
+// returns an error for unknown resources.
+func (b *Builder) WithEnabledResources(c []string) error {
+	return b.internal.WithEnabledResources(c)
+}
+
+// EnabledResources returns the enabled resources of a Builder, in the order
+// their stores are returned by Build.
+func (b *Builder) EnabledResources() []string {
+	return b.internal.EnabledResources()
+}
+
+// WithNamespaces sets the namespaces property of a Builder.
+func (b *Builder) WithNamespaces(n options.NamespaceList) {
+	b.internal.WithNamespaces(n)
+}
+
+// WithSharding sets the shard and totalShards property of a Builder.
+func (b *Builder) WithSharding(shard int32, totalShards int) {
+	b.internal.WithSharding(shard, totalShards)
+}
+
+// WithListPageSize sets the listPageSize property of a Builder. Initial lists
+// are retrieved in chunks of at most this many objects. 0 disables chunking.
+func (b *Builder) WithListPageSize(pageSize int64) {
+	b.internal.WithListPageSize(pageSize)
+}
+
+// WithUseAPIServerCache sets the useAPIServerCache property of a Builder. If
+// set, all lists are served from the apiserver watch cache, regardless of the
+// list page size.
+func (b *Builder) WithUseAPIServerCache(useAPIServerCache bool) {
+	b.internal.WithUseAPIServerCache(useAPIServerCache)
+}
+
+// WithNode sets the node property of a Builder. If set, only pods scheduled to
+// the given node are collected.
+func (b *Builder) WithNode(node string) {
+	b.internal.WithNode(node)
+}
+
+// WithFamilyHeaders configures whether the HELP and TYPE lines of metric
+// families are left out of the output of stores built by the Builder.
+func (b *Builder) WithFamilyHeaders(omitHelp, omitType bool) {
+	b.internal.WithFamilyHeaders(omitHelp, omitType)
+}
+
+// WithStableMetricsOnly configures whether only stable metric families are
+// exposed by the stores built by the Builder.
+func (b *Builder) WithStableMetricsOnly(stableOnly bool) {
+	b.internal.WithStableMetricsOnly(stableOnly)
+}
+
+// WithDeprecatedMetrics configures whether metric families deprecated in an
+// earlier minor release are exposed by the stores built by the Builder.
+func (b *Builder) WithDeprecatedMetrics(includeDeprecated bool) {
+	b.internal.WithDeprecatedMetrics(includeDeprecated)
+}
+
+// WithCustomLabels sets labels added to all metrics of the stores built by the
+// Builder, e.g. the region or environment of the cluster.
+func (b *Builder) WithCustomLabels(labels map[string]string) {
+	b.internal.WithCustomLabels(labels)
+}
+
+// WithMetricPrefix sets the prefix replacing kube_ in the names of the metrics
+// of the stores built by the Builder.
+func (b *Builder) WithMetricPrefix(prefix string) {
+	b.internal.WithMetricPrefix(prefix)
+}
+
+// WithContainerReasonWhitelist sets the waiting and terminated reasons of
+// containers reported by the pod collector. If empty, the known reasons and any
+// other current reason are reported.
+func (b *Builder) WithContainerReasonWhitelist(reasons []string) {
+	b.internal.WithContainerReasonWhitelist(reasons)
+}
+
+// WithEmptyPodPhaseAsUnknown configures whether pods without a phase, e.g.
+// right after their creation, are reported in the Unknown phase by the pod
+// collector instead of being left out of kube_pod_status_phase.
+func (b *Builder) WithEmptyPodPhaseAsUnknown(emptyPhaseUnknown bool) {
+	b.internal.WithEmptyPodPhaseAsUnknown(emptyPhaseUnknown)
+}
+
+// WithFamilyHooks sets hooks wrapping the generation of all metric families
+// of the stores built by the Builder, e.g. to hash user identifiers in label
+// values. Hooks are applied in the given order, after all other options.
+func (b *Builder) WithFamilyHooks(hooks ...metric.FamilyHook) {
+	b.internal.WithFamilyHooks(hooks...)
+}
+
+// WithContext sets the ctx property of a Builder. The reflectors populating
+// the stores are stopped once ctx is done.
+func (b *Builder) WithContext(ctx context.Context) {
+	b.internal.WithContext(ctx)
+}
+
+// WithKubeClient sets the kubeClient property of a Builder.
+func (b *Builder) WithKubeClient(c clientset.Interface) {
+	b.internal.WithKubeClient(c)
+}
+
+// WithVPAClient sets the vpaClient property of a Builder so that the verticalpodautoscaler collector can query VPA objects.
+func (b *Builder) WithVPAClient(c vpaclientset.Interface) {
+	b.internal.WithVPAClient(c)
+}
+
+// WithDynamicClient sets the dynamicClient property of a Builder so that the
+// collectors of KubeSphere custom resources can query their objects.
+func (b *Builder) WithDynamicClient(c dynamic.Interface) {
+	b.internal.WithDynamicClient(c)
+}
+
+// WithCluster adds a member cluster the stores built by the Builder are
+// populated from with the given clients, labeling its metrics with its name.
+// Once a member cluster is added, the stores are only populated from member
+// clusters, and the list watch functions of collectors registered with
+// RegisterResource are passed the kube client of each of them.
+func (b *Builder) WithCluster(name string, kubeClient clientset.Interface, vpaClient vpaclientset.Interface, dynamicClient dynamic.Interface) {
+	b.internal.WithCluster(name, kubeClient, vpaClient, dynamicClient)
+}
+
+// WithWhiteBlackList configures the white or blacklisted metric to be exposed
+// by the store build by the Builder.
+func (b *Builder) WithWhiteBlackList(l ksmtypes.WhiteBlackLister) {
+	b.internal.WithWhiteBlackList(l)
+}
+
+// WithListWatchFunc overrides the function creating the ListerWatchers of the
+// given resource, e.g. to list and watch objects from a cache, a proxy or
+// recorded fixtures instead of the apiserver.
+func (b *Builder) WithListWatchFunc(resource string, f ksmtypes.ListWatchFunc) {
+	b.internal.WithListWatchFunc(resource, f)
+}
+
+// Validate returns an error listing every metric family of the enabled
+// resources that would be exposed with an invalid name, type or custom label
+// name, or under the name of another family, without generating any metrics.
+func (b *Builder) Validate() error {
+	return b.internal.Validate()
+}
+
+// SelfCheck returns an error listing every violation of the Prometheus
+// exposition format and its conventions by the metrics of the enabled
+// resources generated for synthetic objects, e.g. metric families whose
+// generation panics, malformed metrics or problems reported by promlint.
+func (b *Builder) SelfCheck() error {
+	return b.internal.SelfCheck()
+}
+
+// DescribeFamilies returns the metric families of the enabled resources as
+// they would be exposed with the configuration of the Builder, including the
+// ones dropped by filters.
+func (b *Builder) DescribeFamilies() []ksmtypes.FamilyDescription {
+	return b.internal.DescribeFamilies()
+}
+
+// Build initializes and registers all enabled stores, starting their
+// reflectors, in the order of the enabled resources.
+func (b *Builder) Build() []*metricsstore.MetricsStore {
+	return b.internal.Build()
+}
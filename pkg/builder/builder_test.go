@@ -0,0 +1,403 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	"k8s.io/kube-state-metrics/pkg/metricshandler"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+func TestBuilderEmbedding(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc"},
+	})
+
+	whiteBlackList, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	if err := b.WithEnabledResources([]string{"configmaps", "unknown"}); err == nil {
+		t.Error("expected error for unknown resource")
+	}
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithWhiteBlackList(whiteBlackList)
+	b.WithKubeClient(kubeClient)
+
+	opts := options.NewOptions()
+	opts.TotalShards = 1
+	m := metricshandler.New(opts, kubeClient, b, false)
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !m.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stores to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	if want := `kube_configmap_info{namespace="default",configmap="cm"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("expected metrics to contain %s but got\n%s", want, body)
+	}
+}
+
+func TestRegisterResource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Config maps stand in for the objects of an external resource.
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "widget", Namespace: "default"},
+		Data:       map[string]string{"color": "blue"},
+	})
+	listWatchFunc := func(kubeClient clientset.Interface, ns string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				return kubeClient.CoreV1().ConfigMaps(ns).List(opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				return kubeClient.CoreV1().ConfigMaps(ns).Watch(opts)
+			},
+		}
+	}
+	families := []metric.FamilyGenerator{
+		{
+			Name: "widget_color",
+			Type: metric.Gauge,
+			Help: "The color of the widget.",
+			GenerateFunc: func(obj interface{}) *metric.Family {
+				cm := obj.(*v1.ConfigMap)
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   []string{"namespace", "widget", "color"},
+							LabelValues: []string{cm.Namespace, cm.Name, cm.Data["color"]},
+							Value:       1,
+						},
+					},
+				}
+			},
+		},
+	}
+
+	if err := RegisterResource("widgets", &v1.ConfigMap{}, listWatchFunc, families); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterResource("widgets", &v1.ConfigMap{}, listWatchFunc, families); err == nil {
+		t.Error("expected error registering the same resource twice")
+	}
+	if err := RegisterResource("configmaps", &v1.ConfigMap{}, listWatchFunc, families); err == nil {
+		t.Error("expected error registering a built-in resource")
+	}
+
+	whiteBlackList, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	if err := b.WithEnabledResources([]string{"widgets"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithWhiteBlackList(whiteBlackList)
+	b.WithKubeClient(kubeClient)
+
+	opts := options.NewOptions()
+	opts.TotalShards = 1
+	m := metricshandler.New(opts, kubeClient, b, false)
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !m.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stores to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	if want := `widget_color{namespace="default",widget="widget",color="blue"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("expected metrics to contain %s but got\n%s", want, body)
+	}
+}
+
+func TestBuilderWithListWatchFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The kube client knows no config maps, they are listed from fixtures.
+	kubeClient := fake.NewSimpleClientset()
+	fixtures := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "recorded", Namespace: "default"},
+	})
+
+	whiteBlackList, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	if err := b.WithEnabledResources([]string{"configmaps", "secrets"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithWhiteBlackList(whiteBlackList)
+	b.WithKubeClient(kubeClient)
+	b.WithListWatchFunc("configmaps", func(_ clientset.Interface, ns string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				return fixtures.CoreV1().ConfigMaps(ns).List(opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				return fixtures.CoreV1().ConfigMaps(ns).Watch(opts)
+			},
+		}
+	})
+
+	opts := options.NewOptions()
+	opts.TotalShards = 1
+	m := metricshandler.New(opts, kubeClient, b, false)
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !m.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stores to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	if want := `kube_configmap_info{namespace="default",configmap="recorded"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("expected metrics to contain %s but got\n%s", want, body)
+	}
+}
+
+func TestBuilderStableMetricsOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", ResourceVersion: "42"},
+	})
+
+	whiteBlackList, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithWhiteBlackList(whiteBlackList)
+	b.WithKubeClient(kubeClient)
+	b.WithStableMetricsOnly(true)
+
+	opts := options.NewOptions()
+	opts.TotalShards = 1
+	m := metricshandler.New(opts, kubeClient, b, false)
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !m.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stores to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	if want := `kube_configmap_info{namespace="default",configmap="cm"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("expected metrics to contain %s but got\n%s", want, body)
+	}
+	if unwanted := "kube_configmap_metadata_resource_version"; strings.Contains(string(body), unwanted) {
+		t.Errorf("expected experimental metric %s to be left out but got\n%s", unwanted, body)
+	}
+}
+
+func TestBuilderWithFamilyHooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret-name", Namespace: "default"},
+	})
+
+	whiteBlackList, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redact := metric.FamilyTransformFunc(func(name string, f *metric.Family) {
+		for _, m := range f.Metrics {
+			values := make([]string, len(m.LabelValues))
+			for i, k := range m.LabelKeys {
+				values[i] = m.LabelValues[i]
+				if k == "configmap" {
+					values[i] = "redacted"
+				}
+			}
+			m.LabelValues = values
+		}
+	})
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithWhiteBlackList(whiteBlackList)
+	b.WithKubeClient(kubeClient)
+	b.WithFamilyHooks(redact)
+
+	opts := options.NewOptions()
+	opts.TotalShards = 1
+	m := metricshandler.New(opts, kubeClient, b, false)
+	go m.Run(ctx)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !m.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for stores to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	if want := `kube_configmap_info{namespace="default",configmap="redacted"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("expected metrics to contain %s but got\n%s", want, body)
+	}
+	if unwanted := "secret-name"; strings.Contains(string(body), unwanted) {
+		t.Errorf("expected %s to be redacted but got\n%s", unwanted, body)
+	}
+}
+
+func TestBuilderObjectDeletion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	kubeClient := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "1"},
+	})
+
+	whiteBlackList, err := whiteblacklist.New(options.MetricSet{}, options.MetricSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder()
+	b.WithMetrics(prometheus.NewRegistry())
+	if err := b.WithEnabledResources([]string{"configmaps"}); err != nil {
+		t.Fatal(err)
+	}
+	b.WithNamespaces(options.DefaultNamespaces)
+	b.WithWhiteBlackList(whiteBlackList)
+	b.WithKubeClient(kubeClient)
+
+	opts := options.NewOptions()
+	opts.TotalShards = 1
+	m := metricshandler.New(opts, kubeClient, b, false)
+	go m.Run(ctx)
+
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body, _ := ioutil.ReadAll(rec.Body)
+		return string(body)
+	}
+	waitFor := func(desc string, cond func(body string) bool) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			body := scrape()
+			if cond(body) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s, got\n%s", desc, body)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitFor("the config map", func(body string) bool {
+		return strings.Contains(body, `kube_configmap_info{namespace="default",configmap="cm"} 1`)
+	})
+
+	configMaps := kubeClient.CoreV1().ConfigMaps("default")
+	if err := configMaps.Delete("cm", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the deletion of the config map", func(body string) bool {
+		return !strings.Contains(body, `configmap="cm"`)
+	})
+
+	// A config map re-created with the same name must only have the series
+	// of the new object.
+	if _, err := configMaps.Create(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "2", ResourceVersion: "7"}}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the re-created config map", func(body string) bool {
+		return strings.Count(body, `configmap="cm"`) == 2 &&
+			strings.Contains(body, `kube_configmap_metadata_resource_version{namespace="default",configmap="cm"} 7`)
+	})
+}
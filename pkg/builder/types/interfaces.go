@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/tracing"
+)
+
+// BuilderInterface represents all methods that a Builder should implement.
+type BuilderInterface interface {
+	WithMetrics(r *prometheus.Registry)
+	WithTracer(t *tracing.Tracer)
+	WithEnabledResources(c []string) error
+	EnabledResources() []string
+	WithNamespaces(n options.NamespaceList)
+	WithSharding(shard int32, totalShards int)
+	WithListPageSize(pageSize int64)
+	WithUseAPIServerCache(useAPIServerCache bool)
+	WithNode(node string)
+	WithFamilyHeaders(omitHelp, omitType bool)
+	WithStableMetricsOnly(stableOnly bool)
+	WithDeprecatedMetrics(includeDeprecated bool)
+	WithCustomLabels(labels map[string]string)
+	WithMetricPrefix(prefix string)
+	WithFamilyHooks(hooks ...metric.FamilyHook)
+	WithContainerReasonWhitelist(reasons []string)
+	WithEmptyPodPhaseAsUnknown(emptyPhaseUnknown bool)
+	WithContext(ctx context.Context)
+	WithKubeClient(c clientset.Interface)
+	WithVPAClient(c vpaclientset.Interface)
+	WithDynamicClient(c dynamic.Interface)
+	WithCluster(name string, kubeClient clientset.Interface, vpaClient vpaclientset.Interface, dynamicClient dynamic.Interface)
+	WithWhiteBlackList(l WhiteBlackLister)
+	WithListWatchFunc(resource string, f ListWatchFunc)
+	Validate() error
+	SelfCheck() error
+	DescribeFamilies() []FamilyDescription
+	Build() []*metricsstore.MetricsStore
+}
+
+// WhiteBlackLister represents a white or blacklist of metric families,
+// e.g. a *whiteblacklist.WhiteBlackList.
+type WhiteBlackLister interface {
+	IsIncluded(string) bool
+	IsExcluded(string) bool
+	IsWhiteList() bool
+}
+
+// ListWatchFunc creates the cache.ListerWatcher of a resource in the given
+// namespace, e.g. listing and watching from a cache, a proxy or recorded
+// fixtures instead of the apiserver. kubeClient is the client configured with
+// WithKubeClient, which may be ignored.
+type ListWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListerWatcher
+
+// FamilyDescription describes a metric family of an enabled collector as it
+// would be exposed with the configuration of a Builder.
+type FamilyDescription struct {
+	Collector      string
+	Name           string
+	Help           string
+	Type           metric.Type
+	StabilityLevel metric.StabilityLevel
+	// DeprecatedVersion is the release in which the family was deprecated,
+	// if any.
+	DeprecatedVersion string
+	// Labels are the names of the labels of the metrics of the family.
+	// Label names derived from objects, e.g. from their Kubernetes labels,
+	// are given by their prefix followed by an asterisk, e.g. "label_*".
+	Labels []string
+	// DroppedBy is the flag of the filter dropping the family, e.g.
+	// "--metric-blacklist", or empty if the family is exposed.
+	DroppedBy string
+}
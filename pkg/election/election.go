@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package election elects a leader among kube-state-metrics replicas using a
+// coordination.k8s.io Lease, so only one of them serves metrics at a time.
+package election
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+)
+
+// Config configures the leader election.
+type Config struct {
+	// Namespace and Name of the Lease.
+	Namespace string
+	Name      string
+	// Identity of this replica, e.g. its pod name.
+	Identity string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// Run takes part in the leader election until ctx is done. It calls
+// onStartedLeading when this replica becomes the leader and onStoppedLeading
+// when it loses the leadership, after which it takes part in the election
+// again. onStoppedLeading may also be called without the leadership having
+// been held. The Lease is released when ctx is done, so a standby replica can
+// take over right away.
+func Run(ctx context.Context, kubeClient kubernetes.Interface, config Config, onStartedLeading, onStoppedLeading func()) error {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: config.Namespace,
+			Name:      config.Name,
+		},
+		Client: kubeClient.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: config.Identity,
+		},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   config.LeaseDuration,
+		RenewDeadline:   config.RenewDeadline,
+		RetryPeriod:     config.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            config.Name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				klog.Infof("%s became the leader of %s/%s", config.Identity, config.Namespace, config.Name)
+				onStartedLeading()
+			},
+			OnStoppedLeading: func() {
+				klog.Infof("%s is standing by for %s/%s", config.Identity, config.Namespace, config.Name)
+				onStoppedLeading()
+			},
+			OnNewLeader: func(identity string) {
+				if identity != config.Identity {
+					klog.Infof("%s is the leader of %s/%s", identity, config.Namespace, config.Name)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Run returns once the leadership is lost or ctx is done.
+	for ctx.Err() == nil {
+		elector.Run(ctx)
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRun(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	config := Config{
+		Namespace:     "kube-system",
+		Name:          "kube-state-metrics",
+		LeaseDuration: time.Second,
+		RenewDeadline: 500 * time.Millisecond,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leading := make(chan string, 2)
+	run := func(identity string) <-chan error {
+		config := config
+		config.Identity = identity
+		done := make(chan error)
+		go func() {
+			done <- Run(ctx, client, config, func() { leading <- identity }, func() {})
+		}()
+		return done
+	}
+	done1 := run("kube-state-metrics-0")
+	done2 := run("kube-state-metrics-1")
+
+	var leader string
+	select {
+	case leader = <-leading:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a replica to become the leader")
+	}
+
+	select {
+	case other := <-leading:
+		t.Fatalf("expected only %s to lead but %s became the leader as well", leader, other)
+	case <-time.After(2 * config.LeaseDuration):
+	}
+
+	lease, err := client.CoordinationV1().Leases("kube-system").Get("kube-state-metrics", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *lease.Spec.HolderIdentity != leader {
+		t.Errorf("expected lease to be held by %s but got %s", leader, *lease.Spec.HolderIdentity)
+	}
+
+	cancel()
+	for _, done := range []<-chan error{done1, done2} {
+		if err := <-done; err != context.Canceled {
+			t.Errorf("expected context.Canceled but got %v", err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// activityListerWatcher implements cache.ListerWatcher
+// which wraps a cache.ListerWatcher,
+// reporting successful lists and watches.
+type activityListerWatcher struct {
+	next     cache.ListerWatcher
+	activity func()
+}
+
+// NewActivityListerWatcher creates a cache.ListerWatcher
+// wrapping the given next cache.ListerWatcher,
+// calling activity after each successful list and each successfully
+// (re-)established watch.
+//
+// Reflectors re-establish their watches within minutes, even if no events
+// occur, hence a lack of activity indicates a broken or wedged reflector.
+func NewActivityListerWatcher(next cache.ListerWatcher, activity func()) cache.ListerWatcher {
+	return &activityListerWatcher{
+		next:     next,
+		activity: activity,
+	}
+}
+
+// List lists the wrapped next listerwatcher.
+func (w *activityListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	list, err := w.next.List(options)
+	if err == nil {
+		w.activity()
+	}
+	return list, err
+}
+
+// Watch watches the wrapped next listerwatcher.
+func (w *activityListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	wi, err := w.next.Watch(options)
+	if err == nil {
+		w.activity()
+	}
+	return wi, err
+}
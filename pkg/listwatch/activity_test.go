@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"errors"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestActivityListerWatcher(t *testing.T) {
+	var err error
+	next := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &v1.ConfigMapList{}, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), err
+		},
+	}
+
+	activity := 0
+	lw := NewActivityListerWatcher(next, func() { activity++ })
+
+	lw.List(metav1.ListOptions{})
+	lw.Watch(metav1.ListOptions{})
+	if activity != 2 {
+		t.Errorf("expected 2 activities but got %d", activity)
+	}
+
+	err = errors.New("connection refused")
+	lw.List(metav1.ListOptions{})
+	lw.Watch(metav1.ListOptions{})
+	if activity != 2 {
+		t.Errorf("expected failed lists and watches not to be activities, got %d activities", activity)
+	}
+}
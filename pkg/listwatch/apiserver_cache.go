@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// apiserverCacheListerWatcher implements cache.ListerWatcher
+// which wraps a cache.ListerWatcher,
+// serving all list requests from the apiserver watch cache.
+type apiserverCacheListerWatcher struct {
+	next cache.ListerWatcher
+}
+
+// NewAPIServerCacheListerWatcher creates a cache.ListerWatcher
+// wrapping the given next cache.ListerWatcher,
+// listing objects at resource version "0".
+//
+// Lists at resource version "0" are served from the apiserver watch cache
+// instead of etcd. They may be stale, but are considerably cheaper for the
+// apiserver, especially when many objects are relisted at once. The apiserver
+// ignores limits for such lists, hence they are never paginated.
+func NewAPIServerCacheListerWatcher(next cache.ListerWatcher) cache.ListerWatcher {
+	return &apiserverCacheListerWatcher{
+		next: next,
+	}
+}
+
+// List lists the wrapped next listerwatcher at resource version "0".
+func (w *apiserverCacheListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	options.ResourceVersion = "0"
+	return w.next.List(options)
+}
+
+// Watch watches the wrapped next listerwatcher.
+func (w *apiserverCacheListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return w.next.Watch(options)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAPIServerCacheListerWatcher(t *testing.T) {
+	tests := []struct {
+		desc     string
+		pageSize int64
+		options  metav1.ListOptions
+	}{
+		{
+			desc:    "initial list",
+			options: metav1.ListOptions{ResourceVersion: "0"},
+		},
+		{
+			desc:    "relist after expired resource version",
+			options: metav1.ListOptions{ResourceVersion: ""},
+		},
+		{
+			desc:    "relist at last synced resource version",
+			options: metav1.ListOptions{ResourceVersion: "42"},
+		},
+		{
+			desc:     "pagination configured",
+			pageSize: 10,
+			options:  metav1.ListOptions{ResourceVersion: "0"},
+		},
+	}
+
+	for _, test := range tests {
+		var requests []metav1.ListOptions
+		// Unlike the apiserver, the fake paginates lists at resource version
+		// "0" as well.
+		lw := NewPaginatedListerWatcher(NewAPIServerCacheListerWatcher(pagingListWatch(25, &requests)), test.pageSize)
+
+		list, err := lw.List(test.options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.desc, err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.desc, err)
+		}
+		if len(items) != 25 {
+			t.Errorf("%s: expected 25 items but got %d", test.desc, len(items))
+		}
+
+		for _, r := range requests {
+			if r.ResourceVersion != "0" {
+				t.Errorf("%s: expected resource version \"0\" but got %q", test.desc, r.ResourceVersion)
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// initialBackoff is the delay after the first failure. Reflectors wait
+	// another second before retrying on their own.
+	initialBackoff = time.Second
+	// maxBackoff caps the delay, so recovered resources are picked up again
+	// within minutes.
+	maxBackoff = 2 * time.Minute
+	// backoffJitter is the maximum fraction of the delay added as jitter, so
+	// the reflectors of many resources and replicas do not retry in lockstep.
+	backoffJitter = 0.5
+)
+
+// backoffListerWatcher implements cache.ListerWatcher
+// which wraps a cache.ListerWatcher,
+// delaying lists and watches after failures.
+type backoffListerWatcher struct {
+	next   cache.ListerWatcher
+	report func(err error, failures int)
+	sleep  func(time.Duration)
+
+	mtx sync.Mutex
+	// failures is the number of failures since the last successfully
+	// established watch.
+	failures int
+	// failed is true if the last list or watch failed.
+	failed bool
+}
+
+// NewBackoffListerWatcher creates a cache.ListerWatcher
+// wrapping the given next cache.ListerWatcher,
+// calling report with the outcome of each list and watch, and the number of
+// failures since the last successfully established watch.
+//
+// Reflectors retry failed lists and watches every second, which floods the
+// apiserver and the logs if a resource persistently fails, e.g. because of
+// missing permissions. After a failure, the next list or watch is delayed
+// exponentially in the number of failures since the last successfully
+// established watch, up to two minutes plus jitter. A successful list alone does not reset
+// the delay, as reflectors relist every time their watch fails.
+func NewBackoffListerWatcher(next cache.ListerWatcher, report func(err error, failures int)) cache.ListerWatcher {
+	return &backoffListerWatcher{
+		next:   next,
+		report: report,
+		sleep:  time.Sleep,
+	}
+}
+
+// List lists the wrapped next listerwatcher.
+func (w *backoffListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	w.wait()
+	list, err := w.next.List(options)
+	w.observe(err, false)
+	return list, err
+}
+
+// Watch watches the wrapped next listerwatcher.
+func (w *backoffListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w.wait()
+	wi, err := w.next.Watch(options)
+	w.observe(err, true)
+	return wi, err
+}
+
+// wait sleeps for the current delay if the last list or watch failed.
+func (w *backoffListerWatcher) wait() {
+	w.mtx.Lock()
+	failed, failures := w.failed, w.failures
+	w.mtx.Unlock()
+
+	if failed {
+		w.sleep(backoff(failures))
+	}
+}
+
+func (w *backoffListerWatcher) observe(err error, watching bool) {
+	w.mtx.Lock()
+	w.failed = err != nil
+	if err != nil {
+		w.failures++
+	} else if watching {
+		w.failures = 0
+	}
+	failures := w.failures
+	w.mtx.Unlock()
+
+	w.report(err, failures)
+}
+
+// backoff returns the jittered delay after the given number of failures.
+func backoff(failures int) time.Duration {
+	d := initialBackoff
+	for i := 1; i < failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return wait.Jitter(d, backoffJitter)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestBackoffListerWatcher(t *testing.T) {
+	var listErr, watchErr error
+	next := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &v1.ConfigMapList{}, listErr
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), watchErr
+		},
+	}
+
+	var reported []error
+	var failures []int
+	lw := NewBackoffListerWatcher(next, func(err error, f int) {
+		reported = append(reported, err)
+		failures = append(failures, f)
+	}).(*backoffListerWatcher)
+	var slept []time.Duration
+	lw.sleep = func(d time.Duration) { slept = append(slept, d) }
+
+	lw.List(metav1.ListOptions{})
+	lw.Watch(metav1.ListOptions{})
+	if len(slept) != 0 {
+		t.Errorf("expected no delay without failures but slept %v", slept)
+	}
+
+	// Persistent list failures are delayed exponentially.
+	listErr = errors.New("forbidden")
+	for i := 0; i < 4; i++ {
+		lw.List(metav1.ListOptions{})
+	}
+	if len(slept) != 3 {
+		t.Fatalf("expected 3 delays but got %v", slept)
+	}
+	for i, d := range slept {
+		min := initialBackoff << uint(i)
+		if d < min || d > min+time.Duration(float64(min)*backoffJitter) {
+			t.Errorf("expected delay %d of %s plus jitter but got %s", i, min, d)
+		}
+	}
+
+	// A successful list does not reset the delay, as the watch may fail.
+	listErr = nil
+	watchErr = errors.New("forbidden")
+	slept = nil
+	lw.List(metav1.ListOptions{})
+	lw.Watch(metav1.ListOptions{})
+	lw.List(metav1.ListOptions{})
+	if len(slept) != 2 || slept[1] < 16*time.Second {
+		t.Errorf("expected the delay to keep growing but slept %v", slept)
+	}
+
+	// A successfully established watch resets the delay.
+	watchErr = nil
+	lw.Watch(metav1.ListOptions{})
+	slept = nil
+	lw.List(metav1.ListOptions{})
+	if len(slept) != 0 {
+		t.Errorf("expected no delay after a successful watch but slept %v", slept)
+	}
+
+	if len(reported) != 11 {
+		t.Fatalf("expected 11 reports but got %d", len(reported))
+	}
+	if reported[0] != nil || reported[2] == nil || reported[len(reported)-1] != nil {
+		t.Errorf("unexpected reports %v", reported)
+	}
+	if want := []int{0, 0, 1, 2, 3, 4, 4, 5, 5, 0, 0}; !reflect.DeepEqual(failures, want) {
+		t.Errorf("expected failures %v but got %v", want, failures)
+	}
+}
+
+func TestBackoffCap(t *testing.T) {
+	if d := backoff(100); d < maxBackoff || d > maxBackoff+time.Duration(float64(maxBackoff)*backoffJitter) {
+		t.Errorf("expected capped delay but got %s", d)
+	}
+}
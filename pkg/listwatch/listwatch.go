@@ -181,6 +181,12 @@ type multiWatch struct {
 	result   chan watch.Event
 	stopped  chan struct{}
 	stoppers []func()
+
+	// rvMtx protects resourceVersions.
+	rvMtx *sync.Mutex
+	// resourceVersions holds the last resource version observed by each
+	// underlying watch.Interface.
+	resourceVersions []string
 }
 
 // newMultiWatch returns a new multiWatch or an error if one of the underlying
@@ -194,6 +200,13 @@ func newMultiWatch(lws []cache.ListerWatcher, resourceVersions []string, options
 		wg       sync.WaitGroup
 	)
 
+	mw := &multiWatch{
+		result:           result,
+		stopped:          stopped,
+		rvMtx:            &sync.Mutex{},
+		resourceVersions: append([]string{}, resourceVersions...),
+	}
+
 	wg.Add(len(lws))
 
 	for i, lw := range lws {
@@ -204,7 +217,7 @@ func newMultiWatch(lws []cache.ListerWatcher, resourceVersions []string, options
 			return nil, err
 		}
 
-		go func() {
+		go func(i int) {
 			defer wg.Done()
 
 			for {
@@ -213,15 +226,18 @@ func newMultiWatch(lws []cache.ListerWatcher, resourceVersions []string, options
 					return
 				}
 
+				event = mw.trackResourceVersion(i, event)
+
 				select {
 				case result <- event:
 				case <-stopped:
 					return
 				}
 			}
-		}()
+		}(i)
 		stoppers = append(stoppers, w.Stop)
 	}
+	mw.stoppers = stoppers
 
 	// result chan must be closed,
 	// once all event sender goroutines exited.
@@ -230,11 +246,39 @@ func newMultiWatch(lws []cache.ListerWatcher, resourceVersions []string, options
 		close(result)
 	}()
 
-	return &multiWatch{
-		result:   result,
-		stoppers: stoppers,
-		stopped:  stopped,
-	}, nil
+	return mw, nil
+}
+
+// trackResourceVersion records the resource version of the given event
+// received from the i-th underlying watch.Interface.
+//
+// Bookmark events only carry the resource version of a single underlying
+// watch. Passing them on as is would make the consumer resume watching all
+// ListerWatchers with that single resource version. Hence they are rewritten to
+// carry the combined resource versions of all underlying watches instead.
+func (mw *multiWatch) trackResourceVersion(i int, event watch.Event) watch.Event {
+	acc, err := meta.Accessor(event.Object)
+	if err != nil {
+		return event
+	}
+
+	mw.rvMtx.Lock()
+	defer mw.rvMtx.Unlock()
+
+	mw.resourceVersions[i] = acc.GetResourceVersion()
+
+	if event.Type != watch.Bookmark {
+		return event
+	}
+
+	obj := event.Object.DeepCopyObject()
+	acc, err = meta.Accessor(obj)
+	if err != nil {
+		return event
+	}
+	acc.SetResourceVersion(strings.Join(mw.resourceVersions, "/"))
+
+	return watch.Event{Type: watch.Bookmark, Object: obj}
 }
 
 // ResultChan implements the watch.Interface interface.
@@ -0,0 +1,87 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestMultiWatchBookmarks(t *testing.T) {
+	fakeWatchers := map[string]*watch.FakeWatcher{
+		"ns1": watch.NewFake(),
+		"ns2": watch.NewFake(),
+	}
+
+	var requested []metav1.ListOptions
+	lw := MultiNamespaceListerWatcher([]string{"ns1", "ns2"}, nil, func(ns string) cache.ListerWatcher {
+		return &cache.ListWatch{
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				requested = append(requested, options)
+				return fakeWatchers[ns], nil
+			},
+		}
+	})
+
+	w, err := lw.Watch(metav1.ListOptions{ResourceVersion: "1/2", AllowWatchBookmarks: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	for _, o := range requested {
+		if !o.AllowWatchBookmarks {
+			t.Fatal("expected bookmarks to be requested from all underlying watches")
+		}
+	}
+
+	go fakeWatchers["ns1"].Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns1", ResourceVersion: "5"}})
+	event := <-w.ResultChan()
+	if event.Type != watch.Added {
+		t.Fatalf("expected added event but got %v", event.Type)
+	}
+	acc, _ := meta.Accessor(event.Object)
+	if acc.GetResourceVersion() != "5" {
+		t.Fatalf("expected regular events to be passed on unchanged, got resource version %q", acc.GetResourceVersion())
+	}
+
+	go fakeWatchers["ns2"].Action(watch.Bookmark, &v1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "7"}})
+	event = <-w.ResultChan()
+	if event.Type != watch.Bookmark {
+		t.Fatalf("expected bookmark event but got %v", event.Type)
+	}
+	acc, _ = meta.Accessor(event.Object)
+	if acc.GetResourceVersion() != "5/7" {
+		t.Fatalf("expected bookmark to carry the combined resource version 5/7, got %q", acc.GetResourceVersion())
+	}
+
+	// The combined resource version must be usable to resume watching.
+	requested = nil
+	w2, err := lw.Watch(metav1.ListOptions{ResourceVersion: acc.GetResourceVersion()})
+	if err != nil {
+		t.Fatalf("expected bookmark resource version to be accepted, got %v", err)
+	}
+	w2.Stop()
+	if requested[0].ResourceVersion != "5" || requested[1].ResourceVersion != "7" {
+		t.Fatalf("expected watches to resume at 5 and 7, got %q and %q", requested[0].ResourceVersion, requested[1].ResourceVersion)
+	}
+}
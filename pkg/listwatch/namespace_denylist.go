@@ -149,7 +149,9 @@ func newDenylistWatch(denylist map[string]struct{}, next watch.Interface) watch.
 					continue
 				}
 
-				if _, denied := denylist[getNamespace(acc)]; denied {
+				// Bookmarks carry no namespace, only the current resource
+				// version, hence they are always passed on.
+				if _, denied := denylist[getNamespace(acc)]; denied && event.Type != watch.Bookmark {
 					klog.V(8).Infof("denied %s", acc.GetSelfLink())
 					continue
 				}
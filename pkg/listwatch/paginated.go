@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/pager"
+)
+
+// paginatedListerWatcher implements cache.ListerWatcher
+// which wraps a cache.ListerWatcher,
+// retrieving list results in chunks and combining them into a single list.
+type paginatedListerWatcher struct {
+	pageSize int64
+	next     cache.ListerWatcher
+}
+
+// NewPaginatedListerWatcher creates a cache.ListerWatcher
+// wrapping the given next cache.ListerWatcher,
+// listing objects in pages of at most pageSize items.
+//
+// All pages are consumed before List returns, hence wrappers further up the
+// chain, which do not forward continue tokens, always observe complete lists.
+// If pageSize is 0, chunking is disabled and each list is retrieved with a
+// single request.
+func NewPaginatedListerWatcher(next cache.ListerWatcher, pageSize int64) cache.ListerWatcher {
+	return &paginatedListerWatcher{
+		pageSize: pageSize,
+		next:     next,
+	}
+}
+
+// List lists all pages of the wrapped next listerwatcher.
+func (w *paginatedListerWatcher) List(options metav1.ListOptions) (runtime.Object, error) {
+	if w.pageSize <= 0 {
+		// Drop the limit requested by the caller, e.g. the reflector's
+		// default page size, as continue tokens are not forwarded.
+		options.Limit = 0
+		return w.next.List(options)
+	}
+
+	// The apiserver ignores the limit for lists served from its watch
+	// cache, i.e. when listing at resource version "0". Fall back to
+	// the most recent resource version, which can be paginated.
+	if options.ResourceVersion == "0" {
+		options.ResourceVersion = ""
+	}
+	options.Limit = w.pageSize
+
+	p := pager.New(pager.SimplePageFunc(w.next.List))
+	p.PageSize = w.pageSize
+
+	return p.List(context.TODO(), options)
+}
+
+// Watch watches the wrapped next listerwatcher.
+func (w *paginatedListerWatcher) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return w.next.Watch(options)
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package listwatch
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// pagingListWatch serves the given number of config maps in pages, similar to
+// the apiserver.
+func pagingListWatch(items int, requests *[]metav1.ListOptions) cache.ListerWatcher {
+	return &cache.ListWatch{
+		DisableChunking: true,
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			*requests = append(*requests, options)
+
+			start := 0
+			if options.Continue != "" {
+				var err error
+				start, err = strconv.Atoi(options.Continue)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			end := items
+			if options.Limit > 0 && start+int(options.Limit) < items {
+				end = start + int(options.Limit)
+			}
+
+			list := &v1.ConfigMapList{ListMeta: metav1.ListMeta{ResourceVersion: "42"}}
+			for i := start; i < end; i++ {
+				list.Items = append(list.Items, v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm%d", i)}})
+			}
+			if end < items {
+				list.Continue = strconv.Itoa(end)
+			}
+
+			return list, nil
+		},
+	}
+}
+
+func TestPaginatedListerWatcher(t *testing.T) {
+	tests := []struct {
+		desc             string
+		pageSize         int64
+		options          metav1.ListOptions
+		items            int
+		wantRequests     int
+		wantRequestLimit int64
+		wantRequestRV    string
+	}{
+		{
+			desc:             "page size configured",
+			pageSize:         10,
+			options:          metav1.ListOptions{ResourceVersion: "0"},
+			items:            25,
+			wantRequests:     3,
+			wantRequestLimit: 10,
+			wantRequestRV:    "",
+		},
+		{
+			desc:             "page size not configured disables chunking",
+			pageSize:         0,
+			options:          metav1.ListOptions{ResourceVersion: "0", Limit: 20},
+			items:            25,
+			wantRequests:     1,
+			wantRequestLimit: 0,
+			wantRequestRV:    "0",
+		},
+		{
+			desc:             "single page",
+			pageSize:         100,
+			options:          metav1.ListOptions{},
+			items:            25,
+			wantRequests:     1,
+			wantRequestLimit: 100,
+			wantRequestRV:    "",
+		},
+	}
+
+	for _, test := range tests {
+		var requests []metav1.ListOptions
+		lw := NewPaginatedListerWatcher(pagingListWatch(test.items, &requests), test.pageSize)
+
+		list, err := lw.List(test.options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.desc, err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.desc, err)
+		}
+		if len(items) != test.items {
+			t.Errorf("%s: expected %d items but got %d", test.desc, test.items, len(items))
+		}
+
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.desc, err)
+		}
+		if listMeta.GetResourceVersion() != "42" {
+			t.Errorf("%s: expected resource version 42 but got %q", test.desc, listMeta.GetResourceVersion())
+		}
+		if listMeta.GetContinue() != "" {
+			t.Errorf("%s: expected no continue token but got %q", test.desc, listMeta.GetContinue())
+		}
+
+		if len(requests) != test.wantRequests {
+			t.Errorf("%s: expected %d list requests but got %d", test.desc, test.wantRequests, len(requests))
+		}
+		for _, r := range requests {
+			if r.Limit != test.wantRequestLimit {
+				t.Errorf("%s: expected limit %d but got %d", test.desc, test.wantRequestLimit, r.Limit)
+			}
+			if r.ResourceVersion != test.wantRequestRV {
+				t.Errorf("%s: expected resource version %q but got %q", test.desc, test.wantRequestRV, r.ResourceVersion)
+			}
+		}
+	}
+}
@@ -31,6 +31,7 @@ func (f Family) ByteSlice() []byte {
 	b := strings.Builder{}
 	for _, m := range f.Metrics {
 		b.WriteString(f.Name)
+		b.WriteString(m.NameSuffix)
 		m.Write(&b)
 	}
 
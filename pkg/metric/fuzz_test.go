@@ -0,0 +1,89 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+func FuzzFamilyByteSlice(f *testing.F) {
+	for _, s := range []struct {
+		value  string
+		metric float64
+	}{
+		{"foo", 1},
+		{"", 0},
+		{"a\"b\\c\nd", -1},
+		{"\\n\\\"", 1e-300},
+		{"\t\x00\x1b[31m", math.NaN()},
+		{"\xff\xfe\xc3\x28", math.Inf(-1)},
+		{"日本語", 1.7976931348623157e308},
+	} {
+		f.Add(s.value, s.metric)
+	}
+
+	f.Fuzz(func(t *testing.T, value string, v float64) {
+		// Label values are sanitized at generation time, see
+		// SanitizeLabelValues, the writer relies on valid UTF-8.
+		value, _ = SanitizeLabelValue(value)
+
+		family := Family{
+			Name: "kube_fuzz_info",
+			Metrics: []*Metric{
+				{
+					LabelKeys:   []string{"namespace", "label_value"},
+					LabelValues: []string{"default", value},
+					Value:       v,
+				},
+			},
+		}
+
+		var parser expfmt.TextParser
+		out := family.ByteSlice()
+		families, err := parser.TextToMetricFamilies(bytes.NewReader(append([]byte("# TYPE kube_fuzz_info gauge\n"), out...)))
+		if err != nil {
+			t.Fatalf("failed to parse %q: %v", out, err)
+		}
+
+		mf, ok := families["kube_fuzz_info"]
+		if !ok || len(mf.Metric) != 1 {
+			t.Fatalf("expected one metric in %q, got %v", out, families)
+		}
+
+		m := mf.Metric[0]
+		labels := map[string]string{}
+		for _, l := range m.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if len(labels) != 2 || labels["namespace"] != "default" || labels["label_value"] != value {
+			t.Errorf("expected label value %q in %q, got %v", value, out, labels)
+		}
+
+		got := m.GetGauge().GetValue()
+		if got != v && !(math.IsNaN(got) && math.IsNaN(v)) {
+			t.Errorf("expected value %v in %q, got %v", v, strings.TrimSpace(string(out)), got)
+		}
+	})
+}
@@ -19,16 +19,64 @@ package metric
 import (
 	"strings"
 
+	"k8s.io/apimachinery/pkg/util/version"
+
 	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 )
 
+// StabilityLevel represents the API guarantees of a metric family.
+type StabilityLevel string
+
+const (
+	// Alpha metric families are in development and can be changed or removed
+	// at any time.
+	Alpha StabilityLevel = "ALPHA"
+	// Experimental metric families normally correspond to alpha Kubernetes API
+	// fields and can be changed at any time.
+	Experimental StabilityLevel = "EXPERIMENTAL"
+	// Stable metric families have very few backwards-incompatible changes
+	// outside of major version updates.
+	Stable StabilityLevel = "STABLE"
+)
+
 // FamilyGenerator provides everything needed to generate a metric family with a
 // Kubernetes object.
 type FamilyGenerator struct {
-	Name         string
-	Help         string
-	Type         Type
-	GenerateFunc func(obj interface{}) *Family
+	Name string
+	Help string
+	Type Type
+	// StabilityLevel of the family, which is considered stable if unset.
+	StabilityLevel StabilityLevel
+	// DeprecatedVersion is the release of kube-state-metrics in which the
+	// family was deprecated, e.g. "1.4.0", if any.
+	DeprecatedVersion string
+	GenerateFunc      func(obj interface{}) *Family
+}
+
+// IsStable returns whether the family is stable.
+func (g *FamilyGenerator) IsStable() bool {
+	return g.StabilityLevel == "" || g.StabilityLevel == Stable
+}
+
+// IsHidden returns whether the family was deprecated in a minor release before
+// the given release of kube-state-metrics, after which deprecated families are
+// hidden unless explicitly included. Families are never hidden if either
+// version cannot be parsed, e.g. in development builds.
+func (g *FamilyGenerator) IsHidden(release string) bool {
+	if g.DeprecatedVersion == "" {
+		return false
+	}
+	deprecated, err := version.ParseGeneric(g.DeprecatedVersion)
+	if err != nil {
+		return false
+	}
+	current, err := version.ParseGeneric(release)
+	if err != nil {
+		return false
+	}
+
+	return current.Major() > deprecated.Major() ||
+		current.Major() == deprecated.Major() && current.Minor() > deprecated.Minor()
 }
 
 // Generate calls the FamilyGenerator.GenerateFunc and gives the family its
@@ -41,17 +89,35 @@ func (g *FamilyGenerator) Generate(obj interface{}) *Family {
 	return family
 }
 
-func (g *FamilyGenerator) generateHeader() string {
+func (g *FamilyGenerator) generateHeader(omitHelp, omitType bool) string {
 	header := strings.Builder{}
-	header.WriteString("# HELP ")
-	header.WriteString(g.Name)
-	header.WriteByte(' ')
-	header.WriteString(g.Help)
-	header.WriteByte('\n')
-	header.WriteString("# TYPE ")
-	header.WriteString(g.Name)
-	header.WriteByte(' ')
-	header.WriteString(string(g.Type))
+	if !omitHelp {
+		header.WriteString("# HELP ")
+		header.WriteString(g.Name)
+		header.WriteByte(' ')
+		// Unstable families are annotated so that users notice before
+		// relying on them.
+		if !g.IsStable() {
+			header.WriteByte('[')
+			header.WriteString(string(g.StabilityLevel))
+			header.WriteString("] ")
+		}
+		if g.DeprecatedVersion != "" {
+			header.WriteString("(Deprecated since ")
+			header.WriteString(g.DeprecatedVersion)
+			header.WriteString(") ")
+		}
+		header.WriteString(g.Help)
+	}
+	if !omitType {
+		if header.Len() > 0 {
+			header.WriteByte('\n')
+		}
+		header.WriteString("# TYPE ")
+		header.WriteString(g.Name)
+		header.WriteByte(' ')
+		header.WriteString(string(g.Type.ClassicType()))
+	}
 
 	return header.String()
 }
@@ -59,15 +125,110 @@ func (g *FamilyGenerator) generateHeader() string {
 // ExtractMetricFamilyHeaders takes in a slice of FamilyGenerator metrics and
 // returns the extracted headers.
 func ExtractMetricFamilyHeaders(families []FamilyGenerator) []string {
+	return ExtractMetricFamilyHeadersWithOptions(families, false, false)
+}
+
+// ExtractMetricFamilyHeadersWithOptions takes in a slice of FamilyGenerator
+// metrics and returns the extracted headers, leaving out the HELP and/or TYPE
+// lines if requested. Headers without any lines are empty.
+func ExtractMetricFamilyHeadersWithOptions(families []FamilyGenerator, omitHelp, omitType bool) []string {
 	headers := make([]string, len(families))
 
 	for i, f := range families {
-		headers[i] = f.generateHeader()
+		headers[i] = f.generateHeader(omitHelp, omitType)
 	}
 
 	return headers
 }
 
+// AddLabels returns copies of the given metric families whose metrics carry the
+// given labels in addition to their own, e.g. to identify the environment of a
+// cluster.
+func AddLabels(families []FamilyGenerator, keys, values []string) []FamilyGenerator {
+	return AddObjectLabels(families, keys, func(interface{}) []string { return values })
+}
+
+// AddObjectLabels returns copies of the given metric families whose metrics
+// carry the given labels in addition to their own, with the values returned by
+// valuesFunc for the object the metrics are generated for, e.g. to identify
+// the cluster of the object.
+func AddObjectLabels(families []FamilyGenerator, keys []string, valuesFunc func(obj interface{}) []string) []FamilyGenerator {
+	labeled := make([]FamilyGenerator, len(families))
+
+	for i, f := range families {
+		generate := f.GenerateFunc
+		f.GenerateFunc = func(obj interface{}) *Family {
+			family := generate(obj)
+			values := valuesFunc(obj)
+			for _, m := range family.Metrics {
+				// The label slices of metrics may be shared, hence new ones
+				// are allocated instead of appending.
+				labelKeys := make([]string, 0, len(m.LabelKeys)+len(keys))
+				m.LabelKeys = append(append(labelKeys, m.LabelKeys...), keys...)
+				labelValues := make([]string, 0, len(m.LabelValues)+len(values))
+				m.LabelValues = append(append(labelValues, m.LabelValues...), values...)
+			}
+			return family
+		}
+		labeled[i] = f
+	}
+
+	return labeled
+}
+
+// ReplacePrefix returns copies of the given metric families whose names have
+// the given prefix replaced. Families without the prefix keep their names.
+func ReplacePrefix(families []FamilyGenerator, prefix, replacement string) []FamilyGenerator {
+	renamed := make([]FamilyGenerator, len(families))
+
+	for i, f := range families {
+		if strings.HasPrefix(f.Name, prefix) {
+			f.Name = replacement + strings.TrimPrefix(f.Name, prefix)
+		}
+		renamed[i] = f
+	}
+
+	return renamed
+}
+
+// FamilyHook wraps the generation of metric families, e.g. to enforce
+// site-specific policies like hashing user identifiers.
+type FamilyHook interface {
+	Wrap(g FamilyGenerator) FamilyGenerator
+}
+
+// FamilyTransformFunc is a FamilyHook transforming each generated family of
+// the given name, e.g. renaming labels, dropping metrics or rewriting values.
+// As label slices may be shared between metrics, they must be replaced rather
+// than modified in place.
+type FamilyTransformFunc func(name string, f *Family)
+
+// Wrap returns a copy of g whose generated families are transformed by fn.
+func (fn FamilyTransformFunc) Wrap(g FamilyGenerator) FamilyGenerator {
+	name, generate := g.Name, g.GenerateFunc
+	g.GenerateFunc = func(obj interface{}) *Family {
+		family := generate(obj)
+		fn(name, family)
+		return family
+	}
+	return g
+}
+
+// ApplyHooks returns the given metric families wrapped by the given hooks, the
+// first hook being the innermost.
+func ApplyHooks(families []FamilyGenerator, hooks []FamilyHook) []FamilyGenerator {
+	wrapped := make([]FamilyGenerator, len(families))
+
+	for i, f := range families {
+		for _, h := range hooks {
+			f = h.Wrap(f)
+		}
+		wrapped[i] = f
+	}
+
+	return wrapped
+}
+
 // ComposeMetricGenFuncs takes a slice of metric families and returns a function
 // that composes their metric generation functions into a single one.
 func ComposeMetricGenFuncs(familyGens []FamilyGenerator) func(obj interface{}) []metricsstore.FamilyByteSlicer {
@@ -100,3 +261,31 @@ func FilterMetricFamilies(l whiteBlackLister, families []FamilyGenerator) []Fami
 
 	return filtered
 }
+
+// FilterStableMetricFamilies returns the stable families of the given slice of
+// metric families.
+func FilterStableMetricFamilies(families []FamilyGenerator) []FamilyGenerator {
+	filtered := []FamilyGenerator{}
+
+	for _, f := range families {
+		if f.IsStable() {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return filtered
+}
+
+// FilterDeprecatedMetricFamilies returns the families of the given slice of
+// metric families which are not hidden in the given release.
+func FilterDeprecatedMetricFamilies(families []FamilyGenerator, release string) []FamilyGenerator {
+	filtered := []FamilyGenerator{}
+
+	for _, f := range families {
+		if !f.IsHidden(release) {
+			filtered = append(filtered, f)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExtractMetricFamilyHeadersWithOptions(t *testing.T) {
+	families := []FamilyGenerator{
+		{Name: "kube_pod_info", Help: "Information about pod.", Type: Gauge},
+	}
+
+	tests := []struct {
+		omitHelp bool
+		omitType bool
+		expected string
+	}{
+		{
+			expected: "# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge",
+		},
+		{
+			omitHelp: true,
+			expected: "# TYPE kube_pod_info gauge",
+		},
+		{
+			omitType: true,
+			expected: "# HELP kube_pod_info Information about pod.",
+		},
+		{
+			omitHelp: true,
+			omitType: true,
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		headers := ExtractMetricFamilyHeadersWithOptions(families, test.omitHelp, test.omitType)
+		if len(headers) != 1 || headers[0] != test.expected {
+			t.Errorf("omitHelp=%v omitType=%v: expected header %q but got %q", test.omitHelp, test.omitType, test.expected, headers)
+		}
+	}
+}
+
+func TestStabilityLevel(t *testing.T) {
+	families := []FamilyGenerator{
+		{Name: "kube_pod_info", Help: "Information about pod.", Type: Gauge},
+		{Name: "kube_pod_created", Help: "Unix creation timestamp.", Type: Gauge, StabilityLevel: Stable},
+		{Name: "kube_pod_alpha", Help: "Alpha metric.", Type: Gauge, StabilityLevel: Alpha},
+		{Name: "kube_pod_experimental", Help: "Experimental metric.", Type: Gauge, StabilityLevel: Experimental},
+	}
+
+	expected := []string{
+		"# HELP kube_pod_info Information about pod.",
+		"# HELP kube_pod_created Unix creation timestamp.",
+		"# HELP kube_pod_alpha [ALPHA] Alpha metric.",
+		"# HELP kube_pod_experimental [EXPERIMENTAL] Experimental metric.",
+	}
+	headers := ExtractMetricFamilyHeadersWithOptions(families, false, true)
+	for i := range expected {
+		if headers[i] != expected[i] {
+			t.Errorf("expected header %q but got %q", expected[i], headers[i])
+		}
+	}
+
+	stable := FilterStableMetricFamilies(families)
+	if len(stable) != 2 || stable[0].Name != "kube_pod_info" || stable[1].Name != "kube_pod_created" {
+		t.Errorf("expected the unset and stable families but got %v", stable)
+	}
+}
+
+func TestDeprecatedVersion(t *testing.T) {
+	families := []FamilyGenerator{
+		{Name: "kube_pod_info", Help: "Information about pod.", Type: Gauge},
+		{Name: "kube_pod_old", Help: "Old metric.", Type: Gauge, DeprecatedVersion: "1.4.0"},
+		{Name: "kube_pod_alpha_old", Help: "Old alpha metric.", Type: Gauge, StabilityLevel: Alpha, DeprecatedVersion: "1.9.0"},
+	}
+
+	expected := []string{
+		"# HELP kube_pod_info Information about pod.",
+		"# HELP kube_pod_old (Deprecated since 1.4.0) Old metric.",
+		"# HELP kube_pod_alpha_old [ALPHA] (Deprecated since 1.9.0) Old alpha metric.",
+	}
+	headers := ExtractMetricFamilyHeadersWithOptions(families, false, true)
+	for i := range expected {
+		if headers[i] != expected[i] {
+			t.Errorf("expected header %q but got %q", expected[i], headers[i])
+		}
+	}
+
+	tests := []struct {
+		release  string
+		expected []string
+	}{
+		{release: "v1.4.2", expected: []string{"kube_pod_info", "kube_pod_old", "kube_pod_alpha_old"}},
+		{release: "v1.9.6", expected: []string{"kube_pod_info", "kube_pod_alpha_old"}},
+		{release: "2.0.0", expected: []string{"kube_pod_info"}},
+		{release: "UNKNOWN", expected: []string{"kube_pod_info", "kube_pod_old", "kube_pod_alpha_old"}},
+	}
+
+	for _, test := range tests {
+		filtered := FilterDeprecatedMetricFamilies(families, test.release)
+		names := []string{}
+		for _, f := range filtered {
+			names = append(names, f.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("release %s: expected families %v but got %v", test.release, test.expected, names)
+		}
+	}
+}
+
+func TestAddLabels(t *testing.T) {
+	labelKeys := []string{"pod"}
+	families := []FamilyGenerator{
+		{
+			Name: "kube_pod_info",
+			Help: "Information about pod.",
+			Type: Gauge,
+			GenerateFunc: func(obj interface{}) *Family {
+				return &Family{
+					Metrics: []*Metric{
+						{LabelKeys: labelKeys, LabelValues: []string{obj.(string)}, Value: 1},
+					},
+				}
+			},
+		},
+	}
+
+	labeled := AddLabels(families, []string{"env", "region"}, []string{"prod", "eu"})
+	family := labeled[0].Generate("pod1")
+
+	expected := "kube_pod_info{pod=\"pod1\",env=\"prod\",region=\"eu\"} 1\n"
+	if got := string(family.ByteSlice()); got != expected {
+		t.Errorf("expected %q but got %q", expected, got)
+	}
+	if len(labelKeys) != 1 || len(families[0].Generate("pod1").Metrics[0].LabelKeys) != 1 {
+		t.Error("expected the original metric families to be left unchanged")
+	}
+}
+
+func TestAddObjectLabels(t *testing.T) {
+	families := []FamilyGenerator{
+		{
+			Name: "kube_pod_info",
+			Help: "Information about pod.",
+			Type: Gauge,
+			GenerateFunc: func(obj interface{}) *Family {
+				return &Family{
+					Metrics: []*Metric{
+						{LabelKeys: []string{"pod"}, LabelValues: []string{"pod1"}, Value: 1},
+					},
+				}
+			},
+		},
+	}
+
+	labeled := AddObjectLabels(families, []string{"cluster"}, func(obj interface{}) []string { return []string{obj.(string)} })
+	for _, cluster := range []string{"a", "b"} {
+		expected := fmt.Sprintf("kube_pod_info{pod=\"pod1\",cluster=%q} 1\n", cluster)
+		if got := string(labeled[0].Generate(cluster).ByteSlice()); got != expected {
+			t.Errorf("expected %q but got %q", expected, got)
+		}
+	}
+}
+
+func TestReplacePrefix(t *testing.T) {
+	families := []FamilyGenerator{
+		{Name: "kube_pod_info", Help: "Information about pod.", Type: Gauge},
+		{Name: "widget_info", Help: "Information about widget.", Type: Gauge},
+	}
+
+	renamed := ReplacePrefix(families, "kube_", "acme_")
+	if renamed[0].Name != "acme_pod_info" || renamed[1].Name != "widget_info" {
+		t.Errorf("expected names acme_pod_info and widget_info but got %s and %s", renamed[0].Name, renamed[1].Name)
+	}
+	if families[0].Name != "kube_pod_info" {
+		t.Errorf("expected the original metric families to be left unchanged but got %s", families[0].Name)
+	}
+
+	headers := ExtractMetricFamilyHeaders(renamed)
+	if expected := "# HELP acme_pod_info Information about pod.\n# TYPE acme_pod_info gauge"; headers[0] != expected {
+		t.Errorf("expected header %q but got %q", expected, headers[0])
+	}
+}
+
+func TestApplyHooks(t *testing.T) {
+	families := []FamilyGenerator{
+		{
+			Name: "kube_pod_info",
+			Help: "Information about pod.",
+			Type: Gauge,
+			GenerateFunc: func(obj interface{}) *Family {
+				return &Family{
+					Metrics: []*Metric{
+						{LabelKeys: []string{"pod", "user"}, LabelValues: []string{obj.(string), "alice"}, Value: 1},
+						{LabelKeys: []string{"pod", "user"}, LabelValues: []string{obj.(string), "bob"}, Value: 2},
+					},
+				}
+			},
+		},
+	}
+
+	dropBob := FamilyTransformFunc(func(name string, f *Family) {
+		metrics := f.Metrics[:0]
+		for _, m := range f.Metrics {
+			if m.LabelValues[1] != "bob" {
+				metrics = append(metrics, m)
+			}
+		}
+		f.Metrics = metrics
+	})
+	renameUser := FamilyTransformFunc(func(name string, f *Family) {
+		for _, m := range f.Metrics {
+			m.LabelKeys = []string{"pod", "owner"}
+			m.Value *= 10
+		}
+	})
+
+	hooked := ApplyHooks(families, []FamilyHook{dropBob, renameUser})
+	family := hooked[0].Generate("pod1")
+
+	expected := "kube_pod_info{pod=\"pod1\",owner=\"alice\"} 10\n"
+	if got := string(family.ByteSlice()); got != expected {
+		t.Errorf("expected %q but got %q", expected, got)
+	}
+	if got := len(families[0].Generate("pod1").Metrics); got != 2 {
+		t.Errorf("expected the original metric families to be left unchanged but got %d metrics", got)
+	}
+}
+
+func TestClassicType(t *testing.T) {
+	families := []FamilyGenerator{
+		{Name: "kube_pod_info", Help: "Information about pod.", Type: Info},
+		{Name: "kube_pod_status_phase", Help: "The pods current phase.", Type: StateSet},
+		{Name: "kube_pod_container_status_restarts_total", Help: "The number of container restarts per container.", Type: Counter},
+	}
+
+	expected := []string{
+		"# HELP kube_pod_info Information about pod.\n# TYPE kube_pod_info gauge",
+		"# HELP kube_pod_status_phase The pods current phase.\n# TYPE kube_pod_status_phase gauge",
+		"# HELP kube_pod_container_status_restarts_total The number of container restarts per container.\n# TYPE kube_pod_container_status_restarts_total counter",
+	}
+	for i, header := range ExtractMetricFamilyHeaders(families) {
+		if header != expected[i] {
+			t.Errorf("expected header %q but got %q", expected[i], header)
+		}
+	}
+}
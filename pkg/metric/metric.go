@@ -47,14 +47,145 @@ var Gauge Type = "gauge"
 // Counter defines a Prometheus counter.
 var Counter Type = "counter"
 
+// Histogram defines a Prometheus histogram, whose metrics are created with
+// NewHistogramMetrics.
+var Histogram Type = "histogram"
+
+// Summary defines a Prometheus summary, whose metrics are created with
+// NewSummaryMetrics.
+var Summary Type = "summary"
+
+// Info defines an OpenMetrics info, whose metrics expose textual information
+// as labels with a value of 1. The names of info families end with InfoSuffix.
+var Info Type = "info"
+
+// StateSet defines an OpenMetrics stateset, whose metrics expose whether an
+// object is in each of a set of states with a value of 1 or 0, e.g. the
+// phases of a pod.
+var StateSet Type = "stateset"
+
+// InfoSuffix is the suffix of the names of info metric families.
+const InfoSuffix = "_info"
+
+// ClassicType returns the type t is exposed as in the Prometheus text format,
+// which lacks info and stateset types, hence exposing them as gauges.
+func (t Type) ClassicType() Type {
+	if t == Info || t == StateSet {
+		return Gauge
+	}
+	return t
+}
+
 // Metric represents a single time series.
 type Metric struct {
 	// The name of a metric is injected by its family to reduce duplication.
+	// NameSuffix is appended to it, e.g. "_bucket" for the buckets of a
+	// histogram.
+	NameSuffix  string
 	LabelKeys   []string
 	LabelValues []string
 	Value       float64
 }
 
+// Histogram and summary metrics have a name suffix depending on what they
+// represent.
+const (
+	BucketSuffix = "_bucket"
+	SumSuffix    = "_sum"
+	CountSuffix  = "_count"
+)
+
+// Bucket is a bucket of a histogram, counting the observations less than or
+// equal to its upper bound, including the ones of all lower buckets.
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Quantile is a quantile of a summary, e.g. the 0.99 quantile of observations.
+type Quantile struct {
+	Quantile float64
+	Value    float64
+}
+
+// NewInfoMetric returns the metric of an info with the given labels.
+func NewInfoMetric(keys, values []string) *Metric {
+	return &Metric{LabelKeys: keys, LabelValues: values, Value: 1}
+}
+
+// NewStateSetMetrics returns the metrics of a stateset with the given labels:
+// a metric per state, labeled by the state with the given key, whose value is
+// 1 if isSet returns true for the state and 0 otherwise.
+func NewStateSetMetrics(keys, values []string, stateKey string, states []string, isSet func(state string) bool) []*Metric {
+	metrics := make([]*Metric, len(states))
+	for i, state := range states {
+		// The given label slices may be shared, hence new ones are allocated
+		// instead of appending.
+		labelKeys := make([]string, 0, len(keys)+1)
+		labelValues := make([]string, 0, len(values)+1)
+		metrics[i] = &Metric{
+			LabelKeys:   append(append(labelKeys, keys...), stateKey),
+			LabelValues: append(append(labelValues, values...), state),
+		}
+		if isSet(state) {
+			metrics[i].Value = 1
+		}
+	}
+	return metrics
+}
+
+// NewHistogramMetrics returns the metrics of a histogram with the given
+// labels: a metric per bucket, labeled by its upper bound, and the sum and
+// count of the observations. The buckets must be sorted by upper bound, and a
+// +Inf bucket counting all observations is added if missing.
+func NewHistogramMetrics(keys, values []string, buckets []Bucket, sum float64, count uint64) []*Metric {
+	metrics := make([]*Metric, 0, len(buckets)+3)
+	for _, b := range buckets {
+		metrics = append(metrics, newSuffixedMetric(BucketSuffix, keys, values, "le", b.UpperBound, float64(b.Count)))
+	}
+	if len(buckets) == 0 || !math.IsInf(buckets[len(buckets)-1].UpperBound, +1) {
+		metrics = append(metrics, newSuffixedMetric(BucketSuffix, keys, values, "le", math.Inf(+1), float64(count)))
+	}
+
+	return append(metrics,
+		&Metric{NameSuffix: SumSuffix, LabelKeys: keys, LabelValues: values, Value: sum},
+		&Metric{NameSuffix: CountSuffix, LabelKeys: keys, LabelValues: values, Value: float64(count)},
+	)
+}
+
+// NewSummaryMetrics returns the metrics of a summary with the given labels: a
+// metric per quantile, labeled by the quantile, and the sum and count of the
+// observations.
+func NewSummaryMetrics(keys, values []string, quantiles []Quantile, sum float64, count uint64) []*Metric {
+	metrics := make([]*Metric, 0, len(quantiles)+2)
+	for _, q := range quantiles {
+		metrics = append(metrics, newSuffixedMetric("", keys, values, "quantile", q.Quantile, q.Value))
+	}
+
+	return append(metrics,
+		&Metric{NameSuffix: SumSuffix, LabelKeys: keys, LabelValues: values, Value: sum},
+		&Metric{NameSuffix: CountSuffix, LabelKeys: keys, LabelValues: values, Value: float64(count)},
+	)
+}
+
+// newSuffixedMetric returns a metric with the given labels and an additional
+// label whose value is formatted like metric values.
+func newSuffixedMetric(suffix string, keys, values []string, key string, bound, value float64) *Metric {
+	s := strings.Builder{}
+	writeFloat(&s, bound)
+
+	// The given label slices may be shared, hence new ones are allocated
+	// instead of appending.
+	labelKeys := make([]string, 0, len(keys)+1)
+	labelValues := make([]string, 0, len(values)+1)
+	return &Metric{
+		NameSuffix:  suffix,
+		LabelKeys:   append(append(labelKeys, keys...), key),
+		LabelValues: append(append(labelValues, values...), s.String()),
+		Value:       value,
+	}
+}
+
 func (m *Metric) Write(s *strings.Builder) {
 	if len(m.LabelKeys) != len(m.LabelValues) {
 		panic(fmt.Sprintf(
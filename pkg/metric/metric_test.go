@@ -19,6 +19,8 @@ package metric
 import (
 	"strings"
 	"testing"
+
+	"github.com/prometheus/common/expfmt"
 )
 
 func TestFamilyString(t *testing.T) {
@@ -41,6 +43,102 @@ func TestFamilyString(t *testing.T) {
 	}
 }
 
+func TestLabelValueEscaping(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: `C:\path`, expected: `C:\\path`},
+		{value: `say "hello"`, expected: `say \"hello\"`},
+		{value: "line1\nline2\n", expected: `line1\nline2\n`},
+		{value: "{\n  \"key\": \"a\\\\b\"\n}", expected: `{\n  \"key\": \"a\\\\b\"\n}`},
+		{value: "tab\tand unicode ✓", expected: "tab\tand unicode ✓"},
+	}
+
+	for _, test := range tests {
+		f := Family{
+			Name: "kube_pod_annotations",
+			Metrics: []*Metric{
+				{LabelKeys: []string{"annotation_config"}, LabelValues: []string{test.value}, Value: 1},
+			},
+		}
+
+		expected := "kube_pod_annotations{annotation_config=\"" + test.expected + "\"} 1\n"
+		got := string(f.ByteSlice())
+		if got != expected {
+			t.Errorf("expected %q but got %q", expected, got)
+			continue
+		}
+
+		var parser expfmt.TextParser
+		families, err := parser.TextToMetricFamilies(strings.NewReader(got))
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", got, err)
+			continue
+		}
+		if parsed := families["kube_pod_annotations"].GetMetric()[0].GetLabel()[0].GetValue(); parsed != test.value {
+			t.Errorf("expected parsed label value %q but got %q", test.value, parsed)
+		}
+	}
+}
+
+func TestStateSetString(t *testing.T) {
+	f := Family{
+		Name: "kube_pod_status_phase",
+		Metrics: NewStateSetMetrics([]string{"pod"}, []string{"pod1"}, "phase", []string{"Pending", "Running"}, func(state string) bool {
+			return state == "Running"
+		}),
+	}
+
+	expected := `kube_pod_status_phase{pod="pod1",phase="Pending"} 0
+kube_pod_status_phase{pod="pod1",phase="Running"} 1
+`
+	if got := string(f.ByteSlice()); got != expected {
+		t.Fatalf("expected:\n%v\nbut got:\n%v", expected, got)
+	}
+}
+
+func TestHistogramString(t *testing.T) {
+	f := Family{
+		Name: "kube_pod_startup_duration_seconds",
+		Metrics: NewHistogramMetrics(
+			[]string{"namespace"}, []string{"default"},
+			[]Bucket{{UpperBound: 0.5, Count: 1}, {UpperBound: 2.5, Count: 3}},
+			5.2, 4,
+		),
+	}
+
+	expected := `kube_pod_startup_duration_seconds_bucket{namespace="default",le="0.5"} 1
+kube_pod_startup_duration_seconds_bucket{namespace="default",le="2.5"} 3
+kube_pod_startup_duration_seconds_bucket{namespace="default",le="+Inf"} 4
+kube_pod_startup_duration_seconds_sum{namespace="default"} 5.2
+kube_pod_startup_duration_seconds_count{namespace="default"} 4
+`
+	if got := string(f.ByteSlice()); got != expected {
+		t.Fatalf("expected:\n%v\nbut got:\n%v", expected, got)
+	}
+}
+
+func TestSummaryString(t *testing.T) {
+	f := Family{
+		Name: "kube_pod_startup_duration_seconds",
+		Metrics: NewSummaryMetrics(
+			[]string{"namespace"}, []string{"default"},
+			[]Quantile{{Quantile: 0.5, Value: 1.2}, {Quantile: 0.99, Value: 2}},
+			5.2, 4,
+		),
+	}
+
+	expected := `kube_pod_startup_duration_seconds{namespace="default",quantile="0.5"} 1.2
+kube_pod_startup_duration_seconds{namespace="default",quantile="0.99"} 2
+kube_pod_startup_duration_seconds_sum{namespace="default"} 5.2
+kube_pod_startup_duration_seconds_count{namespace="default"} 4
+`
+	if got := string(f.ByteSlice()); got != expected {
+		t.Fatalf("expected:\n%v\nbut got:\n%v", expected, got)
+	}
+}
+
 func BenchmarkMetricWrite(b *testing.B) {
 	tests := []struct {
 		testName       string
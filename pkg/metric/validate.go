@@ -0,0 +1,338 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CounterSuffix is the suffix of the names of counter metric families.
+const CounterSuffix = "_total"
+
+// LabelConflictSuffix is appended to label names occurring more than once in
+// a metric, followed by a number counting from 1.
+const LabelConflictSuffix = "_conflict"
+
+// NewMetric returns a metric with the given labels and value, or an error if a
+// label name is invalid or the numbers of label keys and values differ.
+func NewMetric(keys, values []string, value float64) (*Metric, error) {
+	m := &Metric{LabelKeys: keys, LabelValues: values, Value: value}
+	if err := m.validateLabels(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NewCounterMetric returns a metric like NewMetric, additionally rejecting
+// negative and NaN values, which are not meaningful for counters.
+func NewCounterMetric(keys, values []string, value float64) (*Metric, error) {
+	if err := validateCounterValue(value); err != nil {
+		return nil, err
+	}
+	return NewMetric(keys, values, value)
+}
+
+// Validate returns an error if the name, type or generate function of the
+// family generator is invalid.
+func (g *FamilyGenerator) Validate() error {
+	if err := ValidateFamily(g.Name, g.Type); err != nil {
+		return err
+	}
+	if g.GenerateFunc == nil {
+		return fmt.Errorf("missing generate function of metric %s", g.Name)
+	}
+	return nil
+}
+
+// ValidateFamily returns an error if the name or type of a metric family is
+// invalid. The names of counters must end with CounterSuffix, and the ones of
+// infos with InfoSuffix.
+func ValidateFamily(name string, t Type) error {
+	if !IsValidMetricName(name) {
+		return fmt.Errorf("invalid metric name %q", name)
+	}
+	switch t {
+	case Gauge, StateSet, Histogram, Summary:
+	case Info:
+		if !strings.HasSuffix(name, InfoSuffix) {
+			return fmt.Errorf("name of info %s must end with %s", name, InfoSuffix)
+		}
+	case Counter:
+		if !strings.HasSuffix(name, CounterSuffix) {
+			return fmt.Errorf("name of counter %s must end with %s", name, CounterSuffix)
+		}
+	default:
+		return fmt.Errorf("unsupported type %q of metric %s", t, name)
+	}
+	return nil
+}
+
+// validate returns an error if the metric would be malformed in a family of the
+// given type.
+func (m *Metric) validate(t Type) error {
+	switch t {
+	case Counter:
+		if err := validateCounterValue(m.Value); err != nil {
+			return err
+		}
+	case Info:
+		if m.Value != 1 {
+			return fmt.Errorf("info value must be 1 but is %v", m.Value)
+		}
+	case StateSet:
+		if m.Value != 0 && m.Value != 1 {
+			return fmt.Errorf("stateset value must be 0 or 1 but is %v", m.Value)
+		}
+	case Histogram, Summary:
+		// Histograms consist of buckets and summaries of quantiles, which have
+		// no name suffix, both followed by the sum and count of observations.
+		switch {
+		case m.NameSuffix == SumSuffix:
+		case m.NameSuffix == CountSuffix, m.NameSuffix == BucketSuffix && t == Histogram:
+			if err := validateCounterValue(m.Value); err != nil {
+				return err
+			}
+		case m.NameSuffix == "" && t == Summary:
+		default:
+			return fmt.Errorf("invalid name suffix %q of %s metric", m.NameSuffix, t)
+		}
+		return m.validateLabels()
+	}
+	if m.NameSuffix != "" {
+		return fmt.Errorf("unexpected name suffix %q of %s metric", m.NameSuffix, t)
+	}
+	return m.validateLabels()
+}
+
+func validateCounterValue(value float64) error {
+	if math.IsNaN(value) || value < 0 {
+		return fmt.Errorf("counter value must not be negative or NaN but is %v", value)
+	}
+	return nil
+}
+
+func (m *Metric) validateLabels() error {
+	if len(m.LabelKeys) != len(m.LabelValues) {
+		return fmt.Errorf("expected label keys %q to be of same length as label values %q", m.LabelKeys, m.LabelValues)
+	}
+	for _, k := range m.LabelKeys {
+		if !IsValidLabelName(k) {
+			return fmt.Errorf("invalid label name %q", k)
+		}
+	}
+	return nil
+}
+
+// ValidateFamilies returns copies of the given metric families dropping
+// malformed metrics at generation time, which would otherwise break the
+// exposition of all metrics. Each dropped metric is reported to onError with
+// the name of its family. Labels whose names occur more than once in a metric,
+// e.g. an object label conflicting with a custom label, are renamed by
+// resolveDuplicateLabels instead.
+func ValidateFamilies(families []FamilyGenerator, onError func(name string, err error)) []FamilyGenerator {
+	validated := make([]FamilyGenerator, len(families))
+
+	for i, f := range families {
+		name, t, generate := f.Name, f.Type, f.GenerateFunc
+		f.GenerateFunc = func(obj interface{}) *Family {
+			family := generate(obj)
+			metrics := family.Metrics[:0]
+			for _, m := range family.Metrics {
+				m.resolveDuplicateLabels()
+				if err := m.validate(t); err != nil {
+					onError(name, err)
+					continue
+				}
+				metrics = append(metrics, m)
+			}
+			family.Metrics = metrics
+			return family
+		}
+		validated[i] = f
+	}
+
+	return validated
+}
+
+// SanitizeLabelValues returns copies of the given metric families replacing
+// invalid UTF-8 sequences and control characters other than tabs and new lines
+// in label values by the Unicode replacement character U+FFFD at generation
+// time. Such values, e.g. of annotations, would otherwise break the parsing of
+// the whole exposition. Each sanitized metric is reported to onSanitize with
+// the name of its family.
+func SanitizeLabelValues(families []FamilyGenerator, onSanitize func(name string)) []FamilyGenerator {
+	sanitized := make([]FamilyGenerator, len(families))
+
+	for i, f := range families {
+		name, generate := f.Name, f.GenerateFunc
+		f.GenerateFunc = func(obj interface{}) *Family {
+			family := generate(obj)
+			for _, m := range family.Metrics {
+				if m.sanitizeLabelValues() {
+					onSanitize(name)
+				}
+			}
+			return family
+		}
+		sanitized[i] = f
+	}
+
+	return sanitized
+}
+
+// sanitizeLabelValues sanitizes the label values of the metric with
+// SanitizeLabelValue and returns whether any of them was changed.
+func (m *Metric) sanitizeLabelValues() bool {
+	var values []string
+	for i, v := range m.LabelValues {
+		s, ok := SanitizeLabelValue(v)
+		if !ok {
+			continue
+		}
+		// The label values of metrics may be shared, hence a new slice is
+		// allocated instead of replacing them in place.
+		if values == nil {
+			values = make([]string, len(m.LabelValues))
+			copy(values, m.LabelValues)
+		}
+		values[i] = s
+	}
+	if values == nil {
+		return false
+	}
+	m.LabelValues = values
+	return true
+}
+
+// SanitizeLabelValue returns v with invalid UTF-8 sequences and control
+// characters other than tabs and new lines replaced by utf8.RuneError, and
+// whether v was changed.
+func SanitizeLabelValue(v string) (string, bool) {
+	i := 0
+	for i < len(v) {
+		if c := v[i]; c >= 0x20 && c < 0x7f {
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(v[i:])
+		if invalidLabelValueRune(r, size) {
+			break
+		}
+		i += size
+	}
+	if i == len(v) {
+		return v, false
+	}
+
+	var b strings.Builder
+	b.Grow(len(v) + 2)
+	b.WriteString(v[:i])
+	for i < len(v) {
+		r, size := utf8.DecodeRuneInString(v[i:])
+		if invalidLabelValueRune(r, size) {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(v[i : i+size])
+		}
+		i += size
+	}
+	return b.String(), true
+}
+
+func invalidLabelValueRune(r rune, size int) bool {
+	if r == utf8.RuneError && size == 1 {
+		return true
+	}
+	return unicode.IsControl(r) && r != '\t' && r != '\n'
+}
+
+// resolveDuplicateLabels keeps the first label of each name, typically one
+// identifying the object, and appends LabelConflictSuffix and a number to the
+// names of the following ones, so that no label name occurs more than once.
+func (m *Metric) resolveDuplicateLabels() {
+	duplicates := false
+	for i := 0; i < len(m.LabelKeys) && !duplicates; i++ {
+		for j := i + 1; j < len(m.LabelKeys); j++ {
+			if m.LabelKeys[i] == m.LabelKeys[j] {
+				duplicates = true
+				break
+			}
+		}
+	}
+	if !duplicates {
+		return
+	}
+
+	// The label keys of metrics may be shared, hence a new slice is allocated
+	// instead of renaming in place.
+	keys := make([]string, len(m.LabelKeys))
+	copy(keys, m.LabelKeys)
+	taken := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		taken[k] = false
+	}
+	numbers := map[string]int{}
+	for i, k := range keys {
+		if !taken[k] {
+			taken[k] = true
+			continue
+		}
+		for {
+			numbers[k]++
+			renamed := k + LabelConflictSuffix + strconv.Itoa(numbers[k])
+			if _, ok := taken[renamed]; !ok {
+				taken[renamed] = true
+				keys[i] = renamed
+				break
+			}
+		}
+	}
+	m.LabelKeys = keys
+}
+
+// IsValidMetricName returns whether the given name matches
+// [a-zA-Z_:][a-zA-Z0-9_:]*.
+func IsValidMetricName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':' || c >= '0' && c <= '9' && i > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidLabelName returns whether the given name matches
+// [a-zA-Z_][a-zA-Z0-9_]*.
+func IsValidLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9' && i > 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestNewMetric(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Keys    []string
+		Values  []string
+		Value   float64
+		Counter bool
+		WantErr bool
+	}{
+		{Desc: "valid labels", Keys: []string{"pod", "_ns2"}, Values: []string{"a", "b"}, Value: 1},
+		{Desc: "no labels", Value: 1},
+		{Desc: "gauge NaN", Keys: []string{"pod"}, Values: []string{"a"}, Value: math.NaN()},
+		{Desc: "counter NaN", Keys: []string{"pod"}, Values: []string{"a"}, Value: math.NaN(), Counter: true, WantErr: true},
+		{Desc: "negative counter", Keys: []string{"pod"}, Values: []string{"a"}, Value: -1, Counter: true, WantErr: true},
+		{Desc: "counter value", Keys: []string{"pod"}, Values: []string{"a"}, Value: 3, Counter: true},
+		{Desc: "missing value", Keys: []string{"pod", "namespace"}, Values: []string{"a"}, WantErr: true},
+		{Desc: "label starting with digit", Keys: []string{"1pod"}, Values: []string{"a"}, WantErr: true},
+		{Desc: "label with colon", Keys: []string{"pod:name"}, Values: []string{"a"}, WantErr: true},
+		{Desc: "empty label", Keys: []string{""}, Values: []string{"a"}, WantErr: true},
+	}
+
+	for _, test := range tests {
+		newMetric := NewMetric
+		if test.Counter {
+			newMetric = NewCounterMetric
+		}
+		_, err := newMetric(test.Keys, test.Values, test.Value)
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Want error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestFamilyGeneratorValidate(t *testing.T) {
+	generate := func(obj interface{}) *Family { return &Family{} }
+	tests := []struct {
+		Desc      string
+		Generator FamilyGenerator
+		WantErr   bool
+	}{
+		{Desc: "valid", Generator: FamilyGenerator{Name: "kube_pod_info", Type: Gauge, GenerateFunc: generate}},
+		{Desc: "recording rule name", Generator: FamilyGenerator{Name: "namespace:kube_pod_info:sum", Type: Gauge, GenerateFunc: generate}},
+		{Desc: "counter", Generator: FamilyGenerator{Name: "kube_pod_container_status_restarts_total", Type: Counter, GenerateFunc: generate}},
+		{Desc: "counter without total suffix", Generator: FamilyGenerator{Name: "kube_pod_container_status_restarts", Type: Counter, GenerateFunc: generate}, WantErr: true},
+		{Desc: "histogram", Generator: FamilyGenerator{Name: "kube_pod_startup_duration_seconds", Type: Histogram, GenerateFunc: generate}},
+		{Desc: "summary", Generator: FamilyGenerator{Name: "kube_pod_startup_duration_seconds", Type: Summary, GenerateFunc: generate}},
+		{Desc: "info", Generator: FamilyGenerator{Name: "kube_pod_info", Type: Info, GenerateFunc: generate}},
+		{Desc: "info without info suffix", Generator: FamilyGenerator{Name: "kube_pod_labels", Type: Info, GenerateFunc: generate}, WantErr: true},
+		{Desc: "stateset", Generator: FamilyGenerator{Name: "kube_pod_status_phase", Type: StateSet, GenerateFunc: generate}},
+		{Desc: "invalid name", Generator: FamilyGenerator{Name: "kube-pod-info", Type: Gauge, GenerateFunc: generate}, WantErr: true},
+		{Desc: "empty name", Generator: FamilyGenerator{Type: Gauge, GenerateFunc: generate}, WantErr: true},
+		{Desc: "invalid type", Generator: FamilyGenerator{Name: "kube_pod_info", Type: "gague", GenerateFunc: generate}, WantErr: true},
+		{Desc: "missing generate function", Generator: FamilyGenerator{Name: "kube_pod_info", Type: Gauge}, WantErr: true},
+	}
+
+	for _, test := range tests {
+		err := test.Generator.Validate()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Want error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestValidateMetric(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Type    Type
+		Metric  Metric
+		WantErr bool
+	}{
+		{Desc: "histogram bucket", Type: Histogram, Metric: Metric{NameSuffix: BucketSuffix, LabelKeys: []string{"le"}, LabelValues: []string{"1"}, Value: 2}},
+		{Desc: "histogram sum", Type: Histogram, Metric: Metric{NameSuffix: SumSuffix, Value: -2}},
+		{Desc: "histogram negative count", Type: Histogram, Metric: Metric{NameSuffix: CountSuffix, Value: -2}, WantErr: true},
+		{Desc: "histogram without suffix", Type: Histogram, Metric: Metric{Value: 2}, WantErr: true},
+		{Desc: "summary quantile", Type: Summary, Metric: Metric{LabelKeys: []string{"quantile"}, LabelValues: []string{"0.5"}, Value: math.NaN()}},
+		{Desc: "summary bucket", Type: Summary, Metric: Metric{NameSuffix: BucketSuffix, Value: 2}, WantErr: true},
+		{Desc: "info", Type: Info, Metric: Metric{LabelKeys: []string{"node"}, LabelValues: []string{"a"}, Value: 1}},
+		{Desc: "info not 1", Type: Info, Metric: Metric{LabelKeys: []string{"node"}, LabelValues: []string{"a"}, Value: 2}, WantErr: true},
+		{Desc: "stateset unset", Type: StateSet, Metric: Metric{LabelKeys: []string{"phase"}, LabelValues: []string{"Running"}, Value: 0}},
+		{Desc: "stateset not boolean", Type: StateSet, Metric: Metric{LabelKeys: []string{"phase"}, LabelValues: []string{"Running"}, Value: 0.5}, WantErr: true},
+		{Desc: "gauge with suffix", Type: Gauge, Metric: Metric{NameSuffix: SumSuffix, Value: 2}, WantErr: true},
+	}
+
+	for _, test := range tests {
+		err := test.Metric.validate(test.Type)
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Want error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestValidateFamilies(t *testing.T) {
+	families := []FamilyGenerator{
+		{
+			Name: "kube_pod_container_status_restarts_total",
+			Type: Counter,
+			GenerateFunc: func(obj interface{}) *Family {
+				return &Family{
+					Metrics: []*Metric{
+						{LabelKeys: []string{"container"}, LabelValues: []string{"a"}, Value: 1},
+						{LabelKeys: []string{"container"}, LabelValues: []string{"b"}, Value: math.NaN()},
+						{LabelKeys: []string{"container"}, LabelValues: []string{"e"}, Value: -1},
+						{LabelKeys: []string{"container", "pod"}, LabelValues: []string{"c"}, Value: 1},
+						{LabelKeys: []string{"container-name"}, LabelValues: []string{"d"}, Value: 1},
+					},
+				}
+			},
+		},
+	}
+
+	dropped := 0
+	validated := ValidateFamilies(families, func(name string, err error) {
+		if name != "kube_pod_container_status_restarts_total" {
+			t.Errorf("unexpected family %s", name)
+		}
+		dropped++
+	})
+
+	expected := "kube_pod_container_status_restarts_total{container=\"a\"} 1\n"
+	if got := string(validated[0].Generate(nil).ByteSlice()); got != expected {
+		t.Errorf("expected %q but got %q", expected, got)
+	}
+	if dropped != 4 {
+		t.Errorf("expected 4 dropped metrics but got %d", dropped)
+	}
+}
+
+func TestResolveDuplicateLabels(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Keys     []string
+		Expected []string
+	}{
+		{Desc: "no duplicates", Keys: []string{"namespace", "pod"}, Expected: []string{"namespace", "pod"}},
+		{Desc: "custom label conflicting with identity label", Keys: []string{"namespace", "pod", "namespace"}, Expected: []string{"namespace", "pod", "namespace_conflict1"}},
+		{Desc: "several duplicates", Keys: []string{"pod", "pod", "pod"}, Expected: []string{"pod", "pod_conflict1", "pod_conflict2"}},
+		{Desc: "suffixed name taken", Keys: []string{"pod", "pod", "pod_conflict1"}, Expected: []string{"pod", "pod_conflict2", "pod_conflict1"}},
+	}
+
+	for _, test := range tests {
+		keys := append([]string{}, test.Keys...)
+		m := &Metric{LabelKeys: keys, LabelValues: make([]string, len(keys))}
+		m.resolveDuplicateLabels()
+		if !reflect.DeepEqual(m.LabelKeys, test.Expected) {
+			t.Errorf("Test error for Desc: %s. Expected %v but got %v", test.Desc, test.Expected, m.LabelKeys)
+		}
+		if !reflect.DeepEqual(keys, test.Keys) {
+			t.Errorf("Test error for Desc: %s. Expected shared label keys to be left unchanged but got %v", test.Desc, keys)
+		}
+	}
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Value    string
+		Expected string
+		Changed  bool
+	}{
+		{Desc: "ascii", Value: "nginx:1.17", Expected: "nginx:1.17"},
+		{Desc: "tabs, new lines and quotes", Value: "{\n\t\"a\": 1\n}", Expected: "{\n\t\"a\": 1\n}"},
+		{Desc: "multi-byte characters", Value: "ünïcødé ✓ 日本", Expected: "ünïcødé ✓ 日本"},
+		{Desc: "replacement character", Value: "a\uFFFDb", Expected: "a\uFFFDb"},
+		{Desc: "invalid byte", Value: "a\xffb", Expected: "a\uFFFDb", Changed: true},
+		{Desc: "truncated sequence", Value: "日本"[:4], Expected: "日\uFFFD", Changed: true},
+		{Desc: "null and escape characters", Value: "a\x00b\x1b[0m", Expected: "a\uFFFDb\uFFFD[0m", Changed: true},
+		{Desc: "delete and C1 control characters", Value: "a\x7fb\u0085", Expected: "a\uFFFDb\uFFFD", Changed: true},
+	}
+
+	for _, test := range tests {
+		got, changed := SanitizeLabelValue(test.Value)
+		if got != test.Expected || changed != test.Changed {
+			t.Errorf("Test error for Desc: %s. Expected (%q, %v) but got (%q, %v)", test.Desc, test.Expected, test.Changed, got, changed)
+		}
+	}
+}
+
+func TestSanitizeLabelValues(t *testing.T) {
+	values := []string{"ns1", "a\xffb\x00"}
+	families := []FamilyGenerator{
+		{
+			Name: "kube_pod_annotations",
+			Type: Gauge,
+			GenerateFunc: func(obj interface{}) *Family {
+				return &Family{Metrics: []*Metric{
+					{LabelKeys: []string{"namespace", "annotation_a"}, LabelValues: values, Value: 1},
+					{LabelKeys: []string{"namespace"}, LabelValues: []string{"ns2"}, Value: 1},
+				}}
+			},
+		},
+	}
+
+	sanitized := 0
+	families = SanitizeLabelValues(families, func(name string) {
+		if name != "kube_pod_annotations" {
+			t.Errorf("Unexpected family %s", name)
+		}
+		sanitized++
+	})
+	family := families[0].Generate(nil)
+	family.Name = families[0].Name
+
+	if sanitized != 1 {
+		t.Errorf("Expected 1 sanitized metric but got %d", sanitized)
+	}
+	if !reflect.DeepEqual(values, []string{"ns1", "a\xffb\x00"}) {
+		t.Errorf("Expected shared label values to be left unchanged but got %q", values)
+	}
+
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(strings.NewReader(string(family.ByteSlice())))
+	if err != nil {
+		t.Fatalf("Failed to parse sanitized metrics: %v", err)
+	}
+	if got := parsed["kube_pod_annotations"].GetMetric()[0].GetLabel()[1].GetValue(); got != "a\uFFFDb\uFFFD" {
+		t.Errorf("Expected sanitized label value %q but got %q", "a\uFFFDb\uFFFD", got)
+	}
+}
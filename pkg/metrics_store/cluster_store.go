@@ -0,0 +1,162 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsstore
+
+import (
+	"bytes"
+	"strings"
+)
+
+// clusterState is the state of the store of a cluster populating a
+// MetricsStore in multi-cluster mode.
+type clusterState struct {
+	synced   bool
+	degraded bool
+}
+
+// ClusterStore implements the k8s.io/client-go/tools/cache.Store interface
+// for the reflector of a single cluster populating a MetricsStore shared by
+// several clusters. It records the name of the cluster along with the
+// metrics of the objects it is given, so that they can be told apart, and only
+// replaces the entries of its own cluster on relists.
+type ClusterStore struct {
+	*MetricsStore
+	cluster string
+}
+
+// ClusterStore returns the store the reflector of the given cluster populates
+// s through. Once a cluster store is returned, s is only considered synced
+// when all of its cluster stores are, and degraded when any of them is.
+func (s *MetricsStore) ClusterStore(cluster string) *ClusterStore {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.clusters == nil {
+		s.clusters = map[string]*clusterState{}
+	}
+	if _, ok := s.clusters[cluster]; !ok {
+		s.clusters[cluster] = &clusterState{}
+	}
+	return &ClusterStore{MetricsStore: s, cluster: cluster}
+}
+
+// Add adds the metrics of obj to the store.
+func (c *ClusterStore) Add(obj interface{}) error {
+	return c.MetricsStore.add(obj, c.cluster)
+}
+
+// Update updates the metrics of obj in the store.
+func (c *ClusterStore) Update(obj interface{}) error {
+	return c.MetricsStore.add(obj, c.cluster)
+}
+
+// Delete deletes the metrics of obj from the store.
+func (c *ClusterStore) Delete(obj interface{}) error {
+	return c.MetricsStore.delete(obj, c.cluster)
+}
+
+// Replace replaces the metrics of the objects of the cluster with the ones of
+// the given list, keeping the ones of other clusters.
+func (c *ClusterStore) Replace(list []interface{}, _ string) error {
+	lists := map[string][]interface{}{c.cluster: list}
+	err := c.MetricsStore.replace(lists, func(key objectKey) bool { return key.cluster != c.cluster })
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.clusters[c.cluster].synced = true
+	c.mutex.Unlock()
+
+	return nil
+}
+
+// HasSynced returns true once the store of the cluster has been populated by
+// an initial list.
+func (c *ClusterStore) HasSynced() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.clusters[c.cluster].synced
+}
+
+// SetDegraded records whether the lists or watches of the reflector of the
+// cluster currently fail. The shared store is degraded while the reflector
+// of any of its clusters is.
+func (c *ClusterStore) SetDegraded(degraded bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.clusters[c.cluster].degraded = degraded
+	for _, state := range c.clusters {
+		if state.degraded {
+			c.MetricsStore.SetDegraded(true)
+			return
+		}
+	}
+	c.MetricsStore.SetDegraded(false)
+}
+
+// Degraded returns true if the lists or watches of the reflector of the
+// cluster currently fail.
+func (c *ClusterStore) Degraded() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.clusters[c.cluster].degraded
+}
+
+// labelValueEscaper escapes label values as the text exposition format
+// requires.
+var labelValueEscaper = strings.NewReplacer("\\", `\\`, "\n", `\n`, "\"", `\"`)
+
+// addLabel returns the given metrics of a metric family, one per line, with
+// the given formatted label appended to the labels of each of them.
+func addLabel(family []byte, label string) []byte {
+	labeled := make([]byte, 0, len(family)+bytes.Count(family, []byte{'\n'})*(len(label)+2))
+	for len(family) > 0 {
+		line := family
+		if i := bytes.IndexByte(family, '\n'); i >= 0 {
+			line = family[:i+1]
+		}
+		family = family[len(line):]
+
+		// The value follows the last space of a metric and contains no
+		// braces, hence its labels, if any, end at the last brace before.
+		value := bytes.LastIndexByte(line, ' ')
+		if value < 0 {
+			labeled = append(labeled, line...)
+			continue
+		}
+		end := bytes.LastIndexByte(line[:value], '}')
+		if end < 0 {
+			labeled = append(labeled, line[:value]...)
+			labeled = append(labeled, '{')
+			labeled = append(labeled, label...)
+			labeled = append(labeled, '}')
+			labeled = append(labeled, line[value:]...)
+			continue
+		}
+		labeled = append(labeled, line[:end]...)
+		if line[end-1] != '{' {
+			labeled = append(labeled, ',')
+		}
+		labeled = append(labeled, label...)
+		labeled = append(labeled, line[end:]...)
+	}
+	return labeled
+}
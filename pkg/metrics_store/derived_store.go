@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsstore
+
+import (
+	"time"
+)
+
+// NamespaceCount is the number of objects a store holds metrics of in a
+// namespace of a cluster.
+type NamespaceCount struct {
+	Cluster   string
+	Namespace string
+	Objects   int
+}
+
+// NamespaceCounts returns the number of objects the store holds metrics of
+// per namespace, sorted by cluster and namespace. Objects without a
+// namespace, i.e. of cluster-scoped resources, are not counted.
+func (s *MetricsStore) NamespaceCounts() []NamespaceCount {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	counts := []NamespaceCount{}
+	for _, key := range s.keys {
+		if key.namespace == "" {
+			continue
+		}
+		if n := len(counts); n > 0 && counts[n-1].Cluster == key.cluster && counts[n-1].Namespace == key.namespace {
+			counts[n-1].Objects++
+			continue
+		}
+		counts = append(counts, NamespaceCount{Cluster: key.cluster, Namespace: key.namespace, Objects: 1})
+	}
+	return counts
+}
+
+// NewDerivedMetricsStore returns a new MetricsStore that is not populated by a
+// reflector, but holds the metrics of the objects returned by list by cluster,
+// which is called each time the store is written, e.g. to derive metrics from
+// the contents of the given source stores. The cluster is empty unless in
+// multi-cluster mode. The store is considered synced once
+// all of its sources are, active as long as all of them are, and degraded
+// while any of them is.
+func NewDerivedMetricsStore(headers []string, generateFunc func(interface{}) []FamilyByteSlicer, sources []*MetricsStore, list func() map[string][]interface{}) *MetricsStore {
+	s := NewMetricsStore(headers, generateFunc)
+	s.sources = sources
+	s.list = list
+	return s
+}
+
+// refresh replaces the contents of a derived store with the objects returned
+// by its list func.
+func (s *MetricsStore) refresh() {
+	if s.list == nil {
+		return
+	}
+	s.replace(s.list(), nil)
+}
+
+// sourcesSynced returns true once all sources of a derived store have synced.
+func (s *MetricsStore) sourcesSynced() bool {
+	for _, source := range s.sources {
+		if !source.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// sourcesLastActive returns the time the least recently active source of a
+// derived store was last active.
+func (s *MetricsStore) sourcesLastActive() time.Time {
+	var lastActive time.Time
+	for i, source := range s.sources {
+		if t := source.LastActive(); i == 0 || t.Before(lastActive) {
+			lastActive = t
+		}
+	}
+	return lastActive
+}
+
+// sourcesDegraded returns true if any source of a derived store is degraded.
+func (s *MetricsStore) sourcesDegraded() bool {
+	for _, source := range s.sources {
+		if source.Degraded() {
+			return true
+		}
+	}
+	return false
+}
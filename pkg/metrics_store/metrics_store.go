@@ -18,10 +18,15 @@ package metricsstore
 
 import (
 	"io"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 )
 
 // FamilyByteSlicer represents a metric family that can be converted to its string
@@ -41,14 +46,64 @@ type MetricsStore struct {
 	// grouped by metric families in order to zip families with their help text in
 	// MetricsStore.WriteAll().
 	metrics map[types.UID][][]byte
+	// keys holds the keys of the objects in metrics, sorted by cluster,
+	// namespace, name and UID, which is the order their metrics are written
+	// in, so that the output of successive scrapes is stable.
+	keys []objectKey
+	// clusters holds the state of the stores of each cluster populating the
+	// store in multi-cluster mode, by cluster name. Protected by mutex.
+	clusters map[string]*clusterState
 	// headers contains the header (TYPE and HELP) of each metric family. It is
 	// later on zipped with with their corresponding metric families in
 	// MetricStore.WriteAll().
 	headers []string
+	// lastSynced is the time, in Unix nanoseconds, the store was last
+	// populated by a list of its reflector, or 0 if it has not been yet.
+	lastSynced int64
+	// lastActive is the time, in Unix nanoseconds, the reflector populating
+	// the store was last observed to be active.
+	lastActive int64
+	// degraded is 1 while the lists or watches of the reflector populating
+	// the store fail.
+	degraded int32
 
 	// generateMetricsFunc generates metrics based on a given Kubernetes object
 	// and returns them grouped by metric family.
 	generateMetricsFunc func(interface{}) []FamilyByteSlicer
+	// clusterLabel is the name of the label the metrics of the objects of
+	// each cluster are labeled with the name of the cluster, if set.
+	clusterLabel string
+
+	// sources are the stores a derived store is derived from, and list
+	// returns the objects it holds the metrics of. Both are nil unless the
+	// store was created by NewDerivedMetricsStore.
+	sources []*MetricsStore
+	list    func() map[string][]interface{}
+}
+
+// objectKey identifies an object in the store.
+type objectKey struct {
+	cluster   string
+	namespace string
+	name      string
+	uid       types.UID
+}
+
+func newObjectKey(cluster string, o metav1.Object) objectKey {
+	return objectKey{cluster: cluster, namespace: o.GetNamespace(), name: o.GetName(), uid: o.GetUID()}
+}
+
+func (k objectKey) less(other objectKey) bool {
+	if k.cluster != other.cluster {
+		return k.cluster < other.cluster
+	}
+	if k.namespace != other.namespace {
+		return k.namespace < other.namespace
+	}
+	if k.name != other.name {
+		return k.name < other.name
+	}
+	return k.uid < other.uid
 }
 
 // NewMetricsStore returns a new MetricsStore
@@ -57,14 +112,28 @@ func NewMetricsStore(headers []string, generateFunc func(interface{}) []FamilyBy
 		generateMetricsFunc: generateFunc,
 		headers:             headers,
 		metrics:             map[types.UID][][]byte{},
+		lastActive:          time.Now().UnixNano(),
 	}
 }
 
+// LabelClusters makes the store label the metrics of the objects of each
+// cluster populating it in multi-cluster mode, i.e. through a ClusterStore or
+// the list func of a derived store, with the label of the given name holding
+// the name of the cluster. It must be called before the store is populated.
+func (s *MetricsStore) LabelClusters(label string) {
+	s.clusterLabel = label
+}
+
 // Implementing k8s.io/client-go/tools/cache.Store interface
 
 // Add inserts adds to the MetricsStore by calling the metrics generator functions and
 // adding the generated metrics to the metrics map that underlies the MetricStore.
 func (s *MetricsStore) Add(obj interface{}) error {
+	return s.add(obj, "")
+}
+
+// add adds obj of the given cluster like Add.
+func (s *MetricsStore) add(obj interface{}, cluster string) error {
 	o, err := meta.Accessor(obj)
 	if err != nil {
 		return err
@@ -73,16 +142,33 @@ func (s *MetricsStore) Add(obj interface{}) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if _, ok := s.metrics[o.GetUID()]; !ok {
+		s.insertKey(newObjectKey(cluster, o))
+	}
+	s.metrics[o.GetUID()] = s.generate(obj, cluster)
+	s.MarkActive()
+
+	return nil
+}
+
+// generate returns the metrics of obj of the given cluster grouped by metric
+// family.
+func (s *MetricsStore) generate(obj interface{}, cluster string) [][]byte {
 	families := s.generateMetricsFunc(obj)
 	familyStrings := make([][]byte, len(families))
 
+	label := ""
+	if cluster != "" && s.clusterLabel != "" {
+		label = s.clusterLabel + `="` + labelValueEscaper.Replace(cluster) + `"`
+	}
 	for i, f := range families {
 		familyStrings[i] = f.ByteSlice()
+		if label != "" {
+			familyStrings[i] = addLabel(familyStrings[i], label)
+		}
 	}
 
-	s.metrics[o.GetUID()] = familyStrings
-
-	return nil
+	return familyStrings
 }
 
 // Update updates the existing entry in the MetricsStore.
@@ -91,8 +177,23 @@ func (s *MetricsStore) Update(obj interface{}) error {
 	return s.Add(obj)
 }
 
-// Delete deletes an existing entry in the MetricsStore.
+// Delete deletes an existing entry in the MetricsStore. Tombstones of objects
+// deleted while not being watched, i.e. cache.DeletedFinalStateUnknown, are
+// unwrapped, and the entries of the object are deleted by its key if the
+// tombstone does not hold a known state of it.
 func (s *MetricsStore) Delete(obj interface{}) error {
+	return s.delete(obj, "")
+}
+
+// delete deletes an existing entry of the given cluster like Delete.
+func (s *MetricsStore) delete(obj interface{}, cluster string) error {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		if o, err := meta.Accessor(tombstone.Obj); err == nil {
+			obj = o
+		} else {
+			return s.deleteByKey(cluster, tombstone.Key)
+		}
+	}
 
 	o, err := meta.Accessor(obj)
 	if err != nil {
@@ -102,11 +203,89 @@ func (s *MetricsStore) Delete(obj interface{}) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	delete(s.metrics, o.GetUID())
+	if _, ok := s.metrics[o.GetUID()]; ok {
+		key := newObjectKey(cluster, o)
+		i := sort.Search(len(s.keys), func(i int) bool { return !s.keys[i].less(key) })
+		if i < len(s.keys) && s.keys[i] == key {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+		} else {
+			s.removeKey(key.uid)
+		}
+		delete(s.metrics, o.GetUID())
+	}
+	s.MarkActive()
 
 	return nil
 }
 
+// insertKey inserts the key of a new object. As only one object of a name can
+// exist in a namespace of a cluster at a time, the entries of objects with the
+// same name but a different UID are deleted, in case their deletion was
+// missed, so that no stale metrics of them are left behind. Objects without a
+// name, which the apiserver does not return, are only identified by their UID.
+func (s *MetricsStore) insertKey(key objectKey) {
+	i := sort.Search(len(s.keys), func(i int) bool {
+		k := s.keys[i]
+		if k.cluster != key.cluster {
+			return k.cluster > key.cluster
+		}
+		return k.namespace > key.namespace || k.namespace == key.namespace && k.name >= key.name
+	})
+	if key.name == "" {
+		i = sort.Search(len(s.keys), func(i int) bool { return !s.keys[i].less(key) })
+	}
+	j := i
+	for key.name != "" && j < len(s.keys) && s.keys[j].cluster == key.cluster && s.keys[j].namespace == key.namespace && s.keys[j].name == key.name {
+		delete(s.metrics, s.keys[j].uid)
+		j++
+	}
+
+	if j == i {
+		s.keys = append(s.keys, objectKey{})
+		copy(s.keys[i+1:], s.keys[i:])
+	} else {
+		s.keys = append(s.keys[:i+1], s.keys[j:]...)
+	}
+	s.keys[i] = key
+}
+
+// deleteByKey deletes the entries of all objects of the given cluster with the
+// given namespace/name key, regardless of their UID.
+func (s *MetricsStore) deleteByKey(cluster, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := s.keys[:0]
+	for _, k := range s.keys {
+		if k.cluster == cluster && k.namespace == namespace && k.name == name {
+			delete(s.metrics, k.uid)
+			continue
+		}
+		keys = append(keys, k)
+	}
+	s.keys = keys
+	s.MarkActive()
+
+	return nil
+}
+
+// removeKey removes the key of the object with the given UID, e.g. if the
+// object was deleted under a different name than it was added with, which
+// requires a linear search.
+func (s *MetricsStore) removeKey(uid types.UID) {
+	for i, key := range s.keys {
+		if key.uid == uid {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return
+		}
+	}
+}
+
 // List implements the List method of the store interface.
 func (s *MetricsStore) List() []interface{} {
 	return nil
@@ -130,18 +309,124 @@ func (s *MetricsStore) GetByKey(key string) (item interface{}, exists bool, err
 // Replace will delete the contents of the store, using instead the
 // given list.
 func (s *MetricsStore) Replace(list []interface{}, _ string) error {
+	return s.replace(map[string][]interface{}{"": list}, nil)
+}
+
+// replace replaces the contents of the store with the given lists of objects
+// by cluster, except for the entries of the objects whose keys keep returns
+// true for, if not nil.
+func (s *MetricsStore) replace(lists map[string][]interface{}, keep func(key objectKey) bool) error {
+	// The metrics are generated before locking the store, which keeps serving
+	// the previous ones in the meantime.
+	metrics := map[types.UID][][]byte{}
+	keys := []objectKey{}
+	for cluster, list := range lists {
+		for _, obj := range list {
+			o, err := meta.Accessor(obj)
+			if err != nil {
+				return err
+			}
+			if _, ok := metrics[o.GetUID()]; !ok {
+				keys = append(keys, newObjectKey(cluster, o))
+			}
+			metrics[o.GetUID()] = s.generate(obj, cluster)
+		}
+	}
+
 	s.mutex.Lock()
-	s.metrics = map[types.UID][][]byte{}
+	if keep != nil {
+		for _, key := range s.keys {
+			if _, ok := metrics[key.uid]; !ok && keep(key) {
+				keys = append(keys, key)
+				metrics[key.uid] = s.metrics[key.uid]
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+	s.metrics = metrics
+	s.keys = keys
 	s.mutex.Unlock()
 
-	for _, o := range list {
-		err := s.Add(o)
-		if err != nil {
-			return err
+	now := time.Now().UnixNano()
+	atomic.StoreInt64(&s.lastSynced, now)
+	atomic.StoreInt64(&s.lastActive, now)
+
+	return nil
+}
+
+// Len returns the number of objects the store holds metrics of.
+func (s *MetricsStore) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.metrics)
+}
+
+// HasSynced returns true once the store has been populated by an initial
+// list, i.e. once Replace has been called, or in multi-cluster mode once the
+// stores of all clusters have been.
+func (s *MetricsStore) HasSynced() bool {
+	if s.list != nil {
+		return s.sourcesSynced()
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if len(s.clusters) > 0 {
+		for _, c := range s.clusters {
+			if !c.synced {
+				return false
+			}
 		}
+		return true
 	}
+	return atomic.LoadInt64(&s.lastSynced) != 0
+}
 
-	return nil
+// LastSynced returns the time the store was last populated by a list of its
+// reflector, or the zero time if it has not been yet.
+func (s *MetricsStore) LastSynced() time.Time {
+	t := atomic.LoadInt64(&s.lastSynced)
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t)
+}
+
+// MarkActive records that the reflector populating the store is active, e.g.
+// because it updated the store or (re-)established its watch.
+func (s *MetricsStore) MarkActive() {
+	atomic.StoreInt64(&s.lastActive, time.Now().UnixNano())
+}
+
+// LastActive returns the time the reflector populating the store was last
+// active, or the time the store was created if it has never been.
+func (s *MetricsStore) LastActive() time.Time {
+	if s.list != nil && len(s.sources) > 0 {
+		return s.sourcesLastActive()
+	}
+	return time.Unix(0, atomic.LoadInt64(&s.lastActive))
+}
+
+// SetDegraded records whether the lists or watches of the reflector
+// populating the store currently fail, in which case the store keeps serving
+// the last known state.
+func (s *MetricsStore) SetDegraded(degraded bool) {
+	var v int32
+	if degraded {
+		v = 1
+	}
+	atomic.StoreInt32(&s.degraded, v)
+}
+
+// Degraded returns true if the lists or watches of the reflector populating
+// the store currently fail.
+func (s *MetricsStore) Degraded() bool {
+	if s.list != nil {
+		return s.sourcesDegraded()
+	}
+	return atomic.LoadInt32(&s.degraded) == 1
 }
 
 // Resync implements the Resync method of the store interface.
@@ -150,16 +435,24 @@ func (s *MetricsStore) Resync() error {
 }
 
 // WriteAll writes all metrics of the store into the given writer, zipped with the
-// help text of each metric family.
+// help text of each metric family. The contents of derived stores are
+// refreshed first.
 func (s *MetricsStore) WriteAll(w io.Writer) {
+	s.refresh()
+
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	for i, help := range s.headers {
-		w.Write([]byte(help))
-		w.Write([]byte{'\n'})
-		for _, metricFamilies := range s.metrics {
-			w.Write(metricFamilies[i])
+		// Headers are empty if both HELP and TYPE lines are omitted.
+		if help != "" {
+			// io.WriteString avoids converting the header to a byte slice for
+			// writers implementing io.StringWriter, e.g. http.ResponseWriter.
+			io.WriteString(w, help)
+			io.WriteString(w, "\n")
+		}
+		for _, key := range s.keys {
+			w.Write(s.metrics[key.uid][i])
 		}
 	}
 }
@@ -18,13 +18,17 @@ package metricsstore
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Mock metricFamily instead of importing /pkg/metric to prevent cyclic
@@ -81,3 +85,449 @@ func TestObjectsSameNameDifferentNamespaces(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteAllEmptyHeaders(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		return []FamilyByteSlicer{&metricFamily{[]byte("kube_service_info 1\n")}}
+	}
+
+	ms := NewMetricsStore([]string{""}, genFunc)
+	err := ms.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service", UID: types.UID("a")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+
+	expected := "kube_service_info 1\n"
+	if w.String() != expected {
+		t.Fatalf("expected %q but got %q", expected, w.String())
+	}
+}
+
+func TestHasSynced(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		return []FamilyByteSlicer{&metricFamily{[]byte("kube_service_info 1\n")}}
+	}
+
+	ms := NewMetricsStore([]string{""}, genFunc)
+	err := ms.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service", UID: types.UID("a")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ms.HasSynced() {
+		t.Fatal("expected store not to be synced before the initial list")
+	}
+
+	if err := ms.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !ms.HasSynced() {
+		t.Fatal("expected store to be synced after the initial list")
+	}
+}
+
+func TestLastActive(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		return []FamilyByteSlicer{&metricFamily{[]byte("kube_service_info 1\n")}}
+	}
+
+	ms := NewMetricsStore([]string{""}, genFunc)
+	created := ms.LastActive()
+	if created.IsZero() {
+		t.Fatal("expected creation of the store to count as activity")
+	}
+
+	time.Sleep(time.Millisecond)
+	err := ms.Add(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service", UID: types.UID("a")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ms.LastActive().After(created) {
+		t.Fatal("expected adding an object to count as activity")
+	}
+}
+
+func TestWriteAllOrder(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []FamilyByteSlicer{&metricFamily{[]byte(fmt.Sprintf("kube_service_info{namespace=%q,service=%q} 1\n", o.GetNamespace(), o.GetName()))}}
+	}
+	newService := func(namespace, name string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "/" + name)}}
+	}
+
+	ms := NewMetricsStore([]string{"# HELP kube_service_info Information about service."}, genFunc)
+	err := ms.Replace([]interface{}{newService("ns2", "a"), newService("ns1", "c"), newService("ns1", "a")}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*v1.Service{newService("ns1", "b"), newService("ns3", "a"), newService("ns0", "z")} {
+		if err := ms.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ms.Delete(newService("ns1", "c")); err != nil {
+		t.Fatal(err)
+	}
+	// Updates must not change the order.
+	if err := ms.Update(newService("ns1", "a")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP kube_service_info Information about service.
+kube_service_info{namespace="ns0",service="z"} 1
+kube_service_info{namespace="ns1",service="a"} 1
+kube_service_info{namespace="ns1",service="b"} 1
+kube_service_info{namespace="ns2",service="a"} 1
+kube_service_info{namespace="ns3",service="a"} 1
+`
+	for i := 0; i < 3; i++ {
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		if w.String() != expected {
+			t.Fatalf("expected:\n%s\nbut got:\n%s", expected, w.String())
+		}
+	}
+}
+
+func TestDeleteTombstone(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []FamilyByteSlicer{&metricFamily{[]byte(fmt.Sprintf("kube_service_info{namespace=%q,service=%q} 1\n", o.GetNamespace(), o.GetName()))}}
+	}
+	newService := func(name string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)}}
+	}
+
+	ms := NewMetricsStore([]string{"# HELP kube_service_info Information about service."}, genFunc)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := ms.Add(newService(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A tombstone holding the last known state of the object.
+	if err := ms.Delete(cache.DeletedFinalStateUnknown{Key: "default/a", Obj: newService("a")}); err != nil {
+		t.Fatal(err)
+	}
+	// A tombstone only holding the key of the object.
+	if err := ms.Delete(cache.DeletedFinalStateUnknown{Key: "default/c"}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+	expected := `# HELP kube_service_info Information about service.
+kube_service_info{namespace="default",service="b"} 1
+`
+	if w.String() != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, w.String())
+	}
+	if ms.Len() != 1 {
+		t.Errorf("expected 1 object but got %d", ms.Len())
+	}
+}
+
+func TestStaleSeriesRemoval(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []FamilyByteSlicer{&metricFamily{[]byte(fmt.Sprintf("kube_pod_info{namespace=%q,pod=%q,uid=%q} 1\n", o.GetNamespace(), o.GetName(), o.GetUID()))}}
+	}
+	newPod := func(name, uid string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(uid)}}
+	}
+	const header = "# HELP kube_pod_info Information about pod.\n"
+
+	tests := []struct {
+		desc     string
+		apply    func(ms *MetricsStore) error
+		expected string
+	}{
+		{
+			desc: "delete",
+			apply: func(ms *MetricsStore) error {
+				return ms.Delete(newPod("web-0", "1"))
+			},
+			expected: header + "kube_pod_info{namespace=\"default\",pod=\"web-1\",uid=\"2\"} 1\n",
+		},
+		{
+			desc: "delete and re-add with new UID",
+			apply: func(ms *MetricsStore) error {
+				if err := ms.Delete(newPod("web-0", "1")); err != nil {
+					return err
+				}
+				return ms.Add(newPod("web-0", "3"))
+			},
+			expected: header + "kube_pod_info{namespace=\"default\",pod=\"web-0\",uid=\"3\"} 1\n" +
+				"kube_pod_info{namespace=\"default\",pod=\"web-1\",uid=\"2\"} 1\n",
+		},
+		{
+			desc: "re-add with new UID after missed delete",
+			apply: func(ms *MetricsStore) error {
+				return ms.Add(newPod("web-0", "3"))
+			},
+			expected: header + "kube_pod_info{namespace=\"default\",pod=\"web-0\",uid=\"3\"} 1\n" +
+				"kube_pod_info{namespace=\"default\",pod=\"web-1\",uid=\"2\"} 1\n",
+		},
+		{
+			desc: "delete of a stale state",
+			apply: func(ms *MetricsStore) error {
+				if err := ms.Add(newPod("web-0", "3")); err != nil {
+					return err
+				}
+				return ms.Delete(newPod("web-0", "1"))
+			},
+			expected: header + "kube_pod_info{namespace=\"default\",pod=\"web-0\",uid=\"3\"} 1\n" +
+				"kube_pod_info{namespace=\"default\",pod=\"web-1\",uid=\"2\"} 1\n",
+		},
+		{
+			desc: "relist without the object",
+			apply: func(ms *MetricsStore) error {
+				return ms.Replace([]interface{}{newPod("web-1", "2")}, "")
+			},
+			expected: header + "kube_pod_info{namespace=\"default\",pod=\"web-1\",uid=\"2\"} 1\n",
+		},
+		{
+			desc: "delete all",
+			apply: func(ms *MetricsStore) error {
+				for _, p := range []*v1.Pod{newPod("web-1", "2"), newPod("web-0", "1")} {
+					if err := ms.Delete(p); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			expected: header,
+		},
+	}
+
+	for _, test := range tests {
+		ms := NewMetricsStore([]string{strings.TrimSuffix(header, "\n")}, genFunc)
+		for _, p := range []*v1.Pod{newPod("web-0", "1"), newPod("web-1", "2")} {
+			if err := ms.Add(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := test.apply(ms); err != nil {
+			t.Fatalf("%s: %v", test.desc, err)
+		}
+
+		w := strings.Builder{}
+		ms.WriteAll(&w)
+		if w.String() != test.expected {
+			t.Errorf("%s: expected:\n%s\nbut got:\n%s", test.desc, test.expected, w.String())
+		}
+		if got := strings.Count(test.expected, "\n") - 1; ms.Len() != got {
+			t.Errorf("%s: expected %d objects but got %d", test.desc, got, ms.Len())
+		}
+	}
+}
+
+func TestClusterStore(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []FamilyByteSlicer{&metricFamily{[]byte(fmt.Sprintf("kube_service_info{namespace=%q,service=%q} 1\n", o.GetNamespace(), o.GetName()))}}
+	}
+	newService := func(uid, name string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(uid)}}
+	}
+
+	ms := NewMetricsStore([]string{"# HELP kube_service_info Information about service."}, genFunc)
+	ms.LabelClusters("cluster")
+	a := ms.ClusterStore("a")
+	b := ms.ClusterStore("b")
+
+	if err := b.Replace([]interface{}{newService("b1", "x"), newService("b2", "y")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !b.HasSynced() || ms.HasSynced() {
+		t.Fatal("expected the store only to be synced once all clusters are")
+	}
+	if err := a.Replace([]interface{}{newService("a1", "x"), newService("a2", "z")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !ms.HasSynced() {
+		t.Fatal("expected the store to be synced once all clusters are")
+	}
+
+	// Objects of the same name in different clusters are distinct, and
+	// relists and tombstones of a cluster leave the other ones alone.
+	added := newService("a3", "y")
+	if err := a.Add(added); err != nil {
+		t.Fatal(err)
+	}
+	if added.ClusterName != "" {
+		t.Fatalf("expected the object not to be modified but got cluster %q", added.ClusterName)
+	}
+	if err := a.Delete(cache.DeletedFinalStateUnknown{Key: "default/z"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Replace([]interface{}{newService("b1", "x")}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	w := strings.Builder{}
+	ms.WriteAll(&w)
+	expected := `# HELP kube_service_info Information about service.
+kube_service_info{namespace="default",service="x",cluster="a"} 1
+kube_service_info{namespace="default",service="y",cluster="a"} 1
+kube_service_info{namespace="default",service="x",cluster="b"} 1
+`
+	if w.String() != expected {
+		t.Fatalf("expected:\n%s\nbut got:\n%s", expected, w.String())
+	}
+
+	a.SetDegraded(true)
+	b.SetDegraded(true)
+	a.SetDegraded(false)
+	if !ms.Degraded() || a.Degraded() {
+		t.Fatal("expected the store to be degraded while any cluster is")
+	}
+	b.SetDegraded(false)
+	if ms.Degraded() {
+		t.Fatal("expected the store not to be degraded once no cluster is")
+	}
+}
+
+func TestNamespaceCounts(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		return []FamilyByteSlicer{&metricFamily{[]byte("kube_service_info 1\n")}}
+	}
+	newService := func(cluster, namespace, name string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(cluster + "/" + namespace + "/" + name)}}
+	}
+
+	ms := NewMetricsStore([]string{""}, genFunc)
+	b := ms.ClusterStore("b")
+	a := ms.ClusterStore("a")
+	if err := a.Replace([]interface{}{newService("a", "ns2", "x"), newService("a", "ns1", "x"), newService("a", "ns1", "y"), newService("a", "", "z")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Replace([]interface{}{newService("b", "ns1", "x")}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []NamespaceCount{
+		{Cluster: "a", Namespace: "ns1", Objects: 2},
+		{Cluster: "a", Namespace: "ns2", Objects: 1},
+		{Cluster: "b", Namespace: "ns1", Objects: 1},
+	}
+	if counts := ms.NamespaceCounts(); !reflect.DeepEqual(counts, expected) {
+		t.Fatalf("expected %v but got %v", expected, counts)
+	}
+}
+
+func TestDerivedMetricsStore(t *testing.T) {
+	genFunc := func(obj interface{}) []FamilyByteSlicer {
+		o, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []FamilyByteSlicer{&metricFamily{[]byte(fmt.Sprintf("kube_service_info{service=%q} 1\n", o.GetName()))}}
+	}
+	newService := func(name string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)}}
+	}
+
+	s1 := NewMetricsStore([]string{""}, genFunc)
+	s2 := NewMetricsStore([]string{""}, genFunc)
+	listed := []interface{}{}
+	ds := NewDerivedMetricsStore([]string{"# HELP kube_service_info Information about service."}, genFunc, []*MetricsStore{s1, s2}, func() map[string][]interface{} {
+		return map[string][]interface{}{"": listed}
+	})
+
+	if err := s1.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if ds.HasSynced() {
+		t.Fatal("expected the store not to be synced before all sources are")
+	}
+	if err := s2.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !ds.HasSynced() {
+		t.Fatal("expected the store to be synced once all sources are")
+	}
+
+	// The contents are refreshed on each write.
+	for _, names := range [][]string{{"b", "a"}, {"c"}} {
+		listed = []interface{}{}
+		expected := "# HELP kube_service_info Information about service.\n"
+		for _, name := range names {
+			listed = append(listed, newService(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			expected += fmt.Sprintf("kube_service_info{service=%q} 1\n", name)
+		}
+
+		w := strings.Builder{}
+		ds.WriteAll(&w)
+		if w.String() != expected {
+			t.Fatalf("expected:\n%s\nbut got:\n%s", expected, w.String())
+		}
+	}
+
+	time.Sleep(time.Millisecond)
+	s1.MarkActive()
+	if !ds.LastActive().Equal(s2.LastActive()) {
+		t.Fatal("expected the store to be as active as its least active source")
+	}
+
+	s2.SetDegraded(true)
+	if !ds.Degraded() {
+		t.Fatal("expected the store to be degraded while any source is")
+	}
+	s2.SetDegraded(false)
+	if ds.Degraded() {
+		t.Fatal("expected the store not to be degraded once no source is")
+	}
+}
+
+func TestAddLabel(t *testing.T) {
+	tests := []struct {
+		family   string
+		expected string
+	}{
+		{
+			family:   "kube_pod_info{namespace=\"default\",pod=\"p\"} 1\nkube_pod_info{namespace=\"other\",pod=\"q\"} 1\n",
+			expected: "kube_pod_info{namespace=\"default\",pod=\"p\",cluster=\"a\"} 1\nkube_pod_info{namespace=\"other\",pod=\"q\",cluster=\"a\"} 1\n",
+		},
+		{
+			family:   "kube_pod_info 1\n",
+			expected: "kube_pod_info{cluster=\"a\"} 1\n",
+		},
+		{
+			family:   "kube_pod_info{} 1\n",
+			expected: "kube_pod_info{cluster=\"a\"} 1\n",
+		},
+		{
+			family:   "kube_pod_labels{label_x=\"} {a b}\"} 1\n",
+			expected: "kube_pod_labels{label_x=\"} {a b}\",cluster=\"a\"} 1\n",
+		},
+		{
+			family:   "",
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		if got := string(addLabel([]byte(test.family), `cluster="a"`)); got != test.expected {
+			t.Errorf("expected %q but got %q", test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+const (
+	// BridgeGraphite sends metrics in the Graphite plaintext protocol over TCP.
+	BridgeGraphite = "graphite"
+	// BridgeStatsD sends metrics as StatsD gauges over UDP.
+	BridgeStatsD = "statsd"
+
+	// statsdPacketSize is the maximum size of StatsD packets, which keeps
+	// them from being fragmented on common networks.
+	statsdPacketSize = 1432
+)
+
+// Bridge sends the metrics of all stores, which are included by families, to
+// the Graphite or StatsD endpoint at address every interval, until ctx is
+// done. Metric names are prefixed with prefix and followed by the label names
+// and values, separated by dots. Standby replicas do not send metrics.
+func (m *MetricsHandler) Bridge(ctx context.Context, protocol, address, prefix string, families *whiteblacklist.WhiteBlackList, interval time.Duration) {
+	every(ctx, interval, func() {
+		if err := m.bridge(ctx, protocol, address, prefix, families, interval, time.Now()); err != nil {
+			klog.Errorf("failed to send metrics to %s endpoint %s: %v", protocol, address, err)
+		}
+	})
+}
+
+func (m *MetricsHandler) bridge(ctx context.Context, protocol, address, prefix string, families *whiteblacklist.WhiteBlackList, timeout time.Duration, now time.Time) error {
+	if atomic.LoadInt32(&m.standby) == 1 {
+		return nil
+	}
+
+	m.mtx.RLock()
+	stores := m.stores
+	m.mtx.RUnlock()
+
+	gathered, err := gatherStores(stores)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, f := range gathered {
+		if !families.IsIncluded(f.GetName()) {
+			continue
+		}
+		for _, sample := range familyMetrics(f) {
+			v := sample.Value
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			name := bridgeName(prefix, f.GetName()+sample.NameSuffix, sample.LabelKeys, sample.LabelValues)
+			switch protocol {
+			case BridgeGraphite:
+				lines = append(lines, fmt.Sprintf("%s %s %d\n", name, formatValue(v), now.Unix()))
+			case BridgeStatsD:
+				// StatsD interprets signed gauge values as deltas, hence
+				// negative values are set by resetting the gauge first.
+				if v < 0 {
+					lines = append(lines, name+":0|g\n")
+				}
+				lines = append(lines, name+":"+formatValue(v)+"|g\n")
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	network := "tcp"
+	if protocol == BridgeStatsD {
+		network = "udp"
+	}
+	d := net.Dialer{Timeout: timeout}
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	if protocol == BridgeGraphite {
+		_, err = conn.Write([]byte(strings.Join(lines, "")))
+		return err
+	}
+
+	// StatsD packets contain as many whole lines as fit into a packet.
+	packet := &bytes.Buffer{}
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+len(line) > statsdPacketSize {
+			if _, err := conn.Write(packet.Bytes()); err != nil {
+				return err
+			}
+			packet.Reset()
+		}
+		packet.WriteString(line)
+	}
+	_, err = conn.Write(packet.Bytes())
+	return err
+}
+
+// bridgeName returns the dot separated name of a sample, e.g.
+// prefix.kube_pod_info.namespace.default.pod.nginx. All characters other than
+// alphanumerics, underscores and dashes are replaced with underscores, as dots
+// separate path components in Graphite and colons, pipes and at signs separate
+// fields in StatsD.
+func bridgeName(prefix, name string, keys, values []string) string {
+	var b strings.Builder
+	if prefix != "" {
+		b.WriteString(prefix)
+		b.WriteByte('.')
+	}
+	b.WriteString(sanitizeBridgeName(name))
+	for i, key := range keys {
+		if values[i] == "" {
+			continue
+		}
+		b.WriteByte('.')
+		b.WriteString(sanitizeBridgeName(key))
+		b.WriteByte('.')
+		b.WriteString(sanitizeBridgeName(values[i]))
+	}
+	return b.String()
+}
+
+func sanitizeBridgeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
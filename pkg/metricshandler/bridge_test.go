@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/whiteblacklist"
+)
+
+func TestBridgeGraphite(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b, _ := ioutil.ReadAll(conn)
+		received <- string(b)
+	}()
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap"), newTestStore(t, "secret")}
+
+	families, err := whiteblacklist.New(options.MetricSet{"kube_configmap_.*": {}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := families.Parse(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.bridge(context.Background(), BridgeGraphite, l.Addr().String(), "k8s.prod", families, time.Second, time.Unix(42, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "k8s.prod.kube_configmap_info.namespace.default.configmap.cm 1 42\n"
+	select {
+	case got := <-received:
+		if got != want {
+			t.Errorf("expected %q but got %q", want, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for metrics")
+	}
+}
+
+func TestBridgeStatsD(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap")}
+
+	families, err := whiteblacklist.New(options.MetricSet{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.bridge(context.Background(), BridgeStatsD, conn.LocalAddr().String(), "", families, time.Second, time.Unix(42, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, statsdPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read packet: %v", err)
+	}
+	want := "kube_configmap_info.namespace.default.configmap.cm:1|g\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("expected %q but got %q", want, got)
+	}
+}
+
+func TestBridgeStatsDHistogram(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestHistogramStore(t)}
+
+	families, err := whiteblacklist.New(options.MetricSet{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.bridge(context.Background(), BridgeStatsD, conn.LocalAddr().String(), "", families, time.Second, time.Unix(42, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, statsdPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read packet: %v", err)
+	}
+	want := "kube_pod_startup_duration_seconds_bucket.namespace.default.le.1:1|g\n" +
+		"kube_pod_startup_duration_seconds_bucket.namespace.default.le._Inf:2|g\n" +
+		"kube_pod_startup_duration_seconds_sum.namespace.default:3|g\n" +
+		"kube_pod_startup_duration_seconds_count.namespace.default:2|g\n"
+	if got := string(buf[:n]); got != want {
+		t.Errorf("expected %q but got %q", want, got)
+	}
+}
+
+func TestBridgeName(t *testing.T) {
+	tests := []struct {
+		prefix string
+		keys   []string
+		values []string
+		want   string
+	}{
+		{
+			prefix: "",
+			want:   "kube_pod_info",
+		},
+		{
+			prefix: "cluster.a",
+			keys:   []string{"namespace", "pod"},
+			values: []string{"default", "nginx-1"},
+			want:   "cluster.a.kube_pod_info.namespace.default.pod.nginx-1",
+		},
+		{
+			prefix: "",
+			keys:   []string{"node", "empty"},
+			values: []string{"ip-10.0.0.1.ec2:internal|x@y", ""},
+			want:   "kube_pod_info.node.ip-10_0_0_1_ec2_internal_x_y",
+		},
+	}
+
+	for _, test := range tests {
+		if got := bridgeName(test.prefix, "kube_pod_info", test.keys, test.values); got != test.want {
+			t.Errorf("expected %q but got %q", test.want, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+// snapshotFamily is the JSON representation of a metric family served by
+// ServeSnapshot.
+type snapshotFamily struct {
+	Name    string           `json:"name"`
+	Metrics []snapshotMetric `json:"metrics"`
+}
+
+type snapshotMetric struct {
+	NameSuffix string            `json:"nameSuffix,omitempty"`
+	Labels     map[string]string `json:"labels"`
+	Value      float64           `json:"value"`
+}
+
+// Snapshot returns the current metrics of all collectors of this shard,
+// ordered by name within each collector. Families without metrics are left
+// out, and no metrics are returned while on standby. It allows embedding
+// applications and tests to inspect the metrics without parsing the
+// exposition format.
+func (m *MetricsHandler) Snapshot() ([]*metric.Family, error) {
+	if atomic.LoadInt32(&m.standby) == 1 {
+		return []*metric.Family{}, nil
+	}
+
+	m.mtx.RLock()
+	stores := m.stores
+	m.mtx.RUnlock()
+
+	gathered, err := gatherStores(stores)
+	if err != nil {
+		return nil, err
+	}
+
+	families := make([]*metric.Family, 0, len(gathered))
+	for _, f := range gathered {
+		families = append(families, &metric.Family{Name: f.GetName(), Metrics: familyMetrics(f)})
+	}
+	return families, nil
+}
+
+// familyMetrics returns the metrics of all samples of a parsed family, which
+// are suffixed series for histograms and summaries.
+func familyMetrics(f *dto.MetricFamily) []*metric.Metric {
+	metrics := make([]*metric.Metric, 0, len(f.GetMetric()))
+	for _, sample := range f.GetMetric() {
+		keys := make([]string, 0, len(sample.GetLabel()))
+		values := make([]string, 0, len(sample.GetLabel()))
+		for _, l := range sample.GetLabel() {
+			keys = append(keys, l.GetName())
+			values = append(values, l.GetValue())
+		}
+		metrics = append(metrics, sampleMetrics(sample, keys, values)...)
+	}
+	return metrics
+}
+
+// sampleMetrics returns the metrics of a parsed sample with the given labels,
+// which are several for histograms and summaries.
+func sampleMetrics(sample *dto.Metric, keys, values []string) []*metric.Metric {
+	switch {
+	case sample.Histogram != nil:
+		h := sample.GetHistogram()
+		buckets := make([]metric.Bucket, len(h.GetBucket()))
+		for i, b := range h.GetBucket() {
+			buckets[i] = metric.Bucket{UpperBound: b.GetUpperBound(), Count: b.GetCumulativeCount()}
+		}
+		return metric.NewHistogramMetrics(keys, values, buckets, h.GetSampleSum(), h.GetSampleCount())
+	case sample.Summary != nil:
+		s := sample.GetSummary()
+		quantiles := make([]metric.Quantile, len(s.GetQuantile()))
+		for i, q := range s.GetQuantile() {
+			quantiles[i] = metric.Quantile{Quantile: q.GetQuantile(), Value: q.GetValue()}
+		}
+		return metric.NewSummaryMetrics(keys, values, quantiles, s.GetSampleSum(), s.GetSampleCount())
+	default:
+		return []*metric.Metric{{LabelKeys: keys, LabelValues: values, Value: sampleValue(sample)}}
+	}
+}
+
+// ServeSnapshot serves the snapshot of the current metrics as JSON.
+func (m *MetricsHandler) ServeSnapshot(w http.ResponseWriter, r *http.Request) {
+	families, err := m.Snapshot()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	snapshot := make([]snapshotFamily, len(families))
+	for i, f := range families {
+		snapshot[i] = snapshotFamily{Name: f.Name, Metrics: make([]snapshotMetric, len(f.Metrics))}
+		for j, sample := range f.Metrics {
+			labels := make(map[string]string, len(sample.LabelKeys))
+			for k, key := range sample.LabelKeys {
+				labels[key] = sample.LabelValues[k]
+			}
+			snapshot[i].Metrics[j] = snapshotMetric{NameSuffix: sample.NameSuffix, Labels: labels, Value: sample.Value}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
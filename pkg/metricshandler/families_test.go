@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestSnapshot(t *testing.T) {
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap"), newTestStore(t, "secret")}
+
+	families, err := m.Snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []*metric.Family{
+		{Name: "kube_configmap_info", Metrics: []*metric.Metric{{LabelKeys: []string{"namespace", "configmap"}, LabelValues: []string{"default", "cm"}, Value: 1}}},
+		{Name: "kube_secret_info", Metrics: []*metric.Metric{{LabelKeys: []string{"namespace", "secret"}, LabelValues: []string{"default", "cm"}, Value: 1}}},
+	}
+	if !reflect.DeepEqual(families, want) {
+		t.Errorf("expected snapshot %+v but got %+v", want, families)
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeSnapshot(rec, httptest.NewRequest("GET", "/snapshot", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected content type application/json but got %s", ct)
+	}
+	var snapshot []snapshotFamily
+	if err := json.NewDecoder(rec.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if len(snapshot) != 2 || snapshot[0].Name != "kube_configmap_info" || snapshot[0].Metrics[0].Labels["configmap"] != "cm" || snapshot[0].Metrics[0].Value != 1 {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+
+	m.standby = 1
+	families, err = m.Snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(families) != 0 {
+		t.Errorf("expected no metrics on standby but got %+v", families)
+	}
+}
+
+// newTestHistogramStore returns a store with a histogram with a single bucket.
+func newTestHistogramStore(t testing.TB) *metricsstore.MetricsStore {
+	t.Helper()
+
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer {
+		return []metricsstore.FamilyByteSlicer{
+			&metric.Family{
+				Name:    "kube_pod_startup_duration_seconds",
+				Metrics: metric.NewHistogramMetrics([]string{"namespace"}, []string{"default"}, []metric.Bucket{{UpperBound: 1, Count: 1}}, 3, 2),
+			},
+		}
+	}
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_pod_startup_duration_seconds Startup duration.\n# TYPE kube_pod_startup_duration_seconds histogram"}, genFunc)
+	if err := s.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc"}}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSnapshotHistogram(t *testing.T) {
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestHistogramStore(t)}
+
+	families, err := m.Snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []*metric.Family{
+		{
+			Name: "kube_pod_startup_duration_seconds",
+			Metrics: []*metric.Metric{
+				{NameSuffix: metric.BucketSuffix, LabelKeys: []string{"namespace", "le"}, LabelValues: []string{"default", "1"}, Value: 1},
+				{NameSuffix: metric.BucketSuffix, LabelKeys: []string{"namespace", "le"}, LabelValues: []string{"default", "+Inf"}, Value: 2},
+				{NameSuffix: metric.SumSuffix, LabelKeys: []string{"namespace"}, LabelValues: []string{"default"}, Value: 3},
+				{NameSuffix: metric.CountSuffix, LabelKeys: []string{"namespace"}, LabelValues: []string{"default"}, Value: 2},
+			},
+		},
+	}
+	if !reflect.DeepEqual(families, want) {
+		t.Errorf("expected snapshot %+v but got %+v", want, families)
+	}
+}
@@ -17,15 +17,22 @@ limitations under the License.
 package metricshandler
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"io"
 	"net/http"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
 	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -33,37 +40,110 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 
-	"k8s.io/kube-state-metrics/internal/store"
+	ksmtypes "k8s.io/kube-state-metrics/pkg/builder/types"
 	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/tracing"
 )
 
+// gzipPool recycles gzip writers between scrapes. Allocating a new writer per
+// request is expensive, as each one carries its own compression buffers.
+var gzipPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// bufferPool recycles the buffers the output of collectors is assembled in
+// when assembling it concurrently.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
+// standbyMetrics is served instead of any other metrics by standby replicas,
+// which do not hold the leader election Lease.
+const standbyMetrics = `# HELP kube_state_metrics_standby Whether this replica is a standby replica, not serving metrics as another replica is the elected leader.
+# TYPE kube_state_metrics_standby gauge
+kube_state_metrics_standby 1
+`
+
+// retryAfterSeconds is the delay clients are asked to wait before retrying
+// scrapes rejected due to too many concurrent scrapes.
+const retryAfterSeconds = "1"
+
 // MetricsHandler is a http.Handler that exposes the main kube-state-metrics
 // /metrics endpoint. It allows concurrent reconfiguration at runtime.
 type MetricsHandler struct {
 	opts               *options.Options
 	kubeClient         kubernetes.Interface
-	storeBuilder       *store.Builder
+	storeBuilder       ksmtypes.BuilderInterface
 	enableGZIPEncoding bool
 
 	cancel func()
 
-	// mtx protects stores, curShard, and curTotalShards
+	// scrapeSlots bounds the number of concurrent scrapes to its capacity, if
+	// opts.MaxConcurrentScrapes is set.
+	scrapeSlots chan struct{}
+
+	// standby is set to 1 while this replica is a standby replica, serving
+	// only standbyMetrics.
+	standby int32
+
+	// snapshots holds the last complete responses, served when generating a
+	// fresh response exceeds opts.ScrapeLatencyBudget.
+	snapshots *snapshotCache
+
+	// writeDuration observes the time it takes to write the metrics of
+	// each store, if set.
+	writeDuration *prometheus.HistogramVec
+
+	// tracer traces scrapes and the writing of each store, if set.
+	tracer *tracing.Tracer
+
+	// mtx protects stores, storeNames, curShard, and curTotalShards
 	mtx            *sync.RWMutex
 	stores         []*metricsstore.MetricsStore
+	storeNames     map[*metricsstore.MetricsStore]string
 	curShard       int32
 	curTotalShards int
 }
 
 // New creates and returns a new MetricsHandler with the given options.
-func New(opts *options.Options, kubeClient kubernetes.Interface, storeBuilder *store.Builder, enableGZIPEncoding bool) *MetricsHandler {
-	return &MetricsHandler{
+func New(opts *options.Options, kubeClient kubernetes.Interface, storeBuilder ksmtypes.BuilderInterface, enableGZIPEncoding bool) *MetricsHandler {
+	m := &MetricsHandler{
 		opts:               opts,
 		kubeClient:         kubeClient,
 		storeBuilder:       storeBuilder,
 		enableGZIPEncoding: enableGZIPEncoding,
 		mtx:                &sync.RWMutex{},
+		snapshots:          newSnapshotCache(),
+	}
+	if opts.MaxConcurrentScrapes > 0 {
+		m.scrapeSlots = make(chan struct{}, opts.MaxConcurrentScrapes)
 	}
+	return m
+}
+
+// WithMetrics registers the metrics of the MetricsHandler itself with the
+// given registry.
+func (m *MetricsHandler) WithMetrics(r prometheus.Registerer) {
+	m.writeDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kube_state_metrics_collector_write_duration_seconds",
+			Help:    "Duration of writing the metrics of a collector in response to a scrape.",
+			Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{"resource"},
+	)
+	r.MustRegister(m.writeDuration)
+}
+
+// WithTracer traces scrapes and the writing of the store of each collector in
+// response to them with the given tracer.
+func (m *MetricsHandler) WithTracer(t *tracing.Tracer) {
+	m.tracer = t
 }
 
 // ConfigureSharding (re-)configures sharding. Re-configuration can be done
@@ -82,10 +162,79 @@ func (m *MetricsHandler) ConfigureSharding(ctx context.Context, shard int32, tot
 	m.storeBuilder.WithSharding(shard, totalShards)
 	m.storeBuilder.WithContext(ctx)
 	m.stores = m.storeBuilder.Build()
+	m.storeNames = map[*metricsstore.MetricsStore]string{}
+	// Build returns a store per enabled resource, in the same order.
+	if resources := m.storeBuilder.EnabledResources(); len(resources) <= len(m.stores) {
+		for i, resource := range resources {
+			m.storeNames[m.stores[i]] = resource
+		}
+	}
+	m.snapshots.reset()
 	m.curShard = shard
 	m.curTotalShards = totalShards
 }
 
+// HasSynced returns true once all stores have been populated by the initial
+// lists of their reflectors. It returns false until sharding has been
+// configured, and again after each re-configuration until the new stores have
+// synced.
+func (m *MetricsHandler) HasSynced() bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	if m.stores == nil {
+		return false
+	}
+	for _, s := range m.stores {
+		if !s.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsStale returns true if the reflector of any store has not been active for
+// longer than threshold, e.g. because its watch broke and cannot be
+// re-established, or because it is wedged.
+func (m *MetricsHandler) IsStale(threshold time.Duration) bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	for _, s := range m.stores {
+		if time.Since(s.LastActive()) > threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// Degraded returns the names of the collectors of all stores, mapped to
+// whether their lists or watches currently fail. Degraded collectors keep
+// serving the last known state.
+func (m *MetricsHandler) Degraded() map[string]bool {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	degraded := make(map[string]bool, len(m.stores))
+	for _, s := range m.stores {
+		if name, ok := m.storeNames[s]; ok {
+			degraded[name] = s.Degraded()
+		}
+	}
+	return degraded
+}
+
+// SetStandby configures whether this replica is a standby replica, which only
+// serves the kube_state_metrics_standby metric. Stores are kept in sync on
+// standby replicas, so they can take over right away.
+func (m *MetricsHandler) SetStandby(standby bool) {
+	var v int32
+	if standby {
+		v = 1
+	}
+	atomic.StoreInt32(&m.standby, v)
+}
+
 // Run configures the MetricsHandler's sharding and if autosharding is enabled
 // re-configures sharding on re-sharding events. Run should only be called
 // once.
@@ -115,6 +264,23 @@ func (m *MetricsHandler) Run(ctx context.Context) error {
 		cache.NewFilteredListWatchFromClient(m.kubeClient.AppsV1().RESTClient(), "statefulsets", m.opts.Namespace, labelSelectorOptions),
 		&appsv1.StatefulSet{}, 0, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
 	)
+	reconfigure := func(ss *appsv1.StatefulSet) {
+		shard, totalShards, err := shardingSettingsFromStatefulSet(ss, m.opts.Pod)
+		if err != nil {
+			klog.Errorf("detect sharding settings from StatefulSet: %v", err)
+			return
+		}
+
+		m.mtx.RLock()
+		shardingUnchanged := m.curShard == shard && m.curTotalShards == totalShards
+		m.mtx.RUnlock()
+
+		if shardingUnchanged {
+			return
+		}
+
+		m.ConfigureSharding(ctx, shard, totalShards)
+	}
 	i.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(o interface{}) {
 			ss := o.(*appsv1.StatefulSet)
@@ -122,21 +288,7 @@ func (m *MetricsHandler) Run(ctx context.Context) error {
 				return
 			}
 
-			shard, totalShards, err := shardingSettingsFromStatefulSet(ss, m.opts.Pod)
-			if err != nil {
-				klog.Errorf("detect sharding settings from StatefulSet: %v", err)
-				return
-			}
-
-			m.mtx.RLock()
-			shardingUnchanged := m.curShard == shard && m.curTotalShards == totalShards
-			m.mtx.RUnlock()
-
-			if shardingUnchanged {
-				return
-			}
-
-			m.ConfigureSharding(ctx, shard, totalShards)
+			reconfigure(ss)
 		},
 		UpdateFunc: func(oldo, curo interface{}) {
 			old := oldo.(*appsv1.StatefulSet)
@@ -149,21 +301,7 @@ func (m *MetricsHandler) Run(ctx context.Context) error {
 				return
 			}
 
-			shard, totalShards, err := shardingSettingsFromStatefulSet(cur, m.opts.Pod)
-			if err != nil {
-				klog.Errorf("detect sharding settings from StatefulSet: %v", err)
-				return
-			}
-
-			m.mtx.RLock()
-			shardingUnchanged := m.curShard == shard && m.curTotalShards == totalShards
-			m.mtx.RUnlock()
-
-			if shardingUnchanged {
-				return
-			}
-
-			m.ConfigureSharding(ctx, shard, totalShards)
+			reconfigure(cur)
 		},
 	})
 	go i.Run(ctx.Done())
@@ -175,31 +313,72 @@ func (m *MetricsHandler) Run(ctx context.Context) error {
 }
 
 // ServeHTTP implements the http.Handler interface. It writes the metrics in
-// its stores to the response body.
+// its stores to the response body, either in the Prometheus text format or, if
+// requested by the client, in the delimited protobuf format.
 func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.scrapeSlots != nil {
+		select {
+		case m.scrapeSlots <- struct{}{}:
+			defer func() { <-m.scrapeSlots }()
+		default:
+			w.Header().Set("Retry-After", retryAfterSeconds)
+			http.Error(w, "too many concurrent scrapes", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	if atomic.LoadInt32(&m.standby) == 1 {
+		w.Header().Set("Content-Type", `text/plain; version=`+"0.0.4")
+		io.WriteString(w, standbyMetrics)
+		return
+	}
+
 	m.mtx.RLock()
 	defer m.mtx.RUnlock()
 	resHeader := w.Header()
 	var writer io.Writer = w
 
-	resHeader.Set("Content-Type", `text/plain; version=`+"0.0.4")
+	ctx, span := m.tracer.Start(tracing.Extract(r.Context(), r.Header), "scrape",
+		tracing.Int("shard", int(m.curShard)),
+		tracing.Int("total_shards", m.curTotalShards),
+	)
+	defer span.End()
+
+	format := expfmt.FmtText
+	writeStore := writeStoreText
+	if expfmt.Negotiate(r.Header) == expfmt.FmtProtoDelim {
+		format = expfmt.FmtProtoDelim
+		writeStore = writeStoreProtobuf
+		resHeader.Set("Content-Type", string(expfmt.FmtProtoDelim))
+	} else {
+		resHeader.Set("Content-Type", `text/plain; version=`+"0.0.4")
+	}
+	span.SetAttributes(tracing.String("format", string(format)))
+	writeStore = m.instrumentWriteStore(ctx, writeStore)
 
 	if m.enableGZIPEncoding {
-		// Gzip response if requested. Taken from
-		// github.com/prometheus/client_golang/prometheus/promhttp.decorateWriter.
-		reqHeader := r.Header.Get("Accept-Encoding")
-		parts := strings.Split(reqHeader, ",")
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "gzip" || strings.HasPrefix(part, "gzip;") {
-				writer = gzip.NewWriter(writer)
-				resHeader.Set("Content-Encoding", "gzip")
-			}
+		resHeader.Add("Vary", "Accept-Encoding")
+		if gzipAccepted(r.Header) {
+			gz := gzipPool.Get().(*gzip.Writer)
+			defer gzipPool.Put(gz)
+
+			gz.Reset(w)
+			writer = gz
+			resHeader.Set("Content-Encoding", "gzip")
 		}
 	}
 
-	for _, s := range m.stores {
-		s.WriteAll(w)
+	if m.opts.ScrapeLatencyBudget > 0 {
+		// Generation may outlive this request, hence it must not rely on
+		// m.mtx being held.
+		stores := m.stores
+		writer.Write(m.snapshots.get(format, m.opts.ScrapeLatencyBudget, func() []byte {
+			buf := &bytes.Buffer{}
+			m.writeStores(stores, buf, writeStore)
+			return buf.Bytes()
+		}))
+	} else {
+		m.writeStores(m.stores, writer, writeStore)
 	}
 
 	// In case we gzipped the response, we have to close the writer.
@@ -208,6 +387,133 @@ func (m *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeStoreText writes the metrics of s to w in the Prometheus text format.
+func writeStoreText(w io.Writer, s *metricsstore.MetricsStore) {
+	s.WriteAll(w)
+}
+
+// writeStoreProtobuf writes the metrics of s to w in the delimited protobuf
+// format. Families without any metrics are omitted. The stores only hold the
+// text representation of their metrics, which is why protobuf responses are
+// more expensive to assemble than text responses. Families never span multiple
+// stores, hence converting them one store at a time is sufficient.
+func writeStoreProtobuf(w io.Writer, s *metricsstore.MetricsStore) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	s.WriteAll(buf)
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(buf)
+	if err != nil {
+		klog.Errorf("failed to convert metrics to protobuf: %v", err)
+		return
+	}
+
+	names := make([]string, 0, len(families))
+	for name, f := range families {
+		if len(f.GetMetric()) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	enc := expfmt.NewEncoder(w, expfmt.FmtProtoDelim)
+	for _, name := range names {
+		if err := enc.Encode(families[name]); err != nil {
+			klog.Errorf("failed to encode metric family %s: %v", name, err)
+			return
+		}
+	}
+}
+
+// writeStores writes the metrics of the given stores to w using writeStore. The
+// response is streamed one store at a time, so at no point the entire payload
+// is held in memory. The output of up to opts.CollectorWorkers stores is
+// assembled concurrently, so a single slow collector does not serialize all
+// others behind it. Stores are still written to w in order, hence the output
+// is the same as writing them one by one.
+func (m *MetricsHandler) writeStores(stores []*metricsstore.MetricsStore, w io.Writer, writeStore func(io.Writer, *metricsstore.MetricsStore)) {
+	workers := m.opts.CollectorWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if workers == 1 || len(stores) <= 1 {
+		for _, s := range stores {
+			writeStore(w, s)
+		}
+		return
+	}
+
+	// The semaphore is released only once a buffer has been written to w, which
+	// bounds both the number of busy workers and the number of buffered
+	// collector outputs held in memory.
+	sem := make(chan struct{}, workers)
+	results := make([]chan *bytes.Buffer, len(stores))
+	for i := range results {
+		results[i] = make(chan *bytes.Buffer, 1)
+	}
+
+	go func() {
+		for i, s := range stores {
+			sem <- struct{}{}
+			go func(s *metricsstore.MetricsStore, result chan<- *bytes.Buffer) {
+				buf := bufferPool.Get().(*bytes.Buffer)
+				buf.Reset()
+				writeStore(buf, s)
+				result <- buf
+			}(s, results[i])
+		}
+	}()
+
+	for _, result := range results {
+		buf := <-result
+		w.Write(buf.Bytes())
+		bufferPool.Put(buf)
+		<-sem
+	}
+}
+
+// instrumentWriteStore instruments writeStore to trace writing each store as
+// a child of the span in ctx, if tracing is enabled, and to observe the
+// duration of writing the store of each collector, if metrics of the
+// MetricsHandler are enabled. It must be called with m.mtx held.
+func (m *MetricsHandler) instrumentWriteStore(ctx context.Context, writeStore func(io.Writer, *metricsstore.MetricsStore)) func(io.Writer, *metricsstore.MetricsStore) {
+	if m.writeDuration == nil && m.tracer == nil {
+		return writeStore
+	}
+
+	names := m.storeNames
+	return func(w io.Writer, s *metricsstore.MetricsStore) {
+		name, ok := names[s]
+		_, span := m.tracer.Start(ctx, "write")
+		if ok {
+			span.SetAttributes(tracing.String("resource", name))
+		}
+		start := time.Now()
+		writeStore(w, s)
+		span.End()
+		if ok && m.writeDuration != nil {
+			m.writeDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		}
+	}
+}
+
+// gzipAccepted reports whether the client accepts gzip encoded responses.
+// Taken from github.com/prometheus/client_golang/prometheus/promhttp.decorateWriter.
+func gzipAccepted(header http.Header) bool {
+	parts := strings.Split(header.Get("Accept-Encoding"), ",")
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "gzip" || strings.HasPrefix(part, "gzip;") {
+			return true
+		}
+	}
+	return false
+}
+
 func shardingSettingsFromStatefulSet(ss *appsv1.StatefulSet, podName string) (nominal int32, totalReplicas int, err error) {
 	nominal, err = detectNominalFromPod(ss.Name, podName)
 	if err != nil {
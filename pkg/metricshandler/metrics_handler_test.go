@@ -0,0 +1,513 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/tracing"
+)
+
+func newTestStore(t testing.TB, name string) *metricsstore.MetricsStore {
+	t.Helper()
+
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer {
+		o := obj.(*v1.ConfigMap)
+		return []metricsstore.FamilyByteSlicer{
+			&metric.Family{
+				Name: "kube_" + name + "_info",
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"namespace", name},
+						LabelValues: []string{o.Namespace, o.Name},
+						Value:       1,
+					},
+				},
+			},
+		}
+	}
+
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_" + name + "_info Information about " + name + ".\n# TYPE kube_" + name + "_info gauge"}, genFunc)
+	err := s.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func newTestHandler(t *testing.T, enableGZIPEncoding bool) *MetricsHandler {
+	t.Helper()
+
+	m := New(&options.Options{}, nil, nil, enableGZIPEncoding)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap")}
+
+	return m
+}
+
+const expectedHandlerOutput = `# HELP kube_configmap_info Information about configmap.
+# TYPE kube_configmap_info gauge
+kube_configmap_info{namespace="default",configmap="cm"} 1
+`
+
+func TestServeHTTPGzip(t *testing.T) {
+	tests := []struct {
+		desc           string
+		enableGzip     bool
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{desc: "gzip disabled", enableGzip: false, acceptEncoding: "gzip", wantGzip: false},
+		{desc: "gzip not requested", enableGzip: true, acceptEncoding: "", wantGzip: false},
+		{desc: "gzip requested", enableGzip: true, acceptEncoding: "gzip", wantGzip: true},
+		{desc: "gzip requested with quality", enableGzip: true, acceptEncoding: "deflate, gzip;q=1.0, *;q=0.5", wantGzip: true},
+	}
+
+	for _, test := range tests {
+		m := newTestHandler(t, test.enableGzip)
+
+		// Run each case twice to make sure pooled writers are reset properly.
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+			if test.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req)
+
+			resp := w.Result()
+			gotGzip := resp.Header.Get("Content-Encoding") == "gzip"
+			if gotGzip != test.wantGzip {
+				t.Fatalf("%s: expected gzip encoding to be %v but got %v", test.desc, test.wantGzip, gotGzip)
+			}
+
+			body := resp.Body
+			if gotGzip {
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("%s: failed to create gzip reader: %v", test.desc, err)
+				}
+				body = gz
+			}
+
+			got, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatalf("%s: failed to read body: %v", test.desc, err)
+			}
+
+			if strings.TrimSpace(string(got)) != strings.TrimSpace(expectedHandlerOutput) {
+				t.Fatalf("%s: expected body\n%s\nbut got\n%s", test.desc, expectedHandlerOutput, got)
+			}
+		}
+	}
+}
+
+func TestServeHTTPCollectorWorkers(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+	expected := strings.Builder{}
+	stores := []*metricsstore.MetricsStore{}
+	for _, name := range names {
+		s := newTestStore(t, name)
+		s.WriteAll(&expected)
+		stores = append(stores, s)
+	}
+
+	for _, workers := range []int{0, 1, 2, 3, len(names) + 1} {
+		m := New(&options.Options{CollectorWorkers: workers}, nil, nil, false)
+		m.stores = stores
+
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		got := w.Body.String()
+		if got != expected.String() {
+			t.Fatalf("with %d workers: expected\n%s\nbut got\n%s", workers, expected.String(), got)
+		}
+	}
+}
+
+func TestServeHTTPProtobuf(t *testing.T) {
+	for _, enableGzip := range []bool{false, true} {
+		m := newTestHandler(t, enableGzip)
+		m.stores = append(m.stores, metricsstore.NewMetricsStore([]string{"# HELP kube_empty_info Empty family.\n# TYPE kube_empty_info gauge"}, nil))
+
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+		req.Header.Set("Accept", `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited`)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if got := resp.Header.Get("Content-Type"); got != string(expfmt.FmtProtoDelim) {
+			t.Fatalf("expected content type %q but got %q", expfmt.FmtProtoDelim, got)
+		}
+
+		var body io.Reader = resp.Body
+		if enableGzip {
+			gz, err := gzip.NewReader(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to create gzip reader: %v", err)
+			}
+			body = gz
+		}
+
+		families := decodeProtobuf(t, body)
+		if len(families) != 1 {
+			t.Fatalf("expected 1 metric family but got %d", len(families))
+		}
+		f := families[0]
+		if f.GetName() != "kube_configmap_info" || f.GetType() != dto.MetricType_GAUGE {
+			t.Fatalf("unexpected metric family %s of type %s", f.GetName(), f.GetType())
+		}
+		if len(f.GetMetric()) != 1 || f.GetMetric()[0].GetGauge().GetValue() != 1 {
+			t.Fatalf("unexpected metrics %v", f.GetMetric())
+		}
+		labels := map[string]string{}
+		for _, l := range f.GetMetric()[0].GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["namespace"] != "default" || labels["configmap"] != "cm" {
+			t.Fatalf("unexpected labels %v", labels)
+		}
+	}
+}
+
+func TestServeHTTPProtobufCollectorWorkers(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f", "g"}
+
+	stores := []*metricsstore.MetricsStore{}
+	for _, name := range names {
+		stores = append(stores, newTestStore(t, name))
+	}
+
+	for _, workers := range []int{1, 3} {
+		m := New(&options.Options{CollectorWorkers: workers}, nil, nil, false)
+		m.stores = stores
+
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+		req.Header.Set("Accept", `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited`)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		families := decodeProtobuf(t, w.Body)
+		if len(families) != len(names) {
+			t.Fatalf("with %d workers: expected %d metric families but got %d", workers, len(names), len(families))
+		}
+		for i, name := range names {
+			if got := families[i].GetName(); got != "kube_"+name+"_info" {
+				t.Fatalf("with %d workers: expected metric family kube_%s_info at position %d but got %s", workers, name, i, got)
+			}
+		}
+	}
+}
+
+func TestServeHTTPMaxConcurrentScrapes(t *testing.T) {
+	m := New(&options.Options{MaxConcurrentScrapes: 1}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap")}
+
+	// Occupy the only slot, as if a scrape was in progress.
+	m.scrapeSlots <- struct{}{}
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status code %d but got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != retryAfterSeconds {
+		t.Fatalf("expected Retry-After header %q but got %q", retryAfterSeconds, got)
+	}
+
+	<-m.scrapeSlots
+
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d but got %d", http.StatusOK, w.Code)
+		}
+		if w.Body.String() != expectedHandlerOutput {
+			t.Fatalf("expected body\n%s\nbut got\n%s", expectedHandlerOutput, w.Body.String())
+		}
+	}
+}
+
+func TestServeHTTPStandby(t *testing.T) {
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap")}
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+
+	m.SetStandby(true)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	if w.Body.String() != standbyMetrics {
+		t.Fatalf("expected body\n%s\nbut got\n%s", standbyMetrics, w.Body.String())
+	}
+
+	m.SetStandby(false)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	if w.Body.String() != expectedHandlerOutput {
+		t.Fatalf("expected body\n%s\nbut got\n%s", expectedHandlerOutput, w.Body.String())
+	}
+}
+
+func TestServeHTTPWriteDuration(t *testing.T) {
+	r := prometheus.NewRegistry()
+	m := New(&options.Options{CollectorWorkers: 2}, nil, nil, false)
+	m.WithMetrics(r)
+	configMaps, secrets := newTestStore(t, "configmap"), newTestStore(t, "secret")
+	m.stores = []*metricsstore.MetricsStore{configMaps, secrets}
+	m.storeNames = map[*metricsstore.MetricsStore]string{configMaps: "configmaps", secrets: "secrets"}
+
+	m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://localhost:8080/metrics", nil))
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]uint64{}
+	for _, f := range families {
+		for _, metric := range f.GetMetric() {
+			got[metric.GetLabel()[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+		}
+	}
+	if len(got) != 2 || got["configmaps"] != 1 || got["secrets"] != 1 {
+		t.Errorf("expected one observation per collector but got %v", got)
+	}
+}
+
+func TestServeHTTPTracer(t *testing.T) {
+	type span struct {
+		TraceID      string `json:"traceId"`
+		SpanID       string `json:"spanId"`
+		ParentSpanID string `json:"parentSpanId"`
+		Name         string `json:"name"`
+	}
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []span `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+	}))
+	defer collector.Close()
+
+	tracer := tracing.New(collector.Client(), collector.URL, "ksm-0")
+	m := New(&options.Options{CollectorWorkers: 2}, nil, nil, false)
+	m.WithTracer(tracer)
+	configMaps, secrets := newTestStore(t, "configmap"), newTestStore(t, "secret")
+	m.stores = []*metricsstore.MetricsStore{configMaps, secrets}
+	m.storeNames = map[*metricsstore.MetricsStore]string{configMaps: "configmaps", secrets: "secrets"}
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	m.ServeHTTP(httptest.NewRecorder(), req)
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(request.ResourceSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("expected a single resource and scope but got %+v", request)
+	}
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("expected a scrape span and a write span per store, got %+v", spans)
+	}
+	scrape := spans[2]
+	if scrape.Name != "scrape" || scrape.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || scrape.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("expected the scrape span to continue the trace of the request, got %+v", scrape)
+	}
+	for _, s := range spans[:2] {
+		if s.Name != "write" || s.TraceID != scrape.TraceID || s.ParentSpanID != scrape.SpanID {
+			t.Errorf("expected a write span as child of the scrape span, got %+v", s)
+		}
+	}
+}
+
+func decodeProtobuf(t *testing.T, r io.Reader) []*dto.MetricFamily {
+	t.Helper()
+
+	dec := expfmt.NewDecoder(r, expfmt.FmtProtoDelim)
+	families := []*dto.MetricFamily{}
+	for {
+		f := &dto.MetricFamily{}
+		err := dec.Decode(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to decode metric family: %v", err)
+		}
+		families = append(families, f)
+	}
+
+	return families
+}
+
+func BenchmarkServeHTTP(b *testing.B) {
+	stores := []*metricsstore.MetricsStore{}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		stores = append(stores, newTestStore(b, name))
+	}
+
+	for _, workers := range []int{1, 4} {
+		m := New(&options.Options{CollectorWorkers: workers}, nil, nil, false)
+		m.stores = stores
+
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+			for i := 0; i < b.N; i++ {
+				m.ServeHTTP(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
+
+func TestShardingSettingsFromStatefulSet(t *testing.T) {
+	replicas := int32(3)
+	tests := []struct {
+		desc            string
+		replicas        *int32
+		pod             string
+		wantShard       int32
+		wantTotalShards int
+		wantErr         bool
+	}{
+		{desc: "first pod", replicas: &replicas, pod: "kube-state-metrics-0", wantShard: 0, wantTotalShards: 3},
+		{desc: "last pod", replicas: &replicas, pod: "kube-state-metrics-2", wantShard: 2, wantTotalShards: 3},
+		{desc: "replicas unset", replicas: nil, pod: "kube-state-metrics-0", wantShard: 0, wantTotalShards: 1},
+		{desc: "pod of other StatefulSet", replicas: &replicas, pod: "other-0", wantErr: true},
+	}
+
+	for _, test := range tests {
+		ss := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-state-metrics"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: test.replicas},
+		}
+
+		shard, totalShards, err := shardingSettingsFromStatefulSet(ss, test.pod)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("%s: expected error to be %v but got %v", test.desc, test.wantErr, err)
+		}
+		if test.wantErr {
+			continue
+		}
+		if shard != test.wantShard || totalShards != test.wantTotalShards {
+			t.Fatalf("%s: expected shard %d of %d but got %d of %d", test.desc, test.wantShard, test.wantTotalShards, shard, totalShards)
+		}
+	}
+}
+
+func TestHasSynced(t *testing.T) {
+	m := &MetricsHandler{mtx: &sync.RWMutex{}}
+	if m.HasSynced() {
+		t.Fatal("expected handler not to be synced before sharding is configured")
+	}
+
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer { return nil }
+	synced := metricsstore.NewMetricsStore(nil, genFunc)
+	if err := synced.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	unsynced := metricsstore.NewMetricsStore(nil, genFunc)
+
+	m.stores = []*metricsstore.MetricsStore{synced, unsynced}
+	if m.HasSynced() {
+		t.Fatal("expected handler not to be synced while a store has not synced")
+	}
+
+	m.stores = []*metricsstore.MetricsStore{synced}
+	if !m.HasSynced() {
+		t.Fatal("expected handler to be synced once all stores have synced")
+	}
+}
+
+func TestDegraded(t *testing.T) {
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer { return nil }
+	pods := metricsstore.NewMetricsStore(nil, genFunc)
+	nodes := metricsstore.NewMetricsStore(nil, genFunc)
+	m := &MetricsHandler{
+		mtx:        &sync.RWMutex{},
+		stores:     []*metricsstore.MetricsStore{pods, nodes},
+		storeNames: map[*metricsstore.MetricsStore]string{pods: "pods", nodes: "nodes"},
+	}
+
+	nodes.SetDegraded(true)
+	if want := map[string]bool{"pods": false, "nodes": true}; !reflect.DeepEqual(m.Degraded(), want) {
+		t.Errorf("expected %v but got %v", want, m.Degraded())
+	}
+
+	nodes.SetDegraded(false)
+	if want := map[string]bool{"pods": false, "nodes": false}; !reflect.DeepEqual(m.Degraded(), want) {
+		t.Errorf("expected %v but got %v", want, m.Degraded())
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer { return nil }
+	m := &MetricsHandler{
+		mtx:    &sync.RWMutex{},
+		stores: []*metricsstore.MetricsStore{metricsstore.NewMetricsStore(nil, genFunc)},
+	}
+
+	if m.IsStale(time.Hour) {
+		t.Fatal("expected recently active stores not to be stale")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if !m.IsStale(5 * time.Millisecond) {
+		t.Fatal("expected inactive stores to be stale")
+	}
+
+	m.stores[0].MarkActive()
+	if m.IsStale(5 * time.Millisecond) {
+		t.Fatal("expected stores not to be stale after activity")
+	}
+}
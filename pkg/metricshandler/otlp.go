@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/pkg/version"
+)
+
+// The types below are the subset of the OTLP metrics protocol, in its JSON
+// encoding, needed to export gauges. See
+// https://github.com/open-telemetry/opentelemetry-proto.
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpMetric struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Gauge       otlpGauge `json:"gauge"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpDataPoint `json:"dataPoints"`
+}
+
+type otlpDataPoint struct {
+	Attributes []otlpAttribute `json:"attributes,omitempty"`
+	// TimeUnixNano is a fixed64, which is encoded as string in JSON.
+	TimeUnixNano string     `json:"timeUnixNano"`
+	AsDouble     otlpDouble `json:"asDouble"`
+}
+
+// otlpDouble is a double, which is encoded as string in JSON if it is not
+// finite, as encoding/json rejects NaN and infinite numbers.
+type otlpDouble float64
+
+// MarshalJSON implements json.Marshaler.
+func (d otlpDouble) MarshalJSON() ([]byte, error) {
+	v := float64(d)
+	switch {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, +1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Infinity"`), nil
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *otlpDouble) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return json.Unmarshal(b, (*float64)(d))
+	}
+	switch s {
+	case "NaN":
+		*d = otlpDouble(math.NaN())
+	case "Infinity":
+		*d = otlpDouble(math.Inf(+1))
+	case "-Infinity":
+		*d = otlpDouble(math.Inf(-1))
+	default:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		*d = otlpDouble(v)
+	}
+	return nil
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// ExportOTLP exports the metrics of all stores as OTLP gauges to the
+// OTLP/HTTP endpoint every interval, until ctx is done. Metrics are encoded
+// as JSON and posted to the /v1/metrics path of the endpoint, e.g. an
+// OpenTelemetry collector. Standby replicas do not export.
+func (m *MetricsHandler) ExportOTLP(ctx context.Context, client *http.Client, endpoint, instance string, interval time.Duration) {
+	every(ctx, interval, func() {
+		if err := m.exportOTLP(ctx, client, endpoint, instance, time.Now()); err != nil {
+			klog.Errorf("failed to export metrics to %s: %v", endpoint, err)
+		}
+	})
+}
+
+func (m *MetricsHandler) exportOTLP(ctx context.Context, client *http.Client, endpoint, instance string, now time.Time) error {
+	if atomic.LoadInt32(&m.standby) == 1 {
+		return nil
+	}
+
+	m.mtx.RLock()
+	stores := m.stores
+	shard := m.curShard
+	m.mtx.RUnlock()
+
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+	families, err := gatherStores(stores)
+	if err != nil {
+		return err
+	}
+
+	// Histograms and summaries are exported as a gauge per series, e.g. the
+	// _bucket, _sum and _count series of a histogram.
+	metrics := make([]otlpMetric, 0, len(families))
+	for _, f := range families {
+		series := map[string]int{}
+		for _, sample := range familyMetrics(f) {
+			i, ok := series[sample.NameSuffix]
+			if !ok {
+				i = len(metrics)
+				series[sample.NameSuffix] = i
+				metrics = append(metrics, otlpMetric{Name: f.GetName() + sample.NameSuffix, Description: f.GetHelp()})
+			}
+			point := otlpDataPoint{TimeUnixNano: timestamp, AsDouble: otlpDouble(sample.Value)}
+			for j, key := range sample.LabelKeys {
+				point.Attributes = append(point.Attributes, otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: sample.LabelValues[j]}})
+			}
+			metrics[i].Gauge.DataPoints = append(metrics[i].Gauge.DataPoints, point)
+		}
+	}
+
+	body, err := json.Marshal(otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{Attributes: []otlpAttribute{
+				{Key: "service.name", Value: otlpAnyValue{StringValue: "kube-state-metrics"}},
+				{Key: "service.instance.id", Value: otlpAnyValue{StringValue: instance}},
+				{Key: "shard", Value: otlpAnyValue{StringValue: strconv.Itoa(int(shard))}},
+			}},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: "kube-state-metrics", Version: version.Release},
+				Metrics: metrics,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/v1/metrics", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(client, req)
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestExportOTLP(t *testing.T) {
+	var path, contentType string
+	var request otlpRequest
+	status := http.StatusOK
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer collector.Close()
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap")}
+
+	now := time.Unix(1, 0)
+	if err := m.exportOTLP(context.Background(), collector.Client(), collector.URL+"/", "ksm-0", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/v1/metrics" {
+		t.Errorf("expected path /v1/metrics but got %s", path)
+	}
+	if contentType != "application/json" {
+		t.Errorf("expected content type application/json but got %s", contentType)
+	}
+
+	if len(request.ResourceMetrics) != 1 || len(request.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("expected a single resource and scope but got %+v", request)
+	}
+	resource := request.ResourceMetrics[0].Resource
+	if got := attribute(resource.Attributes, "service.instance.id"); got != "ksm-0" {
+		t.Errorf("expected service.instance.id ksm-0 but got %q", got)
+	}
+	metrics := request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	if len(metrics) != 1 || len(metrics[0].Gauge.DataPoints) != 1 {
+		t.Fatalf("expected a single metric with a single data point but got %+v", metrics)
+	}
+	if metrics[0].Name != "kube_configmap_info" || metrics[0].Description != "Information about configmap." {
+		t.Errorf("unexpected metric %s: %s", metrics[0].Name, metrics[0].Description)
+	}
+	p := metrics[0].Gauge.DataPoints[0]
+	if p.TimeUnixNano != "1000000000" {
+		t.Errorf("expected timestamp 1000000000 but got %s", p.TimeUnixNano)
+	}
+	if p.AsDouble != 1 {
+		t.Errorf("expected value 1 but got %v", p.AsDouble)
+	}
+	if got := attribute(p.Attributes, "configmap"); got != "cm" {
+		t.Errorf("expected configmap attribute cm but got %q", got)
+	}
+
+	path = ""
+	m.SetStandby(true)
+	if err := m.exportOTLP(context.Background(), collector.Client(), collector.URL, "ksm-0", now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "" {
+		t.Error("expected standby replicas not to export")
+	}
+
+	m.SetStandby(false)
+	status = http.StatusBadRequest
+	if err := m.exportOTLP(context.Background(), collector.Client(), collector.URL, "ksm-0", now); err == nil {
+		t.Error("expected error for rejected export")
+	}
+}
+
+func attribute(attributes []otlpAttribute, key string) string {
+	for _, a := range attributes {
+		if a.Key == key {
+			return a.Value.StringValue
+		}
+	}
+	return ""
+}
+
+func TestExportOTLPHistogram(t *testing.T) {
+	var request otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+	}))
+	defer collector.Close()
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestHistogramStore(t)}
+
+	if err := m.exportOTLP(context.Background(), collector.Client(), collector.URL, "ksm-0", time.Unix(1, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Each series of the histogram is exported as a gauge.
+	got := map[string][]float64{}
+	les := []string{}
+	for _, metric := range request.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		for _, p := range metric.Gauge.DataPoints {
+			got[metric.Name] = append(got[metric.Name], float64(p.AsDouble))
+			if le := attribute(p.Attributes, "le"); le != "" {
+				les = append(les, le)
+			}
+		}
+	}
+	want := map[string][]float64{
+		"kube_pod_startup_duration_seconds_bucket": {1, 2},
+		"kube_pod_startup_duration_seconds_sum":    {3},
+		"kube_pod_startup_duration_seconds_count":  {2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected gauges %v but got %v", want, got)
+	}
+	if !reflect.DeepEqual(les, []string{"1", "+Inf"}) {
+		t.Errorf("expected buckets 1 and +Inf but got %v", les)
+	}
+}
+
+func TestExportOTLPNonFinite(t *testing.T) {
+	var request otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+	}))
+	defer collector.Close()
+
+	values := []float64{math.NaN(), math.Inf(+1), math.Inf(-1), 1.5}
+	genFunc := func(obj interface{}) []metricsstore.FamilyByteSlicer {
+		family := &metric.Family{Name: "kube_configmap_value"}
+		for i, v := range values {
+			family.Metrics = append(family.Metrics, &metric.Metric{LabelKeys: []string{"i"}, LabelValues: []string{strconv.Itoa(i)}, Value: v})
+		}
+		return []metricsstore.FamilyByteSlicer{family}
+	}
+	s := metricsstore.NewMetricsStore([]string{"# HELP kube_configmap_value Value.\n# TYPE kube_configmap_value gauge"}, genFunc)
+	if err := s.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: "abc"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{s}
+
+	if err := m.exportOTLP(context.Background(), collector.Client(), collector.URL, "ksm-0", time.Unix(1, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	points := request.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Gauge.DataPoints
+	if len(points) != len(values) {
+		t.Fatalf("expected %d data points but got %d", len(values), len(points))
+	}
+	for _, p := range points {
+		i, _ := strconv.Atoi(attribute(p.Attributes, "i"))
+		got, want := float64(p.AsDouble), values[i]
+		if got != want && !(math.IsNaN(got) && math.IsNaN(want)) {
+			t.Errorf("expected value %v but got %v", want, got)
+		}
+	}
+}
+
+func TestOTLPDoubleMarshalJSON(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{value: 1.5, want: `1.5`},
+		{value: math.NaN(), want: `"NaN"`},
+		{value: math.Inf(+1), want: `"Infinity"`},
+		{value: math.Inf(-1), want: `"-Infinity"`},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(otlpDouble(test.value))
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.value, err)
+			continue
+		}
+		if string(b) != test.want {
+			t.Errorf("%v: expected %s but got %s", test.value, test.want, b)
+		}
+	}
+}
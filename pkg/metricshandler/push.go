@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// Push pushes the metrics of all stores to the Pushgateway at gatewayURL every
+// interval, until ctx is done. Metrics are grouped by job, instance and the
+// shard of this instance, and each push replaces the previous one of the same
+// group. Standby replicas do not push.
+func (m *MetricsHandler) Push(ctx context.Context, client *http.Client, gatewayURL, job, instance string, interval time.Duration) {
+	every(ctx, interval, func() {
+		if err := m.push(ctx, client, gatewayURL, job, instance); err != nil {
+			klog.Errorf("failed to push metrics to %s: %v", gatewayURL, err)
+		}
+	})
+}
+
+// every calls f every interval until ctx is done.
+func every(ctx context.Context, interval time.Duration, f func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		f()
+	}
+}
+
+func (m *MetricsHandler) push(ctx context.Context, client *http.Client, gatewayURL, job, instance string) error {
+	if atomic.LoadInt32(&m.standby) == 1 {
+		return nil
+	}
+
+	body := &bytes.Buffer{}
+	m.mtx.RLock()
+	m.writeStores(m.stores, body, writeStoreText)
+	shard := m.curShard
+	m.mtx.RUnlock()
+
+	u := strings.TrimSuffix(gatewayURL, "/") +
+		"/metrics/job/" + url.PathEscape(job) +
+		"/instance/" + url.PathEscape(instance) +
+		"/shard/" + strconv.Itoa(int(shard))
+	req, err := http.NewRequest(http.MethodPut, u, body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", `text/plain; version=`+"0.0.4")
+
+	return do(client, req)
+}
+
+// do sends req, returning an error including the beginning of the response
+// body if the response does not indicate success.
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// gatherStores parses the metrics of the given stores, returning all families
+// with at least one metric ordered by name within each store.
+func gatherStores(stores []*metricsstore.MetricsStore) ([]*dto.MetricFamily, error) {
+	var families []*dto.MetricFamily
+	buf := &bytes.Buffer{}
+	for _, s := range stores {
+		buf.Reset()
+		s.WriteAll(buf)
+
+		var parser expfmt.TextParser
+		parsed, err := parser.TextToMetricFamilies(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(parsed))
+		for name, f := range parsed {
+			if len(f.GetMetric()) > 0 {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			families = append(families, parsed[name])
+		}
+	}
+	return families, nil
+}
+
+// sampleValue returns the value of a gauge, counter or untyped sample.
+func sampleValue(m *dto.Metric) float64 {
+	switch {
+	case m.Gauge != nil:
+		return m.GetGauge().GetValue()
+	case m.Counter != nil:
+		return m.GetCounter().GetValue()
+	default:
+		return m.GetUntyped().GetValue()
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestPush(t *testing.T) {
+	var method, path, body string
+	status := http.StatusOK
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(b)
+		w.WriteHeader(status)
+	}))
+	defer gateway.Close()
+
+	m := New(&options.Options{}, nil, nil, false)
+	m.stores = []*metricsstore.MetricsStore{newTestStore(t, "configmap")}
+	m.curShard = 1
+
+	err := m.push(context.Background(), gateway.Client(), gateway.URL+"/", "kube-state-metrics", "ksm/0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPut {
+		t.Errorf("expected method PUT but got %s", method)
+	}
+	if want := "/metrics/job/kube-state-metrics/instance/ksm%2F0/shard/1"; path != want {
+		t.Errorf("expected path %s but got %s", want, path)
+	}
+	if body != expectedHandlerOutput {
+		t.Errorf("expected body\n%s\nbut got\n%s", expectedHandlerOutput, body)
+	}
+
+	method = ""
+	m.SetStandby(true)
+	if err := m.push(context.Background(), gateway.Client(), gateway.URL, "kube-state-metrics", "ksm-0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != "" {
+		t.Error("expected standby replicas not to push")
+	}
+
+	m.SetStandby(false)
+	status = http.StatusBadRequest
+	if err := m.push(context.Background(), gateway.Client(), gateway.URL, "kube-state-metrics", "ksm-0"); err == nil {
+		t.Error("expected error for rejected push")
+	}
+}
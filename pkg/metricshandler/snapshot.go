@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/klog"
+)
+
+// snapshotCache holds the last complete response per exposition format. It is
+// served in place of a fresh response whenever generating the latter exceeds
+// the scrape latency budget, while generation finishes in the background.
+type snapshotCache struct {
+	mtx       sync.Mutex
+	snapshots map[expfmt.Format][]byte
+	// pending holds the in-flight generation per format.
+	pending map[expfmt.Format]*generation
+	// epoch is incremented on reset, so generations started before a reset
+	// do not store their outdated result.
+	epoch uint64
+}
+
+// generation is a generation of a response in progress. data is only set
+// once done is closed.
+type generation struct {
+	done chan struct{}
+	data []byte
+}
+
+func newSnapshotCache() *snapshotCache {
+	return &snapshotCache{
+		snapshots: map[expfmt.Format][]byte{},
+		pending:   map[expfmt.Format]*generation{},
+	}
+}
+
+// get returns a fresh response of the given format if it can be generated
+// within budget, and the last complete response otherwise. If there is no
+// previous response yet, get waits for generation to finish. Concurrent
+// callers share a single in-flight generation.
+func (c *snapshotCache) get(format expfmt.Format, budget time.Duration, generate func() []byte) []byte {
+	c.mtx.Lock()
+	g, ok := c.pending[format]
+	if !ok {
+		g = &generation{done: make(chan struct{})}
+		c.pending[format] = g
+		epoch := c.epoch
+
+		go func() {
+			g.data = generate()
+
+			c.mtx.Lock()
+			if c.epoch == epoch {
+				c.snapshots[format] = g.data
+				delete(c.pending, format)
+			}
+			c.mtx.Unlock()
+			close(g.done)
+		}()
+	}
+	c.mtx.Unlock()
+
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+
+	select {
+	case <-g.done:
+	case <-timer.C:
+		c.mtx.Lock()
+		data, ok := c.snapshots[format]
+		c.mtx.Unlock()
+		if ok {
+			klog.V(2).Infof("generating metrics exceeded scrape latency budget of %v, serving last complete snapshot", budget)
+			return data
+		}
+		<-g.done
+	}
+
+	return g.data
+}
+
+// reset drops all snapshots and in-flight generations, e.g. once the stores
+// they were generated from are replaced.
+func (c *snapshotCache) reset() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.epoch++
+	c.snapshots = map[expfmt.Format][]byte{}
+	c.pending = map[expfmt.Format]*generation{}
+}
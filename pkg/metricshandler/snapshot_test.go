@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricshandler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestSnapshotCache(t *testing.T) {
+	c := newSnapshotCache()
+	budget := 10 * time.Millisecond
+
+	// Without a previous snapshot, slow generations are waited for.
+	got := c.get(expfmt.FmtText, budget, func() []byte {
+		time.Sleep(5 * budget)
+		return []byte("first")
+	})
+	if string(got) != "first" {
+		t.Fatalf("expected first response to be served, got %q", got)
+	}
+
+	// Fast generations are served directly.
+	got = c.get(expfmt.FmtText, budget, func() []byte {
+		return []byte("second")
+	})
+	if string(got) != "second" {
+		t.Fatalf("expected fresh response to be served, got %q", got)
+	}
+
+	// Slow generations fall back to the last snapshot and finish in the
+	// background.
+	unblock := make(chan struct{})
+	got = c.get(expfmt.FmtText, budget, func() []byte {
+		<-unblock
+		return []byte("third")
+	})
+	if string(got) != "second" {
+		t.Fatalf("expected last snapshot to be served, got %q", got)
+	}
+
+	// Concurrent scrapes share the in-flight generation.
+	got = c.get(expfmt.FmtText, budget, func() []byte {
+		t.Fatal("expected in-flight generation to be reused")
+		return nil
+	})
+	if string(got) != "second" {
+		t.Fatalf("expected last snapshot to be served, got %q", got)
+	}
+
+	// Snapshots are kept per format.
+	got = c.get(expfmt.FmtProtoDelim, budget, func() []byte {
+		return []byte("protobuf")
+	})
+	if string(got) != "protobuf" {
+		t.Fatalf("expected protobuf response to be served, got %q", got)
+	}
+
+	close(unblock)
+	got = c.get(expfmt.FmtText, time.Second, func() []byte {
+		return []byte("fourth")
+	})
+	if string(got) != "third" && string(got) != "fourth" {
+		t.Fatalf("expected background generation to have finished, got %q", got)
+	}
+
+	// Once reset, previous snapshots are no longer served.
+	c.reset()
+	got = c.get(expfmt.FmtText, budget, func() []byte {
+		time.Sleep(5 * budget)
+		return []byte("fifth")
+	})
+	if string(got) != "fifth" {
+		t.Fatalf("expected fresh response after reset, got %q", got)
+	}
+}
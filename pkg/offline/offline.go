@@ -0,0 +1,398 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offline generates metrics from Kubernetes objects read from
+// manifests instead of an apiserver, e.g. to debug collectors or to validate
+// the expected series in CI.
+//
+// Manifests are YAML or JSON files holding any number of objects or lists of
+// objects, directories of such files, gzipped tarballs of them, e.g. Velero
+// backups, and Kubernetes audit logs, of which the objects of the responses
+// to create, update and patch requests are read, and objects are removed
+// again by delete requests. Objects read later replace earlier objects of
+// the same resource, namespace and name.
+//
+// The objects are served by fake clients, which list and watch them without
+// any apiserver. Unlike the apiserver, they neither set the creation
+// timestamp nor default any fields of objects. Collectors only see objects of
+// the API version they list, as objects are not converted between versions.
+// Field selectors, e.g. of the pods scheduled to a node, are not supported.
+package offline
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	vpafake "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned/fake"
+	vpascheme "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned/scheme"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// auditAPIGroup is the API group of Kubernetes audit events.
+const auditAPIGroup = "audit.k8s.io"
+
+// objectKey identifies an object read from manifests.
+type objectKey struct {
+	resource  schema.GroupResource
+	namespace string
+	name      string
+}
+
+// Objects are the Kubernetes objects read from manifests, in the order they
+// were first read.
+type Objects struct {
+	keys    []objectKey
+	objects map[objectKey]*unstructured.Unstructured
+}
+
+// NewObjects returns an empty set of objects.
+func NewObjects() *Objects {
+	return &Objects{objects: map[objectKey]*unstructured.Unstructured{}}
+}
+
+// Len returns the number of objects.
+func (o *Objects) Len() int {
+	return len(o.objects)
+}
+
+// List returns the objects in the order they were first read.
+func (o *Objects) List() []*unstructured.Unstructured {
+	list := make([]*unstructured.Unstructured, 0, len(o.objects))
+	for _, k := range o.keys {
+		if obj, ok := o.objects[k]; ok {
+			list = append(list, obj)
+		}
+	}
+	return list
+}
+
+func (o *Objects) set(obj *unstructured.Unstructured) {
+	gvr, _ := meta.UnsafeGuessKindToResource(obj.GroupVersionKind())
+	k := objectKey{resource: gvr.GroupResource(), namespace: obj.GetNamespace(), name: obj.GetName()}
+	// The metrics of objects are stored by their UID, which manifests
+	// usually leave out.
+	if obj.GetUID() == "" {
+		obj.SetUID(types.UID(strings.Join([]string{k.resource.String(), k.namespace, k.name}, "/")))
+	}
+	if _, ok := o.objects[k]; !ok {
+		o.keys = append(o.keys, k)
+	}
+	o.objects[k] = obj
+}
+
+func (o *Objects) delete(k objectKey) {
+	delete(o.objects, k)
+}
+
+// ReadPaths reads the objects of the manifests at the given paths. Files are
+// read whatever their extension, "-" reads stdin. Directories are walked
+// recursively for .yaml, .yml and .json files and gzipped tarballs of them.
+func (o *Objects) ReadPaths(paths ...string) error {
+	for _, path := range paths {
+		if path == "-" {
+			if err := o.Read(os.Stdin); err != nil {
+				return errors.Wrap(err, "failed to read manifests from stdin")
+			}
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if err := o.readFile(path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !(isManifest(p) || isTarball(p)) {
+				return err
+			}
+			return o.readFile(p)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *Objects) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if isTarball(path) {
+		err = o.readTarball(f)
+	} else {
+		err = o.Read(f)
+	}
+	return errors.Wrapf(err, "failed to read manifests from %s", path)
+}
+
+// readTarball reads the manifests in the given gzipped tarball.
+func (o *Objects) readTarball(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg || !isManifest(h.Name) {
+			continue
+		}
+		if err := o.Read(tr); err != nil {
+			return errors.Wrap(err, h.Name)
+		}
+	}
+}
+
+// Read reads the objects of the YAML or JSON documents of r.
+func (o *Objects) Read(r io.Reader) error {
+	d := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, nil)
+		if err != nil {
+			return err
+		}
+		if err := o.add(obj); err != nil {
+			return err
+		}
+	}
+}
+
+func (o *Objects) add(obj runtime.Object) error {
+	switch obj := obj.(type) {
+	case *unstructured.UnstructuredList:
+		for i := range obj.Items {
+			if err := o.add(&obj.Items[i]); err != nil {
+				return err
+			}
+		}
+	case *unstructured.Unstructured:
+		if obj.GroupVersionKind().Group == auditAPIGroup {
+			return o.addAuditEvent(obj)
+		}
+		o.set(obj)
+	}
+	return nil
+}
+
+// addAuditEvent applies the request of the given audit event to the objects.
+// Only events of completed, successful requests are applied.
+func (o *Objects) addAuditEvent(event *unstructured.Unstructured) error {
+	if event.GetKind() != "Event" {
+		return nil
+	}
+	stage, _, _ := unstructured.NestedString(event.Object, "stage")
+	code, _, _ := unstructured.NestedInt64(event.Object, "responseStatus", "code")
+	if stage != "ResponseComplete" || code < 200 || code >= 300 {
+		return nil
+	}
+
+	verb, _, _ := unstructured.NestedString(event.Object, "verb")
+	switch verb {
+	case "create", "update", "patch":
+		response, ok, _ := unstructured.NestedMap(event.Object, "responseObject")
+		if !ok {
+			return nil
+		}
+		obj := &unstructured.Unstructured{Object: response}
+		if obj.GetKind() == "" || obj.GetKind() == "Status" || obj.GetName() == "" {
+			return nil
+		}
+		o.set(obj)
+	case "delete":
+		ref, ok, _ := unstructured.NestedStringMap(event.Object, "objectRef")
+		if !ok || ref["subresource"] != "" {
+			return nil
+		}
+		o.delete(objectKey{
+			resource:  schema.GroupResource{Group: ref["apiGroup"], Resource: ref["resource"]},
+			namespace: ref["namespace"],
+			name:      ref["name"],
+		})
+	}
+	return nil
+}
+
+// Clients returns fake clients serving the objects. Objects of the built-in
+// and VerticalPodAutoscaler API groups are served by the typed clients, all
+// others, e.g. custom resources, by the dynamic client.
+func (o *Objects) Clients() (clientset.Interface, vpaclientset.Interface, dynamic.Interface, error) {
+	var kubeObjects, vpaObjects, dynamicObjects []runtime.Object
+
+	for _, obj := range o.List() {
+		gvk := obj.GroupVersionKind()
+		typed, err := convert(scheme.Scheme, obj)
+		if err == nil && typed != nil {
+			kubeObjects = append(kubeObjects, typed)
+			continue
+		}
+		if err == nil {
+			typed, err = convert(vpascheme.Scheme, obj)
+		}
+		if err != nil {
+			return nil, nil, nil, errors.Wrapf(err, "failed to convert %s %s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName())
+		}
+		if typed != nil {
+			vpaObjects = append(vpaObjects, typed)
+			continue
+		}
+		dynamicObjects = append(dynamicObjects, obj)
+	}
+
+	return fake.NewSimpleClientset(kubeObjects...),
+		vpafake.NewSimpleClientset(vpaObjects...),
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), dynamicObjects...),
+		nil
+}
+
+// convert converts the given object to its type in the given scheme. It
+// returns nil if the scheme does not know the kind of the object.
+func convert(s *runtime.Scheme, obj *unstructured.Unstructured) (runtime.Object, error) {
+	typed, err := s.New(obj.GroupVersionKind())
+	if runtime.IsNotRegisteredError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return nil, err
+	}
+	return typed, nil
+}
+
+// RecoverHook is a metric.FamilyHook leaving out the metrics of an object
+// whose generation panics, e.g. because fields set by the apiserver like the
+// creation timestamp or defaults are missing from its manifest. Each panic is
+// reported to the hook with the name of the family, the object and the
+// recovered value.
+type RecoverHook func(name string, obj interface{}, recovered interface{})
+
+// Wrap returns a copy of g recovering from panics while generating families.
+func (fn RecoverHook) Wrap(g metric.FamilyGenerator) metric.FamilyGenerator {
+	name, generate := g.Name, g.GenerateFunc
+	g.GenerateFunc = func(obj interface{}) (family *metric.Family) {
+		defer func() {
+			if r := recover(); r != nil {
+				fn(name, obj, r)
+				family = &metric.Family{}
+			}
+		}()
+		return generate(obj)
+	}
+	return g
+}
+
+// WriteMetrics writes the metrics of the given stores to w once they have
+// synced. As some collectors aggregate objects listed by further reflectors,
+// e.g. the usage of workspaces, the metrics are written once they stopped
+// changing, or after the given timeout.
+func WriteMetrics(ctx context.Context, stores []*metricsstore.MetricsStore, w io.Writer, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := wait.PollImmediateUntil(10*time.Millisecond, func() (bool, error) {
+		for _, s := range stores {
+			if !s.HasSynced() {
+				return false, nil
+			}
+		}
+		return true, nil
+	}, ctx.Done())
+	if err != nil {
+		return errors.Wrap(err, "failed to wait for the stores to sync")
+	}
+
+	var last []byte
+	_ = wait.PollImmediateUntil(100*time.Millisecond, func() (bool, error) {
+		var b bytes.Buffer
+		for _, s := range stores {
+			s.WriteAll(&b)
+		}
+		stable := bytes.Equal(b.Bytes(), last)
+		last = b.Bytes()
+		return stable, nil
+	}, ctx.Done())
+
+	_, err = io.Copy(w, bytes.NewReader(last))
+	return err
+}
+
+func isManifest(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func isTarball(path string) bool {
+	path = strings.ToLower(path)
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+const manifests = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+---
+# A list of objects.
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: configmap
+    namespace: default
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: deployment
+    namespace: default
+---
+{"apiVersion": "iam.kubesphere.io/v1alpha2", "kind": "User", "metadata": {"name": "admin"}}
+`
+
+const auditLog = `
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"create","objectRef":{"resource":"configmaps","namespace":"default","name":"created","apiVersion":"v1"},"responseStatus":{"code":201},"responseObject":{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"created","namespace":"default","uid":"1"}}}
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"create","objectRef":{"resource":"configmaps","namespace":"default","name":"forbidden","apiVersion":"v1"},"responseStatus":{"code":403},"responseObject":{"kind":"Status","apiVersion":"v1"}}
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"RequestReceived","verb":"delete","objectRef":{"resource":"configmaps","namespace":"default","name":"configmap","apiVersion":"v1"}}
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","stage":"ResponseComplete","verb":"delete","objectRef":{"resource":"deployments","namespace":"default","name":"deployment","apiGroup":"apps","apiVersion":"v1"},"responseStatus":{"code":200}}
+`
+
+func names(o *Objects) []string {
+	var names []string
+	for _, obj := range o.List() {
+		names = append(names, strings.Join([]string{obj.GetKind(), obj.GetNamespace(), obj.GetName()}, "/"))
+	}
+	return names
+}
+
+func expectNames(t *testing.T, o *Objects, want ...string) {
+	t.Helper()
+	got := names(o)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected objects %v, got %v", want, got)
+	}
+}
+
+func TestRead(t *testing.T) {
+	o := NewObjects()
+	if err := o.Read(strings.NewReader(manifests)); err != nil {
+		t.Fatal(err)
+	}
+	expectNames(t, o, "Namespace//default", "ConfigMap/default/configmap", "Deployment/default/deployment", "User//admin")
+
+	for _, obj := range o.List() {
+		if obj.GetUID() == "" {
+			t.Errorf("expected UID to be set on %s %s", obj.GetKind(), obj.GetName())
+		}
+	}
+
+	if err := o.Read(strings.NewReader(auditLog)); err != nil {
+		t.Fatal(err)
+	}
+	expectNames(t, o, "Namespace//default", "ConfigMap/default/configmap", "User//admin", "ConfigMap/default/created")
+
+	if uid := o.List()[3].GetUID(); uid != "1" {
+		t.Errorf("expected UID of the created object to be kept, got %q", uid)
+	}
+
+	if err := o.Read(strings.NewReader("kind: [")); err == nil {
+		t.Error("expected an error reading invalid YAML")
+	}
+}
+
+func TestReadPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"resources/namespaces/default.json": `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "backup"}}`,
+		"velero-backup.log":                 "not a manifest",
+	}
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string][]byte{
+		"manifests/objects.yaml":  []byte(manifests),
+		"manifests/README.md":     []byte("not a manifest"),
+		"manifests/backup.tar.gz": b.Bytes(),
+		"audit.log":               []byte(auditLog),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := NewObjects()
+	if err := o.ReadPaths(filepath.Join(dir, "manifests"), filepath.Join(dir, "audit.log")); err != nil {
+		t.Fatal(err)
+	}
+	expectNames(t, o, "Namespace//backup", "Namespace//default", "ConfigMap/default/configmap", "User//admin", "ConfigMap/default/created")
+
+	if err := o.ReadPaths(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error reading a missing path")
+	}
+}
+
+func TestClients(t *testing.T) {
+	o := NewObjects()
+	if err := o.Read(strings.NewReader(manifests)); err != nil {
+		t.Fatal(err)
+	}
+	kubeClient, _, dynamicClient, err := o.Clients()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cms, err := kubeClient.CoreV1().ConfigMaps("default").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cms.Items) != 1 || cms.Items[0].Name != "configmap" {
+		t.Errorf("expected the config map to be served by the typed client, got %v", cms.Items)
+	}
+
+	users, err := dynamicClient.Resource(schema.GroupVersionResource{Group: "iam.kubesphere.io", Version: "v1alpha2", Resource: "users"}).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users.Items) != 1 || users.Items[0].GetName() != "admin" {
+		t.Errorf("expected the user to be served by the dynamic client, got %v", users.Items)
+	}
+}
+
+func TestRecoverHook(t *testing.T) {
+	var recovered []string
+	hook := RecoverHook(func(name string, obj interface{}, r interface{}) {
+		recovered = append(recovered, name)
+	})
+
+	g := hook.Wrap(metric.FamilyGenerator{
+		Name: "kube_panic",
+		Type: metric.Gauge,
+		GenerateFunc: func(obj interface{}) *metric.Family {
+			panic("missing field")
+		},
+	})
+	if f := g.GenerateFunc(nil); f == nil || len(f.Metrics) != 0 {
+		t.Errorf("expected an empty family, got %v", f)
+	}
+	if len(recovered) != 1 || recovered[0] != "kube_panic" {
+		t.Errorf("expected the panic of kube_panic to be reported, got %v", recovered)
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	store := metricsstore.NewMetricsStore([]string{"# HELP kube_test Test."}, func(obj interface{}) []metricsstore.FamilyByteSlicer {
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := WriteMetrics(ctx, []*metricsstore.MetricsStore{store}, ioutil.Discard, 50*time.Millisecond); err == nil {
+		t.Error("expected an error writing the metrics of a store that has not synced")
+	}
+
+	if err := store.Replace(nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := WriteMetrics(ctx, []*metricsstore.MetricsStore{store}, &b, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); got != "# HELP kube_test Test.\n" {
+		t.Errorf("unexpected metrics %q", got)
+	}
+}
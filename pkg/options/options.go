@@ -19,35 +19,119 @@ package options
 import (
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"k8s.io/klog"
 
 	"github.com/spf13/pflag"
 )
 
+// DefaultMetricPrefix is the prefix of the names of the metrics of the
+// collectors.
+const DefaultMetricPrefix = "kube_"
+
+var metricPrefixRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// ClusterLabel is the label holding the name of the cluster, if configured.
+const ClusterLabel = "cluster"
+
 // Options are the configurable parameters for kube-state-metrics.
 type Options struct {
 	Apiserver                            string
 	Kubeconfig                           string
+	Context                              string
+	ProxyURL                             string
+	KubeAPIQPS                           float32
+	KubeAPIBurst                         int
 	Help                                 bool
+	LogFormat                            string
 	Port                                 int
 	Host                                 string
+	MetricsPaths                         []string
+	ListenSocket                         string
 	TelemetryPort                        int
+	TLSCertFile                          string
+	TLSKeyFile                           string
+	TLSClientCAFile                      string
+	EnableDelegatedAuth                  bool
+	AuthResource                         string
+	AuthResourceGroup                    string
+	AuthResourceNamespace                string
+	AuthResourceName                     string
+	AuthVerb                             string
+	BasicAuthUsername                    string
+	BasicAuthPasswordFile                string
+	BasicAuthHtpasswdFile                string
 	TelemetryHost                        string
+	EnableProfiling                      bool
 	Collectors                           CollectorSet
+	ExternalCollectors                   []string
+	ContainerReasonWhitelist             []string
+	EmptyPodPhaseAsUnknown               bool
 	Namespaces                           NamespaceList
 	Shard                                int32
 	TotalShards                          int
+	ListPageSize                         int64
+	UseAPIServerCache                    bool
+	AutoSharding                         bool
+	LeaderElect                          bool
+	LeaderElectNamespace                 string
+	LeaderElectLeaseName                 string
+	LeaderElectLeaseDuration             time.Duration
+	LeaderElectRenewDeadline             time.Duration
+	LeaderElectRetryPeriod               time.Duration
+	Node                                 string
 	Pod                                  string
 	Namespace                            string
 	MetricBlacklist                      MetricSet
 	MetricWhitelist                      MetricSet
+	CustomLabels                         LabelSet
+	ClusterName                          string
+	ClusterNameFromKubeconfig            bool
+	MemberClusters                       MemberClusterList
+	MetricPrefix                         string
 	Version                              bool
+	Validate                             bool
+	HelpMetrics                          bool
+	FromFiles                            []string
+	FromDirs                             []string
 	DisablePodNonGenericResourceMetrics  bool
 	DisableNodeNonGenericResourceMetrics bool
 
-	EnableGZIPEncoding bool
+	EnableGZIPEncoding      bool
+	OmitMetricHelp          bool
+	OmitMetricType          bool
+	StableMetricsOnly       bool
+	IncludeDeprecated       bool
+	CollectorWorkers        int
+	ScrapeLatencyBudget     time.Duration
+	MaxConcurrentScrapes    int
+	WatchStalenessThreshold time.Duration
+	PushgatewayURL          string
+	PushgatewayJob          string
+	PushgatewayInterval     time.Duration
+	OTLPEndpoint            string
+	OTLPInterval            time.Duration
+	OTLPTracesEndpoint      string
+	BridgeProtocol          string
+	BridgeAddress           string
+	BridgePrefix            string
+	BridgeMetricFamilies    MetricSet
+	BridgeInterval          time.Duration
+	ShutdownDrainTimeout    time.Duration
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ServerMaxHeaderBytes    int
+	GOMAXPROCS              int
+	GOMEMLIMIT              int64
+	GOMEMLIMITRatio         float64
 
 	flags *pflag.FlagSet
 }
@@ -55,9 +139,11 @@ type Options struct {
 // NewOptions returns a new instance of `Options`.
 func NewOptions() *Options {
 	return &Options{
-		Collectors:      CollectorSet{},
-		MetricWhitelist: MetricSet{},
-		MetricBlacklist: MetricSet{},
+		Collectors:           CollectorSet{},
+		MetricWhitelist:      MetricSet{},
+		MetricBlacklist:      MetricSet{},
+		BridgeMetricFamilies: MetricSet{},
+		CustomLabels:         LabelSet{},
 	}
 }
 
@@ -79,32 +165,316 @@ func (o *Options) AddFlags() {
 
 	o.flags.StringVar(&o.Apiserver, "apiserver", "", `The URL of the apiserver to use as a master`)
 	o.flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file")
+	o.flags.StringVar(&o.Context, "context", "", "The name of the kubeconfig context to use instead of the current context. Uses the kubeconfig file given by --kubeconfig, the KUBECONFIG environment variable or ~/.kube/config.")
+	o.flags.StringVar(&o.ProxyURL, "proxy-url", "", "URL of the proxy to send requests to the Kubernetes apiserver through, except for hosts excluded by the NO_PROXY environment variable. Defaults to the HTTPS_PROXY and HTTP_PROXY environment variables.")
+	o.flags.Float32Var(&o.KubeAPIQPS, "kube-api-qps", 5, "Maximum queries per second to the Kubernetes apiserver. Raising it speeds up the initial sync of large clusters.")
+	o.flags.IntVar(&o.KubeAPIBurst, "kube-api-burst", 10, "Maximum burst of queries to the Kubernetes apiserver exceeding --kube-api-qps.")
 	o.flags.BoolVarP(&o.Help, "help", "h", false, "Print Help text")
+	o.flags.StringVar(&o.LogFormat, "log-format", "text", "Format of the log output, either text or json. JSON log lines contain the fields ts, level, caller and msg, and are always written to stderr. Verbosity is still controlled with -v.")
 	o.flags.IntVar(&o.Port, "port", 80, `Port to expose metrics on.`)
-	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Host to expose metrics on.`)
+	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Comma-separated list of hosts to expose metrics on, e.g. 10.0.0.1,fd00::1 to expose metrics on an IPv4 and an IPv6 address.`)
+	o.flags.StringSliceVar(&o.MetricsPaths, "metrics-path", []string{"/metrics"}, "Comma-separated list of paths to expose metrics on, e.g. /metrics,/kapis/monitoring/metrics to additionally expose them behind the KubeSphere API gateway without a rewriting proxy.")
+	o.flags.StringVar(&o.ListenSocket, "listen-socket", "", "Path of a Unix socket to additionally expose metrics on, e.g. for agents in the same pod to scrape without the network stack. The socket is served without TLS. Disabled when empty.")
 	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 81, `Port to expose kube-state-metrics self metrics on.`)
-	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Host to expose kube-state-metrics self metrics on.`)
+	o.flags.StringVar(&o.TLSCertFile, "tls-cert-file", "", "Certificate file to serve the metrics endpoint over HTTPS with. Requires --tls-key-file. Rotated certificates are reloaded without restarting.")
+	o.flags.StringVar(&o.TLSKeyFile, "tls-key-file", "", "Private key file matching --tls-cert-file.")
+	o.flags.StringVar(&o.TLSClientCAFile, "tls-client-ca-file", "", "CA certificates file to verify client certificates with. When set, clients of the metrics endpoint are required to present a certificate signed by one of these CAs. Requires --tls-cert-file.")
+	o.flags.BoolVar(&o.EnableDelegatedAuth, "enable-delegated-auth", false, "Require scrapes of the metrics endpoint to present a bearer token, which is authenticated with a TokenReview and authorized with a SubjectAccessReview against the Kubernetes apiserver.")
+	o.flags.StringVar(&o.AuthResource, "auth-resource", "", "Resource, optionally followed by /subresource, scrapes are authorized against with --enable-delegated-auth, e.g. services/proxy. When empty, scrapes are authorized against the non-resource URL path of the metrics endpoint.")
+	o.flags.StringVar(&o.AuthResourceGroup, "auth-resource-group", "", "API group of --auth-resource.")
+	o.flags.StringVar(&o.AuthResourceNamespace, "auth-resource-namespace", "", "Namespace of --auth-resource.")
+	o.flags.StringVar(&o.AuthResourceName, "auth-resource-name", "", "Name of --auth-resource.")
+	o.flags.StringVar(&o.BasicAuthUsername, "basic-auth-username", "", "Require scrapes of the metrics endpoint to authenticate with HTTP basic auth as this user, with the password read from --basic-auth-password-file.")
+	o.flags.StringVar(&o.BasicAuthPasswordFile, "basic-auth-password-file", "", "File containing the password of --basic-auth-username. A trailing newline is ignored.")
+	o.flags.StringVar(&o.BasicAuthHtpasswdFile, "basic-auth-htpasswd-file", "", "Require scrapes of the metrics endpoint to authenticate with HTTP basic auth as one of the users of this htpasswd file. Passwords must be hashed with bcrypt (htpasswd -B) or SHA-1 (htpasswd -s).")
+	o.flags.StringVar(&o.AuthVerb, "auth-verb", "get", "Verb scrapes are authorized for with --enable-delegated-auth.")
+	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Comma-separated list of hosts to expose kube-state-metrics self metrics on.`)
+	o.flags.BoolVar(&o.EnableProfiling, "profile", false, "Expose the net/http/pprof profiling endpoints under /debug/pprof/ on the telemetry port.")
 	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q", &DefaultCollectors))
+	o.flags.StringSliceVar(&o.ExternalCollectors, "external-collectors", nil, "Comma-separated list of executables generating metrics of the objects of a resource, which are enabled in addition to the collectors. See the README for their protocol.")
+	o.flags.BoolVar(&o.EmptyPodPhaseAsUnknown, "pod-empty-phase-as-unknown", false, "Report pods without a phase, e.g. right after their creation, in the Unknown phase of kube_pod_status_phase instead of leaving them out.")
+	o.flags.StringSliceVar(&o.ContainerReasonWhitelist, "container-reason-whitelist", nil, "Comma-separated list of waiting and terminated reasons of containers to be exposed, e.g. CrashLoopBackOff,OOMKilled. Defaults to a list of known reasons and any other current reason.")
 	o.flags.Var(&o.Namespaces, "namespace", fmt.Sprintf("Comma-separated list of namespaces to be enabled. Defaults to %q", &DefaultNamespaces))
 	o.flags.Var(&o.MetricWhitelist, "metric-whitelist", "Comma-separated list of metrics to be exposed. This list comprises of exact metric names and/or regex patterns. The whitelist and blacklist are mutually exclusive.")
 	o.flags.Var(&o.MetricBlacklist, "metric-blacklist", "Comma-separated list of metrics not to be enabled. This list comprises of exact metric names and/or regex patterns. The whitelist and blacklist are mutually exclusive.")
+	o.flags.Var(&o.CustomLabels, "custom-labels", "Comma-separated list of name=value labels added to all metrics of the collectors, e.g. region=eu-west-1,environment=production.")
+	o.flags.StringVar(&o.ClusterName, "cluster-name", "", "Name of the cluster added as cluster label to all metrics of the collectors, e.g. to tell clusters apart that write to the same Prometheus.")
+	o.flags.BoolVar(&o.ClusterNameFromKubeconfig, "cluster-name-from-kubeconfig", false, "Use the name of the cluster of the kubeconfig context as cluster label of all metrics of the collectors.")
+	o.flags.Var(&o.MemberClusters, "member-cluster", "Cluster to collect objects from, given as name=kubeconfig[@context]. Can be repeated to collect from several clusters, whose metrics carry the name as cluster label. An empty kubeconfig refers to the cluster configured by --apiserver and --kubeconfig. If unset, only that cluster is collected from.")
+	o.flags.StringVar(&o.MetricPrefix, "metric-prefix", DefaultMetricPrefix, "Prefix replacing kube_ in the names of the metrics of the collectors, e.g. to whitelabel them. The metric whitelist and blacklist match the original names.")
 	o.flags.Int32Var(&o.Shard, "shard", int32(0), "The instances shard nominal (zero indexed) within the total number of shards. (default 0)")
 	o.flags.IntVar(&o.TotalShards, "total-shards", 1, "The total number of shards. Sharding is disabled when total shards is set to 1.")
+	o.flags.Int64Var(&o.ListPageSize, "list-page-size", 0, "Maximum number of objects retrieved per list request when listing resources. Chunked lists are served from etcd instead of the apiserver watch cache. Chunking is disabled when set to 0.")
+	o.flags.BoolVar(&o.UseAPIServerCache, "use-apiserver-cache", false, "Serve all list requests from the apiserver watch cache, including relists. This considerably lowers the load on the apiserver and etcd, e.g. when kube-state-metrics restarts, at the cost of possibly stale lists. Takes precedence over --list-page-size, as lists served from the watch cache cannot be chunked.")
+
+	o.flags.StringVar(&o.Node, "node", "", "Name of the node kube-state-metrics runs on. When set, the pods collector only collects pods scheduled to this node, which allows running kube-state-metrics as a DaemonSet for pod metrics. Most likely this should be passed via the downward API.")
 
 	autoshardingNotice := "When set, it is expected that --pod and --pod-namespace are both set. Most likely this should be passed via the downward API. This is used for auto-detecting sharding. If set, this has preference over statically configured sharding. This is experimental, it may be removed without notice."
 
+	o.flags.BoolVar(&o.AutoSharding, "auto-sharding", false, "Derive the shard index and total number of shards from the ordinal of the pod within its StatefulSet and the StatefulSet's replicas. The pod is taken from --pod and --pod-namespace or, if unset, from the POD_NAME and POD_NAMESPACE environment variables. This is experimental, it may be removed without notice.")
 	o.flags.StringVar(&o.Pod, "pod", "", "Name of the pod that contains the kube-state-metrics container. "+autoshardingNotice)
 	o.flags.StringVar(&o.Namespace, "pod-namespace", "", "Name of the namespace of the pod specified by --pod. "+autoshardingNotice)
+	o.flags.BoolVar(&o.LeaderElect, "leader-elect", false, "Elect a leader among replicas using a coordination.k8s.io Lease. Only the leader serves metrics, standby replicas only serve the kube_state_metrics_standby metric. Replicas keep their caches in sync, so a standby can take over right away.")
+	o.flags.StringVar(&o.LeaderElectNamespace, "leader-elect-namespace", "", "Namespace of the leader election Lease. Defaults to the POD_NAMESPACE environment variable.")
+	o.flags.StringVar(&o.LeaderElectLeaseName, "leader-elect-lease-name", "kube-state-metrics", "Name of the leader election Lease.")
+	o.flags.DurationVar(&o.LeaderElectLeaseDuration, "leader-elect-lease-duration", 15*time.Second, "Duration standby replicas wait before taking over the leadership of a leader that stopped renewing the Lease.")
+	o.flags.DurationVar(&o.LeaderElectRenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "Duration the leader retries renewing the Lease before giving up the leadership.")
+	o.flags.DurationVar(&o.LeaderElectRetryPeriod, "leader-elect-retry-period", 2*time.Second, "Duration between attempts to acquire or renew the Lease.")
 	o.flags.BoolVarP(&o.Version, "version", "", false, "kube-state-metrics build version information")
+	o.flags.BoolVar(&o.Validate, "validate", false, "Validate the names, types and labels of the metric families exposed with the given configuration, lint the metrics generated for synthetic objects of the enabled collectors and exit, without connecting to the apiserver.")
+	o.flags.BoolVar(&o.HelpMetrics, "help-metrics", false, "Print the collector, name, type, stability, labels and help of every metric family of the enabled collectors with the given configuration, and whether a filter drops it, and exit, without connecting to the apiserver.")
+	o.flags.StringSliceVar(&o.FromFiles, "from-file", nil, "Comma-separated list of YAML or JSON manifests, gzipped tarballs of them, e.g. Velero backups, or Kubernetes audit logs to read objects from instead of the apiserver. The metrics of the objects are printed to stdout once, then kube-state-metrics exits. The file - reads stdin.")
+	o.flags.StringSliceVar(&o.FromDirs, "from-dir", nil, "Comma-separated list of directories walked for YAML and JSON manifests and gzipped tarballs of them to read objects from like --from-file.")
 	o.flags.BoolVarP(&o.DisablePodNonGenericResourceMetrics, "disable-pod-non-generic-resource-metrics", "", false, "Disable pod non generic resource request and limit metrics")
 	o.flags.BoolVarP(&o.DisableNodeNonGenericResourceMetrics, "disable-node-non-generic-resource-metrics", "", false, "Disable node non generic resource request and limit metrics")
 	o.flags.BoolVar(&o.EnableGZIPEncoding, "enable-gzip-encoding", false, "Gzip responses when requested by clients via 'Accept-Encoding: gzip' header.")
+	o.flags.BoolVar(&o.OmitMetricHelp, "omit-metric-help", false, "Leave out the HELP lines of metric families, considerably reducing the size of responses.")
+	o.flags.BoolVar(&o.OmitMetricType, "omit-metric-type", false, "Leave out the TYPE lines of metric families. Prometheus treats metrics without type as untyped.")
+	o.flags.BoolVar(&o.StableMetricsOnly, "stable-metrics-only", false, "Only serve stable metric families, leaving out alpha and experimental ones, whose HELP lines are prefixed with their stability level.")
+	o.flags.BoolVar(&o.IncludeDeprecated, "include-deprecated-metrics", false, "Serve metric families deprecated in an earlier minor release, which are hidden by default, e.g. while migrating dashboards to their replacements.")
+	o.flags.IntVar(&o.CollectorWorkers, "collector-workers", 0, "Number of collectors whose metrics are assembled concurrently during a scrape. Defaults to GOMAXPROCS when set to 0.")
+	o.flags.IntVar(&o.GOMAXPROCS, "gomaxprocs", 0, "Maximum number of CPUs executing Go code simultaneously. Derived from the cgroup CPU limit when set to 0, unless the GOMAXPROCS environment variable is set.")
+	o.flags.Int64Var(&o.GOMEMLIMIT, "gomemlimit", 0, "Soft memory limit of the Go runtime in bytes. Derived from the cgroup memory limit and --gomemlimit-ratio when set to 0, unless the GOMEMLIMIT environment variable is set. Requires a build with Go 1.19 or later.")
+	o.flags.Float64Var(&o.GOMEMLIMITRatio, "gomemlimit-ratio", 0, "Ratio of the cgroup memory limit used as soft memory limit of the Go runtime, e.g. 0.9. Not derived from the cgroup memory limit when set to 0. Requires a build with Go 1.19 or later.")
+	o.flags.IntVar(&o.MaxConcurrentScrapes, "max-concurrent-scrapes", 0, "Maximum number of concurrently served scrapes of the metrics endpoint. Further scrapes are rejected with 503 Service Unavailable and a Retry-After header. Unlimited when set to 0.")
+	o.flags.DurationVar(&o.WatchStalenessThreshold, "watch-staleness-threshold", 0, "Duration after which /livez fails if the list and watch of any collector have not been active, i.e. their watch broke and could not be re-established. Watches are re-established at least every 10 minutes, hence the threshold should be larger. Disabled when set to 0.")
+	o.flags.DurationVar(&o.ShutdownDrainTimeout, "shutdown-drain-timeout", 25*time.Second, "Maximum time to wait for in-flight scrapes to finish when shutting down on SIGTERM. Should be shorter than the termination grace period of the pod.")
+	o.flags.DurationVar(&o.ServerReadTimeout, "server-read-timeout", 60*time.Second, "Maximum duration for reading an entire request to the metrics server, including the body. No timeout when set to 0.")
+	o.flags.DurationVar(&o.ServerReadHeaderTimeout, "server-read-header-timeout", 5*time.Second, "Maximum duration for reading the headers of a request to the metrics server. Defaults to --server-read-timeout when set to 0.")
+	o.flags.DurationVar(&o.ServerWriteTimeout, "server-write-timeout", 60*time.Second, "Maximum duration from reading the headers of a request to the metrics server until writing the response has finished. Should be longer than the scrape timeout. No timeout when set to 0.")
+	o.flags.DurationVar(&o.ServerIdleTimeout, "server-idle-timeout", 5*time.Minute, "Maximum duration to wait for the next request on keep-alive connections to the metrics server. Defaults to --server-read-timeout when set to 0.")
+	o.flags.IntVar(&o.ServerMaxHeaderBytes, "server-max-header-bytes", http.DefaultMaxHeaderBytes, "Maximum size of the headers of requests to the metrics server, in bytes.")
+	o.flags.StringVar(&o.PushgatewayURL, "pushgateway-url", "", "URL of a Prometheus Pushgateway to push metrics to every --pushgateway-interval, grouped by job, instance and shard. Disabled when empty.")
+	o.flags.StringVar(&o.PushgatewayJob, "pushgateway-job", "kube-state-metrics", "Job label of metrics pushed to the Pushgateway.")
+	o.flags.DurationVar(&o.PushgatewayInterval, "pushgateway-interval", time.Minute, "Interval between pushes to the Pushgateway.")
+	o.flags.StringVar(&o.OTLPEndpoint, "otlp-endpoint", "", "Base URL of an OpenTelemetry collector to export metrics to as OTLP gauges every --otlp-interval, using OTLP/HTTP with JSON encoding, e.g. http://otel-collector:4318. Disabled when empty.")
+	o.flags.DurationVar(&o.OTLPInterval, "otlp-interval", time.Minute, "Interval between exports to the OTLP endpoint.")
+	o.flags.StringVar(&o.OTLPTracesEndpoint, "otlp-traces-endpoint", "", "Base URL of an OpenTelemetry collector to export traces of scrapes, of writing the metrics of each collector and of relists to, using OTLP/HTTP with JSON encoding, e.g. http://otel-collector:4318. Disabled when empty.")
+	o.flags.StringVar(&o.BridgeProtocol, "bridge-protocol", "", "Protocol to send metrics to --bridge-address with every --bridge-interval, for monitoring systems which cannot scrape the Prometheus format. Either graphite (plaintext protocol over TCP) or statsd (gauges over UDP). Disabled when empty.")
+	o.flags.StringVar(&o.BridgeAddress, "bridge-address", "", "Address of the Graphite or StatsD endpoint, e.g. graphite:2003.")
+	o.flags.StringVar(&o.BridgePrefix, "bridge-prefix", "", "Prefix of the names of metrics sent to the Graphite or StatsD endpoint, e.g. clusters.prod.")
+	o.flags.Var(&o.BridgeMetricFamilies, "bridge-metric-families", "Comma-separated list of metric families sent to the Graphite or StatsD endpoint, out of the metrics exposed according to --metric-whitelist and --metric-blacklist. This list comprises of exact metric names and/or regex patterns. Defaults to all metric families.")
+	o.flags.DurationVar(&o.BridgeInterval, "bridge-interval", time.Minute, "Interval between sending metrics to the Graphite or StatsD endpoint.")
+	o.flags.DurationVar(&o.ScrapeLatencyBudget, "scrape-latency-budget", 0, "Maximum time spent generating the response of a scrape. If exceeded, the last complete response is served instead, while generation finishes in the background. The served metrics may be outdated by up to one scrape interval. Disabled when set to 0.")
 }
 
 // Parse parses the flag definitions from the argument list.
 func (o *Options) Parse() error {
 	err := o.flags.Parse(os.Args)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if o.AutoSharding {
+		if o.Pod == "" {
+			o.Pod = os.Getenv("POD_NAME")
+		}
+		if o.Namespace == "" {
+			o.Namespace = os.Getenv("POD_NAMESPACE")
+		}
+		if o.Pod == "" || o.Namespace == "" {
+			return fmt.Errorf("auto-sharding requires --pod and --pod-namespace or the POD_NAME and POD_NAMESPACE environment variables to be set")
+		}
+	}
+
+	if o.LeaderElect {
+		if o.AutoSharding {
+			return fmt.Errorf("--leader-elect cannot be combined with --auto-sharding")
+		}
+		if o.LeaderElectNamespace == "" {
+			o.LeaderElectNamespace = os.Getenv("POD_NAMESPACE")
+		}
+		if o.LeaderElectNamespace == "" {
+			return fmt.Errorf("leader election requires --leader-elect-namespace or the POD_NAMESPACE environment variable to be set")
+		}
+		if o.LeaderElectRenewDeadline >= o.LeaderElectLeaseDuration {
+			return fmt.Errorf("--leader-elect-renew-deadline must be shorter than --leader-elect-lease-duration")
+		}
+	}
+
+	for _, t := range []struct {
+		flag    string
+		timeout time.Duration
+	}{
+		{"--server-read-timeout", o.ServerReadTimeout},
+		{"--server-read-header-timeout", o.ServerReadHeaderTimeout},
+		{"--server-write-timeout", o.ServerWriteTimeout},
+		{"--server-idle-timeout", o.ServerIdleTimeout},
+	} {
+		if t.timeout < 0 {
+			return fmt.Errorf("%s must not be negative, got %s", t.flag, t.timeout)
+		}
+	}
+	if o.ServerMaxHeaderBytes < 1 {
+		return fmt.Errorf("--server-max-header-bytes must be at least 1, got %d", o.ServerMaxHeaderBytes)
+	}
+
+	if o.PushgatewayURL != "" {
+		u, err := url.Parse(o.PushgatewayURL)
+		if err != nil {
+			return fmt.Errorf("invalid --pushgateway-url: %v", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("--pushgateway-url must be an absolute URL, got %q", o.PushgatewayURL)
+		}
+		if o.PushgatewayJob == "" {
+			return fmt.Errorf("--pushgateway-job must not be empty")
+		}
+		if o.PushgatewayInterval <= 0 {
+			return fmt.Errorf("--pushgateway-interval must be positive, got %s", o.PushgatewayInterval)
+		}
+	}
+
+	if o.OTLPEndpoint != "" {
+		u, err := url.Parse(o.OTLPEndpoint)
+		if err != nil {
+			return fmt.Errorf("invalid --otlp-endpoint: %v", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("--otlp-endpoint must be an absolute URL, got %q", o.OTLPEndpoint)
+		}
+		if o.OTLPInterval <= 0 {
+			return fmt.Errorf("--otlp-interval must be positive, got %s", o.OTLPInterval)
+		}
+	}
+
+	if o.OTLPTracesEndpoint != "" {
+		u, err := url.Parse(o.OTLPTracesEndpoint)
+		if err != nil {
+			return fmt.Errorf("invalid --otlp-traces-endpoint: %v", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("--otlp-traces-endpoint must be an absolute URL, got %q", o.OTLPTracesEndpoint)
+		}
+	}
+
+	if len(o.MetricsPaths) == 0 {
+		return fmt.Errorf("--metrics-path must not be empty")
+	}
+	metricsPaths := map[string]bool{}
+	for _, p := range o.MetricsPaths {
+		if !strings.HasPrefix(p, "/") || p == "/" {
+			return fmt.Errorf("--metrics-path must be absolute paths other than /, got %q", p)
+		}
+		if metricsPaths[p] {
+			return fmt.Errorf("--metrics-path contains %q more than once", p)
+		}
+		metricsPaths[p] = true
+	}
+
+	switch o.BridgeProtocol {
+	case "":
+	case "graphite", "statsd":
+		if o.BridgeAddress == "" {
+			return fmt.Errorf("--bridge-address must be set with --bridge-protocol")
+		}
+		if o.BridgeInterval <= 0 {
+			return fmt.Errorf("--bridge-interval must be positive, got %s", o.BridgeInterval)
+		}
+	default:
+		return fmt.Errorf("--bridge-protocol must be graphite or statsd, got %q", o.BridgeProtocol)
+	}
+
+	if o.LogFormat != "text" && o.LogFormat != "json" {
+		return fmt.Errorf("--log-format must be text or json, got %q", o.LogFormat)
+	}
+
+	for _, h := range []struct {
+		flag  string
+		hosts string
+	}{
+		{"--host", o.Host},
+		{"--telemetry-host", o.TelemetryHost},
+	} {
+		// A single empty host listens on all addresses.
+		if h.hosts == "" {
+			continue
+		}
+		for _, host := range strings.Split(h.hosts, ",") {
+			if strings.TrimSpace(host) == "" {
+				return fmt.Errorf("%s must not contain empty hosts, got %q", h.flag, h.hosts)
+			}
+		}
+	}
+
+	if (o.TLSCertFile == "") != (o.TLSKeyFile == "") {
+		return fmt.Errorf("--tls-cert-file and --tls-key-file must be set together")
+	}
+	if o.TLSClientCAFile != "" && o.TLSCertFile == "" {
+		return fmt.Errorf("--tls-client-ca-file requires --tls-cert-file and --tls-key-file")
+	}
+	if !o.EnableDelegatedAuth && (o.AuthResource != "" || o.AuthResourceGroup != "" || o.AuthResourceNamespace != "" || o.AuthResourceName != "") {
+		return fmt.Errorf("--auth-resource flags require --enable-delegated-auth")
+	}
+	if (o.BasicAuthUsername == "") != (o.BasicAuthPasswordFile == "") {
+		return fmt.Errorf("--basic-auth-username and --basic-auth-password-file must be set together")
+	}
+	if o.BasicAuthUsername != "" && o.BasicAuthHtpasswdFile != "" {
+		return fmt.Errorf("--basic-auth-username and --basic-auth-htpasswd-file are mutually exclusive")
+	}
+	if o.EnableDelegatedAuth && (o.BasicAuthUsername != "" || o.BasicAuthHtpasswdFile != "") {
+		return fmt.Errorf("--enable-delegated-auth and basic auth are mutually exclusive")
+	}
+
+	if o.ProxyURL != "" {
+		u, err := url.Parse(o.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid --proxy-url: %v", err)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("--proxy-url must be an http, https or socks5 URL, got %q", o.ProxyURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("--proxy-url must include a host, got %q", o.ProxyURL)
+		}
+	}
+
+	if o.KubeAPIQPS <= 0 {
+		return fmt.Errorf("--kube-api-qps must be positive, got %v", o.KubeAPIQPS)
+	}
+	if o.KubeAPIBurst < 1 {
+		return fmt.Errorf("--kube-api-burst must be at least 1, got %d", o.KubeAPIBurst)
+	}
+
+	if o.ClusterName != "" && o.ClusterNameFromKubeconfig {
+		return fmt.Errorf("--cluster-name and --cluster-name-from-kubeconfig are mutually exclusive")
+	}
+	if _, ok := o.CustomLabels[ClusterLabel]; ok && (o.ClusterName != "" || o.ClusterNameFromKubeconfig) {
+		return fmt.Errorf("--custom-labels must not contain the %s label if the cluster name is set", ClusterLabel)
+	}
+	if len(o.MemberClusters) > 0 {
+		if o.ClusterName != "" || o.ClusterNameFromKubeconfig {
+			return fmt.Errorf("--member-cluster and --cluster-name or --cluster-name-from-kubeconfig are mutually exclusive")
+		}
+		if _, ok := o.CustomLabels[ClusterLabel]; ok {
+			return fmt.Errorf("--custom-labels must not contain the %s label if member clusters are set", ClusterLabel)
+		}
+	}
+
+	if o.Offline() && len(o.MemberClusters) > 0 {
+		return fmt.Errorf("--from-file and --from-dir cannot be combined with --member-cluster")
+	}
+
+	if !metricPrefixRegexp.MatchString(o.MetricPrefix) {
+		return fmt.Errorf("--metric-prefix must be a valid metric name prefix, got %q", o.MetricPrefix)
+	}
+
+	if o.TotalShards < 1 {
+		return fmt.Errorf("total shards must be at least 1, got %d", o.TotalShards)
+	}
+	if o.Shard < 0 || int(o.Shard) >= o.TotalShards {
+		return fmt.Errorf("shard must be between 0 and %d, got %d", o.TotalShards-1, o.Shard)
+	}
+
+	return nil
+}
+
+// Offline returns whether objects are read from manifests given by --from-file
+// or --from-dir instead of the apiserver.
+func (o *Options) Offline() bool {
+	return len(o.FromFiles) > 0 || len(o.FromDirs) > 0
 }
 
 // Usage is the function called when an error occurs while parsing flags.
@@ -18,6 +18,7 @@ package options
 
 import (
 	"os"
+	"reflect"
 	"sync"
 	"testing"
 
@@ -73,3 +74,720 @@ func TestOptionsParse(t *testing.T) {
 		}
 	}
 }
+
+func TestOptionsParseSharding(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "sharding disabled",
+			Args:    []string{"./kube-state-metrics"},
+			WantErr: false,
+		},
+		{
+			Desc:    "last shard",
+			Args:    []string{"./kube-state-metrics", "--shard=2", "--total-shards=3"},
+			WantErr: false,
+		},
+		{
+			Desc:    "shard out of range",
+			Args:    []string{"./kube-state-metrics", "--shard=3", "--total-shards=3"},
+			WantErr: true,
+		},
+		{
+			Desc:    "negative shard",
+			Args:    []string{"./kube-state-metrics", "--shard=-1", "--total-shards=3"},
+			WantErr: true,
+		},
+		{
+			Desc:    "no shards",
+			Args:    []string{"./kube-state-metrics", "--total-shards=0"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseAutoSharding(t *testing.T) {
+	os.Setenv("POD_NAME", "kube-state-metrics-1")
+	os.Setenv("POD_NAMESPACE", "monitoring")
+	defer os.Unsetenv("POD_NAME")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	opts := NewOptions()
+	opts.AddFlags()
+	os.Args = []string{"./kube-state-metrics", "--auto-sharding", "--pod=ksm-0"}
+
+	if err := opts.Parse(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Pod != "ksm-0" {
+		t.Errorf("expected --pod to take preference, got %q", opts.Pod)
+	}
+	if opts.Namespace != "monitoring" {
+		t.Errorf("expected namespace from environment, got %q", opts.Namespace)
+	}
+
+	os.Unsetenv("POD_NAMESPACE")
+	opts = NewOptions()
+	opts.AddFlags()
+	os.Args = []string{"./kube-state-metrics", "--auto-sharding"}
+
+	if err := opts.Parse(); err == nil {
+		t.Error("expected error when pod namespace cannot be determined")
+	}
+}
+
+func TestOptionsParseTLS(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "TLS disabled",
+			Args:    []string{"./kube-state-metrics"},
+			WantErr: false,
+		},
+		{
+			Desc:    "certificate and key",
+			Args:    []string{"./kube-state-metrics", "--tls-cert-file=tls.crt", "--tls-key-file=tls.key"},
+			WantErr: false,
+		},
+		{
+			Desc:    "certificate without key",
+			Args:    []string{"./kube-state-metrics", "--tls-cert-file=tls.crt"},
+			WantErr: true,
+		},
+		{
+			Desc:    "key without certificate",
+			Args:    []string{"./kube-state-metrics", "--tls-key-file=tls.key"},
+			WantErr: true,
+		},
+		{
+			Desc:    "client CA",
+			Args:    []string{"./kube-state-metrics", "--tls-cert-file=tls.crt", "--tls-key-file=tls.key", "--tls-client-ca-file=ca.crt"},
+			WantErr: false,
+		},
+		{
+			Desc:    "client CA without certificate",
+			Args:    []string{"./kube-state-metrics", "--tls-client-ca-file=ca.crt"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseDelegatedAuth(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "delegated auth",
+			Args:    []string{"./kube-state-metrics", "--enable-delegated-auth"},
+			WantErr: false,
+		},
+		{
+			Desc:    "delegated auth with resource",
+			Args:    []string{"./kube-state-metrics", "--enable-delegated-auth", "--auth-resource=services/proxy", "--auth-resource-namespace=kube-system"},
+			WantErr: false,
+		},
+		{
+			Desc:    "resource without delegated auth",
+			Args:    []string{"./kube-state-metrics", "--auth-resource=services/proxy"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseBasicAuth(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "username and password file",
+			Args:    []string{"./kube-state-metrics", "--basic-auth-username=prometheus", "--basic-auth-password-file=/etc/ksm/password"},
+			WantErr: false,
+		},
+		{
+			Desc:    "htpasswd file",
+			Args:    []string{"./kube-state-metrics", "--basic-auth-htpasswd-file=/etc/ksm/htpasswd"},
+			WantErr: false,
+		},
+		{
+			Desc:    "username without password file",
+			Args:    []string{"./kube-state-metrics", "--basic-auth-username=prometheus"},
+			WantErr: true,
+		},
+		{
+			Desc:    "username and htpasswd file",
+			Args:    []string{"./kube-state-metrics", "--basic-auth-username=prometheus", "--basic-auth-password-file=/etc/ksm/password", "--basic-auth-htpasswd-file=/etc/ksm/htpasswd"},
+			WantErr: true,
+		},
+		{
+			Desc:    "basic auth and delegated auth",
+			Args:    []string{"./kube-state-metrics", "--basic-auth-htpasswd-file=/etc/ksm/htpasswd", "--enable-delegated-auth"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseServerTimeouts(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "defaults",
+			Args:    []string{"./kube-state-metrics"},
+			WantErr: false,
+		},
+		{
+			Desc:    "timeouts disabled",
+			Args:    []string{"./kube-state-metrics", "--server-read-timeout=0", "--server-write-timeout=0", "--server-idle-timeout=0", "--server-read-header-timeout=0"},
+			WantErr: false,
+		},
+		{
+			Desc:    "negative timeout",
+			Args:    []string{"./kube-state-metrics", "--server-write-timeout=-1s"},
+			WantErr: true,
+		},
+		{
+			Desc:    "no header bytes",
+			Args:    []string{"./kube-state-metrics", "--server-max-header-bytes=0"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseProxyURL(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "http proxy",
+			Args:    []string{"./kube-state-metrics", "--proxy-url=http://proxy.example.com:3128"},
+			WantErr: false,
+		},
+		{
+			Desc:    "socks5 proxy",
+			Args:    []string{"./kube-state-metrics", "--proxy-url=socks5://proxy.example.com:1080"},
+			WantErr: false,
+		},
+		{
+			Desc:    "unsupported scheme",
+			Args:    []string{"./kube-state-metrics", "--proxy-url=ftp://proxy.example.com"},
+			WantErr: true,
+		},
+		{
+			Desc:    "no host",
+			Args:    []string{"./kube-state-metrics", "--proxy-url=http://"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseHosts(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "all addresses",
+			Args:    []string{"./kube-state-metrics", "--host="},
+			WantErr: false,
+		},
+		{
+			Desc:    "IPv4 and IPv6 addresses",
+			Args:    []string{"./kube-state-metrics", "--host=10.0.0.1,fd00::1", "--telemetry-host=127.0.0.1,::1"},
+			WantErr: false,
+		},
+		{
+			Desc:    "empty host",
+			Args:    []string{"./kube-state-metrics", "--host=10.0.0.1,"},
+			WantErr: true,
+		},
+		{
+			Desc:    "empty telemetry host",
+			Args:    []string{"./kube-state-metrics", "--telemetry-host=,::1"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseKubeAPIRateLimits(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "defaults",
+			Args:    []string{"./kube-state-metrics"},
+			WantErr: false,
+		},
+		{
+			Desc:    "raised limits",
+			Args:    []string{"./kube-state-metrics", "--kube-api-qps=50", "--kube-api-burst=100"},
+			WantErr: false,
+		},
+		{
+			Desc:    "zero qps",
+			Args:    []string{"./kube-state-metrics", "--kube-api-qps=0"},
+			WantErr: true,
+		},
+		{
+			Desc:    "zero burst",
+			Args:    []string{"./kube-state-metrics", "--kube-api-burst=0"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseLogFormat(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "text",
+			Args:    []string{"./kube-state-metrics", "--log-format=text"},
+			WantErr: false,
+		},
+		{
+			Desc:    "json",
+			Args:    []string{"./kube-state-metrics", "--log-format=json"},
+			WantErr: false,
+		},
+		{
+			Desc:    "unknown format",
+			Args:    []string{"./kube-state-metrics", "--log-format=logfmt"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseLeaderElection(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		Env     string
+		WantErr bool
+	}{
+		{
+			Desc:    "namespace flag",
+			Args:    []string{"./kube-state-metrics", "--leader-elect", "--leader-elect-namespace=kube-system"},
+			WantErr: false,
+		},
+		{
+			Desc:    "namespace from environment",
+			Args:    []string{"./kube-state-metrics", "--leader-elect"},
+			Env:     "kube-system",
+			WantErr: false,
+		},
+		{
+			Desc:    "namespace missing",
+			Args:    []string{"./kube-state-metrics", "--leader-elect"},
+			WantErr: true,
+		},
+		{
+			Desc:    "renew deadline exceeding lease duration",
+			Args:    []string{"./kube-state-metrics", "--leader-elect", "--leader-elect-namespace=kube-system", "--leader-elect-renew-deadline=20s"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+		os.Setenv("POD_NAMESPACE", test.Env)
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+	os.Unsetenv("POD_NAMESPACE")
+}
+
+func TestOptionsParseClusterName(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "cluster name",
+			Args:    []string{"./kube-state-metrics", "--cluster-name=prod-eu", "--custom-labels=region=eu"},
+			WantErr: false,
+		},
+		{
+			Desc:    "cluster name from kubeconfig",
+			Args:    []string{"./kube-state-metrics", "--cluster-name-from-kubeconfig"},
+			WantErr: false,
+		},
+		{
+			Desc:    "cluster label without cluster name",
+			Args:    []string{"./kube-state-metrics", "--custom-labels=cluster=prod-eu"},
+			WantErr: false,
+		},
+		{
+			Desc:    "cluster name and cluster name from kubeconfig",
+			Args:    []string{"./kube-state-metrics", "--cluster-name=prod-eu", "--cluster-name-from-kubeconfig"},
+			WantErr: true,
+		},
+		{
+			Desc:    "cluster name and cluster label",
+			Args:    []string{"./kube-state-metrics", "--cluster-name=prod-eu", "--custom-labels=cluster=prod-us"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseMemberClusters(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "member clusters",
+			Args:    []string{"./kube-state-metrics", "--member-cluster=host=", "--member-cluster=member1=/etc/kubeconfig/member1", "--custom-labels=region=eu"},
+			WantErr: false,
+		},
+		{
+			Desc:    "member clusters and cluster name",
+			Args:    []string{"./kube-state-metrics", "--member-cluster=member1=/etc/kubeconfig/member1", "--cluster-name=prod-eu"},
+			WantErr: true,
+		},
+		{
+			Desc:    "member clusters and cluster name from kubeconfig",
+			Args:    []string{"./kube-state-metrics", "--member-cluster=member1=/etc/kubeconfig/member1", "--cluster-name-from-kubeconfig"},
+			WantErr: true,
+		},
+		{
+			Desc:    "member clusters and cluster label",
+			Args:    []string{"./kube-state-metrics", "--member-cluster=member1=/etc/kubeconfig/member1", "--custom-labels=cluster=prod-eu"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseMetricPrefix(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "default prefix",
+			Args:    []string{"./kube-state-metrics"},
+			WantErr: false,
+		},
+		{
+			Desc:    "custom prefix",
+			Args:    []string{"./kube-state-metrics", "--metric-prefix=acme_"},
+			WantErr: false,
+		},
+		{
+			Desc:    "empty prefix",
+			Args:    []string{"./kube-state-metrics", "--metric-prefix="},
+			WantErr: true,
+		},
+		{
+			Desc:    "invalid prefix",
+			Args:    []string{"./kube-state-metrics", "--metric-prefix=acme-"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseOffline(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantErr     bool
+		WantOffline bool
+	}{
+		{
+			Desc:        "online",
+			Args:        []string{"./kube-state-metrics"},
+			WantErr:     false,
+			WantOffline: false,
+		},
+		{
+			Desc:        "from files",
+			Args:        []string{"./kube-state-metrics", "--from-file=pods.yaml,-"},
+			WantErr:     false,
+			WantOffline: true,
+		},
+		{
+			Desc:        "from dir",
+			Args:        []string{"./kube-state-metrics", "--from-dir=manifests"},
+			WantErr:     false,
+			WantOffline: true,
+		},
+		{
+			Desc:        "from dir and member clusters",
+			Args:        []string{"./kube-state-metrics", "--from-dir=manifests", "--member-cluster=member1=/etc/kubeconfig/member1"},
+			WantErr:     true,
+			WantOffline: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+		if opts.Offline() != test.WantOffline {
+			t.Errorf("Test error for Desc: %s. Expected offline: %v, got: %v", test.Desc, test.WantOffline, opts.Offline())
+		}
+	}
+}
+
+func TestOptionsParseOTLPTracesEndpoint(t *testing.T) {
+	tests := []struct {
+		Desc    string
+		Args    []string
+		WantErr bool
+	}{
+		{
+			Desc:    "disabled",
+			Args:    []string{"./kube-state-metrics"},
+			WantErr: false,
+		},
+		{
+			Desc:    "collector",
+			Args:    []string{"./kube-state-metrics", "--otlp-traces-endpoint=http://otel-collector:4318"},
+			WantErr: false,
+		},
+		{
+			Desc:    "relative URL",
+			Args:    []string{"./kube-state-metrics", "--otlp-traces-endpoint=otel-collector:4318"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+	}
+}
+
+func TestOptionsParseMetricsPaths(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Args      []string
+		WantErr   bool
+		WantPaths []string
+	}{
+		{
+			Desc:      "default",
+			Args:      []string{"./kube-state-metrics"},
+			WantPaths: []string{"/metrics"},
+		},
+		{
+			Desc:      "aliases",
+			Args:      []string{"./kube-state-metrics", "--metrics-path=/metrics,/kapis/monitoring/metrics"},
+			WantPaths: []string{"/metrics", "/kapis/monitoring/metrics"},
+		},
+		{
+			Desc:    "relative path",
+			Args:    []string{"./kube-state-metrics", "--metrics-path=metrics"},
+			WantErr: true,
+		},
+		{
+			Desc:    "root",
+			Args:    []string{"./kube-state-metrics", "--metrics-path=/"},
+			WantErr: true,
+		},
+		{
+			Desc:    "duplicate",
+			Args:    []string{"./kube-state-metrics", "--metrics-path=/metrics,/metrics"},
+			WantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantErr {
+			t.Errorf("Test error for Desc: %s. Expected error: %v, got: %v", test.Desc, test.WantErr, err)
+		}
+		if !test.WantErr && !reflect.DeepEqual(opts.MetricsPaths, test.WantPaths) {
+			t.Errorf("Test error for Desc: %s. Expected paths %v, got %v", test.Desc, test.WantPaths, opts.MetricsPaths)
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -126,3 +128,113 @@ func (n *NamespaceList) Set(value string) error {
 func (n *NamespaceList) Type() string {
 	return "string"
 }
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// LabelSet represents labels added to all metrics, by name.
+type LabelSet map[string]string
+
+func (l *LabelSet) String() string {
+	s := *l
+	pairs := make([]string, 0, len(s))
+	for _, name := range s.Names() {
+		pairs = append(pairs, name+"="+s[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set converts a comma-separated string of name=value pairs into labels and
+// adds them to the LabelSet.
+func (l *LabelSet) Set(value string) error {
+	s := *l
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("label %q must be of the form name=value", pair)
+		}
+		name := strings.TrimSpace(kv[0])
+		if !labelNameRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if _, ok := s[name]; ok {
+			return fmt.Errorf("duplicate label %q", name)
+		}
+		s[name] = strings.TrimSpace(kv[1])
+	}
+	return nil
+}
+
+// Names returns the sorted names of the labels in the LabelSet.
+func (l LabelSet) Names() []string {
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Type returns a descriptive string about the LabelSet type.
+func (l *LabelSet) Type() string {
+	return "string"
+}
+
+// MemberCluster is a cluster whose objects are collected in multi-cluster
+// mode.
+type MemberCluster struct {
+	// Name is the value of the cluster label of the metrics of the cluster.
+	Name string
+	// Kubeconfig is the path of the kubeconfig of the cluster. If empty,
+	// the apiserver and kubeconfig of kube-state-metrics itself are used.
+	Kubeconfig string
+	// Context is the kubeconfig context of the cluster. If empty, the
+	// current context is used.
+	Context string
+}
+
+// MemberClusterList represents the member clusters of a multi-cluster
+// deployment, in the order they were given.
+type MemberClusterList []MemberCluster
+
+func (l *MemberClusterList) String() string {
+	clusters := make([]string, len(*l))
+	for i, c := range *l {
+		clusters[i] = c.Name + "=" + c.Kubeconfig
+		if c.Context != "" {
+			clusters[i] += "@" + c.Context
+		}
+	}
+	return strings.Join(clusters, ",")
+}
+
+// Set converts a name=kubeconfig[@context] string into a member cluster and
+// adds it to the MemberClusterList.
+func (l *MemberClusterList) Set(value string) error {
+	kv := strings.SplitN(strings.TrimSpace(value), "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("member cluster %q must be of the form name=kubeconfig[@context]", value)
+	}
+	c := MemberCluster{Name: strings.TrimSpace(kv[0]), Kubeconfig: strings.TrimSpace(kv[1])}
+	if i := strings.LastIndex(c.Kubeconfig, "@"); i >= 0 {
+		c.Kubeconfig, c.Context = c.Kubeconfig[:i], c.Kubeconfig[i+1:]
+	}
+	if c.Name == "" {
+		return fmt.Errorf("member cluster %q must have a name", value)
+	}
+	for _, other := range *l {
+		if other.Name == c.Name {
+			return fmt.Errorf("duplicate member cluster %q", c.Name)
+		}
+	}
+	*l = append(*l, c)
+	return nil
+}
+
+// Type returns a descriptive string about the MemberClusterList type.
+func (l *MemberClusterList) Type() string {
+	return "string"
+}
@@ -116,3 +116,119 @@ func TestMetricSetSet(t *testing.T) {
 		}
 	}
 }
+
+func TestLabelSetSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      LabelSet
+		WantedError bool
+	}{
+		{
+			Desc:   "empty labels",
+			Value:  "",
+			Wanted: LabelSet{},
+		},
+		{
+			Desc:   "normal labels",
+			Value:  "region=eu-west-1, env=prod",
+			Wanted: LabelSet{"region": "eu-west-1", "env": "prod"},
+		},
+		{
+			Desc:   "empty value",
+			Value:  "env=",
+			Wanted: LabelSet{"env": ""},
+		},
+		{
+			Desc:        "missing value",
+			Value:       "env",
+			Wanted:      LabelSet{},
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid name",
+			Value:       "1env=prod",
+			Wanted:      LabelSet{},
+			WantedError: true,
+		},
+		{
+			Desc:        "reserved name",
+			Value:       "__env=prod",
+			Wanted:      LabelSet{},
+			WantedError: true,
+		},
+		{
+			Desc:        "duplicate name",
+			Value:       "env=prod,env=dev",
+			Wanted:      LabelSet{"env": "prod"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		ls := &LabelSet{}
+		gotError := ls.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*ls, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *ls, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestMemberClusterListSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Values      []string
+		Wanted      MemberClusterList
+		WantedError bool
+	}{
+		{
+			Desc:   "kubeconfig",
+			Values: []string{"member1=/etc/kubeconfig/member1"},
+			Wanted: MemberClusterList{{Name: "member1", Kubeconfig: "/etc/kubeconfig/member1"}},
+		},
+		{
+			Desc:   "kubeconfig and context",
+			Values: []string{"member1=/etc/kubeconfig/members@member1", "member2=/etc/kubeconfig/members@member2"},
+			Wanted: MemberClusterList{
+				{Name: "member1", Kubeconfig: "/etc/kubeconfig/members", Context: "member1"},
+				{Name: "member2", Kubeconfig: "/etc/kubeconfig/members", Context: "member2"},
+			},
+		},
+		{
+			Desc:   "in-cluster config",
+			Values: []string{"host="},
+			Wanted: MemberClusterList{{Name: "host"}},
+		},
+		{
+			Desc:        "missing kubeconfig",
+			Values:      []string{"member1"},
+			Wanted:      MemberClusterList{},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing name",
+			Values:      []string{"=/etc/kubeconfig/member1"},
+			Wanted:      MemberClusterList{},
+			WantedError: true,
+		},
+		{
+			Desc:        "duplicate name",
+			Values:      []string{"member1=/etc/kubeconfig/member1", "member1=/etc/kubeconfig/member2"},
+			Wanted:      MemberClusterList{{Name: "member1", Kubeconfig: "/etc/kubeconfig/member1"}},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		l := &MemberClusterList{}
+		var gotError error
+		for _, v := range test.Values {
+			if err := l.Set(v); err != nil {
+				gotError = err
+			}
+		}
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*l, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *l, test.WantedError, gotError)
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
 )
 
 type shardedListWatch struct {
@@ -32,6 +33,8 @@ type shardedListWatch struct {
 	lw       cache.ListerWatcher
 }
 
+// NewShardedListWatch returns a cache.ListerWatcher wrapping the given lw,
+// which only lists and watches objects belonging to the given shard.
 func NewShardedListWatch(shard int32, totalShards int, lw cache.ListerWatcher) cache.ListerWatcher {
 	// This is an "optimization" as this configuration means no sharding is to
 	// be performed.
@@ -51,9 +54,16 @@ func (s *shardedListWatch) List(options metav1.ListOptions) (runtime.Object, err
 	if err != nil {
 		return nil, err
 	}
+	metaObj, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
 	res := &metav1.List{
 		Items: []runtime.RawExtension{},
 	}
+	// The reflector starts watching from the resource version of the list,
+	// hence it has to be preserved.
+	res.ListMeta.ResourceVersion = metaObj.GetResourceVersion()
 	for _, item := range items {
 		a, err := meta.Accessor(item)
 		if err != nil {
@@ -74,9 +84,15 @@ func (s *shardedListWatch) Watch(options metav1.ListOptions) (watch.Interface, e
 	}
 
 	return watch.Filter(w, func(in watch.Event) (out watch.Event, keep bool) {
+		// Bookmarks do not belong to any object and only carry the current
+		// resource version, hence every shard needs to observe them.
+		if in.Type == watch.Bookmark {
+			return in, true
+		}
+
 		a, err := meta.Accessor(in.Object)
 		if err != nil {
-			// TODO(brancz): needs logging
+			klog.Warningf("failed to get object meta of watch event, passing it on regardless of its shard: %v", err)
 			return in, true
 		}
 
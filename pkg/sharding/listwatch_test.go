@@ -18,10 +18,15 @@ package sharding
 
 import (
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestSharding(t *testing.T) {
@@ -54,3 +59,64 @@ func TestSharding(t *testing.T) {
 		t.Fatal("Shard two should not pick up the object.")
 	}
 }
+
+func TestShardedListPreservesResourceVersion(t *testing.T) {
+	lw := NewShardedListWatch(0, 2, &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &v1.ConfigMapList{
+				ListMeta: metav1.ListMeta{ResourceVersion: "42"},
+				Items: []v1.ConfigMap{
+					{ObjectMeta: metav1.ObjectMeta{Name: "configmap1", UID: types.UID("test_uid")}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "configmap2"}},
+				},
+			}, nil
+		},
+	})
+
+	list, err := lw.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listMeta.GetResourceVersion() != "42" {
+		t.Fatalf("expected resource version 42, got %q", listMeta.GetResourceVersion())
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("expected 1 object in shard, got %d", len(items))
+	}
+}
+
+func TestShardedWatchKeepsBookmarks(t *testing.T) {
+	fw := watch.NewFake()
+	lw := NewShardedListWatch(0, 2, &cache.ListWatch{
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return fw, nil
+		},
+	})
+
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	go fw.Action(watch.Bookmark, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "10"}})
+
+	select {
+	case event := <-w.ResultChan():
+		if event.Type != watch.Bookmark {
+			t.Fatalf("expected bookmark event to be passed on, got %v", event.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected bookmark event to be passed on")
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutils tests the metrics generated by metric families against
+// their expected output in the Prometheus text exposition format, inline or
+// in golden files. The comparison ignores the order of lines and labels and
+// surrounding whitespace.
+package testutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+)
+
+// UpdateGoldenFilesEnv is the environment variable which, if set to a non
+// empty value, makes CompareGoldenFile write the actual output to the golden
+// files instead of comparing it, e.g. `UPDATE_GOLDEN_FILES=1 go test ./...`.
+const UpdateGoldenFilesEnv = "UPDATE_GOLDEN_FILES"
+
+// GenerateMetrics returns the headers of the given metric families followed
+// by the metrics they generate for the given object. If names are given,
+// only the headers and metrics matching one of them are kept.
+func GenerateMetrics(families []metric.FamilyGenerator, obj interface{}, names ...string) string {
+	return FormatMetrics(metric.ComposeMetricGenFuncs(families)(obj), metric.ExtractMetricFamilyHeaders(families), names)
+}
+
+// FormatMetrics returns the given headers followed by the metrics of the
+// given families. If names are given, only the headers and metrics matching
+// one of them are kept.
+func FormatMetrics(families []metricsstore.FamilyByteSlicer, headers []string, names []string) string {
+	metricFamilyStrings := []string{}
+	for _, f := range families {
+		metricFamilyStrings = append(metricFamilyStrings, string(f.ByteSlice()))
+	}
+	metrics := strings.Split(strings.Join(metricFamilyStrings, ""), "\n")
+
+	return strings.Join(filterMetricNames(headers, names), "\n") + "\n" + strings.Join(filterMetricNames(metrics, names), "\n")
+}
+
+// CompareOutput compares the expected and actual output, ignoring the order
+// of lines and labels. The returned error lists the metrics whose values
+// differ, and the lines missing from or unexpected in the actual output, or
+// the line whose labels cannot be parsed.
+func CompareOutput(expected, actual string) error {
+	want, err := normalize(expected)
+	if err != nil {
+		return errors.Wrap(err, "invalid expected output")
+	}
+	got, err := normalize(actual)
+	if err != nil {
+		return errors.Wrap(err, "invalid actual output")
+	}
+	if want == got {
+		return nil
+	}
+
+	return errors.New(diff(lines(want), lines(got)))
+}
+
+// CompareGoldenFile compares the contents of the golden file at the given path
+// with the actual output like CompareOutput. If the UpdateGoldenFilesEnv
+// environment variable is set, the normalized actual output is written to the
+// file instead.
+func CompareGoldenFile(path, actual string) error {
+	if os.Getenv(UpdateGoldenFilesEnv) != "" {
+		normalized, err := normalize(actual)
+		if err != nil {
+			return errors.Wrap(err, "invalid actual output")
+		}
+		return errors.Wrapf(ioutil.WriteFile(path, []byte(normalized+"\n"), 0644), "failed to update golden file %s", path)
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read golden file %s, set %s to create it", path, UpdateGoldenFilesEnv)
+	}
+
+	return errors.Wrapf(CompareOutput(string(expected), actual), "output differs from golden file %s", path)
+}
+
+// normalize aligns the given output for comparison.
+func normalize(s string) (string, error) {
+	s, err := sortLabels(removeUnusedWhitespace(s))
+	if err != nil {
+		return "", err
+	}
+	return sortByLine(s), nil
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diff describes the differences between the sorted wanted and actual lines.
+// A missing and an unexpected metric of the same series are reported as a
+// changed value.
+func diff(want, got []string) string {
+	missing, unexpected := subtract(want, got), subtract(got, want)
+
+	unexpectedValues := map[string]string{}
+	for _, l := range unexpected {
+		if series, value, ok := splitValue(l); ok {
+			unexpectedValues[series] = value
+		}
+	}
+
+	var changed, removed, added []string
+	changedSeries := map[string]struct{}{}
+	for _, l := range missing {
+		series, value, ok := splitValue(l)
+		if actual, found := unexpectedValues[series]; ok && found {
+			changed = append(changed, fmt.Sprintf("  %s: expected value %s, got %s", series, value, actual))
+			changedSeries[series] = struct{}{}
+			continue
+		}
+		removed = append(removed, "- "+l)
+	}
+	for _, l := range unexpected {
+		if series, _, ok := splitValue(l); ok {
+			if _, found := changedSeries[series]; found {
+				continue
+			}
+		}
+		added = append(added, "+ "+l)
+	}
+
+	var b strings.Builder
+	b.WriteString("metrics differ")
+	for _, section := range []struct {
+		title string
+		lines []string
+	}{
+		{"changed values", changed},
+		{"missing lines", removed},
+		{"unexpected lines", added},
+	} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s:\n%s", section.title, strings.Join(section.lines, "\n"))
+	}
+
+	return b.String()
+}
+
+// subtract returns the lines of a missing from b, counting duplicates.
+func subtract(a, b []string) []string {
+	counts := map[string]int{}
+	for _, l := range b {
+		counts[l]++
+	}
+
+	var rest []string
+	for _, l := range a {
+		if counts[l] > 0 {
+			counts[l]--
+			continue
+		}
+		rest = append(rest, l)
+	}
+
+	return rest
+}
+
+// splitValue splits a metric line into its series and value. Headers are not
+// split.
+func splitValue(line string) (string, string, bool) {
+	i := strings.LastIndex(line, " ")
+	if strings.HasPrefix(line, "#") || i < 0 {
+		return "", "", false
+	}
+	return line[:i], line[i+1:], true
+}
+
+// sortLabels sorts the order of labels in each line of the given metric. The
+// Prometheus exposition format does not force ordering of labels. Hence a test
+// should not fail due to different metric orders.
+func sortLabels(s string) (string, error) {
+	sorted := []string{}
+
+	for _, line := range strings.Split(s, "\n") {
+		// skipping if its headers or has no labels
+		open := strings.Index(line, "{")
+		if strings.HasPrefix(line, "# ") || open < 0 {
+			sorted = append(sorted, line)
+			continue
+		}
+
+		labels, value, err := splitLabels(line[open+1:])
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to sort labels in %q", line)
+		}
+		sort.Strings(labels)
+
+		sorted = append(sorted, fmt.Sprintf("%v{%v}%v", line[:open], strings.Join(labels, ","), value))
+	}
+
+	return strings.Join(sorted, "\n"), nil
+}
+
+// splitLabels splits the labels of a metric following its opening brace at
+// the commas between them, and returns them along with the rest of the line
+// following the closing brace. Commas, braces and escaped quotes in quoted
+// label values are part of the label.
+func splitLabels(s string) ([]string, string, error) {
+	labels := []string{}
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == ',':
+			labels = append(labels, s[start:i])
+			start = i + 1
+		case !quoted && c == '}':
+			// A trailing comma is allowed.
+			if i > start {
+				labels = append(labels, s[start:i])
+			}
+			return labels, s[i+1:], nil
+		}
+	}
+	return nil, "", errors.New("unterminated labels")
+}
+
+func sortByLine(s string) string {
+	split := strings.Split(s, "\n")
+	sort.Strings(split)
+	return strings.Join(split, "\n")
+}
+
+// filterMetricNames removes those metrics and headers that
+// are not part of the names.
+func filterMetricNames(ms []string, names []string) []string {
+	// In case the test case is based on all returned metric, MetricNames does
+	// not need to me defined.
+	if len(names) == 0 {
+		return ms
+	}
+	filtered := []string{}
+
+	regexps := []*regexp.Regexp{}
+	for _, n := range names {
+		regexps = append(regexps, regexp.MustCompile(fmt.Sprintf(".*%v.*$", n)))
+	}
+
+	for _, m := range ms {
+		drop := true
+		for _, r := range regexps {
+			if r.MatchString(m) {
+				drop = false
+				break
+			}
+		}
+		if !drop {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+func removeUnusedWhitespace(s string) string {
+	var (
+		trimmedLine  string
+		trimmedLines []string
+		lines        = strings.Split(s, "\n")
+	)
+
+	for _, l := range lines {
+		trimmedLine = strings.TrimSpace(l)
+
+		if len(trimmedLine) > 0 {
+			trimmedLines = append(trimmedLines, trimmedLine)
+		}
+	}
+
+	return strings.Join(trimmedLines, "\n")
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestSortLabels(t *testing.T) {
+	in := `kube_pod_container_info{container_id="docker://cd456",image="k8s.gcr.io/hyperkube2",container="container2",image_id="docker://sha256:bbb",namespace="ns2",pod="pod2"} 1
+kube_pod_container_info{namespace="ns2",container="container3",container_id="docker://ef789",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",pod="pod2"} 1`
+
+	want := `kube_pod_container_info{container="container2",container_id="docker://cd456",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",namespace="ns2",pod="pod2"} 1
+kube_pod_container_info{container="container3",container_id="docker://ef789",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",namespace="ns2",pod="pod2"} 1`
+
+	out, err := sortLabels(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want != out {
+		t.Fatalf("expected:\n%v\nbut got:\n%v", want, out)
+	}
+}
+
+func TestSortLabelsQuoted(t *testing.T) {
+	in := `kube_pod_labels{pod="p",label_a="x,y}",label_b="{\"z\", 1}"} 1`
+
+	want := `kube_pod_labels{label_a="x,y}",label_b="{\"z\", 1}",pod="p"} 1`
+
+	out, err := sortLabels(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want != out {
+		t.Fatalf("expected:\n%v\nbut got:\n%v", want, out)
+	}
+
+	for _, in := range []string{`kube_pod_labels{pod="p} 1`, `kube_pod_labels{pod="p" 1`} {
+		if _, err := sortLabels(in); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+}
+
+func TestRemoveUnusedWhitespace(t *testing.T) {
+	in := "       kube_cron_job_info \n        kube_pod_container_info \n        kube_config_map_info     "
+
+	want := "kube_cron_job_info\nkube_pod_container_info\nkube_config_map_info"
+
+	out := removeUnusedWhitespace(in)
+
+	if want != out {
+		t.Fatalf("expected: %q\nbut got: %q", want, out)
+	}
+}
+
+func TestSortByLine(t *testing.T) {
+	in := "kube_cron_job_info \nkube_pod_container_info \nkube_config_map_info"
+
+	want := "kube_config_map_info\nkube_cron_job_info \nkube_pod_container_info "
+
+	out := sortByLine(in)
+
+	if want != out {
+		t.Fatalf("expected: %q\nbut got: %q", want, out)
+	}
+}
+
+var testFamilies = []metric.FamilyGenerator{
+	{
+		Name: "kube_test_info",
+		Type: metric.Gauge,
+		Help: "Information about test.",
+		GenerateFunc: func(obj interface{}) *metric.Family {
+			return &metric.Family{
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"name", "kind"},
+						LabelValues: []string{obj.(string), "test"},
+						Value:       1,
+					},
+				},
+			}
+		},
+	},
+	{
+		Name: "kube_test_replicas",
+		Type: metric.Gauge,
+		Help: "Number of replicas of test.",
+		GenerateFunc: func(obj interface{}) *metric.Family {
+			return &metric.Family{
+				Metrics: []*metric.Metric{
+					{
+						LabelKeys:   []string{"name"},
+						LabelValues: []string{obj.(string)},
+						Value:       3,
+					},
+				},
+			}
+		},
+	},
+}
+
+func TestGenerateMetrics(t *testing.T) {
+	want := `
+		# HELP kube_test_replicas Number of replicas of test.
+		# TYPE kube_test_replicas gauge
+		kube_test_replicas{name="foo"} 3
+	`
+
+	if err := CompareOutput(want, GenerateMetrics(testFamilies, "foo", "kube_test_replicas")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompareOutput(t *testing.T) {
+	actual := GenerateMetrics(testFamilies, "foo")
+
+	tests := []struct {
+		name     string
+		expected string
+		errLines []string
+	}{
+		{
+			name: "equal in other order",
+			expected: `
+				kube_test_replicas{name="foo"} 3
+				# TYPE kube_test_replicas gauge
+				# HELP kube_test_replicas Number of replicas of test.
+				kube_test_info{kind="test",name="foo"} 1
+				# HELP kube_test_info Information about test.
+				# TYPE kube_test_info gauge
+			`,
+		},
+		{
+			name: "changed value",
+			expected: `
+				# HELP kube_test_info Information about test.
+				# TYPE kube_test_info gauge
+				# HELP kube_test_replicas Number of replicas of test.
+				# TYPE kube_test_replicas gauge
+				kube_test_info{name="foo",kind="test"} 1
+				kube_test_replicas{name="foo"} 2
+			`,
+			errLines: []string{
+				"changed values:",
+				`  kube_test_replicas{name="foo"}: expected value 2, got 3`,
+			},
+		},
+		{
+			name: "missing and unexpected lines",
+			expected: `
+				# HELP kube_test_info Information about test.
+				# TYPE kube_test_info gauge
+				# HELP kube_test_replicas Number of replicas of test.
+				# TYPE kube_test_replicas gauge
+				kube_test_info{name="bar",kind="test"} 1
+				kube_test_replicas{name="foo"} 3
+			`,
+			errLines: []string{
+				"missing lines:",
+				`- kube_test_info{kind="test",name="bar"} 1`,
+				"unexpected lines:",
+				`+ kube_test_info{kind="test",name="foo"} 1`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := CompareOutput(test.expected, actual)
+		if len(test.errLines) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+
+		for _, l := range test.errLines {
+			if !strings.Contains(err.Error(), l+"\n") && !strings.HasSuffix(err.Error(), l) {
+				t.Errorf("%s: expected error to contain line %q, got:\n%v", test.name, l, err)
+			}
+		}
+	}
+}
+
+func TestCompareGoldenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testutils")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.golden")
+	actual := GenerateMetrics(testFamilies, "foo")
+
+	if err := CompareGoldenFile(path, actual); err == nil {
+		t.Fatal("expected an error comparing with a missing golden file")
+	}
+
+	os.Setenv(UpdateGoldenFilesEnv, "1")
+	err = CompareGoldenFile(path, actual)
+	os.Unsetenv(UpdateGoldenFilesEnv)
+	if err != nil {
+		t.Fatalf("failed to update golden file: %v", err)
+	}
+
+	if err := CompareGoldenFile(path, actual); err != nil {
+		t.Fatalf("unexpected error comparing with updated golden file: %v", err)
+	}
+
+	if err := CompareGoldenFile(path, GenerateMetrics(testFamilies, "bar")); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected an error naming the golden file, got: %v", err)
+	}
+}
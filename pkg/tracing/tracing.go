@@ -0,0 +1,288 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing records spans of the work of kube-state-metrics, e.g. of
+// scrapes and relists, and exports them to an OpenTelemetry collector using
+// OTLP/HTTP with JSON encoding. A nil *Tracer is valid and records nothing,
+// so callers do not need to check whether tracing is enabled.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+
+	"k8s.io/kube-state-metrics/pkg/version"
+)
+
+const (
+	// exportInterval is the interval between exports of the recorded spans.
+	exportInterval = 5 * time.Second
+	// maxQueuedSpans is the number of spans queued for the next export,
+	// beyond which further spans are dropped.
+	maxQueuedSpans = 2048
+)
+
+// Tracer records spans and exports them to an OTLP/HTTP endpoint.
+type Tracer struct {
+	client   *http.Client
+	endpoint string
+	instance string
+
+	mtx     sync.Mutex
+	queued  []otlpSpan
+	dropped int
+}
+
+// New returns a new Tracer exporting spans to the /v1/traces path of the
+// OTLP/HTTP endpoint, e.g. an OpenTelemetry collector, with instance as
+// service.instance.id of its resource.
+func New(client *http.Client, endpoint, instance string) *Tracer {
+	return &Tracer{
+		client:   client,
+		endpoint: endpoint,
+		instance: instance,
+	}
+}
+
+// Attribute is an attribute of a span.
+type Attribute struct {
+	key   string
+	value otlpAnyValue
+}
+
+// String returns a string attribute.
+func String(key, value string) Attribute {
+	return Attribute{key: key, value: otlpAnyValue{StringValue: &value}}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attribute {
+	// Int64 values are encoded as strings in JSON.
+	v := strconv.Itoa(value)
+	return Attribute{key: key, value: otlpAnyValue{IntValue: &v}}
+}
+
+// spanContext identifies a span and its trace.
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+type spanContextKey struct{}
+
+// Span is an operation recorded by a Tracer. A nil *Span is valid and
+// records nothing.
+type Span struct {
+	tracer     *Tracer
+	ctx        spanContext
+	parentID   [8]byte
+	name       string
+	start      time.Time
+	attributes []Attribute
+	err        error
+	ended      sync.Once
+}
+
+// Start starts a span with the given name and attributes, which is a child of
+// the span in ctx, if any. It returns a copy of ctx holding the new span. If
+// t is nil, ctx is returned unchanged along with a nil span.
+func (t *Tracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &Span{tracer: t, name: name, start: time.Now(), attributes: attributes}
+	if parent, ok := ctx.Value(spanContextKey{}).(spanContext); ok {
+		s.ctx.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		rand.Read(s.ctx.traceID[:])
+	}
+	rand.Read(s.ctx.spanID[:])
+
+	return context.WithValue(ctx, spanContextKey{}, s.ctx), s
+}
+
+// Extract returns a copy of ctx holding the remote span propagated in the
+// W3C traceparent header of h, if any, so that spans started with the
+// returned context continue the trace of the caller.
+func Extract(ctx context.Context, h http.Header) context.Context {
+	// version-traceid-parentid-flags, e.g.
+	// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	parts := strings.Split(strings.TrimSpace(h.Get("traceparent")), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ctx
+	}
+
+	var sc spanContext
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil || len(traceID) != len(sc.traceID) {
+		return ctx
+	}
+	spanID, err := hex.DecodeString(parts[2])
+	if err != nil || len(spanID) != len(sc.spanID) {
+		return ctx
+	}
+	copy(sc.traceID[:], traceID)
+	copy(sc.spanID[:], spanID)
+	if sc.traceID == [16]byte{} || sc.spanID == [8]byte{} {
+		return ctx
+	}
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SetAttributes adds the given attributes to the span.
+func (s *Span) SetAttributes(attributes ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.attributes = append(s.attributes, attributes...)
+}
+
+// RecordError marks the span as failed with err, unless err is nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.err = err
+}
+
+// End ends the span and queues it for export. Only the first call has an
+// effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.ended.Do(func() {
+		s.tracer.queue(s.otlp(time.Now()))
+	})
+}
+
+func (s *Span) otlp(end time.Time) otlpSpan {
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.ctx.traceID[:]),
+		SpanID:            hex.EncodeToString(s.ctx.spanID[:]),
+		Name:              s.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(end.UnixNano(), 10),
+		Attributes:        otlpAttributes(s.attributes),
+	}
+	if s.parentID != [8]byte{} {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != nil {
+		span.Status = &otlpStatus{Code: otlpStatusCodeError, Message: s.err.Error()}
+	}
+	return span
+}
+
+func (t *Tracer) queue(span otlpSpan) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if len(t.queued) >= maxQueuedSpans {
+		t.dropped++
+		return
+	}
+	t.queued = append(t.queued, span)
+}
+
+// Run exports the recorded spans every few seconds until ctx is done, when
+// the remaining spans are exported one last time.
+func (t *Tracer) Run(ctx context.Context) {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := t.Flush(context.Background()); err != nil {
+				klog.Errorf("failed to export traces to %s: %v", t.endpoint, err)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		if err := t.Flush(ctx); err != nil {
+			klog.Errorf("failed to export traces to %s: %v", t.endpoint, err)
+		}
+	}
+}
+
+// Flush exports the spans recorded so far, which are dropped even if the
+// export fails.
+func (t *Tracer) Flush(ctx context.Context) error {
+	t.mtx.Lock()
+	spans, dropped := t.queued, t.dropped
+	t.queued, t.dropped = nil, 0
+	t.mtx.Unlock()
+
+	if dropped > 0 {
+		klog.Warningf("dropped %d spans exceeding the export queue of %d spans", dropped, maxQueuedSpans)
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: otlpAttributes([]Attribute{
+				String("service.name", "kube-state-metrics"),
+				String("service.instance.id", t.instance),
+			})},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "kube-state-metrics", Version: version.Release},
+				Spans: spans,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(t.endpoint, "/")+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
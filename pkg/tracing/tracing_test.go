@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	var path, contentType string
+	var request otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+	}))
+	defer collector.Close()
+
+	tracer := New(collector.Client(), collector.URL+"/", "ksm-0")
+	ctx, parent := tracer.Start(context.Background(), "scrape", String("format", "text"))
+	_, child := tracer.Start(ctx, "write", String("resource", "pods"))
+	child.SetAttributes(Int("bytes", 42))
+	child.RecordError(errors.New("failed"))
+	child.End()
+	child.End()
+	parent.End()
+
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/v1/traces" {
+		t.Errorf("expected path /v1/traces but got %s", path)
+	}
+	if contentType != "application/json" {
+		t.Errorf("expected content type application/json but got %s", contentType)
+	}
+
+	if len(request.ResourceSpans) != 1 || len(request.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("expected a single resource and scope but got %+v", request)
+	}
+	attributes := map[string]string{}
+	for _, a := range request.ResourceSpans[0].Resource.Attributes {
+		attributes[a.Key] = *a.Value.StringValue
+	}
+	if attributes["service.name"] != "kube-state-metrics" || attributes["service.instance.id"] != "ksm-0" {
+		t.Errorf("unexpected resource attributes %v", attributes)
+	}
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans but got %+v", spans)
+	}
+	c, p := spans[0], spans[1]
+	if c.Name != "write" || p.Name != "scrape" {
+		t.Fatalf("expected the spans in the order they ended, got %s and %s", c.Name, p.Name)
+	}
+	if c.TraceID != p.TraceID || c.ParentSpanID != p.SpanID || p.ParentSpanID != "" {
+		t.Errorf("expected write to be a child of scrape, got %+v and %+v", c, p)
+	}
+	if len(c.TraceID) != 32 || len(c.SpanID) != 16 {
+		t.Errorf("expected hex encoded IDs, got %+v", c)
+	}
+	if len(c.Attributes) != 2 || *c.Attributes[0].Value.StringValue != "pods" || *c.Attributes[1].Value.IntValue != "42" {
+		t.Errorf("unexpected attributes %+v", c.Attributes)
+	}
+	if c.Status == nil || c.Status.Code != otlpStatusCodeError || c.Status.Message != "failed" || p.Status != nil {
+		t.Errorf("expected only write to have failed, got %+v and %+v", c.Status, p.Status)
+	}
+
+	// Exported spans are dequeued.
+	path = ""
+	if err := tracer.Flush(context.Background()); err != nil || path != "" {
+		t.Errorf("expected nothing to be exported, got path %q and error %v", path, err)
+	}
+}
+
+func TestExportError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	tracer := New(collector.Client(), collector.URL, "ksm-0")
+	_, span := tracer.Start(context.Background(), "scrape")
+	span.End()
+
+	if err := tracer.Flush(context.Background()); err == nil {
+		t.Error("expected error for unsuccessful status code")
+	}
+}
+
+func TestQueueLimit(t *testing.T) {
+	tracer := New(nil, "", "ksm-0")
+	for i := 0; i < maxQueuedSpans+2; i++ {
+		_, span := tracer.Start(context.Background(), "scrape")
+		span.End()
+	}
+	if len(tracer.queued) != maxQueuedSpans || tracer.dropped != 2 {
+		t.Errorf("expected %d queued and 2 dropped spans, got %d and %d", maxQueuedSpans, len(tracer.queued), tracer.dropped)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	ctx := context.Background()
+	got, span := tracer.Start(ctx, "scrape")
+	if got != ctx || span != nil {
+		t.Fatalf("expected a nil tracer to return ctx and a nil span, got %v and %v", got, span)
+	}
+	span.SetAttributes(String("format", "text"))
+	span.RecordError(errors.New("failed"))
+	span.End()
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		traceparent string
+		wantTraceID string
+		wantParent  string
+	}{
+		{
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantParent:  "00f067aa0ba902b7",
+		},
+		{traceparent: ""},
+		{traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-01"},
+		{traceparent: "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01"},
+		{traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{traceparent: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01"},
+	}
+
+	for _, test := range tests {
+		h := http.Header{}
+		h.Set("traceparent", test.traceparent)
+
+		tracer := New(nil, "", "ksm-0")
+		_, span := tracer.Start(Extract(context.Background(), h), "scrape")
+		span.End()
+
+		s := tracer.queued[0]
+		if test.wantTraceID == "" {
+			if s.ParentSpanID != "" {
+				t.Errorf("%q: expected a new trace, got parent %s", test.traceparent, s.ParentSpanID)
+			}
+			continue
+		}
+		if s.TraceID != test.wantTraceID || s.ParentSpanID != test.wantParent {
+			t.Errorf("%q: expected trace %s and parent %s, got %s and %s", test.traceparent, test.wantTraceID, test.wantParent, s.TraceID, s.ParentSpanID)
+		}
+	}
+}
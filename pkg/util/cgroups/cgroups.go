@@ -0,0 +1,197 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cgroups detects the CPU and memory limits imposed on the process by
+// cgroups, e.g. container resource limits, and tunes the Go runtime to them.
+package cgroups
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// Root is the mount point of the cgroup filesystem as seen from within a
+// container.
+const Root = "/sys/fs/cgroup"
+
+// cgroup v1 reports values close to the maximum int64 as memory limit if
+// unlimited.
+const unlimitedMemoryV1 = int64(1) << 62
+
+// CPULimit returns the CPU limit in cores of the cgroup mounted at root. The
+// returned bool is false if no limit is set. Both cgroup v1 and v2 are
+// supported.
+func CPULimit(root string) (float64, bool, error) {
+	// cgroup v2: "$MAX $PERIOD", where $MAX may be "max".
+	if content, err := readFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(content)
+		if len(fields) != 2 {
+			return 0, false, errors.Errorf("unexpected format of cpu.max: %q", content)
+		}
+		if fields[0] == "max" {
+			return 0, false, nil
+		}
+		return cpuQuota(fields[0], fields[1])
+	} else if !os.IsNotExist(err) {
+		return 0, false, err
+	}
+
+	// cgroup v1: quota is -1 if unlimited.
+	for _, dir := range []string{"cpu", "cpu,cpuacct"} {
+		quota, err := readFile(filepath.Join(root, dir, "cpu.cfs_quota_us"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		if quota == "-1" {
+			return 0, false, nil
+		}
+		period, err := readFile(filepath.Join(root, dir, "cpu.cfs_period_us"))
+		if err != nil {
+			return 0, false, err
+		}
+		return cpuQuota(quota, period)
+	}
+
+	return 0, false, nil
+}
+
+// MemoryLimit returns the memory limit in bytes of the cgroup mounted at
+// root. The returned bool is false if no limit is set. Both cgroup v1 and v2
+// are supported.
+func MemoryLimit(root string) (int64, bool, error) {
+	// cgroup v2: limit in bytes or "max".
+	if content, err := readFile(filepath.Join(root, "memory.max")); err == nil {
+		if content == "max" {
+			return 0, false, nil
+		}
+		limit, err := strconv.ParseInt(content, 10, 64)
+		if err != nil {
+			return 0, false, errors.Wrap(err, "parse memory.max")
+		}
+		return limit, true, nil
+	} else if !os.IsNotExist(err) {
+		return 0, false, err
+	}
+
+	// cgroup v1
+	content, err := readFile(filepath.Join(root, "memory", "memory.limit_in_bytes"))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	limit, err := strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "parse memory.limit_in_bytes")
+	}
+	if limit >= unlimitedMemoryV1 {
+		return 0, false, nil
+	}
+	return limit, true, nil
+}
+
+// SetGOMAXPROCS sets GOMAXPROCS to procs. If procs is 0, it is derived from
+// the CPU limit of the cgroup mounted at root, rounded up to full cores,
+// unless the GOMAXPROCS environment variable is set.
+func SetGOMAXPROCS(root string, procs int) {
+	if procs <= 0 {
+		if os.Getenv("GOMAXPROCS") != "" {
+			return
+		}
+
+		cpus, ok, err := CPULimit(root)
+		if err != nil {
+			klog.Warningf("Failed to detect cgroup CPU limit: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+
+		procs = int(math.Ceil(cpus))
+		if procs < 1 {
+			procs = 1
+		}
+		if procs >= runtime.NumCPU() {
+			return
+		}
+	}
+
+	runtime.GOMAXPROCS(procs)
+	klog.Infof("Set GOMAXPROCS to %d", procs)
+}
+
+// SetMemoryLimit sets the soft memory limit of the Go runtime to limit bytes.
+// If limit is 0, it is derived as ratio of the memory limit of the cgroup
+// mounted at root, unless the GOMEMLIMIT environment variable is set or ratio
+// is 0.
+func SetMemoryLimit(root string, limit int64, ratio float64) {
+	if limit <= 0 {
+		if os.Getenv("GOMEMLIMIT") != "" || ratio <= 0 {
+			return
+		}
+
+		memory, ok, err := MemoryLimit(root)
+		if err != nil {
+			klog.Warningf("Failed to detect cgroup memory limit: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+
+		limit = int64(float64(memory) * ratio)
+	}
+
+	if setMemoryLimit(limit) {
+		klog.Infof("Set GOMEMLIMIT to %d bytes", limit)
+	}
+}
+
+func cpuQuota(quota, period string) (float64, bool, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "parse CPU quota")
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "parse CPU period")
+	}
+	if q <= 0 || p <= 0 {
+		return 0, false, nil
+	}
+	return q / p, true, nil
+}
+
+func readFile(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
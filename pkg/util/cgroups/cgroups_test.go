@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root, err := ioutil.TempDir("", "cgroups")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestCPULimit(t *testing.T) {
+	tests := []struct {
+		desc      string
+		files     map[string]string
+		wantCPUs  float64
+		wantLimit bool
+		wantErr   bool
+	}{
+		{desc: "no cgroup", files: map[string]string{}},
+		{desc: "v2 unlimited", files: map[string]string{"cpu.max": "max 100000"}},
+		{desc: "v2 limited", files: map[string]string{"cpu.max": "250000 100000"}, wantCPUs: 2.5, wantLimit: true},
+		{desc: "v2 malformed", files: map[string]string{"cpu.max": "250000"}, wantErr: true},
+		{desc: "v1 unlimited", files: map[string]string{"cpu/cpu.cfs_quota_us": "-1", "cpu/cpu.cfs_period_us": "100000"}},
+		{desc: "v1 limited", files: map[string]string{"cpu/cpu.cfs_quota_us": "50000", "cpu/cpu.cfs_period_us": "100000"}, wantCPUs: 0.5, wantLimit: true},
+		{desc: "v1 cpu,cpuacct", files: map[string]string{"cpu,cpuacct/cpu.cfs_quota_us": "400000", "cpu,cpuacct/cpu.cfs_period_us": "100000"}, wantCPUs: 4, wantLimit: true},
+	}
+
+	for _, test := range tests {
+		root := writeFiles(t, test.files)
+		defer os.RemoveAll(root)
+
+		cpus, limited, err := CPULimit(root)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("%s: expected error to be %v but got %v", test.desc, test.wantErr, err)
+		}
+		if cpus != test.wantCPUs || limited != test.wantLimit {
+			t.Errorf("%s: expected %v CPUs (limited: %v) but got %v (limited: %v)", test.desc, test.wantCPUs, test.wantLimit, cpus, limited)
+		}
+	}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		desc      string
+		files     map[string]string
+		wantBytes int64
+		wantLimit bool
+		wantErr   bool
+	}{
+		{desc: "no cgroup", files: map[string]string{}},
+		{desc: "v2 unlimited", files: map[string]string{"memory.max": "max"}},
+		{desc: "v2 limited", files: map[string]string{"memory.max": "536870912"}, wantBytes: 536870912, wantLimit: true},
+		{desc: "v2 malformed", files: map[string]string{"memory.max": "512Mi"}, wantErr: true},
+		{desc: "v1 unlimited", files: map[string]string{"memory/memory.limit_in_bytes": "9223372036854771712"}},
+		{desc: "v1 limited", files: map[string]string{"memory/memory.limit_in_bytes": "268435456"}, wantBytes: 268435456, wantLimit: true},
+	}
+
+	for _, test := range tests {
+		root := writeFiles(t, test.files)
+		defer os.RemoveAll(root)
+
+		bytes, limited, err := MemoryLimit(root)
+		if (err != nil) != test.wantErr {
+			t.Fatalf("%s: expected error to be %v but got %v", test.desc, test.wantErr, err)
+		}
+		if bytes != test.wantBytes || limited != test.wantLimit {
+			t.Errorf("%s: expected %v bytes (limited: %v) but got %v (limited: %v)", test.desc, test.wantBytes, test.wantLimit, bytes, limited)
+		}
+	}
+}
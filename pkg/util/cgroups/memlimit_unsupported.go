@@ -0,0 +1,31 @@
+//go:build !go1.19
+// +build !go1.19
+
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cgroups
+
+import (
+	"k8s.io/klog"
+)
+
+// setMemoryLimit has no effect before Go 1.19, which introduced the soft
+// memory limit.
+func setMemoryLimit(limit int64) bool {
+	klog.Warningf("Not setting GOMEMLIMIT to %d bytes, as it requires a build with Go 1.19 or later", limit)
+	return false
+}
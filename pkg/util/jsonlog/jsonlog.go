@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonlog formats the log output of klog as JSON lines.
+package jsonlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// headerLayout is the layout of the timestamp in klog headers, which are
+// formatted as "Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg".
+const headerLayout = "0102 15:04:05.000000"
+
+var levels = map[byte]string{
+	'I': "info",
+	'W': "warning",
+	'E': "error",
+	'F': "fatal",
+}
+
+// entry is a log line in JSON format.
+type entry struct {
+	Time   string `json:"ts,omitempty"`
+	Level  string `json:"level,omitempty"`
+	Caller string `json:"caller,omitempty"`
+	Msg    string `json:"msg"`
+}
+
+// Writer converts the log lines written by klog to JSON lines, written to the
+// wrapped io.Writer.
+type Writer struct {
+	mtx sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewWriter returns a new Writer writing JSON lines to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, now: time.Now}
+}
+
+// Write converts the given klog line to JSON. Lines without klog header, e.g.
+// with -skip_headers, are written with their message only.
+func (w *Writer) Write(p []byte) (int, error) {
+	line, err := json.Marshal(w.parse(p))
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if _, err := w.w.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *Writer) parse(p []byte) entry {
+	msg := string(bytes.TrimSuffix(p, []byte("\n")))
+
+	if len(msg) < 1+len(headerLayout) {
+		return entry{Msg: msg}
+	}
+	level, ok := levels[msg[0]]
+	if !ok {
+		return entry{Msg: msg}
+	}
+	now := w.now()
+	ts, err := time.ParseInLocation(headerLayout, msg[1:1+len(headerLayout)], now.Location())
+	if err != nil {
+		return entry{Msg: msg}
+	}
+	// The header lacks the year. Entries of the past year can only be
+	// written around New Year's Eve.
+	year := now.Year()
+	if ts.Month() > now.Month() {
+		year--
+	}
+	ts = ts.AddDate(year, 0, 0)
+
+	// Skip the thread id.
+	rest := bytes.TrimLeft([]byte(msg[1+len(headerLayout):]), " 0123456789")
+	end := bytes.Index(rest, []byte("] "))
+	if end < 0 {
+		return entry{Msg: msg}
+	}
+
+	return entry{
+		Time:   ts.Format(time.RFC3339Nano),
+		Level:  level,
+		Caller: string(rest[:end]),
+		Msg:    string(rest[end+2:]),
+	}
+}
+
+// Enable redirects all output of klog, and of the standard library logger, as
+// JSON lines to w. It must be called after flags have been parsed, as it
+// overrides the klog flags determining where logs are written to.
+func Enable(w io.Writer) {
+	flags := flag.NewFlagSet("jsonlog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	// Write all entries to the info log only. klog writes entries to the
+	// logs of all severities up to their own, which would duplicate them.
+	// Fatal entries are still written to stderr in klog's format too, along
+	// with the stack traces.
+	flags.Set("logtostderr", "false")
+	flags.Set("alsologtostderr", "false")
+	flags.Set("stderrthreshold", "FATAL")
+	flags.Set("log_file", "")
+
+	klog.SetOutputBySeverity("INFO", NewWriter(w))
+	for _, severity := range []string{"WARNING", "ERROR", "FATAL"} {
+		klog.SetOutputBySeverity(severity, ioutil.Discard)
+	}
+	klog.CopyStandardLogTo("ERROR")
+}
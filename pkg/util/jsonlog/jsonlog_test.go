@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonlog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	now := time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc string
+		line string
+		want string
+	}{
+		{
+			desc: "info",
+			line: "I0301 11:59:58.123456   16818 main.go:80] Starting metrics server: [::]:8080\n",
+			want: `{"ts":"2020-03-01T11:59:58.123456Z","level":"info","caller":"main.go:80","msg":"Starting metrics server: [::]:8080"}` + "\n",
+		},
+		{
+			desc: "error with quotes",
+			line: "E0301 11:59:58.000001 1234567 builder.go:12] failed to list \"pods\"\n",
+			want: `{"ts":"2020-03-01T11:59:58.000001Z","level":"error","caller":"builder.go:12","msg":"failed to list \"pods\""}` + "\n",
+		},
+		{
+			desc: "previous year",
+			line: "W1231 23:59:59.000000   1 main.go:1] happy new year\n",
+			want: `{"ts":"2019-12-31T23:59:59Z","level":"warning","caller":"main.go:1","msg":"happy new year"}` + "\n",
+		},
+		{
+			desc: "without header",
+			line: "some message\n",
+			want: `{"msg":"some message"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		w := NewWriter(out)
+		w.now = func() time.Time { return now }
+
+		n, err := w.Write([]byte(test.line))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.desc, err)
+		}
+		if n != len(test.line) {
+			t.Errorf("%s: expected %d bytes written but got %d", test.desc, len(test.line), n)
+		}
+		if out.String() != test.want {
+			t.Errorf("%s: expected %s but got %s", test.desc, test.want, out.String())
+		}
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tlsconfig builds TLS configurations for the servers of
+// kube-state-metrics, reloading rotated certificates without restarts.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+)
+
+// reloadInterval is the minimum interval between checks of the certificate
+// files for changes.
+const reloadInterval = 10 * time.Second
+
+// NewServerConfig returns a TLS configuration serving the certificate and key
+// of the given files. The files are checked for changes at most every
+// reloadInterval during handshakes, so rotated certificates are served without
+// restarting the server. If clientCAFile is set, clients are required to
+// present a certificate signed by one of the CAs in that file.
+func NewServerConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	r := &keyPairReloader{certFile: certFile, keyFile: keyFile, interval: reloadInterval}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.getCertificate,
+	}
+
+	if clientCAFile != "" {
+		pem, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "read client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no valid certificates found in client CA file %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// keyPairReloader loads a certificate and its key from files and reloads them
+// once the files are modified, checking them at most every interval.
+type keyPairReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mtx         sync.Mutex
+	cert        *tls.Certificate
+	modTimes    [2]time.Time
+	lastChecked time.Time
+}
+
+func (r *keyPairReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if time.Since(r.lastChecked) >= r.interval {
+		r.lastChecked = time.Now()
+		if err := r.reloadIfModified(); err != nil {
+			// Keep serving the previous certificate, the files may be in
+			// the midst of being rotated.
+			klog.Errorf("failed to reload TLS certificate: %v", err)
+		}
+	}
+
+	return r.cert, nil
+}
+
+// reload loads the certificate and key. It must be called with r.mtx held,
+// unless r is not shared yet.
+func (r *keyPairReloader) reload() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "load TLS certificate")
+	}
+
+	r.cert = &cert
+	r.modTimes = modTimes
+	r.lastChecked = time.Now()
+	return nil
+}
+
+func (r *keyPairReloader) reloadIfModified() error {
+	modTimes, err := r.fileModTimes()
+	if err != nil {
+		return err
+	}
+	if modTimes == r.modTimes {
+		return nil
+	}
+
+	if err := r.reload(); err != nil {
+		return err
+	}
+	klog.Infof("Reloaded TLS certificate %s", r.certFile)
+	return nil
+}
+
+func (r *keyPairReloader) fileModTimes() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, errors.Wrap(err, "stat TLS certificate")
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKeyPair writes a self-signed certificate with the given common name and
+// its key to certFile and keyFile.
+func writeKeyPair(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{"localhost"},
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func servedCommonName(t *testing.T, r *keyPairReloader) string {
+	t.Helper()
+
+	cert, err := r.getCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestKeyPairReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeKeyPair(t, certFile, keyFile, "first")
+
+	if _, err := NewServerConfig(certFile, filepath.Join(dir, "missing.key"), ""); err == nil {
+		t.Fatal("expected error for missing key file")
+	}
+
+	r := &keyPairReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cn := servedCommonName(t, r); cn != "first" {
+		t.Fatalf("expected certificate first to be served, got %s", cn)
+	}
+
+	// Rotate the certificate, making sure the modification time changes.
+	writeKeyPair(t, certFile, keyFile, "second")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+
+	if cn := servedCommonName(t, r); cn != "second" {
+		t.Fatalf("expected rotated certificate second to be served, got %s", cn)
+	}
+
+	// Broken files keep the previous certificate served.
+	if err := ioutil.WriteFile(keyFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	future = future.Add(time.Minute)
+	os.Chtimes(keyFile, future, future)
+
+	if cn := servedCommonName(t, r); cn != "second" {
+		t.Fatalf("expected previous certificate second to be served, got %s", cn)
+	}
+}
+
+func TestClientCertificates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	serverCert := filepath.Join(dir, "server.crt")
+	serverKey := filepath.Join(dir, "server.key")
+	writeKeyPair(t, serverCert, serverKey, "server")
+	clientCert := filepath.Join(dir, "client.crt")
+	clientKey := filepath.Join(dir, "client.key")
+	writeKeyPair(t, clientCert, clientKey, "client")
+	otherCert := filepath.Join(dir, "other.crt")
+	otherKey := filepath.Join(dir, "other.key")
+	writeKeyPair(t, otherCert, otherKey, "other")
+
+	if _, err := NewServerConfig(serverCert, serverKey, serverKey); err == nil {
+		t.Fatal("expected error for client CA file without certificates")
+	}
+
+	// The self-signed client certificate acts as its own CA.
+	config, err := NewServerConfig(serverCert, serverKey, clientCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.TLS = config
+	server.StartTLS()
+	defer server.Close()
+
+	serverCA, err := ioutil.ReadFile(serverCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AppendCertsFromPEM(serverCA)
+
+	tests := []struct {
+		desc     string
+		certFile string
+		keyFile  string
+		wantErr  bool
+	}{
+		{desc: "no client certificate", wantErr: true},
+		{desc: "untrusted client certificate", certFile: otherCert, keyFile: otherKey, wantErr: true},
+		{desc: "trusted client certificate", certFile: clientCert, keyFile: clientKey, wantErr: false},
+	}
+
+	for _, test := range tests {
+		clientConfig := &tls.Config{RootCAs: rootCAs, ServerName: "localhost"}
+		if test.certFile != "" {
+			cert, err := tls.LoadX509KeyPair(test.certFile, test.keyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clientConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: expected error to be %v but got %v", test.desc, test.wantErr, err)
+		}
+	}
+}
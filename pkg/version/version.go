@@ -18,6 +18,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -62,3 +64,30 @@ func GetVersion() Version {
 		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
 }
+
+// Print returns a human-readable description of the version, as printed by
+// --version.
+func (v Version) Print() string {
+	return fmt.Sprintf(`kube-state-metrics, version %s (revision: %s)
+  build date:  %s
+  go version:  %s
+  compiler:    %s
+  platform:    %s
+`, v.Release, v.GitCommit, v.BuildDate, v.GoVersion, v.Compiler, v.Platform)
+}
+
+// NewCollector returns a collector exposing the
+// kube_state_metrics_build_info metric, which always has the value 1 and
+// carries the version as labels.
+func NewCollector() prometheus.Collector {
+	v := GetVersion()
+	buildInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_build_info",
+			Help: "A metric with a constant '1' value labeled by version, revision, go_version and platform from which kube-state-metrics was built.",
+		},
+		[]string{"version", "revision", "go_version", "platform"},
+	)
+	buildInfo.WithLabelValues(v.Release, v.GitCommit, v.GoVersion, v.Platform).Set(1)
+	return buildInfo
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewCollector(t *testing.T) {
+	Release, Commit = "v1.9.6", "abcdef0"
+	defer func() { Release, Commit = "UNKNOWN", "UNKNOWN" }()
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(NewCollector())
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].GetName() != "kube_state_metrics_build_info" {
+		t.Fatalf("expected kube_state_metrics_build_info but got %v", families)
+	}
+
+	m := families[0].GetMetric()[0]
+	labels := map[string]string{}
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	if labels["version"] != "v1.9.6" || labels["revision"] != "abcdef0" || labels["go_version"] == "" || labels["platform"] == "" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+	if m.GetGauge().GetValue() != 1 {
+		t.Errorf("expected value 1 but got %v", m.GetGauge().GetValue())
+	}
+}
+
+func TestPrint(t *testing.T) {
+	v := Version{Release: "v1.9.6", GitCommit: "abcdef0"}
+	if got := v.Print(); !strings.HasPrefix(got, "kube-state-metrics, version v1.9.6 (revision: abcdef0)\n") {
+		t.Errorf("unexpected version output %q", got)
+	}
+}
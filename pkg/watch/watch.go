@@ -18,6 +18,7 @@ package watch
 
 import (
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -25,13 +26,19 @@ import (
 )
 
 type ListWatchMetrics struct {
-	WatchTotal *prometheus.CounterVec
-	ListTotal  *prometheus.CounterVec
+	WatchTotal         *prometheus.CounterVec
+	ListTotal          *prometheus.CounterVec
+	ListItemsTotal     *prometheus.CounterVec
+	WatchEventsTotal   *prometheus.CounterVec
+	WatchFailuresTotal *prometheus.CounterVec
 }
 
 // NewListWatchMetrics takes in a prometheus registry and initializes
-// and registers the kube_state_metrics_list_total and
-// kube_state_metrics_watch_total metrics. It returns those registered metrics.
+// and registers the kube_state_metrics_list_total,
+// kube_state_metrics_watch_total, kube_state_metrics_list_items_total,
+// kube_state_metrics_watch_events_total and
+// kube_state_metrics_watch_failures_total metrics. It returns those
+// registered metrics.
 func NewListWatchMetrics(r *prometheus.Registry) *ListWatchMetrics {
 	var m ListWatchMetrics
 	m.WatchTotal = prometheus.NewCounterVec(
@@ -49,10 +56,37 @@ func NewListWatchMetrics(r *prometheus.Registry) *ListWatchMetrics {
 		},
 		[]string{"result", "resource"},
 	)
+
+	m.ListItemsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_list_items_total",
+			Help: "Number of total resource items returned by lists in kube-state-metrics",
+		},
+		[]string{"resource"},
+	)
+
+	m.WatchEventsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_watch_events_total",
+			Help: "Number of total resource watch events by type in kube-state-metrics",
+		},
+		[]string{"type", "resource"},
+	)
+
+	m.WatchFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_state_metrics_watch_failures_total",
+			Help: "Number of total failed resource lists and watches in kube-state-metrics, which are retried with exponential backoff",
+		},
+		[]string{"resource"},
+	)
 	if r != nil {
 		r.MustRegister(
 			m.ListTotal,
 			m.WatchTotal,
+			m.ListItemsTotal,
+			m.WatchEventsTotal,
+			m.WatchFailuresTotal,
 		)
 	}
 	return &m
@@ -83,11 +117,13 @@ func (i *InstrumentedListerWatcher) List(options metav1.ListOptions) (res runtim
 	}
 
 	i.metrics.ListTotal.WithLabelValues("success", i.resource).Inc()
+	i.metrics.ListItemsTotal.WithLabelValues(i.resource).Add(float64(meta.LenList(res)))
 	return
 }
 
 // Watch is a wrapper func around the cache.ListerWatcher.Watch func. It increases the success/error
-// counters based on the outcome of the Watch operation it instruments.
+// counters based on the outcome of the Watch operation it instruments, and counts the events of
+// successful watches by type.
 func (i *InstrumentedListerWatcher) Watch(options metav1.ListOptions) (res watch.Interface, err error) {
 	res, err = i.lw.Watch(options)
 	if err != nil {
@@ -96,5 +132,9 @@ func (i *InstrumentedListerWatcher) Watch(options metav1.ListOptions) (res watch
 	}
 
 	i.metrics.WatchTotal.WithLabelValues("success", i.resource).Inc()
+	res = watch.Filter(res, func(e watch.Event) (watch.Event, bool) {
+		i.metrics.WatchEventsTotal.WithLabelValues(string(e.Type), i.resource).Inc()
+		return e, true
+	})
 	return
 }
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package watch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func counterValue(t *testing.T, r *prometheus.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if labels[l.GetName()] != l.GetValue() {
+					continue metrics
+				}
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestInstrumentedListerWatcher(t *testing.T) {
+	r := prometheus.NewRegistry()
+	fw := watch.NewFake()
+	var err error
+	lw := NewInstrumentedListerWatcher(&cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return &v1.ConfigMapList{Items: make([]v1.ConfigMap, 3)}, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return fw, err
+		},
+	}, NewListWatchMetrics(r), "*v1.ConfigMap")
+
+	if _, err := lw.List(metav1.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	w, err := lw.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		fw.Add(&v1.ConfigMap{})
+		fw.Modify(&v1.ConfigMap{})
+		fw.Modify(&v1.ConfigMap{})
+	}()
+	for i := 0; i < 3; i++ {
+		<-w.ResultChan()
+	}
+	w.Stop()
+
+	err = errors.New("forbidden")
+	lw.List(metav1.ListOptions{})
+	lw.Watch(metav1.ListOptions{})
+
+	resource := "*v1.ConfigMap"
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"kube_state_metrics_list_total", map[string]string{"result": "success", "resource": resource}, 1},
+		{"kube_state_metrics_list_total", map[string]string{"result": "error", "resource": resource}, 1},
+		{"kube_state_metrics_watch_total", map[string]string{"result": "success", "resource": resource}, 1},
+		{"kube_state_metrics_watch_total", map[string]string{"result": "error", "resource": resource}, 1},
+		{"kube_state_metrics_list_items_total", map[string]string{"resource": resource}, 3},
+		{"kube_state_metrics_watch_events_total", map[string]string{"type": "ADDED", "resource": resource}, 1},
+		{"kube_state_metrics_watch_events_total", map[string]string{"type": "MODIFIED", "resource": resource}, 2},
+	}
+	for _, test := range tests {
+		if got := counterValue(t, r, test.name, test.labels); got != test.want {
+			t.Errorf("expected %s%v to be %v but got %v", test.name, test.labels, test.want, got)
+		}
+	}
+}
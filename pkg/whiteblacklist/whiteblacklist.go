@@ -119,6 +119,11 @@ func (l *WhiteBlackList) IsExcluded(item string) bool {
 	return !l.IsIncluded(item)
 }
 
+// IsWhiteList returns if the list is a whitelist, as opposed to a blacklist.
+func (l *WhiteBlackList) IsWhiteList() bool {
+	return l.isWhiteList
+}
+
 // Status returns the status of the WhiteBlackList that can e.g. be passed into
 // a logger.
 func (l *WhiteBlackList) Status() string {
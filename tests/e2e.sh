@@ -17,21 +17,27 @@
 set -e
 set -o pipefail
 
-KUBERNETES_VERSION=v1.17.3
+KUBERNETES_VERSION=v1.17.5
 KUBE_STATE_METRICS_LOG_DIR=./log
 KUBE_STATE_METRICS_IMAGE_NAME='quay.io/coreos/kube-state-metrics'
-PROMETHEUS_VERSION=2.12.0
-E2E_SETUP_MINIKUBE=${E2E_SETUP_MINIKUBE:-}
+E2E_SETUP_KIND=${E2E_SETUP_KIND:-}
 E2E_SETUP_KUBECTL=${E2E_SETUP_KUBECTL:-}
-E2E_SETUP_PROMTOOL=${E2E_SETUP_PROMTOOL:-}
-MINIKUBE_VERSION=v1.3.1
-MINIKUBE_DRIVER=${MINIKUBE_DRIVER:-virtualbox}
+KIND_VERSION=v0.8.1
+KIND_CLUSTER_NAME=${KIND_CLUSTER_NAME:-kube-state-metrics-e2e}
 SUDO=${SUDO:-}
 
 OS=$(uname -s | awk '{print tolower($0)}')
 OS=${OS:-linux}
 
-EXCLUDED_RESOURCE_REGEX="verticalpodautoscaler"
+# all collectors but verticalpodautoscalers, whose CRD is not installed, keep
+# in sync with excludedCollectors in tests/e2e/metrics_test.go
+E2E_COLLECTORS="applications,certificatesigningrequests,configmaps,cronjobs,daemonsets,deployments,endpoints,\
+federatedconfigmaps,federateddeployments,federatedingresses,federatedpersistentvolumeclaims,federatedsecrets,\
+federatedservices,federatedstatefulsets,gateways,horizontalpodautoscalers,ingresses,jobs,kubesphereclusters,\
+limitranges,mutatingwebhookconfigurations,namespaceobjectcounts,namespaces,networkpolicies,nodes,notificationconfigs,notificationreceivers,\
+persistentvolumeclaims,persistentvolumes,pipelineruns,pipelines,poddisruptionbudgets,pods,replicasets,\
+replicationcontrollers,resourcequotas,rulegroups,s2ibuilders,s2iruns,secrets,services,statefulsets,storageclasses,\
+users,validatingwebhookconfigurations,volumeattachments,workspacerolebindings,workspaces"
 
 mkdir -p ${KUBE_STATE_METRICS_LOG_DIR}
 
@@ -39,14 +45,13 @@ function finish() {
     echo "calling cleanup function"
     # kill kubectl proxy in background
     kill %1 || true
-    kubectl delete -f examples/standard/ || true
-    kubectl delete -f tests/manifests/ || true
+    kind delete cluster --name="${KIND_CLUSTER_NAME}" || true
 }
 
-function setup_minikube() {
-    curl -sLo minikube https://storage.googleapis.com/minikube/releases/${MINIKUBE_VERSION}/minikube-"${OS}"-amd64 \
-        && chmod +x minikube \
-        && ${SUDO} mv minikube /usr/local/bin/
+function setup_kind() {
+    curl -sLo kind https://github.com/kubernetes-sigs/kind/releases/download/${KIND_VERSION}/kind-"${OS}"-amd64 \
+        && chmod +x kind \
+        && ${SUDO} mv kind /usr/local/bin/
 }
 
 function setup_kubectl() {
@@ -55,72 +60,36 @@ function setup_kubectl() {
         && ${SUDO} mv kubectl /usr/local/bin/
 }
 
-function setup_promtool() {
-    wget -q -O /tmp/prometheus.tar.gz https://github.com/prometheus/prometheus/releases/download/v${PROMETHEUS_VERSION}/prometheus-${PROMETHEUS_VERSION}."${OS}"-amd64.tar.gz
-    tar zxfv /tmp/prometheus.tar.gz -C /tmp/ prometheus-${PROMETHEUS_VERSION}."${OS}"-amd64/promtool
-    ${SUDO} mv /tmp/prometheus-${PROMETHEUS_VERSION}."${OS}"-amd64/promtool /usr/local/bin/
-    rmdir /tmp/prometheus-${PROMETHEUS_VERSION}."${OS}"-amd64
-    rm /tmp/prometheus.tar.gz
-}
-
-[[ -n "${E2E_SETUP_MINIKUBE}" ]] && setup_minikube
+[[ -n "${E2E_SETUP_KIND}" ]] && setup_kind
 
-minikube version
+kind version
 
 [[ -n "${E2E_SETUP_KUBECTL}" ]] && setup_kubectl
 
-export MINIKUBE_WANTUPDATENOTIFICATION=false
-export MINIKUBE_WANTREPORTERRORPROMPT=false
-export MINIKUBE_HOME=$HOME
-export CHANGE_MINIKUBE_NONE_USER=true
-mkdir "${HOME}"/.kube || true
-touch "${HOME}"/.kube/config
-
-export KUBECONFIG=$HOME/.kube/config
-${SUDO} minikube start --vm-driver="${MINIKUBE_DRIVER}" --kubernetes-version=${KUBERNETES_VERSION} --logtostderr
-
-minikube update-context
-
-set +e
-
-is_kube_running="false"
-
-# this for loop waits until kubectl can access the api server that Minikube has created
-for _ in {1..90}; do # timeout for 3 minutes
-   kubectl get po 1>/dev/null 2>&1
-   if [[ $? -ne 1 ]]; then
-      is_kube_running="true"
-      break
-   fi
-
-   echo "waiting for Kubernetes cluster up"
-   sleep 2
-done
-
-if [[ ${is_kube_running} == "false" ]]; then
-   minikube logs
-   echo "Kubernetes does not start within 3 minutes"
-   exit 1
-fi
+trap finish EXIT
 
-set -e
+kind create cluster --name="${KIND_CLUSTER_NAME}" --image=kindest/node:${KUBERNETES_VERSION} --wait=3m
 
 kubectl version
 
-# ensure that we build docker image in minikube
-[[ "$MINIKUBE_DRIVER" != "none" ]] && eval "$(minikube docker-env)"
-
-# query kube-state-metrics image tag
+# build kube-state-metrics image and load it into the kind nodes
 make container
-docker images -a
-KUBE_STATE_METRICS_IMAGE_TAG=$(docker images -a|grep 'quay.io/coreos/kube-state-metrics'|grep -v 'latest'|awk '{print $2}'|sort -u)
-echo "local kube-state-metrics image tag: $KUBE_STATE_METRICS_IMAGE_TAG"
+KUBE_STATE_METRICS_IMAGE_TAG=v$(cat VERSION)
+KUBE_STATE_METRICS_IMAGE=${KUBE_STATE_METRICS_IMAGE_NAME}-$(go env GOARCH):${KUBE_STATE_METRICS_IMAGE_TAG}
+echo "local kube-state-metrics image: $KUBE_STATE_METRICS_IMAGE"
+kind load docker-image --name="${KIND_CLUSTER_NAME}" "${KUBE_STATE_METRICS_IMAGE}"
 
-# update kube-state-metrics image tag in deployment.yaml
-sed -i.bak "s|${KUBE_STATE_METRICS_IMAGE_NAME}:v.*|${KUBE_STATE_METRICS_IMAGE_NAME}:${KUBE_STATE_METRICS_IMAGE_TAG}|g" ./examples/standard/deployment.yaml
+# update kube-state-metrics image in deployment.yaml
+sed -i.bak "s|${KUBE_STATE_METRICS_IMAGE_NAME}:v.*|${KUBE_STATE_METRICS_IMAGE}|g" ./examples/standard/deployment.yaml
 cat ./examples/standard/deployment.yaml
 
-trap finish EXIT
+# set up the fixtures before kube-state-metrics, so that all of them are
+# listed by the first scrape
+kubectl create -f ./tests/manifests/
+kubectl create -f ./tests/manifests/kubesphere/crds.yaml
+kubectl wait --for condition=established --timeout=1m -f ./tests/manifests/kubesphere/crds.yaml
+kubectl create -f ./tests/manifests/kubesphere/rbac.yaml
+kubectl create -f ./tests/manifests/kubesphere/fixtures.yaml
 
 # set up kube-state-metrics manifests
 kubectl create -f ./examples/standard/service-account.yaml
@@ -129,11 +98,11 @@ kubectl create -f ./examples/standard/cluster-role.yaml
 kubectl create -f ./examples/standard/cluster-role-binding.yaml
 
 kubectl create -f ./examples/standard/deployment.yaml
+kubectl --namespace=kube-system patch deployment kube-state-metrics --type=json \
+    -p "[{\"op\": \"add\", \"path\": \"/spec/template/spec/containers/0/args\", \"value\": [\"--collectors=${E2E_COLLECTORS}\"]}]"
 
 kubectl create -f ./examples/standard/service.yaml
 
-kubectl create -f ./tests/manifests/
-
 echo "make requests to kube-state-metrics"
 
 set +e
@@ -168,21 +137,6 @@ echo "start e2e test for kube-state-metrics"
 KSMURL='http://localhost:8001/api/v1/namespaces/kube-system/services/kube-state-metrics:http-metrics/proxy'
 go test -v ./tests/e2e/ --ksmurl=${KSMURL}
 
-# TODO: re-implement the following test cases in Go with the goal of removing this file.
-echo "access kube-state-metrics metrics endpoint"
-curl -s "http://localhost:8001/api/v1/namespaces/kube-system/services/kube-state-metrics:http-metrics/proxy/metrics" >${KUBE_STATE_METRICS_LOG_DIR}/metrics
-
-echo "check metrics format with promtool"
-[[ -n "${E2E_SETUP_PROMTOOL}" ]] && setup_promtool
-< ${KUBE_STATE_METRICS_LOG_DIR}/metrics promtool check metrics
-
-resources=$(find internal/store/ -maxdepth 1 -name "*.go" -not -name "*_test.go" -not -name "builder.go" -not -name "testutils.go" -not -name "utils.go" -print0 | xargs -0 -n1 basename | awk -F. '{print $1}'| grep -v "$EXCLUDED_RESOURCE_REGEX")
-echo "available resources: $resources"
-for resource in ${resources}; do
-    echo "checking that kube_${resource}* metrics exists"
-    grep "^kube_${resource}_" ${KUBE_STATE_METRICS_LOG_DIR}/metrics
-done
-
 KUBE_STATE_METRICS_STATUS=$(curl -s "http://localhost:8001/api/v1/namespaces/kube-system/services/kube-state-metrics:http-metrics/proxy/healthz")
 if [[ "${KUBE_STATE_METRICS_STATUS}" == "OK" ]]; then
     echo "kube-state-metrics is still running after accessing metrics endpoint"
@@ -17,6 +17,7 @@ limitations under the License.
 package e2e
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -24,6 +25,9 @@ import (
 	"net/url"
 	"path"
 	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 const (
@@ -123,3 +127,15 @@ func (k *KSMClient) metrics(w io.Writer) error {
 
 	return nil
 }
+
+// families returns the metric families scraped from the metrics endpoint by
+// name.
+func (k *KSMClient) families() (map[string]*dto.MetricFamily, error) {
+	buf := &bytes.Buffer{}
+	if err := k.metrics(buf); err != nil {
+		return nil, err
+	}
+
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(buf)
+}
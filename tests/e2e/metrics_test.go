@@ -0,0 +1,308 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/kube-state-metrics/internal/store"
+)
+
+// excludedCollectors are the collectors whose resources are not served by the
+// e2e cluster, see tests/e2e.sh.
+var excludedCollectors = map[string]struct{}{
+	"verticalpodautoscalers": {},
+}
+
+// federatedKinds are the names of the federated collectors' objects in
+// tests/manifests/kubesphere/fixtures.yaml.
+var federatedKinds = []string{
+	"federatedconfigmap",
+	"federateddeployment",
+	"federatedingress",
+	"federatedpersistentvolumeclaim",
+	"federatedsecret",
+	"federatedservice",
+	"federatedstatefulset",
+}
+
+type expectedSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+func TestCollectorsExposeMetrics(t *testing.T) {
+	families, err := framework.KsmClient.families()
+	if err != nil {
+		t.Fatalf("failed to get metrics from kube-state-metrics: %v", err)
+	}
+
+	for _, c := range store.MetricFamilies() {
+		if _, ok := excludedCollectors[c.Collector]; ok {
+			continue
+		}
+
+		exposed := false
+		for _, f := range c.Families {
+			if mf, ok := families[f.Name]; ok && len(mf.Metric) > 0 {
+				exposed = true
+				break
+			}
+		}
+
+		if !exposed {
+			t.Errorf("collector %q exposes no metrics", c.Collector)
+		}
+	}
+}
+
+func TestFixtureMetrics(t *testing.T) {
+	families, err := framework.KsmClient.families()
+	if err != nil {
+		t.Fatalf("failed to get metrics from kube-state-metrics: %v", err)
+	}
+
+	samples := []expectedSample{
+		// tests/manifests
+		{
+			name:   "kube_cronjob_info",
+			labels: map[string]string{"namespace": "default", "cronjob": "cronjob", "schedule": "@hourly", "concurrency_policy": "Allow"},
+			value:  1,
+		},
+		{
+			name:   "kube_persistentvolume_capacity_bytes",
+			labels: map[string]string{"persistentvolume": "persistentvolume"},
+			value:  5 * 1024 * 1024 * 1024,
+		},
+		{
+			name:   "kube_resourcequota",
+			labels: map[string]string{"namespace": "default", "resourcequota": "resourcequota", "resource": "configmaps", "type": "hard"},
+			value:  10,
+		},
+		{
+			name:   "kube_storageclass_info",
+			labels: map[string]string{"storageclass": "storageclass", "provisioner": "kubernetes.io/rbd", "reclaimPolicy": "Delete", "volumeBindingMode": "Immediate"},
+			value:  1,
+		},
+		// tests/manifests/kubesphere
+		{
+			name:   "kube_application_info",
+			labels: map[string]string{"namespace": "e2e-project", "application": "application", "type": "web", "version": "v1"},
+			value:  1,
+		},
+		{
+			name:   "kube_application_spec_assembly_phase",
+			labels: map[string]string{"namespace": "e2e-project", "application": "application", "phase": "Succeeded"},
+			value:  1,
+		},
+		{
+			name:   "kube_application_status_components",
+			labels: map[string]string{"namespace": "e2e-project", "application": "application", "group": "apps", "kind": "Deployment"},
+			value:  1,
+		},
+		{
+			name:   "kube_gateway_info",
+			labels: map[string]string{"namespace": "e2e-project", "gateway": "kubesphere-router-e2e-project", "type": "project", "project": "e2e-project", "service_type": "NodePort"},
+			value:  1,
+		},
+		{
+			name:   "kube_gateway_spec_replicas",
+			labels: map[string]string{"namespace": "e2e-project", "gateway": "kubesphere-router-e2e-project"},
+			value:  2,
+		},
+		{
+			name:   "kube_kubespherecluster_info",
+			labels: map[string]string{"kubespherecluster": "host", "provider": "kind", "connection_type": "direct", "kubernetes_version": "v1.17.5", "kubesphere_version": "v3.0.0"},
+			value:  1,
+		},
+		{
+			name:   "kube_kubespherecluster_status_condition",
+			labels: map[string]string{"kubespherecluster": "host", "condition": "Ready", "status": "true"},
+			value:  1,
+		},
+		{
+			name:   "kube_kubespherecluster_status_nodes",
+			labels: map[string]string{"kubespherecluster": "host"},
+			value:  1,
+		},
+		{
+			name:   "kube_namespace_object_count",
+			labels: map[string]string{"namespace": "e2e-project", "resource": "applications"},
+			value:  1,
+		},
+		{
+			name:   "kube_notificationconfig_channel",
+			labels: map[string]string{"notificationconfig": "notificationconfig", "channel": "email"},
+			value:  1,
+		},
+		{
+			name:   "kube_notificationreceiver_channel_enabled",
+			labels: map[string]string{"notificationreceiver": "notificationreceiver", "channel": "email"},
+			value:  1,
+		},
+		{
+			name:   "kube_notificationreceiver_info",
+			labels: map[string]string{"notificationreceiver": "notificationreceiver", "type": "global"},
+			value:  1,
+		},
+		{
+			name:   "kube_pipeline_info",
+			labels: map[string]string{"namespace": "e2e-project", "pipeline": "pipeline", "type": "pipeline"},
+			value:  1,
+		},
+		{
+			name:   "kube_pipelinerun_duration_seconds",
+			labels: map[string]string{"namespace": "e2e-project", "pipelinerun": "pipelinerun"},
+			value:  300,
+		},
+		{
+			name:   "kube_pipelinerun_info",
+			labels: map[string]string{"namespace": "e2e-project", "pipelinerun": "pipelinerun", "pipeline": "pipeline", "scm_ref": "master"},
+			value:  1,
+		},
+		{
+			name:   "kube_pipelinerun_status_phase",
+			labels: map[string]string{"namespace": "e2e-project", "pipelinerun": "pipelinerun", "phase": "Succeeded"},
+			value:  1,
+		},
+		{
+			name:   "kube_rulegroup_rules",
+			labels: map[string]string{"namespace": "e2e-project", "rulegroup": "rulegroup", "state": "enabled"},
+			value:  1,
+		},
+		{
+			name:   "kube_rulegroup_rules",
+			labels: map[string]string{"namespace": "e2e-project", "rulegroup": "rulegroup", "state": "disabled"},
+			value:  1,
+		},
+		{
+			name:   "kube_s2ibuilder_info",
+			labels: map[string]string{"namespace": "e2e-project", "s2ibuilder": "s2ibuilder", "builder_image": "kubesphere/java-8-centos7:v2.1.0", "image_name": "e2e/hello", "tag": "latest"},
+			value:  1,
+		},
+		{
+			name:   "kube_s2ibuilder_status_runs",
+			labels: map[string]string{"namespace": "e2e-project", "s2ibuilder": "s2ibuilder"},
+			value:  1,
+		},
+		{
+			name:   "kube_s2irun_duration_seconds",
+			labels: map[string]string{"namespace": "e2e-project", "s2irun": "s2irun"},
+			value:  120,
+		},
+		{
+			name:   "kube_s2irun_info",
+			labels: map[string]string{"namespace": "e2e-project", "s2irun": "s2irun", "s2ibuilder": "s2ibuilder", "tag": "v1"},
+			value:  1,
+		},
+		{
+			name:   "kube_s2irun_status_run_state",
+			labels: map[string]string{"namespace": "e2e-project", "s2irun": "s2irun", "state": "Successful"},
+			value:  1,
+		},
+		{
+			name:   "kube_user_status_state",
+			labels: map[string]string{"user": "admin", "state": "Active"},
+			value:  1,
+		},
+		{
+			name:   "kube_workspace_info",
+			labels: map[string]string{"workspace": "e2e-workspace", "manager": "admin", "network_isolation": "true"},
+			value:  1,
+		},
+		{
+			name:   "kube_workspace_member_info",
+			labels: map[string]string{"workspacerolebinding": "admin-e2e-workspace-admin", "workspace": "e2e-workspace", "user": "admin", "role": "e2e-workspace-admin"},
+			value:  1,
+		},
+		{
+			name:   "kube_workspace_namespaces",
+			labels: map[string]string{"workspace": "e2e-workspace"},
+			value:  1,
+		},
+	}
+
+	for _, kind := range federatedKinds {
+		samples = append(samples, expectedSample{
+			name:   "kube_" + kind + "_placement_cluster",
+			labels: map[string]string{"namespace": "e2e-project", kind: kind, "cluster": "host"},
+			value:  1,
+		})
+	}
+
+	for _, s := range samples {
+		m := findSample(families[s.name], s.labels)
+		if m == nil {
+			t.Errorf("missing metric %s", formatSample(s.name, s.labels))
+			continue
+		}
+
+		if v := sampleValue(m); v != s.value {
+			t.Errorf("metric %s: expected value %v, got %v", formatSample(s.name, s.labels), s.value, v)
+		}
+	}
+}
+
+// findSample returns the first metric of the given family carrying all the
+// given labels, or nil if there is none.
+func findSample(mf *dto.MetricFamily, labels map[string]string) *dto.Metric {
+	if mf == nil {
+		return nil
+	}
+
+	for _, m := range mf.Metric {
+		matched := 0
+		for _, l := range m.Label {
+			if v, ok := labels[l.GetName()]; ok && v == l.GetValue() {
+				matched++
+			}
+		}
+
+		if matched == len(labels) {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func sampleValue(m *dto.Metric) float64 {
+	switch {
+	case m.Gauge != nil:
+		return m.Gauge.GetValue()
+	case m.Counter != nil:
+		return m.Counter.GetValue()
+	default:
+		return m.Untyped.GetValue()
+	}
+}
+
+func formatSample(name string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, v))
+	}
+	sort.Strings(pairs)
+
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package synthetic generates the objects of synthetic clusters of a given size,
+used to benchmark kube-state-metrics against clusters of realistic shape
+without a running apiserver.
+*/
+package synthetic
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// Cluster describes the size of a synthetic cluster. Deployments are spread
+// evenly across namespaces and their pods across nodes.
+type Cluster struct {
+	Name                  string
+	Namespaces            int
+	Nodes                 int
+	Deployments           int
+	ReplicasPerDeployment int
+}
+
+// Clusters are the synthetic cluster sizes benchmarks are run against.
+var Clusters = []Cluster{
+	{Name: "small", Namespaces: 5, Nodes: 10, Deployments: 20, ReplicasPerDeployment: 5},
+	{Name: "medium", Namespaces: 20, Nodes: 100, Deployments: 200, ReplicasPerDeployment: 5},
+	{Name: "large", Namespaces: 50, Nodes: 500, Deployments: 1000, ReplicasPerDeployment: 10},
+}
+
+// Pods returns the number of pods of the cluster.
+func (c Cluster) Pods() int {
+	return c.Deployments * c.ReplicasPerDeployment
+}
+
+// NewClientset returns a fake clientset populated with all objects of the
+// cluster.
+func (c Cluster) NewClientset() *fake.Clientset {
+	return fake.NewSimpleClientset(c.Objects()...)
+}
+
+// Objects returns all objects of the cluster.
+func (c Cluster) Objects() []runtime.Object {
+	objs := []runtime.Object{}
+	for _, n := range c.NodeObjects() {
+		objs = append(objs, n)
+	}
+	for _, d := range c.DeploymentObjects() {
+		objs = append(objs, d)
+	}
+	for _, p := range c.PodObjects() {
+		objs = append(objs, p)
+	}
+	return objs
+}
+
+// NodeObjects returns the nodes of the cluster.
+func (c Cluster) NodeObjects() []*v1.Node {
+	nodes := make([]*v1.Node, 0, c.Nodes)
+	for i := 0; i < c.Nodes; i++ {
+		name := fmt.Sprintf("node-%d", i)
+		nodes = append(nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				UID:               types.UID(name),
+				CreationTimestamp: creationTimestamp,
+				Labels: map[string]string{
+					"kubernetes.io/hostname": name,
+					"kubernetes.io/os":       "linux",
+				},
+			},
+			Spec: v1.NodeSpec{
+				ProviderID: "synthetic://" + name,
+			},
+			Status: v1.NodeStatus{
+				Capacity:    nodeResources,
+				Allocatable: nodeResources,
+				Conditions: []v1.NodeCondition{
+					{Type: v1.NodeReady, Status: v1.ConditionTrue},
+					{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse},
+					{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse},
+				},
+				NodeInfo: v1.NodeSystemInfo{
+					KernelVersion:           "5.4.0",
+					OSImage:                 "Ubuntu 18.04",
+					ContainerRuntimeVersion: "docker://19.3.5",
+					KubeletVersion:          "v1.17.0",
+					KubeProxyVersion:        "v1.17.0",
+				},
+			},
+		})
+	}
+	return nodes
+}
+
+// DeploymentObjects returns the deployments of the cluster.
+func (c Cluster) DeploymentObjects() []*appsv1.Deployment {
+	deployments := make([]*appsv1.Deployment, 0, c.Deployments)
+	for i := 0; i < c.Deployments; i++ {
+		name := deploymentName(i)
+		replicas := int32(c.ReplicasPerDeployment)
+		deployments = append(deployments, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         c.namespace(i),
+				UID:               types.UID(c.namespace(i) + "-" + name),
+				CreationTimestamp: creationTimestamp,
+				Generation:        1,
+				Labels:            map[string]string{"app": name},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				Replicas:           replicas,
+				UpdatedReplicas:    replicas,
+				ReadyReplicas:      replicas,
+				AvailableReplicas:  replicas,
+			},
+		})
+	}
+	return deployments
+}
+
+// PodObjects returns the pods of the cluster.
+func (c Cluster) PodObjects() []*v1.Pod {
+	pods := make([]*v1.Pod, 0, c.Pods())
+	for i := 0; i < c.Deployments; i++ {
+		deployment := deploymentName(i)
+		for j := 0; j < c.ReplicasPerDeployment; j++ {
+			name := fmt.Sprintf("%s-%d", deployment, j)
+			node := ""
+			if c.Nodes > 0 {
+				node = fmt.Sprintf("node-%d", (i*c.ReplicasPerDeployment+j)%c.Nodes)
+			}
+			pods = append(pods, &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         c.namespace(i),
+					UID:               types.UID(c.namespace(i) + "-" + name),
+					CreationTimestamp: creationTimestamp,
+					Labels:            map[string]string{"app": deployment},
+					OwnerReferences: []metav1.OwnerReference{
+						{Kind: "ReplicaSet", Name: deployment + "-5d8f7c9b4"},
+					},
+				},
+				Spec: v1.PodSpec{
+					NodeName:      node,
+					RestartPolicy: v1.RestartPolicyAlways,
+					Containers: []v1.Container{
+						{
+							Name:  "app",
+							Image: "registry.example.com/app:1.0.0",
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse("100m"),
+									v1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+								Limits: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse("500m"),
+									v1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					Phase:     v1.PodRunning,
+					HostIP:    "10.0.0.1",
+					PodIP:     "10.1.0.1",
+					StartTime: &creationTimestamp,
+					Conditions: []v1.PodCondition{
+						{Type: v1.PodReady, Status: v1.ConditionTrue},
+						{Type: v1.PodScheduled, Status: v1.ConditionTrue},
+					},
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:        "app",
+							Image:       "registry.example.com/app:1.0.0",
+							ImageID:     "docker://sha256:aaa",
+							ContainerID: "docker://" + name,
+							Ready:       true,
+							State: v1.ContainerState{
+								Running: &v1.ContainerStateRunning{StartedAt: creationTimestamp},
+							},
+						},
+					},
+				},
+			})
+		}
+	}
+	return pods
+}
+
+func (c Cluster) namespace(deployment int) string {
+	if c.Namespaces == 0 {
+		return metav1.NamespaceDefault
+	}
+	return fmt.Sprintf("namespace-%d", deployment%c.Namespaces)
+}
+
+func deploymentName(i int) string {
+	return fmt.Sprintf("deployment-%d", i)
+}
+
+var (
+	creationTimestamp = metav1.Time{Time: time.Unix(1500000000, 0)}
+
+	nodeResources = v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("8"),
+		v1.ResourceMemory: resource.MustParse("32Gi"),
+		v1.ResourcePods:   resource.MustParse("110"),
+	}
+)
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command metricsdocs generates the documentation of the metrics of all
+// collectors from their metric families, as JSON or as Markdown tables.
+//
+//	go run ./tools/metricsdocs --format=markdown
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+// collectorDoc documents the metrics of a collector.
+type collectorDoc struct {
+	Collector string      `json:"collector"`
+	Metrics   []metricDoc `json:"metrics"`
+}
+
+// metricDoc documents a metric family. Its labels are the ones known to be
+// present on all of its metrics.
+type metricDoc struct {
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Help              string   `json:"help"`
+	Stability         string   `json:"stability"`
+	DeprecatedVersion string   `json:"deprecatedVersion,omitempty"`
+	Labels            []string `json:"labels"`
+}
+
+func main() {
+	format := flag.String("format", "markdown", "Output format, either json or markdown.")
+	flag.Parse()
+
+	docs := collectorDocs(store.MetricFamilies())
+
+	var err error
+	switch *format {
+	case "json":
+		err = writeJSON(os.Stdout, docs)
+	case "markdown":
+		err = writeMarkdown(os.Stdout, docs)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func collectorDocs(collectors []store.CollectorFamilies) []collectorDoc {
+	docs := make([]collectorDoc, 0, len(collectors))
+
+	for _, c := range collectors {
+		doc := collectorDoc{Collector: c.Collector, Metrics: []metricDoc{}}
+
+		familyLabels := make([][]string, len(c.Families))
+		var identity []string
+		for i, f := range c.Families {
+			familyLabels[i] = labels(f, c.ExpectedType)
+			if len(familyLabels[i]) > 0 {
+				identity = intersect(identity, familyLabels[i])
+			}
+		}
+
+		for i, f := range c.Families {
+			// Families without metrics for empty objects carry at least
+			// the labels identifying the object, which all others share.
+			if len(familyLabels[i]) == 0 && identity != nil {
+				familyLabels[i] = identity
+			}
+
+			stability := metric.Stable
+			if f.StabilityLevel != "" {
+				stability = f.StabilityLevel
+			}
+			doc.Metrics = append(doc.Metrics, metricDoc{
+				Name:              f.Name,
+				Type:              string(f.Type),
+				Help:              f.Help,
+				Stability:         string(stability),
+				DeprecatedVersion: f.DeprecatedVersion,
+				Labels:            familyLabels[i],
+			})
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs
+}
+
+// labels returns the labels of the metrics generated by the given family for
+// an empty object of the expected type. These comprise at least the labels
+// identifying the object, while labels of optional metrics may be missing.
+func labels(f metric.FamilyGenerator, expectedType interface{}) (keys []string) {
+	keys = []string{}
+	defer func() {
+		// Generating metrics for incomplete objects is not guaranteed to
+		// succeed.
+		if recover() != nil {
+			keys = []string{}
+		}
+	}()
+
+	obj := reflect.New(reflect.TypeOf(expectedType).Elem()).Interface()
+	seen := map[string]struct{}{}
+	for _, m := range f.Generate(obj).Metrics {
+		for _, k := range m.LabelKeys {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return keys
+}
+
+// intersect returns the labels of b which are in a as well, or b if a is nil.
+func intersect(a, b []string) []string {
+	if a == nil {
+		return b
+	}
+
+	keys := []string{}
+	for _, k := range b {
+		for _, l := range a {
+			if k == l {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	return keys
+}
+
+func writeJSON(w io.Writer, docs []collectorDoc) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(docs)
+}
+
+func writeMarkdown(w io.Writer, docs []collectorDoc) error {
+	b := strings.Builder{}
+	for i, doc := range docs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s\n\n", doc.Collector)
+		b.WriteString("| Metric name | Metric type | Labels/tags | Status | Description |\n")
+		b.WriteString("| ----------- | ----------- | ----------- | ------ | ----------- |\n")
+		for _, m := range doc.Metrics {
+			labels := make([]string, len(m.Labels))
+			for i, l := range m.Labels {
+				labels[i] = "`" + l + "`"
+			}
+			status := m.Stability
+			if m.DeprecatedVersion != "" {
+				status = "DEPRECATED"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", m.Name, strings.Title(m.Type), strings.Join(labels, " <br> "), status, strings.Replace(m.Help, "|", "\\|", -1))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/kube-state-metrics/internal/store"
+	"k8s.io/kube-state-metrics/pkg/metric"
+)
+
+func TestCollectorDocs(t *testing.T) {
+	collectors := []store.CollectorFamilies{
+		{
+			Collector:    "configmaps",
+			ExpectedType: &v1.ConfigMap{},
+			Families: []metric.FamilyGenerator{
+				{
+					Name: "kube_configmap_info",
+					Type: metric.Gauge,
+					Help: "Information about configmap.",
+					GenerateFunc: func(obj interface{}) *metric.Family {
+						cm := obj.(*v1.ConfigMap)
+						return &metric.Family{Metrics: []*metric.Metric{
+							{LabelKeys: []string{"namespace", "configmap"}, LabelValues: []string{cm.Namespace, cm.Name}, Value: 1},
+						}}
+					},
+				},
+				{
+					Name:              "kube_configmap_data",
+					Type:              metric.Gauge,
+					Help:              "Keys of the configmap | data.",
+					StabilityLevel:    metric.Experimental,
+					DeprecatedVersion: "1.9.0",
+					GenerateFunc: func(obj interface{}) *metric.Family {
+						cm := obj.(*v1.ConfigMap)
+						ms := []*metric.Metric{}
+						for k := range cm.Data {
+							ms = append(ms, &metric.Metric{LabelKeys: []string{"namespace", "configmap", "key"}, LabelValues: []string{cm.Namespace, cm.Name, k}, Value: 1})
+						}
+						return &metric.Family{Metrics: ms}
+					},
+				},
+				{
+					Name: "kube_configmap_broken",
+					Type: metric.Gauge,
+					Help: "Panics for empty objects.",
+					GenerateFunc: func(obj interface{}) *metric.Family {
+						panic("unexpected empty object")
+					},
+				},
+			},
+		},
+	}
+
+	docs := collectorDocs(collectors)
+
+	want := []collectorDoc{
+		{
+			Collector: "configmaps",
+			Metrics: []metricDoc{
+				{Name: "kube_configmap_info", Type: "gauge", Help: "Information about configmap.", Stability: "STABLE", Labels: []string{"namespace", "configmap"}},
+				{Name: "kube_configmap_data", Type: "gauge", Help: "Keys of the configmap | data.", Stability: "EXPERIMENTAL", DeprecatedVersion: "1.9.0", Labels: []string{"namespace", "configmap"}},
+				{Name: "kube_configmap_broken", Type: "gauge", Help: "Panics for empty objects.", Stability: "STABLE", Labels: []string{"namespace", "configmap"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("expected docs\n%+v\nbut got\n%+v", want, docs)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeJSON(buf, docs); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []collectorDoc
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("expected JSON to decode to\n%+v\nbut got\n%+v", want, decoded)
+	}
+
+	buf.Reset()
+	if err := writeMarkdown(buf, docs); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range []string{
+		"| kube_configmap_info | Gauge | `namespace` <br> `configmap` | STABLE | Information about configmap. |",
+		"| kube_configmap_data | Gauge | `namespace` <br> `configmap` | DEPRECATED | Keys of the configmap \\| data. |",
+	} {
+		if !strings.Contains(buf.String(), row) {
+			t.Errorf("expected Markdown to contain\n%s\nbut got\n%s", row, buf.String())
+		}
+	}
+}
+
+func TestAllCollectorsDocumented(t *testing.T) {
+	for _, doc := range collectorDocs(store.MetricFamilies()) {
+		if len(doc.Metrics) == 0 {
+			t.Errorf("expected metrics for collector %s", doc.Collector)
+		}
+		for _, m := range doc.Metrics {
+			if m.Name == "" || m.Type == "" || m.Help == "" {
+				t.Errorf("collector %s: expected name, type and help of metric %+v", doc.Collector, m)
+			}
+		}
+	}
+}